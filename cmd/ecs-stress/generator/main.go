@@ -19,12 +19,14 @@ const (
 func main() {
 	componentCount := flag.Int("components", 250, "number of components to generate")
 	systemCount := flag.Int("systems", 50, "number of systems to generate")
+	seed := flag.Int64("seed", 1, "seed for the generated component and system layouts")
 	flag.Parse()
 
-	log.Printf("Generating %d components and %d systems...\n", *componentCount, *systemCount)
+	rng := rand.New(rand.NewSource(*seed))
+	log.Printf("Generating %d components and %d systems (seed %d)...\n", *componentCount, *systemCount, *seed)
 
-	components := generateComponentData(*componentCount)
-	systems := generateSystemData(components, *systemCount)
+	components := generateComponentData(rng, *componentCount)
+	systems := generateSystemData(rng, components, *systemCount)
 
 	generateFile(componentsTemplate, components, componentsFile)
 	generateFile(systemsTemplate, systems, systemsFile)
@@ -88,14 +90,14 @@ var fieldTypes = []struct {
 	{"[32]byte", 32},
 }
 
-func generateComponentData(count int) []Component {
+func generateComponentData(rng *rand.Rand, count int) []Component {
 	components := make([]Component, count)
 	for i := 0; i < count; i++ {
-		numFields := rand.Intn(8) + 1
+		numFields := rng.Intn(8) + 1
 		fields := make([]Field, numFields)
 		totalSize := 0
 		for j := 0; j < numFields; j++ {
-			fieldType := fieldTypes[rand.Intn(len(fieldTypes))]
+			fieldType := fieldTypes[rng.Intn(len(fieldTypes))]
 			fields[j] = Field{
 				Name: fmt.Sprintf("Field%d", j),
 				Type: fieldType.Type,
@@ -116,11 +118,10 @@ var componentsTemplate = template.Must(template.New("components").Parse(`// Code
 
 package main
 
-import (
-	"math/rand"
+import "github.com/plus3/ooftn/ecs"
 
-	"github.com/plus3/ooftn/ecs"
-)
+// GeneratedComponentCount is the number of generated component types
+const GeneratedComponentCount = {{len .}}
 
 {{range .}}
 type {{.Name}} struct {
@@ -136,18 +137,15 @@ func RegisterAllGeneratedComponents(registry *ecs.ComponentRegistry) {
 {{- end}}
 }
 
-func SpawnRandomEntity(storage *ecs.Storage, numComponents int) {
-	components := make([]any, numComponents)
-	for i := 0; i < numComponents; i++ {
-		componentID := rand.Intn({{len .}})
-		switch componentID {
-		{{- range .}}
-		case {{.ID}}:
-			components[i] = {{.Name}}{}
-		{{- end}}
-		}
+// NewGeneratedComponent returns a zero value of the generated component with the given id
+func NewGeneratedComponent(componentID int) any {
+	switch componentID {
+	{{- range .}}
+	case {{.ID}}:
+		return {{.Name}}{}
+	{{- end}}
 	}
-	storage.Spawn(components...)
+	panic("unknown generated component id")
 }
 `))
 
@@ -159,7 +157,7 @@ type System struct {
 	Components []Component
 }
 
-func generateSystemData(components []Component, count int) []System {
+func generateSystemData(rng *rand.Rand, components []Component, count int) []System {
 	systems := make([]System, count)
 	if len(components) < 2 {
 		return systems
@@ -167,12 +165,12 @@ func generateSystemData(components []Component, count int) []System {
 
 	for i := 0; i < count; i++ {
 		// Pick 1 to 4 components for the query
-		numComps := rand.Intn(4) + 1
+		numComps := rng.Intn(4) + 1
 		queryComps := make([]Component, 0, numComps)
 		compSet := make(map[int]struct{})
 
 		for k := 0; k < numComps; k++ {
-			compIdx := rand.Intn(len(components))
+			compIdx := rng.Intn(len(components))
 			if _, exists := compSet[compIdx]; !exists {
 				queryComps = append(queryComps, components[compIdx])
 				compSet[compIdx] = struct{}{}
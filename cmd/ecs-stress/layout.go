@@ -0,0 +1,123 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"slices"
+	"strings"
+
+	"github.com/plus3/ooftn/ecs"
+)
+
+// Layout controls how components are distributed across the initial entities
+type Layout string
+
+const (
+	// LayoutRandom gives every entity a random set of components
+	LayoutRandom Layout = "random"
+	// LayoutFragmented gives every entity a distinct set of components where possible,
+	// creating as many archetypes as the entity count allows
+	LayoutFragmented Layout = "fragmented"
+	// LayoutWide gives every entity the same set of max-width components, producing a
+	// single archetype of wide entities
+	LayoutWide Layout = "wide"
+)
+
+func parseLayout(value string) (Layout, error) {
+	switch layout := Layout(value); layout {
+	case LayoutRandom, LayoutFragmented, LayoutWide:
+		return layout, nil
+	}
+	return "", fmt.Errorf("unknown layout %q (expected random, fragmented or wide)", value)
+}
+
+// Populator spawns the initial entities according to a layout and width distribution.
+// All randomness comes from the provided rng so runs with the same seed are identical.
+type Populator struct {
+	rng      *rand.Rand
+	layout   Layout
+	minWidth int
+	maxWidth int
+	seen     map[string]struct{}
+}
+
+func NewPopulator(rng *rand.Rand, layout Layout, minWidth, maxWidth int) (*Populator, error) {
+	if minWidth < 1 || maxWidth < minWidth {
+		return nil, fmt.Errorf("invalid entity width range %d..%d", minWidth, maxWidth)
+	}
+	if maxWidth > GeneratedComponentCount {
+		return nil, fmt.Errorf("max entity width %d exceeds the %d generated components", maxWidth, GeneratedComponentCount)
+	}
+
+	return &Populator{
+		rng:      rng,
+		layout:   layout,
+		minWidth: minWidth,
+		maxWidth: maxWidth,
+		seen:     make(map[string]struct{}),
+	}, nil
+}
+
+// Spawn creates a single entity and returns its component count
+func (p *Populator) Spawn(storage *ecs.Storage) int {
+	ids := p.componentIDs()
+
+	components := make([]any, len(ids))
+	for i, id := range ids {
+		components[i] = NewGeneratedComponent(id)
+	}
+	storage.SpawnSlice(components)
+	return len(ids)
+}
+
+func (p *Populator) componentIDs() []int {
+	switch p.layout {
+	case LayoutWide:
+		ids := make([]int, p.maxWidth)
+		for i := range ids {
+			ids[i] = i
+		}
+		return ids
+
+	case LayoutFragmented:
+		// Retry a bounded number of times to find a component set no other entity has
+		const attempts = 16
+		var ids []int
+		for range attempts {
+			ids = p.randomIDs()
+			key := archetypeKey(ids)
+			if _, exists := p.seen[key]; !exists {
+				p.seen[key] = struct{}{}
+				break
+			}
+		}
+		return ids
+	}
+
+	return p.randomIDs()
+}
+
+// randomIDs picks a random width in the configured range and that many distinct components
+func (p *Populator) randomIDs() []int {
+	width := p.minWidth + p.rng.Intn(p.maxWidth-p.minWidth+1)
+
+	ids := make([]int, 0, width)
+	for len(ids) < width {
+		id := p.rng.Intn(GeneratedComponentCount)
+		if !slices.Contains(ids, id) {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+func archetypeKey(ids []int) string {
+	sorted := slices.Clone(ids)
+	slices.Sort(sorted)
+
+	var key strings.Builder
+	for _, id := range sorted {
+		fmt.Fprintf(&key, "%d,", id)
+	}
+	return key.String()
+}
@@ -1,6 +1,6 @@
 package main
 
-//go:generate go run github.com/plus3/ooftn/cmd/ecs-stress/generator -components=250 -systems=50
+//go:generate go run github.com/plus3/ooftn/cmd/ecs-stress/generator -components=250 -systems=50 -seed=1
 
 import (
 	"context"
@@ -28,8 +28,18 @@ func main() {
 	entityCount := flag.Int("entities", 10000, "The initial number of entities to create.")
 	gcPauseMetrics := flag.Bool("gc-pause-metrics", false, "Enable detailed GC pause metrics in the report.")
 	pprofAddr := flag.String("pprof", "", "Address to listen on for pprof server (e.g., ':6060')")
+	frameBudget := flag.Duration("frame-budget", 16600*time.Microsecond, "Frame time budget used to count overruns (0 disables).")
+	seed := flag.Int64("seed", 0, "Seed for entity population (0 picks a time-based seed, which is printed in the report).")
+	layoutFlag := flag.String("layout", string(LayoutRandom), "Component layout: random, fragmented (max archetype fragmentation) or wide (one archetype of max-width entities).")
+	minWidth := flag.Int("min-width", 1, "Minimum number of components per entity.")
+	maxWidth := flag.Int("max-width", 5, "Maximum number of components per entity.")
 	flag.Parse()
 
+	layout, err := parseLayout(*layoutFlag)
+	if err != nil {
+		log.Fatal(err)
+	}
+
 	if *pprofAddr != "" {
 		log.Printf("Starting pprof HTTP server on %s", *pprofAddr)
 		go func() {
@@ -40,7 +50,15 @@ func main() {
 	}
 
 	log.Println("Starting ECS stress test...")
-	rand.Seed(time.Now().UnixNano())
+	if *seed == 0 {
+		*seed = time.Now().UnixNano()
+	}
+	log.Printf("Using seed %d\n", *seed)
+
+	populator, err := NewPopulator(rand.New(rand.NewSource(*seed)), layout, *minWidth, *maxWidth)
+	if err != nil {
+		log.Fatal(err)
+	}
 
 	// 1. Setup Registry, Storage, and Scheduler
 	registry := ecs.NewComponentRegistry()
@@ -50,26 +68,42 @@ func main() {
 	RegisterAllGeneratedSystems(scheduler)
 
 	// 2. Populate Storage with initial entities
-	log.Printf("Populating storage with %d entities...\n", *entityCount)
+	log.Printf("Populating storage with %d entities (%s layout)...\n", *entityCount, layout)
+	totalWidth := 0
 	for i := 0; i < *entityCount; i++ {
-		// Spawn an entity with 1 to 5 random components
-		numComponents := rand.Intn(5) + 1
-		SpawnRandomEntity(storage, numComponents)
+		totalWidth += populator.Spawn(storage)
 	}
 	log.Println("Population complete.")
 
 	// 3. Run the simulation loop
 	report := &Report{
-		Duration:       *duration,
-		Entities:       *entityCount,
-		Components:     componentCount,
-		Systems:        systemCount,
-		GCPauseMetrics: *gcPauseMetrics,
+		Duration:         *duration,
+		Entities:         *entityCount,
+		Components:       componentCount,
+		Systems:          systemCount,
+		Seed:             *seed,
+		Layout:           layout,
+		MinWidth:         *minWidth,
+		MaxWidth:         *maxWidth,
+		ArchetypeCount:   len(storage.GetArchetypes()),
+		GCPauseMetrics:   *gcPauseMetrics,
+		FrameBudget:      *frameBudget,
+		OverrunsBySystem: make(map[string]int),
 		UpdateTime: Stats{
 			Samples: make([]time.Duration, 0),
 		},
 	}
 
+	scheduler.SetFrameBudget(*frameBudget)
+	scheduler.OnBudgetExceeded(func(frameDuration time.Duration, worst ecs.SystemStats) {
+		report.BudgetOverruns++
+		report.OverrunsBySystem[worst.Name]++
+	})
+
+	if *entityCount > 0 {
+		report.AvgEntityWidth = float64(totalWidth) / float64(*entityCount)
+	}
+
 	runtime.ReadMemStats(&report.MemStatsStart)
 
 	log.Printf("Running simulation for %s...\n", *duration)
@@ -101,6 +135,7 @@ Loop:
 	report.TotalTime = time.Since(startTime)
 	report.TotalUpdates = totalUpdates
 	report.UpdateTime.Finalize()
+	report.CollectSystemTimings(scheduler.GetStats())
 	runtime.ReadMemStats(&report.MemStatsEnd)
 
 	log.Println("Simulation finished.")
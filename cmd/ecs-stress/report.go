@@ -4,24 +4,63 @@ import (
 	"fmt"
 	"io"
 	"runtime"
+	"sort"
 	"text/template"
 	"time"
+
+	"github.com/plus3/ooftn/ecs"
 )
 
 type Report struct {
 	// Configuration
-	Duration   time.Duration
-	Entities   int
-	Components int
-	Systems    int
+	Duration    time.Duration
+	Entities    int
+	Components  int
+	Systems     int
+	FrameBudget time.Duration
+	Seed        int64
+	Layout      Layout
+	MinWidth    int
+	MaxWidth    int
+
+	// World shape
+	ArchetypeCount int
+	AvgEntityWidth float64
 
 	// Results
-	TotalUpdates   int64
-	TotalTime      time.Duration
-	UpdateTime     Stats
-	GCPauseMetrics bool
-	MemStatsStart  runtime.MemStats
-	MemStatsEnd    runtime.MemStats
+	TotalUpdates     int64
+	TotalTime        time.Duration
+	UpdateTime       Stats
+	BudgetOverruns   int
+	OverrunsBySystem map[string]int
+	SystemTimings    []SystemTiming
+	GCPauseMetrics   bool
+	MemStatsStart    runtime.MemStats
+	MemStatsEnd      runtime.MemStats
+}
+
+// SystemTiming is the per-system execution time captured at the end of a run
+type SystemTiming struct {
+	Name  string
+	Avg   time.Duration
+	Max   time.Duration
+	Total time.Duration
+}
+
+// CollectSystemTimings records per-system timings, slowest systems first
+func (r *Report) CollectSystemTimings(stats *ecs.SchedulerStats) {
+	r.SystemTimings = make([]SystemTiming, 0, len(stats.Systems))
+	for _, sys := range stats.Systems {
+		r.SystemTimings = append(r.SystemTimings, SystemTiming{
+			Name:  sys.Name,
+			Avg:   sys.AvgDuration,
+			Max:   sys.MaxDuration,
+			Total: sys.TotalDuration,
+		})
+	}
+	sort.Slice(r.SystemTimings, func(i, j int) bool {
+		return r.SystemTimings[i].Total > r.SystemTimings[j].Total
+	})
 }
 
 type Stats struct {
@@ -61,6 +100,12 @@ func (r *Report) Generate(w io.Writer) error {
 - **Initial Entities:** {{.Entities}}
 - **Generated Components:** {{.Components}}
 - **Generated Systems:** {{.Systems}}
+- **Seed:** {{.Seed}}
+- **Layout:** {{.Layout}} ({{.MinWidth}}-{{.MaxWidth}} components per entity)
+
+## World Shape
+- **Archetypes:** {{.ArchetypeCount}}
+- **Avg Entity Width:** {{printf "%.2f" .AvgEntityWidth}} components
 
 ## Performance Results
 - **Total Updates:** {{.TotalUpdates}}
@@ -70,6 +115,17 @@ func (r *Report) Generate(w io.Writer) error {
   - **Min:** {{.UpdateTime.Min}}
   - **Max:** {{.UpdateTime.Max}}
 
+## System Timings (slowest first)
+| System | Avg | Max | Total |
+|---|---|---|---|
+{{range .SystemTimings}}| {{.Name}} | {{.Avg}} | {{.Max}} | {{.Total}} |
+{{end}}
+{{if .FrameBudget}}
+## Frame Budget ({{.FrameBudget}})
+- **Overruns:** {{.BudgetOverruns}}
+{{range $name, $count := .OverrunsBySystem}}  - **{{$name}}:** {{$count}} (worst system)
+{{end}}{{end}}
+
 ## Memory Usage (Raw Bytes)
 - Heap Alloc:     {{.MemStatsStart.HeapAlloc}} (start) -> {{.MemStatsEnd.HeapAlloc}} (end) -> delta: {{bsub .MemStatsEnd.HeapAlloc .MemStatsStart.HeapAlloc}}
 - Total Alloc:    {{.MemStatsStart.TotalAlloc}} (start) -> {{.MemStatsEnd.TotalAlloc}} (end) -> delta: {{bsub .MemStatsEnd.TotalAlloc .MemStatsStart.TotalAlloc}}
@@ -0,0 +1,63 @@
+package ecs
+
+import "cmp"
+
+// Number is the constraint of the values Sum and Avg fold
+type Number interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr |
+		~float32 | ~float64
+}
+
+// Sum returns the sum of value over every entity matched by a View or Query, e.g. the total
+// amount of all resources:
+//
+//	total := ecs.Sum(&resources, func(r ResourceView) int { return r.Resource.Amount })
+//
+// A View is matched on demand, while a Query reuses its cached archetype matches.
+func Sum[T any, N Number](source entitySource[T], value func(T) N) N {
+	var sum N
+	for _, item := range source.iterEntities() {
+		sum += value(item)
+	}
+	return sum
+}
+
+// Min returns the smallest value over every entity matched by a View or Query, or false if
+// no entity matches
+func Min[T any, N cmp.Ordered](source entitySource[T], value func(T) N) (N, bool) {
+	return fold(source, value, func(a, b N) bool { return a < b })
+}
+
+// Max returns the largest value over every entity matched by a View or Query, or false if
+// no entity matches
+func Max[T any, N cmp.Ordered](source entitySource[T], value func(T) N) (N, bool) {
+	return fold(source, value, func(a, b N) bool { return a > b })
+}
+
+// Avg returns the mean of value over every entity matched by a View or Query, or false if no
+// entity matches. Values are summed as float64, so integer values don't overflow or truncate.
+func Avg[T any, N Number](source entitySource[T], value func(T) N) (float64, bool) {
+	var sum float64
+	count := 0
+	for _, item := range source.iterEntities() {
+		sum += float64(value(item))
+		count++
+	}
+	if count == 0 {
+		return 0, false
+	}
+	return sum / float64(count), true
+}
+
+// fold returns the value for which better holds against every other value
+func fold[T any, N cmp.Ordered](source entitySource[T], value func(T) N, better func(a, b N) bool) (N, bool) {
+	var best N
+	found := false
+	for _, item := range source.iterEntities() {
+		if v := value(item); !found || better(v, best) {
+			best, found = v, true
+		}
+	}
+	return best, found
+}
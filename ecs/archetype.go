@@ -2,6 +2,7 @@ package ecs
 
 import (
 	"reflect"
+	"slices"
 	"weak"
 
 	"github.com/kamstrup/intmap"
@@ -12,7 +13,7 @@ type byTypeName []reflect.Type
 
 func (a byTypeName) Len() int           { return len(a) }
 func (a byTypeName) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
-func (a byTypeName) Less(i, j int) bool { return a[i].String() < a[j].String() }
+func (a byTypeName) Less(i, j int) bool { return TypeName(a[i]) < TypeName(a[j]) }
 
 // Archetype represents a unique combination of component types
 type Archetype struct {
@@ -22,48 +23,112 @@ type Archetype struct {
 	refs     *intmap.Map[EntityId, weak.Pointer[EntityRef]]
 
 	typeSet *intsets.Sparse
+	mask    ComponentMask
+
+	// disabled is a bitset of slots holding disabled entities, see Storage.SpawnDisabled
+	disabled      []uint64
+	disabledCount int
+
+	// births holds the birth order of the entity in each slot, see Storage.BirthOrder
+	births []uint64
+
+	// typeNames holds the TypeNames of the component types in ascending order, see compareArchetypes
+	typeNames []string
+
+	// version changes whenever an entity is added, removed, moved within the archetype or
+	// enabled or disabled, so results cached by Query.Len can tell they are stale
+	version uint64
 }
 
 // NewArchetype creates a new archetype with the given ID and sorted component types
 func NewArchetype(id uint32, types []reflect.Type, registry *ComponentRegistry) *Archetype {
+	slots := registry.slotConfigFor(types)
+	if len(registry.priorities) > 0 {
+		types = slices.Clone(types)
+		slices.SortStableFunc(types, func(a, b reflect.Type) int {
+			return registry.priorities[b] - registry.priorities[a]
+		})
+	}
+
 	a := &Archetype{
-		id:       id,
-		types:    types,
-		storages: make([]iComponentStorage, len(types)),
-		refs:     intmap.New[EntityId, weak.Pointer[EntityRef]](256),
-		typeSet:  &intsets.Sparse{},
+		id:        id,
+		types:     types,
+		storages:  make([]iComponentStorage, len(types)),
+		refs:      intmap.New[EntityId, weak.Pointer[EntityRef]](256),
+		typeSet:   &intsets.Sparse{},
+		typeNames: make([]string, len(types)),
 	}
 
 	// Initialize storage for each component type
 	for idx, typ := range types {
+		a.typeNames[idx] = TypeName(typ)
 		a.typeSet.Insert(typeId(typ))
 		factory := registry.getFactory(typ)
 		if factory == nil {
 			panic("component type " + typ.String() + " not registered")
 		}
-		a.storages[idx] = factory()
+		a.storages[idx] = factory(slots)
 	}
+	a.mask = NewComponentMask(registry, types...)
+	slices.Sort(a.typeNames)
 
 	return a
 }
 
+// compareArchetypes orders archetypes by the TypeNames of their component types, which unlike
+// archetype ids are the same in every run of a program
+func compareArchetypes(a, b *Archetype) int {
+	return slices.Compare(a.typeNames, b.typeNames)
+}
+
 // Spawn creates a new entity in this archetype with the given components
 // Returns the storage position as the entity index
 func (a *Archetype) Spawn(components []any) uint32 {
 	var storagePos int
 	for _, comp := range components {
+		// A pointer is either a reference component itself or points to the component
 		compType := reflect.TypeOf(comp)
-		if compType.Kind() == reflect.Ptr {
-			compType = compType.Elem()
+		idx := slices.Index(a.types, compType)
+		if idx == -1 && compType.Kind() == reflect.Ptr {
+			idx = slices.Index(a.types, compType.Elem())
+		}
+		if idx != -1 {
+			storagePos = a.storages[idx].Append(comp)
 		}
+	}
+
+	a.version++
+	return uint32(storagePos)
+}
 
-		for idx, typ := range a.types {
-			if typ == compType {
-				storagePos = a.storages[idx].Append(comp)
+// migrate copies the entity at srcIndex in src into this archetype and returns its new index.
+// Components whose types exist in both archetypes are copied storage-to-storage; the
+// optional extra component fills the one type not present in src. Components of src
+// that this archetype lacks are dropped. The source entity is left untouched.
+func (a *Archetype) migrate(src *Archetype, srcIndex uint32, extra any) uint32 {
+	var storagePos int
+	for idx, typ := range a.types {
+		srcIdx := -1
+		for i, srcTyp := range src.types {
+			if srcTyp == typ {
+				srcIdx = i
+				break
 			}
 		}
+
+		if srcIdx == -1 {
+			storagePos = a.storages[idx].Append(extra)
+		} else {
+			storagePos = a.storages[idx].AppendFrom(src.storages[srcIdx], int(srcIndex))
+		}
+		if storagePos == -1 {
+			panic("cannot migrate entity: no valid " + typ.String() + " component to copy")
+		}
 	}
 
+	a.setDisabled(storagePos, src.isDisabled(int(srcIndex)))
+	a.setBirth(storagePos, src.birth(int(srcIndex)))
+	a.version++
 	return uint32(storagePos)
 }
 
@@ -93,7 +158,7 @@ func (a *Archetype) Delete(entityIndex uint32) {
 	if ok {
 		// Update the EntityRef to mark it as deleted
 		if ref := weakPtr.Value(); ref != nil {
-			ref.Id = 0
+			ref.Id = InvalidEntityId
 			ref.Archetype = nil
 		}
 		a.refs.Del(entityId)
@@ -102,6 +167,40 @@ func (a *Archetype) Delete(entityIndex uint32) {
 	for _, storage := range a.storages {
 		storage.Delete(int(entityIndex))
 	}
+	a.setDisabled(int(entityIndex), false)
+	a.setBirth(int(entityIndex), 0)
+	a.version++
+}
+
+// vacate empties an entity's slots after its components were migrated to another archetype.
+// Only the dropped component type (if any) is disposed; all others are now owned by the new archetype.
+// EntityRefs are expected to have been moved by the caller.
+func (a *Archetype) vacate(entityIndex uint32, dropped reflect.Type) {
+	for idx, storage := range a.storages {
+		if a.types[idx] == dropped {
+			storage.Delete(int(entityIndex))
+		} else {
+			storage.Vacate(int(entityIndex))
+		}
+	}
+	a.setDisabled(int(entityIndex), false)
+	a.setBirth(int(entityIndex), 0)
+	a.version++
+}
+
+// storageFor returns the component storage for the given type, or nil if the archetype doesn't have it
+func (a *Archetype) storageFor(compType reflect.Type) iComponentStorage {
+	for idx, typ := range a.types {
+		if typ == compType {
+			return a.storages[idx]
+		}
+	}
+	return nil
+}
+
+// has reports whether the slot at entityIndex is occupied by a live entity
+func (a *Archetype) has(entityIndex uint32) bool {
+	return len(a.storages) > 0 && a.storages[0].Has(int(entityIndex))
 }
 
 // HasComponent checks if this archetype has the given component type
@@ -109,29 +208,66 @@ func (a *Archetype) HasComponent(compType reflect.Type) bool {
 	return a.typeSet.Has(typeId(compType))
 }
 
+// hasExactTypes reports whether the archetype holds exactly the given distinct component types
+func (a *Archetype) hasExactTypes(types []reflect.Type) bool {
+	if len(types) != len(a.types) {
+		return false
+	}
+	for _, t := range types {
+		if !a.typeSet.Has(typeId(t)) {
+			return false
+		}
+	}
+	return true
+}
+
 // ID returns the archetype's unique identifier
 func (a *Archetype) ID() uint32 {
 	return a.id
 }
 
-// Types returns the sorted component types for this archetype
+// Types returns the component types for this archetype in storage order: sorted by type
+// name, or by descending priority when component priorities are set.
 func (a *Archetype) Types() []reflect.Type {
 	return a.types
 }
 
+// Mask returns the component mask for this archetype.
+// The returned mask is shared and must not be modified.
+func (a *Archetype) Mask() ComponentMask {
+	return a.mask
+}
+
 // Compact reorganizes all component storage to eliminate empty slots and reduce fragmentation
-// EntityRefs remain valid and are automatically updated to point to the new indices
+// EntityRefs remain valid and are automatically updated to point to the new indices. Compacting
+// an archetype without free slots is a cheap no-op that keeps every id, so periodically
+// compacting every archetype mostly costs the archetypes that need it.
 func (a *Archetype) Compact() {
-	if len(a.storages) == 0 {
-		return
+	a.compact()
+}
+
+// compact compacts the archetype and returns the mapping of old to new slot indices, or nil
+// if no entity moved
+func (a *Archetype) compact() map[int]int {
+	// All storages share the same slot layout, so the first one tells whether any slot is free
+	if len(a.storages) == 0 || a.storages[0].dense() {
+		return nil
 	}
 
 	// Compact the first storage and use it as the canonical index mapping
-	indexMap := a.storages[0].Compact()
+	indexMap, moved := a.storages[0].Compact()
 	for i := 1; i < len(a.storages); i++ {
 		a.storages[i].Compact()
 	}
 
+	// All storages share the same slot layout, so if nothing moved the refs are still valid
+	if !moved {
+		return nil
+	}
+	a.version++
+	a.remapDisabled(indexMap)
+	a.remapBirths(indexMap)
+
 	// Update EntityRefs to point to new indices and clean up dead weak pointers
 	// First, update all the refs and collect the mappings
 	updatedRefs := make(map[EntityId]weak.Pointer[EntityRef])
@@ -155,9 +291,26 @@ func (a *Archetype) Compact() {
 	for newEntityId, weakPtr := range updatedRefs {
 		a.refs.Put(newEntityId, weakPtr)
 	}
+	return indexMap
+}
+
+// emptySlots returns the number of free slots below the archetype's highest used slot
+func (a *Archetype) emptySlots() int {
+	if len(a.storages) == 0 {
+		return 0
+	}
+	return a.storages[0].freeSlotCount()
+}
+
+// Len returns the number of live entities in this archetype without iterating them
+func (a *Archetype) Len() int {
+	if len(a.storages) == 0 {
+		return 0
+	}
+	return a.storages[0].Len()
 }
 
-// Iter returns an iterator over all valid EntityIds in this archetype
+// Iter returns an iterator over all valid EntityIds in this archetype, including disabled entities
 func (a *Archetype) Iter() func(yield func(EntityId) bool) {
 	return func(yield func(EntityId) bool) {
 		if len(a.storages) == 0 {
@@ -0,0 +1,39 @@
+package ecs
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestArchetypeMigratePanics(t *testing.T) {
+	registry := NewComponentRegistry()
+	RegisterComponent[int](registry)
+	RegisterComponent[string](registry)
+	storage := NewStorage(registry)
+
+	expectPanic := func(t *testing.T, contains string, fn func()) {
+		t.Helper()
+		defer func() {
+			t.Helper()
+			if r := recover(); !strings.Contains(fmt.Sprint(r), contains) {
+				t.Errorf("expected a panic naming %q, got %v", contains, r)
+			}
+		}()
+		fn()
+	}
+
+	id := storage.Spawn(1)
+	src := storage.ArchetypeOf(id)
+	dst := storage.ArchetypeOf(storage.Spawn(2, "dst"))
+
+	t.Run("missing source component", func(t *testing.T) {
+		deleted := storage.Spawn(3)
+		storage.Delete(deleted)
+		expectPanic(t, "int", func() { dst.migrate(src, deleted.Index(), "extra") })
+	})
+
+	t.Run("invalid extra component", func(t *testing.T) {
+		expectPanic(t, "string", func() { dst.migrate(src, id.Index(), 3.5) })
+	})
+}
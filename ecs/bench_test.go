@@ -1,7 +1,9 @@
 package ecs_test
 
 import (
+	"math/rand/v2"
 	"reflect"
+	"runtime"
 	"testing"
 
 	"github.com/plus3/ooftn/ecs"
@@ -89,6 +91,50 @@ func BenchmarkRemoveComponent(b *testing.B) {
 	}
 }
 
+func spawnWide(storage *ecs.Storage) ecs.EntityId {
+	return storage.Spawn(
+		Position{X: 1.0, Y: 2.0},
+		Velocity{DX: 0.5, DY: 0.5},
+		Health{Current: 100, Max: 100},
+		Name("Entity"),
+		AI{},
+		Score(10),
+		Tag("wide"),
+		Temperature(20.5),
+		Inventory{},
+	)
+}
+
+func BenchmarkAddComponentWide(b *testing.B) {
+	registry := newTestRegistry()
+	storage := ecs.NewStorage(registry)
+
+	ids := make([]ecs.EntityId, b.N)
+	for i := 0; i < b.N; i++ {
+		ids[i] = spawnWide(storage)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		storage.AddComponent(ids[i], Stats{})
+	}
+}
+
+func BenchmarkRemoveComponentWide(b *testing.B) {
+	registry := newTestRegistry()
+	storage := ecs.NewStorage(registry)
+
+	ids := make([]ecs.EntityId, b.N)
+	for i := 0; i < b.N; i++ {
+		ids[i] = spawnWide(storage)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		storage.RemoveComponent(ids[i], reflect.TypeOf(Velocity{}))
+	}
+}
+
 func BenchmarkEntityRef(b *testing.B) {
 	registry := newTestRegistry()
 	storage := ecs.NewStorage(registry)
@@ -271,6 +317,37 @@ func BenchmarkQueryIter(b *testing.B) {
 	}
 }
 
+func BenchmarkQueryForEachChunk(b *testing.B) {
+	storage := ecs.NewStorage(newTestRegistry())
+	for i := 0; i < 10000; i++ {
+		storage.Spawn(Position{X: float32(i), Y: float32(i)}, Velocity{DX: 0.5, DY: 0.5})
+	}
+	type PosVel struct {
+		*Position
+		*Velocity
+	}
+	query := ecs.NewQuery[PosVel](storage)
+
+	var sum float32
+	b.Run("iter", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			for pv := range query.Iter() {
+				sum += pv.Position.X
+			}
+		}
+	})
+	b.Run("chunks", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			query.ForEachChunk(func(chunk ecs.ViewChunk[PosVel]) {
+				for j := range chunk.Len() {
+					sum += chunk.At(j).Position.X
+				}
+			})
+		}
+	})
+	_ = sum
+}
+
 func BenchmarkQueryIterLarge(b *testing.B) {
 	registry := newTestRegistry()
 	storage := ecs.NewStorage(registry)
@@ -294,6 +371,92 @@ func BenchmarkQueryIterLarge(b *testing.B) {
 	}
 }
 
+func BenchmarkQueryExecuteIntoFirstFrame(b *testing.B) {
+	registry := newTestRegistry()
+	storage := ecs.NewStorage(registry)
+
+	type PosVel struct {
+		*Position
+		*Velocity
+	}
+
+	for i := 0; i < 10000; i++ {
+		storage.Spawn(Position{X: float32(i), Y: float32(i)}, Velocity{DX: 0.5, DY: 0.5})
+	}
+
+	query := ecs.NewQuery[PosVel](storage)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var results []PosVel
+		query.ExecuteInto(&results)
+	}
+}
+
+func BenchmarkQueryExecuteIntoSteadyState(b *testing.B) {
+	registry := newTestRegistry()
+	storage := ecs.NewStorage(registry)
+
+	type PosVel struct {
+		*Position
+		*Velocity
+	}
+
+	for i := 0; i < 10000; i++ {
+		storage.Spawn(Position{X: float32(i), Y: float32(i)}, Velocity{DX: 0.5, DY: 0.5})
+	}
+
+	query := ecs.NewQuery[PosVel](storage)
+
+	var results []PosVel
+	query.ExecuteInto(&results)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		results = results[:0]
+		query.ExecuteInto(&results)
+	}
+}
+
+func BenchmarkQueryMovement(b *testing.B) {
+	registry := newTestRegistry()
+	storage := ecs.NewStorage(registry)
+
+	for i := 0; i < 10000; i++ {
+		storage.Spawn(Position{X: float32(i), Y: float32(i)}, Velocity{DX: 0.5, DY: 0.5})
+	}
+
+	query := ecs.NewQuery[struct {
+		*Position
+		*Velocity
+	}](storage)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for item := range query.Iter() {
+			item.Position.X += item.Velocity.DX
+			item.Position.Y += item.Velocity.DY
+		}
+	}
+}
+
+func BenchmarkIterMut2Movement(b *testing.B) {
+	registry := newTestRegistry()
+	storage := ecs.NewStorage(registry)
+
+	for i := 0; i < 10000; i++ {
+		storage.Spawn(Position{X: float32(i), Y: float32(i)}, Velocity{DX: 0.5, DY: 0.5})
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ecs.IterMut2(storage, func(id ecs.EntityId, pos *Position, vel *Velocity) {
+			pos.X += vel.DX
+			pos.Y += vel.DY
+		})
+	}
+}
+
 type benchMovementSystem struct {
 	Entities ecs.Query[struct {
 		*Position
@@ -356,3 +519,183 @@ func BenchmarkSchedulerMultipleSystems(b *testing.B) {
 		scheduler.Once(0.016)
 	}
 }
+
+func BenchmarkComponentViewIter(b *testing.B) {
+	registry := newTestRegistry()
+	storage := ecs.NewStorage(registry)
+
+	for i := 0; i < 10000; i++ {
+		storage.Spawn(Position{X: float32(i), Y: float32(i)}, Velocity{DX: 0.5, DY: 0.5})
+	}
+
+	view := ecs.NewComponentView[Position](storage)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, pos := range view.Iter() {
+			pos.X += 1
+		}
+	}
+}
+
+func BenchmarkViewIterLargeComponentPriority(b *testing.B) {
+	type PosVel struct {
+		*Position
+		*Velocity
+	}
+
+	for _, prioritized := range []bool{false, true} {
+		name := "name order"
+		if prioritized {
+			name = "prioritized"
+		}
+
+		b.Run(name, func(b *testing.B) {
+			registry := newTestRegistry()
+			if prioritized {
+				ecs.SetComponentPriority[Position](registry, 2)
+				ecs.SetComponentPriority[Velocity](registry, 1)
+			}
+			storage := ecs.NewStorage(registry)
+
+			for i := 0; i < 10000; i++ {
+				storage.Spawn(
+					Position{X: float32(i), Y: float32(i)},
+					Velocity{DX: 0.5, DY: 0.5},
+					Health{Current: 100, Max: 100},
+					AI{State: i},
+					Name("entity"),
+					Inventory{},
+				)
+			}
+
+			view := ecs.NewView[PosVel](storage)
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				for pv := range view.Iter() {
+					pv.Position.X += pv.Velocity.DX
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkIncrementalGridUpdate(b *testing.B) {
+	registry := newTestRegistry()
+	storage := ecs.NewStorage(registry)
+
+	ids := make([]ecs.EntityId, 10000)
+	for i := range ids {
+		ids[i] = storage.Spawn(Position{X: float32(i % 1000), Y: float32(i / 10)})
+	}
+
+	grid := ecs.NewIncrementalGrid(storage, 10, gridPosition)
+	grid.Update()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		// Move 1% of the entities each frame
+		for j := 0; j < 100; j++ {
+			ecs.ReadComponent[Position](storage, ids[(i*100+j)%len(ids)]).X += 7
+		}
+		grid.Update()
+	}
+}
+
+// BenchmarkViewIterChurn iterates an archetype that shrank from 20000 to 5000 entities and
+// then saw heavy churn, comparing how densely each slot policy leaves the live entities
+func BenchmarkViewIterChurn(b *testing.B) {
+	type PosVel struct {
+		*Position
+		*Velocity
+	}
+
+	for _, policy := range []struct {
+		name   string
+		policy ecs.SlotPolicy
+	}{
+		{"lifo", ecs.SlotReuseLIFO},
+		{"lowest", ecs.SlotReuseLowest},
+	} {
+		b.Run(policy.name, func(b *testing.B) {
+			registry := newTestRegistry()
+			registry.SetSlotPolicy(policy.policy)
+			storage := ecs.NewStorage(registry)
+			rng := rand.New(rand.NewPCG(1, 2))
+
+			live := make([]ecs.EntityId, 0, 20000)
+			for i := 0; i < 20000; i++ {
+				live = append(live, storage.Spawn(Position{X: float32(i)}, Velocity{}))
+			}
+			deleteRandom := func(n int) {
+				for range n {
+					i := rng.IntN(len(live))
+					storage.Delete(live[i])
+					live[i] = live[len(live)-1]
+					live = live[:len(live)-1]
+				}
+			}
+
+			deleteRandom(15000)
+			for range 200 {
+				deleteRandom(500)
+				for range 500 {
+					live = append(live, storage.Spawn(Position{}, Velocity{}))
+				}
+			}
+
+			view := ecs.NewView[PosVel](storage)
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				for pv := range view.Iter() {
+					_ = pv
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkStorageKind(b *testing.B) {
+	const entities = 100_000
+
+	for _, kind := range []struct {
+		name string
+		kind ecs.StorageKind
+	}{
+		{"blocks", ecs.StorageBlocks},
+		{"bitset", ecs.StorageBitset},
+	} {
+		b.Run(kind.name, func(b *testing.B) {
+			registry := ecs.NewComponentRegistry()
+			ecs.RegisterComponentWith[Position](registry, kind.kind)
+			ecs.RegisterComponentWith[Velocity](registry, kind.kind)
+
+			var before, after runtime.MemStats
+			runtime.GC()
+			runtime.ReadMemStats(&before)
+			storage := ecs.NewStorage(registry)
+			storage.Reserve(entities, Position{}, Velocity{})
+			for i := 0; i < entities; i++ {
+				storage.Spawn(Position{X: float32(i)}, Velocity{DX: 1})
+			}
+			runtime.GC()
+			runtime.ReadMemStats(&after)
+
+			view := ecs.NewView[struct {
+				*Position
+				*Velocity
+			}](storage)
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				for item := range view.Iter() {
+					item.Position.X += item.Velocity.DX
+				}
+			}
+			b.ReportMetric(float64(int64(after.HeapAlloc)-int64(before.HeapAlloc))/entities, "heap-B/entity")
+			runtime.KeepAlive(storage)
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package ecs
+
+// BirthOrder returns the entity's birth order, a sequence number assigned when it was
+// spawned that increases with every spawn in the storage. It is stable for the entity's
+// lifetime: it is carried over when the entity moves to another archetype, including through
+// ReplaceComponents, and when its archetype is compacted, and it is never reused by a later
+// entity, even one spawned into the same slot. Unlike the entity's id it makes a
+// deterministic sort key, e.g. to decide which entity of a pair acts first or to order
+// entities by age. Returns 0 if the entity doesn't exist.
+func (s *Storage) BirthOrder(id EntityId) uint64 {
+	archetype := s.ArchetypeOf(id)
+	if archetype == nil {
+		return 0
+	}
+	return archetype.birth(int(id.Index()))
+}
+
+// LastBirthOrder returns the birth order of the most recently spawned entity, or 0 if no
+// entity was spawned yet. Entities whose BirthOrder is greater than a value recorded earlier
+// were spawned after it was recorded, e.g. during the current frame.
+func (s *Storage) LastBirthOrder() uint64 {
+	return s.births
+}
+
+// assignBirth gives the entity spawned at index in archetype the next birth order
+func (s *Storage) assignBirth(archetype *Archetype, index uint32) {
+	s.births++
+	archetype.setBirth(int(index), s.births)
+}
+
+func (a *Archetype) birth(index int) uint64 {
+	if index >= len(a.births) {
+		return 0
+	}
+	return a.births[index]
+}
+
+func (a *Archetype) setBirth(index int, birth uint64) {
+	if index >= len(a.births) {
+		if birth == 0 {
+			return
+		}
+		a.births = append(a.births, make([]uint64, index+1-len(a.births))...)
+	}
+	a.births[index] = birth
+}
+
+// remapBirths moves birth orders to their new slots after compaction
+func (a *Archetype) remapBirths(indexMap map[int]int) {
+	old := a.births
+	a.births = nil
+	for oldIdx, newIdx := range indexMap {
+		if oldIdx < len(old) {
+			a.setBirth(newIdx, old[oldIdx])
+		}
+	}
+}
@@ -0,0 +1,220 @@
+package ecs
+
+import (
+	"fmt"
+	"iter"
+	"math/bits"
+	"slices"
+)
+
+// bitsetComponentStorage is an iComponentStorage that keeps components of type T in a single
+// growable slice and tracks filled slots in a bitset, one bit per slot. See StorageBitset.
+type bitsetComponentStorage[T any] struct {
+	values     []T
+	filled     []uint64
+	disposable bool
+	slotAllocator
+}
+
+// Append adds a component to storage and returns its index.
+func (cs *bitsetComponentStorage[T]) Append(item any) int {
+	var concreteItem T
+	if ptr, ok := item.(*T); ok {
+		concreteItem = *ptr
+	} else if val, ok := item.(T); ok {
+		concreteItem = val
+	} else {
+		return -1 // Invalid type
+	}
+
+	return cs.appendValue(concreteItem)
+}
+
+// AppendFrom copies the component at index in src into this storage and returns its new index.
+// When src holds the same component type the value is copied directly without boxing.
+func (cs *bitsetComponentStorage[T]) AppendFrom(src iComponentStorage, index int) int {
+	typed, ok := src.(*bitsetComponentStorage[T])
+	if !ok {
+		return cs.Append(src.Get(index))
+	}
+	if !typed.Has(index) {
+		return -1
+	}
+	return cs.appendValue(typed.values[index])
+}
+
+// appendValue stores a value in the next free slot and returns its index.
+func (cs *bitsetComponentStorage[T]) appendValue(concreteItem T) int {
+	index := cs.allocate()
+	if index >= len(cs.values) {
+		cs.values = append(cs.values, concreteItem)
+	} else {
+		cs.values[index] = concreteItem
+	}
+
+	word := index / 64
+	if word >= len(cs.filled) {
+		cs.filled = append(cs.filled, 0)
+	}
+	cs.filled[word] |= 1 << (index % 64)
+	return index
+}
+
+// Get returns a pointer to the component at the given index.
+func (cs *bitsetComponentStorage[T]) Get(index int) any {
+	if !cs.Has(index) {
+		return nil
+	}
+	return &cs.values[index]
+}
+
+// Delete disposes of the component (if it implements Disposer) and marks its slot as empty.
+func (cs *bitsetComponentStorage[T]) Delete(index int) {
+	cs.clearSlot(index, cs.disposable)
+}
+
+// Vacate marks a component slot as empty without disposing of the component.
+// It is used once the value has been copied into another storage.
+func (cs *bitsetComponentStorage[T]) Vacate(index int) {
+	cs.clearSlot(index, false)
+}
+
+func (cs *bitsetComponentStorage[T]) clearSlot(index int, dispose bool) {
+	if !cs.Has(index) {
+		return
+	}
+
+	if dispose {
+		any(&cs.values[index]).(Disposer).Dispose()
+	}
+	cs.filled[index/64] &^= 1 << (index % 64)
+	var zero T
+	cs.values[index] = zero // Zero out the value
+	cs.freeSlot(index)
+}
+
+// Has checks if a component exists at the given index.
+func (cs *bitsetComponentStorage[T]) Has(index int) bool {
+	if index < 0 || index/64 >= len(cs.filled) {
+		return false
+	}
+	return cs.filled[index/64]&(1<<(index%64)) != 0
+}
+
+// Len returns the number of live components in O(1).
+func (cs *bitsetComponentStorage[T]) Len() int {
+	return cs.nextIndex - len(cs.freeSlots)
+}
+
+// Reserve grows the value slice so that count more components can be appended without
+// reallocating it.
+func (cs *bitsetComponentStorage[T]) Reserve(count int) {
+	needed := cs.growthFor(count)
+	if needed <= 0 {
+		return
+	}
+
+	cs.values = slices.Grow(cs.values, max(cs.nextIndex+needed-len(cs.values), 0))
+	cs.filled = slices.Grow(cs.filled, max((cs.nextIndex+needed+63)/64-len(cs.filled), 0))
+}
+
+// clone returns a copy of the storage with the same slots filled. Components are copied by
+// value, so anything they point to is shared with the original.
+func (cs *bitsetComponentStorage[T]) clone() iComponentStorage {
+	return &bitsetComponentStorage[T]{
+		values:        slices.Clone(cs.values),
+		filled:        slices.Clone(cs.filled),
+		disposable:    cs.disposable,
+		slotAllocator: cs.cloneSlots(),
+	}
+}
+
+// Compact moves every component to the front of the storage in index order. It returns the
+// mapping of old to new indices and whether any slot moved; when the storage is already
+// dense it returns early with a nil map and false.
+func (cs *bitsetComponentStorage[T]) Compact() (map[int]int, bool) {
+	if len(cs.freeSlots) == 0 {
+		return nil, false
+	}
+
+	indexMap := make(map[int]int, cs.Len())
+	writePos := 0
+	for readIdx := range cs.Iter() {
+		indexMap[readIdx] = writePos
+		cs.values[writePos] = cs.values[readIdx]
+		writePos++
+	}
+
+	clear(cs.values[writePos:])
+	cs.values = cs.values[:writePos]
+	clear(cs.filled)
+	cs.filled = cs.filled[:(writePos+63)/64]
+	for word := range writePos / 64 {
+		cs.filled[word] = ^uint64(0)
+	}
+	if rest := writePos % 64; rest != 0 {
+		cs.filled[writePos/64] = 1<<rest - 1
+	}
+	cs.resetSlots(writePos)
+
+	return indexMap, true
+}
+
+func (cs *bitsetComponentStorage[T]) Iter() iter.Seq[int] {
+	return cs.IterFrom(0)
+}
+
+// IterFrom iterates the filled slots with an index of at least start, skipping a whole word
+// of empty slots at a time
+func (cs *bitsetComponentStorage[T]) IterFrom(start int) iter.Seq[int] {
+	return func(yield func(int) bool) {
+		start = max(start, 0)
+		for word := start / 64; word < len(cs.filled); word++ {
+			mask := cs.filled[word]
+			if word == start/64 {
+				mask &^= 1<<(start%64) - 1
+			}
+			for mask != 0 {
+				index := word*64 + bits.TrailingZeros64(mask)
+				if !yield(index) {
+					return
+				}
+				mask &= mask - 1
+			}
+		}
+	}
+}
+
+// checkConsistency verifies that the filled bits, free list and next index agree
+func (cs *bitsetComponentStorage[T]) checkConsistency() error {
+	if cs.nextIndex > len(cs.values) {
+		return fmt.Errorf("next index %d exceeds capacity %d", cs.nextIndex, len(cs.values))
+	}
+
+	filledCount := 0
+	for index := range cs.Iter() {
+		if index >= cs.nextIndex {
+			return fmt.Errorf("slot %d is filled beyond next index %d", index, cs.nextIndex)
+		}
+		filledCount++
+	}
+
+	free := make(map[int]bool, len(cs.freeSlots))
+	for _, index := range cs.freeSlots {
+		if index < 0 || index >= cs.nextIndex {
+			return fmt.Errorf("free slot %d is outside 0..%d", index, cs.nextIndex)
+		}
+		if free[index] {
+			return fmt.Errorf("free slot %d is listed twice", index)
+		}
+		if cs.Has(index) {
+			return fmt.Errorf("free slot %d is filled", index)
+		}
+		free[index] = true
+	}
+
+	if filledCount+len(cs.freeSlots) != cs.nextIndex {
+		return fmt.Errorf("%d filled and %d free slots do not account for next index %d", filledCount, len(cs.freeSlots), cs.nextIndex)
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package ecs
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestBitsetComponentStorage(t *testing.T) {
+	newStorage := func(n int) *bitsetComponentStorage[int] {
+		cs := &bitsetComponentStorage[int]{}
+		for i := range n {
+			cs.Append(i)
+		}
+		return cs
+	}
+
+	t.Run("iterates filled slots", func(t *testing.T) {
+		cs := newStorage(200)
+		for i := range 200 {
+			if i%3 != 0 && i != 130 {
+				cs.Delete(i)
+			}
+		}
+
+		var got []int
+		for index := range cs.IterFrom(60) {
+			got = append(got, index)
+		}
+		var want []int
+		for i := 60; i < 200; i++ {
+			if i%3 == 0 || i == 130 {
+				want = append(want, i)
+			}
+		}
+		if !slices.Equal(got, want) {
+			t.Errorf("expected %v, got %v", want, got)
+		}
+		if cs.Len() != 68 {
+			t.Errorf("expected 68 components, got %d", cs.Len())
+		}
+		if err := cs.checkConsistency(); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("reuses freed slots", func(t *testing.T) {
+		cs := newStorage(10)
+		cs.Delete(4)
+		if index := cs.Append(42); index != 4 {
+			t.Errorf("expected freed slot 4 to be reused, got %d", index)
+		}
+		if got := *cs.Get(4).(*int); got != 42 {
+			t.Errorf("expected 42, got %d", got)
+		}
+	})
+
+	t.Run("compact", func(t *testing.T) {
+		cs := newStorage(130)
+		if _, moved := cs.Compact(); moved {
+			t.Error("expected dense storage to report no moves")
+		}
+
+		for i := 0; i < 130; i += 2 {
+			cs.Delete(i)
+		}
+		indexMap, moved := cs.Compact()
+		if !moved || len(indexMap) != 65 || indexMap[129] != 64 {
+			t.Fatalf("unexpected compaction %v, %v", moved, indexMap)
+		}
+		for i := range 65 {
+			if got := *cs.Get(i).(*int); got != 2*i+1 {
+				t.Errorf("expected slot %d to hold %d, got %d", i, 2*i+1, got)
+			}
+		}
+		if cs.Has(65) {
+			t.Error("expected slots past the last component to be empty")
+		}
+		if err := cs.checkConsistency(); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("reserve", func(t *testing.T) {
+		cs := &bitsetComponentStorage[int]{}
+		cs.Reserve(1000)
+		values := cap(cs.values)
+		for i := range 1000 {
+			cs.Append(i)
+		}
+		if cap(cs.values) != values {
+			t.Errorf("expected no reallocation, capacity changed from %d to %d", values, cap(cs.values))
+		}
+	})
+}
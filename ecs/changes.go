@@ -0,0 +1,185 @@
+package ecs
+
+import (
+	"reflect"
+	"slices"
+	"weak"
+)
+
+// entityChanges records the component types added to and removed from an entity during a flush
+type entityChanges struct {
+	added   []reflect.Type
+	removed []reflect.Type
+}
+
+// changeTracker records structural changes made during a command flush so views using
+// the `ecs:"added"` and `ecs:"removed"` tags can react to them during the next frame.
+// Entries are keyed by the entity's current id and follow the entity as it moves between
+// archetypes. Tracking is only enabled while a view that needs it exists.
+type changeTracker struct {
+	// users holds weak handles to the views needing the changes, see changeUser
+	users     []weak.Pointer[changeUser]
+	recording bool
+	current   map[EntityId]*entityChanges
+	last      map[EntityId]*entityChanges
+	// suspended holds the changes recorded by Commands.FlushNow until the next flush
+	suspended map[EntityId]*entityChanges
+
+	// marks holds the sequence number of the latest Storage.MarkChanged call for each entity
+	// that some query hasn't applied yet, see markReader
+	marks       map[EntityId]uint64
+	markSeq     uint64
+	markReaders []weak.Pointer[markReader]
+}
+
+// changeUser is held by each view using the `ecs:"added"` or `ecs:"removed"` tags. The storage
+// only holds it weakly, so tracking stops once every such view has been garbage collected.
+type changeUser struct {
+	_ byte // zero-size allocations share an address, so weak pointers to them never expire
+}
+
+// trackChanges enables change tracking for as long as user is reachable
+func (s *Storage) trackChanges(user *changeUser) {
+	s.changes.users = append(s.changes.users, weak.Make(user))
+}
+
+// tracked reports whether any view still needs the recorded changes, dropping the handles of
+// the views that were garbage collected
+func (c *changeTracker) tracked() bool {
+	c.users = slices.DeleteFunc(c.users, func(user weak.Pointer[changeUser]) bool {
+		return user.Value() == nil
+	})
+	return len(c.users) > 0
+}
+
+// beginChanges starts recording structural changes for a flush. It returns false if
+// tracking is disabled or a flush is already being recorded (e.g. a nested flush).
+func (s *Storage) beginChanges() bool {
+	if s.changes.recording {
+		return false
+	}
+	if !s.changes.tracked() {
+		// Drop the changes kept for views that no longer exist
+		s.changes.last = nil
+		s.changes.suspended = nil
+		return false
+	}
+	s.changes.recording = true
+	s.changes.current = s.changes.suspended
+	s.changes.suspended = nil
+	if s.changes.current == nil {
+		s.changes.current = make(map[EntityId]*entityChanges)
+	}
+	return true
+}
+
+// endChanges finishes recording and makes the recorded changes visible to views
+func (s *Storage) endChanges() {
+	s.changes.last = s.changes.current
+	s.changes.current = nil
+	s.changes.recording = false
+}
+
+// suspendChanges stops recording without making the recorded changes visible, so the next
+// flush continues recording where this one stopped
+func (s *Storage) suspendChanges() {
+	s.changes.suspended = s.changes.current
+	s.changes.current = nil
+	s.changes.recording = false
+}
+
+func (s *Storage) entryFor(id EntityId) *entityChanges {
+	entry, ok := s.changes.current[id]
+	if !ok {
+		entry = &entityChanges{}
+		s.changes.current[id] = entry
+	}
+	return entry
+}
+
+func (s *Storage) recordAdded(id EntityId, types ...reflect.Type) {
+	if !s.changes.recording {
+		return
+	}
+	entry := s.entryFor(id)
+	for _, t := range types {
+		if i := slices.Index(entry.removed, t); i != -1 {
+			entry.removed = slices.Delete(entry.removed, i, i+1)
+			continue
+		}
+		entry.added = append(entry.added, t)
+	}
+}
+
+func (s *Storage) recordRemoved(id EntityId, t reflect.Type) {
+	if !s.changes.recording {
+		return
+	}
+	entry := s.entryFor(id)
+	if i := slices.Index(entry.added, t); i != -1 {
+		entry.added = slices.Delete(entry.added, i, i+1)
+		return
+	}
+	entry.removed = append(entry.removed, t)
+}
+
+func (s *Storage) recordMoved(oldId, newId EntityId) {
+	if seq, ok := s.changes.marks[oldId]; ok {
+		delete(s.changes.marks, oldId)
+		s.changes.marks[newId] = seq
+	}
+	if !s.changes.recording {
+		return
+	}
+	if entry, ok := s.changes.current[oldId]; ok {
+		delete(s.changes.current, oldId)
+		s.changes.current[newId] = entry
+	}
+}
+
+func (s *Storage) recordDeleted(id EntityId) {
+	delete(s.changes.marks, id)
+	if !s.changes.recording {
+		return
+	}
+	delete(s.changes.current, id)
+}
+
+// recordCompacted moves the recorded changes and marks of an archetype's entities to the
+// ids they were given by compacting it
+func (s *Storage) recordCompacted(archetypeId uint32, indexMap map[int]int) {
+	s.changes.last = remapCompacted(s.changes.last, archetypeId, indexMap)
+	s.changes.suspended = remapCompacted(s.changes.suspended, archetypeId, indexMap)
+	s.changes.marks = remapCompacted(s.changes.marks, archetypeId, indexMap)
+}
+
+// remapCompacted rekeys the entries of entities in the compacted archetype. Old and new ids
+// overlap, so every moved entry is taken out before any is put back.
+func remapCompacted[V any](entries map[EntityId]V, archetypeId uint32, indexMap map[int]int) map[EntityId]V {
+	moved := make(map[EntityId]V)
+	for id, entry := range entries {
+		if id.ArchetypeId() != archetypeId {
+			continue
+		}
+		if newIndex, ok := indexMap[int(id.Index())]; ok {
+			moved[NewEntityId(archetypeId, uint32(newIndex))] = entry
+		}
+		delete(entries, id)
+	}
+	for id, entry := range moved {
+		entries[id] = entry
+	}
+	return entries
+}
+
+// wasAdded reports whether the component type was added to the entity during the last flush
+func (s *Storage) wasAdded(id EntityId, t reflect.Type) bool {
+	entry, ok := s.changes.last[id]
+	return ok && slices.Contains(entry.added, t)
+}
+
+// wasRemoved reports whether the component type was removed from the entity during the last flush
+func (s *Storage) wasRemoved(id EntityId, t reflect.Type) bool {
+	entry, ok := s.changes.last[id]
+	return ok && slices.Contains(entry.removed, t)
+}
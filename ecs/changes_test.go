@@ -0,0 +1,31 @@
+package ecs
+
+import (
+	"runtime"
+	"testing"
+)
+
+func TestChangeTrackingRelease(t *testing.T) {
+	registry := NewComponentRegistry()
+	RegisterComponent[int](registry)
+	storage := NewStorage(registry)
+	commands := newCommands()
+
+	view := NewView[struct {
+		Value *int `ecs:"added"`
+	}](storage)
+	commands.Spawn(1)
+	commands.Flush(storage)
+	if len(storage.changes.last) != 1 || view.Count() != 1 {
+		t.Errorf("expected the spawn to be tracked while the view exists, got %v", storage.changes.last)
+	}
+
+	// Once the view is collected, the storage stops tracking and drops the recorded changes
+	view = nil
+	runtime.GC()
+	commands.Spawn(2)
+	commands.Flush(storage)
+	if len(storage.changes.users) != 0 || storage.changes.last != nil {
+		t.Errorf("expected tracking to stop, got %d users and changes %v", len(storage.changes.users), storage.changes.last)
+	}
+}
@@ -0,0 +1,74 @@
+package ecs
+
+import "iter"
+
+// ViewChunk holds the matching entities of one archetype, with indexed access for splitting
+// the work of a system across goroutines or processing entities in batches. See
+// View.ForEachChunk.
+type ViewChunk[T any] struct {
+	archetype *Archetype
+	ids       []EntityId
+	items     []T
+}
+
+// Archetype returns the archetype the chunk's entities belong to
+func (c ViewChunk[T]) Archetype() *Archetype {
+	return c.archetype
+}
+
+// Len returns the number of entities in the chunk
+func (c ViewChunk[T]) Len() int {
+	return len(c.items)
+}
+
+// At returns the populated view struct of the i-th entity of the chunk
+func (c ViewChunk[T]) At(i int) T {
+	return c.items[i]
+}
+
+// Id returns the id of the i-th entity of the chunk
+func (c ViewChunk[T]) Id(i int) EntityId {
+	return c.ids[i]
+}
+
+// ForEachChunk calls fn once per matching archetype holding matching entities, with a chunk
+// of that archetype's entities, one archetype after another. Chunks are populated before fn
+// is called, so each call allocates the chunk's entries.
+//
+// Chunks never share entities, so fn may hand chunks to goroutines that each modify the
+// components of their own chunk's entities through the view struct pointers. Chunks stay
+// valid after ForEachChunk returns, until the next structural change to storage, so the
+// goroutines must be waited for before commands are flushed. They must not make structural
+// changes themselves, nor touch entities outside their chunk.
+func (v *View[T]) ForEachChunk(fn func(chunk ViewChunk[T])) {
+	forEachChunk(v.matchingArchetypes(), v.iterArchetype, fn)
+}
+
+// ForEachChunk calls fn once per matching archetype with a chunk of its entities. See
+// View.ForEachChunk.
+func (q *Query[T]) ForEachChunk(fn func(chunk ViewChunk[T])) {
+	defer q.endPass(q.beginPass())
+	forEachChunk(q.view.matchingArchetypes(), q.iterArchetype, fn)
+}
+
+func forEachChunk[T any](archetypes []*Archetype, entities func(*Archetype) iter.Seq2[EntityId, T], fn func(chunk ViewChunk[T])) {
+	for _, archetype := range archetypes {
+		count := archetype.Len()
+		if count == 0 {
+			continue
+		}
+
+		chunk := ViewChunk[T]{
+			archetype: archetype,
+			ids:       make([]EntityId, 0, count),
+			items:     make([]T, 0, count),
+		}
+		for id, item := range entities(archetype) {
+			chunk.ids = append(chunk.ids, id)
+			chunk.items = append(chunk.items, item)
+		}
+		if chunk.Len() > 0 {
+			fn(chunk)
+		}
+	}
+}
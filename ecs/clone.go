@@ -0,0 +1,280 @@
+package ecs
+
+import (
+	"maps"
+	"reflect"
+	"slices"
+	"unsafe"
+	"weak"
+
+	"github.com/kamstrup/intmap"
+)
+
+var (
+	entityRefType = reflect.TypeFor[EntityRef]()
+	archetypeType = reflect.TypeFor[Archetype]()
+	storageType   = reflect.TypeFor[Storage]()
+	registryType  = reflect.TypeFor[ComponentRegistry]()
+	schedulerType = reflect.TypeFor[Scheduler]()
+)
+
+// Clone returns a deep copy of the storage, e.g. to run a simulation ahead speculatively and
+// discard the result. Unlike Snapshot, the copy stays in memory and keeps every EntityId, free
+// slot, disabled entity and birth order, so systems behave against the clone exactly as they
+// would against the storage. The clone shares the storage's ComponentRegistry.
+//
+// Components and singletons are copied deeply, including their unexported fields: pointers,
+// slices, maps and interfaces are followed and copied, and memory shared between values stays
+// shared between their copies. EntityRef and *EntityRef values referring to entities of the
+// storage refer to the clone's entities instead. Functions and channels are not copied, nor
+// are pointers to the registry, to a Scheduler, or to other storages and their entities.
+//
+// Structural change listeners and the OnArchetypeCreated callback are not copied, so views,
+// queries and helpers that follow structural changes, such as IncrementalGrid, must be created
+// again for the clone. Views using `ecs:"added"` and `ecs:"removed"` created for the clone see
+// the changes of the storage's last flush.
+func (s *Storage) Clone() *Storage {
+	clone := &Storage{
+		archetypes:       make(map[uint32]*Archetype, len(s.archetypes)),
+		archetypeOrder:   make([]*Archetype, 0, len(s.archetypeOrder)),
+		registry:         s.registry,
+		singletons:       make(map[reflect.Type]*singletonEntry, len(s.singletons)),
+		singletonAliases: maps.Clone(s.singletonAliases),
+		validation:       s.validation,
+		retainEmpty:      s.retainEmpty,
+		births:           s.births,
+		moveCounts:       maps.Clone(s.moveCounts),
+	}
+	clone.changes.last = cloneChanges(s.changes.last)
+	clone.changes.suspended = cloneChanges(s.changes.suspended)
+	clone.changes.marks = maps.Clone(s.changes.marks)
+	clone.changes.markSeq = s.changes.markSeq
+
+	for _, archetype := range s.archetypeOrder {
+		copied := archetype.clone()
+		clone.archetypes[copied.id] = copied
+		clone.archetypeOrder = append(clone.archetypeOrder, copied)
+	}
+
+	cloner := &storageCloner{
+		source: s,
+		clone:  clone,
+		copies: make(map[clonedPointer]reflect.Value),
+		deep:   make(map[reflect.Type]bool),
+	}
+	for _, archetype := range clone.archetypeOrder {
+		for i, t := range archetype.types {
+			if !cloner.needsCopy(t) {
+				continue
+			}
+			storage := archetype.storages[i]
+			for index := range storage.Iter() {
+				cloner.rewrite(reflect.ValueOf(storage.Get(index)).Elem())
+			}
+		}
+	}
+
+	for t, entry := range s.singletons {
+		value := reflect.New(t)
+		value.Elem().Set(reflect.NewAt(t, entry.dataPtr).Elem())
+		cloner.rewrite(value.Elem())
+		clone.singletons[t] = &singletonEntry{
+			componentType: t,
+			dataPtr:       value.UnsafePointer(),
+		}
+	}
+
+	return clone
+}
+
+// clone returns a copy of the archetype holding copies of its components, for Storage.Clone.
+// EntityRefs are not copied; the clone's refs are created as the components referring to them
+// are copied.
+func (a *Archetype) clone() *Archetype {
+	clone := &Archetype{
+		id:            a.id,
+		types:         a.types,
+		storages:      make([]iComponentStorage, len(a.storages)),
+		refs:          intmap.New[EntityId, weak.Pointer[EntityRef]](256),
+		typeSet:       a.typeSet,
+		mask:          a.mask,
+		disabled:      slices.Clone(a.disabled),
+		disabledCount: a.disabledCount,
+		births:        slices.Clone(a.births),
+		typeNames:     a.typeNames,
+		version:       a.version,
+	}
+	for i, storage := range a.storages {
+		clone.storages[i] = storage.clone()
+	}
+	return clone
+}
+
+// cloneChanges copies recorded structural changes, which are appended to while recording
+func cloneChanges(changes map[EntityId]*entityChanges) map[EntityId]*entityChanges {
+	if changes == nil {
+		return nil
+	}
+	clone := make(map[EntityId]*entityChanges, len(changes))
+	for id, entry := range changes {
+		clone[id] = &entityChanges{
+			added:   slices.Clone(entry.added),
+			removed: slices.Clone(entry.removed),
+		}
+	}
+	return clone
+}
+
+// storageCloner replaces the memory referenced by values copied out of the source storage with
+// copies of it. EntityRefs and archetypes of the source are mapped to those of clone, which is
+// the source itself when the copies stay in the same storage.
+type storageCloner struct {
+	source *Storage
+	clone  *Storage
+	// copies maps the pointers copied so far to their copy, so memory shared in the source is
+	// shared in the clone and cyclic values are copied once
+	copies map[clonedPointer]reflect.Value
+	// deep caches whether values of a type reference memory that has to be copied
+	deep map[reflect.Type]bool
+}
+
+// clonedPointer identifies a pointer by type as well as address, since a struct and its first
+// field share an address
+type clonedPointer struct {
+	addr uintptr
+	typ  reflect.Type
+}
+
+// needsCopy reports whether values of type t can reference memory that has to be copied
+func (c *storageCloner) needsCopy(t reflect.Type) bool {
+	if deep, ok := c.deep[t]; ok {
+		return deep
+	}
+
+	deep := false
+	switch t.Kind() {
+	case reflect.Pointer, reflect.Slice, reflect.Map, reflect.Interface:
+		deep = true
+	case reflect.Array:
+		deep = t.Len() > 0 && c.needsCopy(t.Elem())
+	case reflect.Struct:
+		for i := range t.NumField() {
+			if c.needsCopy(t.Field(i).Type) {
+				deep = true
+				break
+			}
+		}
+	}
+	c.deep[t] = deep
+	return deep
+}
+
+// rewrite replaces the memory referenced by v, an addressable value in the clone, with copies
+func (c *storageCloner) rewrite(v reflect.Value) {
+	if !c.needsCopy(v.Type()) {
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		for i := range v.NumField() {
+			c.rewrite(settable(v.Field(i)))
+		}
+	case reflect.Array:
+		for i := range v.Len() {
+			c.rewrite(v.Index(i))
+		}
+	default:
+		v.Set(c.copy(v))
+	}
+}
+
+// settable returns v, an addressable value, in a form that can be set even if it was reached
+// through an unexported field
+func settable(v reflect.Value) reflect.Value {
+	if v.CanSet() {
+		return v
+	}
+	return reflect.NewAt(v.Type(), unsafe.Pointer(v.UnsafeAddr())).Elem()
+}
+
+// copy returns a copy of v, a pointer, slice, map or interface referencing the source's memory
+func (c *storageCloner) copy(v reflect.Value) reflect.Value {
+	if v.IsNil() {
+		return v
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer:
+		return c.pointer(v)
+	case reflect.Slice:
+		copied := reflect.MakeSlice(v.Type(), v.Len(), v.Cap())
+		reflect.Copy(copied, v)
+		for i := range copied.Len() {
+			c.rewrite(copied.Index(i))
+		}
+		return copied
+	case reflect.Map:
+		copied := reflect.MakeMapWithSize(v.Type(), v.Len())
+		for iter := v.MapRange(); iter.Next(); {
+			copied.SetMapIndex(c.detached(iter.Key()), c.detached(iter.Value()))
+		}
+		return copied
+	case reflect.Interface:
+		return c.detached(v.Elem())
+	}
+	return v
+}
+
+// detached returns a copy of v that references no memory of the source
+func (c *storageCloner) detached(v reflect.Value) reflect.Value {
+	if !c.needsCopy(v.Type()) {
+		return v
+	}
+	copied := reflect.New(v.Type()).Elem()
+	copied.Set(v)
+	c.rewrite(copied)
+	return copied
+}
+
+func (c *storageCloner) pointer(v reflect.Value) reflect.Value {
+	switch v.Type().Elem() {
+	case entityRefType:
+		ref := v.Interface().(*EntityRef)
+		if ref.Id.IsValid() {
+			if !c.owns(ref.Archetype) {
+				// A ref into another storage keeps following that storage's entity
+				return v
+			}
+			return reflect.ValueOf(c.clone.CreateEntityRef(ref.Id))
+		}
+		// A ref to a deleted entity is copied like any other value
+	case archetypeType:
+		if archetype := v.Interface().(*Archetype); c.owns(archetype) {
+			return reflect.ValueOf(c.clone.archetypes[archetype.id])
+		}
+		return v
+	case storageType:
+		if v.Interface().(*Storage) == c.source {
+			return reflect.ValueOf(c.clone)
+		}
+		return v
+	case registryType, schedulerType:
+		return v
+	}
+
+	key := clonedPointer{v.Pointer(), v.Type()}
+	if copied, ok := c.copies[key]; ok {
+		return copied
+	}
+	copied := reflect.New(v.Type().Elem())
+	c.copies[key] = copied
+	copied.Elem().Set(v.Elem())
+	c.rewrite(copied.Elem())
+	return copied
+}
+
+// owns reports whether archetype belongs to the source storage
+func (c *storageCloner) owns(archetype *Archetype) bool {
+	return archetype != nil && c.source.archetypes[archetype.id] == archetype
+}
@@ -0,0 +1,93 @@
+package ecs_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/plus3/ooftn/ecs"
+	"github.com/stretchr/testify/assert"
+)
+
+type cloneFollower struct {
+	Leader *ecs.EntityRef
+	Path   []Position
+	Seen   map[string]*Position
+	last   *Position
+}
+
+func TestStorageClone(t *testing.T) {
+	registry := newTestRegistry()
+	ecs.RegisterComponent[cloneFollower](registry)
+	ecs.RegisterKeyedComponent[weapon](registry)
+
+	newWorld := func() (storage *ecs.Storage, leader, follower ecs.EntityId) {
+		storage = ecs.NewStorage(registry)
+		storage.Delete(storage.Spawn(Position{}))
+		leader = storage.Spawn(Position{X: 1}, Health{Current: 10})
+		shared := &Position{X: 2}
+		follower = storage.Spawn(Position{}, cloneFollower{
+			Leader: storage.CreateEntityRef(leader),
+			Path:   []Position{{X: 1}, {X: 2}},
+			Seen:   map[string]*Position{"a": shared, "b": shared},
+			last:   shared,
+		})
+		follower = storage.AddKeyedComponent(follower, "left", weapon{Damage: 5})
+		ecs.NewSingleton(storage, GameScore{Points: 3})
+		return storage, leader, follower
+	}
+
+	t.Run("entities keep their ids", func(t *testing.T) {
+		storage, leader, follower := newWorld()
+		clone := storage.Clone()
+
+		assert.Equal(t, storage.StateHash(), clone.StateHash())
+		assert.Equal(t, storage.BirthOrder(follower), clone.BirthOrder(follower))
+		assert.Equal(t, float32(1), ecs.ReadComponent[Position](clone, leader).X)
+		assert.Equal(t, 3, clone.GetSingleton(reflect.TypeFor[GameScore]()).(*GameScore).Points)
+		assert.Equal(t, storage.Spawn(Position{}), clone.Spawn(Position{}), "free slots are reused alike")
+	})
+
+	t.Run("components are copied", func(t *testing.T) {
+		storage, leader, follower := newWorld()
+		clone := storage.Clone()
+
+		ecs.ReadComponent[Position](clone, leader).X = 100
+		copied := ecs.ReadComponent[cloneFollower](clone, follower)
+		copied.Path[0].X = 100
+		copied.Seen["a"].X = 100
+		ecs.ReadComponent[ecs.Keyed[weapon]](clone, follower).Get("left").Damage = 100
+		clone.GetSingleton(reflect.TypeFor[GameScore]()).(*GameScore).Points = 100
+
+		original := ecs.ReadComponent[cloneFollower](storage, follower)
+		assert.Equal(t, float32(1), ecs.ReadComponent[Position](storage, leader).X)
+		assert.Equal(t, float32(1), original.Path[0].X)
+		assert.Equal(t, float32(2), original.Seen["a"].X)
+		assert.Equal(t, 5, ecs.ReadComponent[ecs.Keyed[weapon]](storage, follower).Get("left").Damage)
+		assert.Equal(t, 3, storage.GetSingleton(reflect.TypeFor[GameScore]()).(*GameScore).Points)
+
+		assert.Same(t, copied.Seen["a"], copied.Seen["b"], "shared memory stays shared")
+		assert.Same(t, copied.Seen["a"], copied.last, "unexported fields are copied")
+		assert.NotSame(t, original.last, copied.last)
+	})
+
+	t.Run("refs are remapped", func(t *testing.T) {
+		storage, leader, follower := newWorld()
+		clone := storage.Clone()
+
+		ref := ecs.ReadComponent[cloneFollower](clone, follower).Leader
+		assert.Same(t, clone.CreateEntityRef(leader), ref)
+		assert.NotSame(t, storage.CreateEntityRef(leader), ref)
+
+		moved := clone.AddComponent(leader, Velocity{})
+		id, ok := clone.ResolveEntityRef(ref)
+		assert.True(t, ok)
+		assert.Equal(t, moved, id)
+
+		clone.Delete(moved)
+		_, ok = clone.ResolveEntityRef(ref)
+		assert.False(t, ok)
+		id, ok = storage.ResolveEntityRef(ecs.ReadComponent[cloneFollower](storage, follower).Leader)
+		assert.True(t, ok, "the original ref is untouched")
+		assert.Equal(t, leader, id)
+	})
+}
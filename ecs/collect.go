@@ -0,0 +1,30 @@
+package ecs
+
+import "iter"
+
+// entitySource is implemented by View and Query so helpers can accept either
+type entitySource[T any] interface {
+	iterEntities() iter.Seq2[EntityId, T]
+	lenHint() int
+}
+
+// CollectMap returns a map of every entity matched by a View or Query to its populated
+// view struct. The map is sized from the live entity count of the matching archetypes.
+// The stored structs hold live component pointers, so they are subject to the same
+// lifetime rules as View results and must not be used after structural changes.
+func CollectMap[T any](source entitySource[T]) map[EntityId]T {
+	dst := make(map[EntityId]T, source.lenHint())
+	for id, item := range source.iterEntities() {
+		dst[id] = item
+	}
+	return dst
+}
+
+// CollectMapInto clears dst and refills it with every entity matched by a View or Query,
+// reusing the map's storage between frames. See CollectMap for pointer lifetime rules.
+func CollectMapInto[T any](source entitySource[T], dst map[EntityId]T) {
+	clear(dst)
+	for id, item := range source.iterEntities() {
+		dst[id] = item
+	}
+}
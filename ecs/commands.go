@@ -1,21 +1,38 @@
 package ecs
 
-import "reflect"
+import (
+	"fmt"
+	"reflect"
+	"runtime/debug"
+)
 
 // Commands provides a buffer for deferred ECS operations that are executed at the end of a frame.
 // This prevents structural changes to the ECS storage during system execution.
 type Commands struct {
-	spawns  []spawnCommand
-	deletes []EntityId
-	adds    []addComponentCommand
-	removes []removeComponentCommand
-	defers  []deferCommand
+	spawns   []spawnCommand
+	deletes  []EntityId
+	adds     []addComponentCommand
+	removes  []removeComponentCommand
+	replaces []replaceComponentsCommand
+	keyed    []keyedComponentCommand
+	defers   []deferCommand
+
+	readOnly bool
+	// onError receives the panics of deferred functions, see Flush
+	onError func(err error)
 }
 
 func newCommands() *Commands {
 	return &Commands{}
 }
 
+// checkWritable panics in debug builds if structural commands are queued on a read-only frame
+func (c *Commands) checkWritable(operation string) {
+	if debugChecks && c.readOnly {
+		panic("cannot queue " + operation + " command on a read-only frame; render systems must not modify storage")
+	}
+}
+
 type deferCommand struct {
 	fn func()
 }
@@ -34,23 +51,49 @@ type removeComponentCommand struct {
 	compType reflect.Type
 }
 
-// Defer queues a function execution operation.
+// keyedComponentCommand adds component under key, or removes the compType component under key
+// if component is nil
+type keyedComponentCommand struct {
+	entity    EntityId
+	key       string
+	component any
+	compType  reflect.Type
+}
+
+type replaceComponentsCommand struct {
+	entity     EntityId
+	components []any
+}
+
+// Defer queues a function execution operation. Deferred functions run at the end of Flush, in
+// the order they were queued.
 func (c *Commands) Defer(fn func()) {
 	c.defers = append(c.defers, deferCommand{fn: fn})
 }
 
 // Spawn queues an entity spawn operation with the given components.
 func (c *Commands) Spawn(components ...any) {
+	c.checkWritable("spawn")
+	c.spawns = append(c.spawns, spawnCommand{components: components})
+}
+
+// SpawnSlice queues an entity spawn operation using a prebuilt slice of components.
+// The slice is retained until the commands are flushed, so callers must not modify
+// or reuse it afterward.
+func (c *Commands) SpawnSlice(components []any) {
+	c.checkWritable("spawn")
 	c.spawns = append(c.spawns, spawnCommand{components: components})
 }
 
 // Delete queues an entity deletion operation.
 func (c *Commands) Delete(entity EntityId) {
+	c.checkWritable("delete")
 	c.deletes = append(c.deletes, entity)
 }
 
 // AddComponent queues a component addition operation.
 func (c *Commands) AddComponent(entity EntityId, component any) {
+	c.checkWritable("add component")
 	c.adds = append(c.adds, addComponentCommand{
 		entity:    entity,
 		component: component,
@@ -59,14 +102,148 @@ func (c *Commands) AddComponent(entity EntityId, component any) {
 
 // RemoveComponent queues a component removal operation.
 func (c *Commands) RemoveComponent(entity EntityId, compType reflect.Type) {
+	c.checkWritable("remove component")
 	c.removes = append(c.removes, removeComponentCommand{
 		entity:   entity,
 		compType: compType,
 	})
 }
 
-// Flush flushes all commands to the provided storage, reseting the buffer state
-func (c *Commands) Flush(storage *Storage) {
+// AddKeyedComponent queues storing a component under key, see Storage.AddKeyedComponent.
+// Keyed additions and removals are applied in order after component additions.
+func (c *Commands) AddKeyedComponent(entity EntityId, key string, component any) {
+	c.checkWritable("add keyed component")
+	c.keyed = append(c.keyed, keyedComponentCommand{
+		entity:    entity,
+		key:       key,
+		component: component,
+	})
+}
+
+// RemoveKeyedComponent queues removing the component stored under key, see
+// Storage.RemoveKeyedComponent.
+func (c *Commands) RemoveKeyedComponent(entity EntityId, key string, compType reflect.Type) {
+	c.checkWritable("remove keyed component")
+	c.keyed = append(c.keyed, keyedComponentCommand{
+		entity:   entity,
+		key:      key,
+		compType: compType,
+	})
+}
+
+// ReplaceComponents queues replacing all of an entity's components with a new set.
+// Replacements are applied after component additions and removals.
+func (c *Commands) ReplaceComponents(entity EntityId, components ...any) {
+	c.checkWritable("replace components")
+	c.replaces = append(c.replaces, replaceComponentsCommand{
+		entity:     entity,
+		components: components,
+	})
+}
+
+// FlushResult summarizes the operations a Flush applied. Commands whose entity was deleted or
+// did not exist by the time they were applied are counted as skipped rather than applied.
+type FlushResult struct {
+	Spawned  []EntityId // ids of the spawned entities, in the order they were queued
+	Deleted  int
+	Added    int // component and keyed component additions
+	Removed  int // component and keyed component removals
+	Replaced int
+	Skipped  int
+}
+
+// add accumulates other into r
+func (r *FlushResult) add(other FlushResult) {
+	r.Spawned = append(r.Spawned, other.Spawned...)
+	r.Deleted += other.Deleted
+	r.Added += other.Added
+	r.Removed += other.Removed
+	r.Replaced += other.Replaced
+	r.Skipped += other.Skipped
+}
+
+// PanicError reports a panic recovered by the scheduler, such as a panicking deferred function.
+type PanicError struct {
+	Value any    // the value passed to panic
+	Stack []byte // the stack of the panicking goroutine
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("recovered panic: %v", e.Value)
+}
+
+// Unwrap returns the panic value if it is an error
+func (e *PanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
+
+// Flush flushes all commands to the provided storage, reseting the buffer state, and returns
+// a summary of the applied operations.
+//
+// A panicking deferred function doesn't stop the others: its panic is recovered and the
+// remaining deferred functions still run. The frames of a Scheduler report such panics to
+// the handler set with Scheduler.OnError as a *PanicError. Without a handler the first panic
+// is raised again once every deferred function has run; a scheduler whose frame raises it
+// ends the frame first, so it keeps working once the caller recovers.
+func (c *Commands) Flush(storage *Storage) FlushResult {
+	if storage.beginChanges() {
+		defer storage.endChanges()
+	}
+	result := c.apply(storage)
+
+	var unhandled *PanicError
+	for _, df := range c.defers {
+		err := runDeferred(df.fn)
+		if err == nil {
+			continue
+		}
+		if c.onError != nil {
+			c.onError(err)
+		} else if unhandled == nil {
+			unhandled = err
+		}
+	}
+	c.defers = c.defers[:0]
+
+	if unhandled != nil {
+		panic(unhandled.Value)
+	}
+	return result
+}
+
+// runDeferred calls fn, returning its panic if it panics
+func runDeferred(fn func()) (err *PanicError) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = &PanicError{Value: r, Stack: debug.Stack()}
+		}
+	}()
+	fn()
+	return nil
+}
+
+// FlushNow applies the structural commands queued so far to storage in the middle of a frame,
+// so that a multi-pass system sees its earlier changes in later passes. Deferred functions stay
+// queued until the frame's regular flush, and the applied commands are removed from the
+// buffer, so they are not applied a second time.
+//
+// This is an escape hatch for advanced use. Call it between queries, never while iterating:
+// spawning, deleting and moving entities invalidates active iterators, view structs and
+// EntityIds held from before the call. Systems executing later in the frame observe the
+// changes too, and the frame's FlushResult only reports the commands applied at the end.
+// Views using the `ecs:"added"` and `ecs:"removed"` tags see the changes during the next
+// frame, together with the changes of the frame's regular flush.
+func (c *Commands) FlushNow(storage *Storage) FlushResult {
+	if storage.beginChanges() {
+		defer storage.suspendChanges()
+	}
+	return c.apply(storage)
+}
+
+// apply applies the structural commands to storage and clears them from the buffer
+func (c *Commands) apply(storage *Storage) FlushResult {
+	var result FlushResult
 	deletedEntities := make(map[EntityId]bool)
 	movedEntities := make(map[EntityId]EntityId)
 
@@ -81,20 +258,33 @@ func (c *Commands) Flush(storage *Storage) {
 		}
 	}
 
+	// skipped reports whether the entity was deleted before the command could be applied
+	skipped := func(id EntityId) bool {
+		if deletedEntities[id] || !storage.Exists(id) {
+			result.Skipped++
+			return true
+		}
+		return false
+	}
+
 	for _, cmd := range c.deletes {
 		currentId := resolveId(cmd)
-		storage.Delete(currentId)
+		if !skipped(currentId) {
+			storage.Delete(currentId)
+			result.Deleted++
+		}
 		deletedEntities[cmd] = true
 		deletedEntities[currentId] = true
 	}
 
 	for _, cmd := range c.removes {
 		currentId := resolveId(cmd.entity)
-		if !deletedEntities[currentId] {
+		if !skipped(currentId) {
 			newId := storage.RemoveComponent(currentId, cmd.compType)
-			if newId != 0 && newId != currentId {
+			result.Removed++
+			if newId.IsValid() && newId != currentId {
 				movedEntities[currentId] = newId
-			} else if newId == 0 {
+			} else if !newId.IsValid() {
 				// Entity was deleted (no components left)
 				deletedEntities[currentId] = true
 				deletedEntities[cmd.entity] = true
@@ -104,25 +294,60 @@ func (c *Commands) Flush(storage *Storage) {
 
 	for _, cmd := range c.adds {
 		currentId := resolveId(cmd.entity)
-		if !deletedEntities[currentId] {
+		if !skipped(currentId) {
 			newId := storage.AddComponent(currentId, cmd.component)
+			result.Added++
 			if newId != currentId {
 				movedEntities[currentId] = newId
 			}
 		}
 	}
 
-	for _, cmd := range c.spawns {
-		storage.Spawn(cmd.components...)
+	for _, cmd := range c.keyed {
+		currentId := resolveId(cmd.entity)
+		if skipped(currentId) {
+			continue
+		}
+
+		var newId EntityId
+		if cmd.component != nil {
+			newId = storage.AddKeyedComponent(currentId, cmd.key, cmd.component)
+			result.Added++
+		} else {
+			newId = storage.RemoveKeyedComponent(currentId, cmd.key, cmd.compType)
+			result.Removed++
+		}
+		if !newId.IsValid() {
+			deletedEntities[currentId] = true
+			deletedEntities[cmd.entity] = true
+		} else if newId != currentId {
+			movedEntities[currentId] = newId
+		}
 	}
 
-	for _, df := range c.defers {
-		df.fn()
+	for _, cmd := range c.replaces {
+		currentId := resolveId(cmd.entity)
+		if !skipped(currentId) {
+			newId := storage.ReplaceComponents(currentId, cmd.components...)
+			result.Replaced++
+			if newId.IsValid() && newId != currentId {
+				movedEntities[currentId] = newId
+			}
+		}
+	}
+
+	if len(c.spawns) > 0 {
+		result.Spawned = make([]EntityId, 0, len(c.spawns))
+	}
+	for _, cmd := range c.spawns {
+		result.Spawned = append(result.Spawned, storage.SpawnSlice(cmd.components))
 	}
 
 	c.spawns = c.spawns[:0]
 	c.deletes = c.deletes[:0]
 	c.adds = c.adds[:0]
 	c.removes = c.removes[:0]
-	c.defers = c.defers[:0]
+	c.replaces = c.replaces[:0]
+	c.keyed = c.keyed[:0]
+	return result
 }
@@ -1,7 +1,9 @@
 package ecs_test
 
 import (
+	"errors"
 	"reflect"
+	"slices"
 	"testing"
 
 	"github.com/plus3/ooftn/ecs"
@@ -17,6 +19,23 @@ func (s *testSpawnSystem) Execute(frame *ecs.UpdateFrame) {
 	frame.Commands.Spawn(Position{X: 3, Y: 4})
 }
 
+type testSpawnSliceSystem struct {
+	prefab func() []any
+}
+
+func (s *testSpawnSliceSystem) Execute(frame *ecs.UpdateFrame) {
+	frame.Commands.SpawnSlice(s.prefab())
+}
+
+type testReplaceSystem struct {
+	entity ecs.EntityId
+}
+
+func (s *testReplaceSystem) Execute(frame *ecs.UpdateFrame) {
+	frame.Commands.AddComponent(s.entity, Health{Current: 1, Max: 1})
+	frame.Commands.ReplaceComponents(s.entity, Health{Current: 7, Max: 7}, Velocity{DX: 1})
+}
+
 type testDeleteSystem struct {
 	entityToDelete ecs.EntityId
 }
@@ -122,6 +141,63 @@ func TestCommands(t *testing.T) {
 		}
 	})
 
+	t.Run("spawn slice", func(t *testing.T) {
+		storage := ecs.NewStorage(registry)
+		scheduler := ecs.NewScheduler(storage)
+
+		scheduler.Register(&testSpawnSliceSystem{prefab: func() []any {
+			return []any{Position{X: 5, Y: 6}, Velocity{DX: 1, DY: 1}}
+		}})
+
+		scheduler.Once(1.0)
+		scheduler.Once(1.0)
+
+		view := ecs.NewView[struct {
+			*Position
+			*Velocity
+		}](storage)
+		count := 0
+		for item := range view.Iter() {
+			if item.Position.X != 5 || item.Velocity.DX != 1 {
+				t.Errorf("unexpected component values %+v %+v", item.Position, item.Velocity)
+			}
+			count++
+		}
+		if count != 2 {
+			t.Errorf("expected 2 entities after two frames, got %d", count)
+		}
+
+		id := storage.SpawnSlice([]any{Position{X: 7}})
+		pos := storage.GetComponent(id, reflect.TypeOf(Position{})).(*Position)
+		if pos.X != 7 {
+			t.Errorf("expected X=7, got %f", pos.X)
+		}
+	})
+
+	t.Run("replace components", func(t *testing.T) {
+		storage := ecs.NewStorage(registry)
+		entity := storage.Spawn(Position{X: 1, Y: 2})
+		ref := storage.CreateEntityRef(entity)
+
+		scheduler := ecs.NewScheduler(storage)
+		scheduler.RegisterOnce(&testReplaceSystem{entity: entity})
+		scheduler.Once(1.0)
+
+		id, ok := storage.ResolveEntityRef(ref)
+		if !ok {
+			t.Fatal("ref should still resolve after replacing components")
+		}
+		if storage.GetComponent(id, reflect.TypeOf(Position{})) != nil {
+			t.Error("old components should be gone")
+		}
+		if health := storage.GetComponent(id, reflect.TypeOf(Health{})).(*Health); health.Current != 7 {
+			t.Errorf("expected replaced health of 7, got %d", health.Current)
+		}
+		if storage.GetComponent(id, reflect.TypeOf(Velocity{})) == nil {
+			t.Error("expected velocity from the replacement set")
+		}
+	})
+
 	t.Run("delete entities", func(t *testing.T) {
 		storage := ecs.NewStorage(registry)
 		e1 := storage.Spawn(Position{X: 1, Y: 2})
@@ -369,5 +445,330 @@ func TestCommands(t *testing.T) {
 		if count > 0 {
 			t.Error("no Health-only entities should exist")
 		}
+
+		if flushed := scheduler.GetStats().LastFlush; flushed.Deleted != 1 || flushed.Added != 0 || flushed.Skipped != 1 {
+			t.Errorf("expected 1 delete and 1 skipped add, got %+v", flushed)
+		}
+	})
+
+	t.Run("flush result", func(t *testing.T) {
+		storage := ecs.NewStorage(registry)
+		moved := storage.Spawn(Position{X: 1, Y: 1}, Velocity{})
+		deleted := storage.Spawn(Position{X: 2, Y: 2})
+
+		commands := &ecs.Commands{}
+		commands.Spawn(Position{X: 3, Y: 3})
+		commands.Spawn(Position{X: 4, Y: 4}, Health{Current: 10})
+		commands.Delete(deleted)
+		commands.Delete(deleted)
+		commands.AddComponent(moved, Health{})
+		commands.AddComponent(deleted, Health{})
+		commands.RemoveComponent(moved, reflect.TypeFor[Position]())
+		commands.ReplaceComponents(moved, Health{Current: 5})
+
+		result := commands.Flush(storage)
+		if len(result.Spawned) != 2 {
+			t.Fatalf("expected 2 spawned entities, got %v", result.Spawned)
+		}
+		if pos := ecs.ReadComponent[Position](storage, result.Spawned[1]); pos == nil || pos.X != 4 {
+			t.Errorf("expected spawned ids in queued order, got %v", result.Spawned)
+		}
+		if result.Deleted != 1 || result.Added != 1 || result.Removed != 1 || result.Replaced != 1 || result.Skipped != 2 {
+			t.Errorf("unexpected flush result %+v", result)
+		}
+
+		if result := commands.Flush(storage); result.Spawned != nil || result.Deleted+result.Added+result.Removed+result.Skipped != 0 {
+			t.Errorf("expected an empty result once the buffer is flushed, got %+v", result)
+		}
 	})
 }
+
+// changeSystem queues a single structural change per frame and records what the
+// added/removed views saw at the start of the frame.
+type changeSystem struct {
+	Added ecs.Query[struct {
+		ecs.EntityId
+		Health *Health `ecs:"added"`
+	}]
+	Removed ecs.Query[struct {
+		ecs.EntityId
+		Health *Health `ecs:"removed"`
+	}]
+
+	queue   []func(cmd *ecs.Commands)
+	added   []ecs.EntityId
+	removed []ecs.EntityId
+}
+
+func (s *changeSystem) Execute(frame *ecs.UpdateFrame) {
+	s.added, s.removed = nil, nil
+	for item := range s.Added.Iter() {
+		s.added = append(s.added, item.EntityId)
+	}
+	for item := range s.Removed.Iter() {
+		if item.Health != nil {
+			panic("removed field should be nil")
+		}
+		s.removed = append(s.removed, item.EntityId)
+	}
+
+	if len(s.queue) > 0 {
+		s.queue[0](frame.Commands)
+		s.queue = s.queue[1:]
+	}
+}
+
+func TestCommandsChangeFilters(t *testing.T) {
+	newStorage := func() *ecs.Storage {
+		registry := ecs.NewComponentRegistry()
+		ecs.RegisterComponent[Position](registry)
+		ecs.RegisterComponent[Health](registry)
+		return ecs.NewStorage(registry)
+	}
+
+	// schedule registers a changeSystem that queues one of queue's changes per frame
+	schedule := func(storage *ecs.Storage, queue ...func(cmd *ecs.Commands)) (*ecs.Scheduler, *changeSystem) {
+		system := &changeSystem{queue: queue}
+		scheduler := ecs.NewScheduler(storage)
+		scheduler.Register(system)
+		return scheduler, system
+	}
+
+	// expectFrame runs a frame and checks the number of entities the system saw added and removed
+	expectFrame := func(t *testing.T, scheduler *ecs.Scheduler, system *changeSystem, added, removed int) {
+		t.Helper()
+		scheduler.Once(1.0)
+		if len(system.added) != added || len(system.removed) != removed {
+			t.Errorf("expected %d added and %d removed, got added %v and removed %v", added, removed, system.added, system.removed)
+		}
+	}
+
+	t.Run("nothing before any flush", func(t *testing.T) {
+		storage := newStorage()
+		storage.Spawn(Position{X: 1, Y: 2})
+		scheduler, system := schedule(storage)
+		expectFrame(t, scheduler, system, 0, 0)
+	})
+
+	t.Run("added is visible for one frame", func(t *testing.T) {
+		storage := newStorage()
+		entity := storage.Spawn(Position{X: 1, Y: 2})
+		scheduler, system := schedule(storage, func(cmd *ecs.Commands) {
+			cmd.AddComponent(entity, Health{Current: 10, Max: 10})
+		})
+
+		expectFrame(t, scheduler, system, 0, 0)
+		expectFrame(t, scheduler, system, 1, 0)
+		if len(system.added) == 1 && ecs.ReadComponent[Health](storage, system.added[0]) == nil {
+			t.Errorf("expected the added entity %d to have a Health", system.added[0])
+		}
+		expectFrame(t, scheduler, system, 0, 0)
+	})
+
+	t.Run("removed is visible for one frame", func(t *testing.T) {
+		storage := newStorage()
+		entity := storage.Spawn(Position{X: 1, Y: 2}, Health{Current: 10, Max: 10})
+		scheduler, system := schedule(storage, func(cmd *ecs.Commands) {
+			cmd.RemoveComponent(entity, reflect.TypeOf(Health{}))
+		})
+
+		expectFrame(t, scheduler, system, 0, 0)
+		expectFrame(t, scheduler, system, 0, 1)
+		if len(system.removed) == 1 && ecs.ReadComponent[Position](storage, system.removed[0]) == nil {
+			t.Errorf("expected the removed id %d to be the entity without its Health", system.removed[0])
+		}
+		expectFrame(t, scheduler, system, 0, 0)
+	})
+
+	t.Run("adding and removing in one flush cancels out", func(t *testing.T) {
+		storage := newStorage()
+		entity := storage.Spawn(Position{X: 1, Y: 2})
+		scheduler, system := schedule(storage, func(cmd *ecs.Commands) {
+			cmd.AddComponent(entity, Health{Current: 5, Max: 10})
+			cmd.RemoveComponent(entity, reflect.TypeOf(Health{}))
+		})
+
+		expectFrame(t, scheduler, system, 0, 0)
+		expectFrame(t, scheduler, system, 0, 0)
+	})
+
+	t.Run("queued spawn counts as added", func(t *testing.T) {
+		storage := newStorage()
+		scheduler, system := schedule(storage, func(cmd *ecs.Commands) {
+			cmd.Spawn(Health{Current: 1, Max: 1})
+		})
+
+		expectFrame(t, scheduler, system, 0, 0)
+		expectFrame(t, scheduler, system, 1, 0)
+	})
+
+	t.Run("direct storage changes are not tracked", func(t *testing.T) {
+		storage := newStorage()
+		entity := storage.Spawn(Position{X: 1, Y: 2})
+		scheduler, system := schedule(storage)
+
+		storage.Spawn(Health{Current: 1, Max: 1})
+		storage.AddComponent(entity, Health{Current: 10, Max: 10})
+		expectFrame(t, scheduler, system, 0, 0)
+		expectFrame(t, scheduler, system, 0, 0)
+	})
+}
+
+// multiPassSystem spawns entities in a first pass and visits them in a second pass of the same frame
+type multiPassSystem struct {
+	Positions ecs.Query[struct{ *Position }]
+
+	seen     int
+	deferred bool
+	result   ecs.FlushResult
+}
+
+func (s *multiPassSystem) Execute(frame *ecs.UpdateFrame) {
+	frame.Commands.Spawn(Position{X: 1})
+	frame.Commands.Spawn(Health{Current: 1})
+	frame.Commands.Defer(func() { s.deferred = true })
+	s.result = frame.Commands.FlushNow(frame.Storage)
+
+	s.seen = 0
+	for range s.Positions.Iter() {
+		s.seen++
+	}
+}
+
+func TestCommandsFlushNow(t *testing.T) {
+	registry := ecs.NewComponentRegistry()
+	ecs.RegisterComponent[Position](registry)
+	ecs.RegisterComponent[Health](registry)
+	storage := ecs.NewStorage(registry)
+
+	system := &multiPassSystem{}
+	changes := &changeSystem{}
+	scheduler := ecs.NewScheduler(storage)
+	scheduler.Register(system)
+	scheduler.Register(changes)
+
+	scheduler.Once(1.0)
+	if system.seen != 1 {
+		t.Errorf("expected changes applied by FlushNow to be visible in the same frame, saw %d", system.seen)
+	}
+	if len(system.result.Spawned) != 2 {
+		t.Errorf("expected FlushNow to spawn 2 entities, got %v", system.result.Spawned)
+	}
+	if !system.deferred {
+		t.Error("expected deferred functions to run with the regular flush")
+	}
+	if spawned := scheduler.GetStats().LastFlush.Spawned; len(spawned) != 0 {
+		t.Errorf("expected commands not to be applied twice, got %v", spawned)
+	}
+	if len(changes.added) != 0 {
+		t.Errorf("expected no added entities in the first frame, got %v", changes.added)
+	}
+
+	scheduler.Once(1.0)
+	if system.seen != 2 {
+		t.Errorf("expected 2 positions in the second frame, saw %d", system.seen)
+	}
+	if len(changes.added) != 1 {
+		t.Errorf("expected FlushNow changes to be reported with the frame's changes, got %v", changes.added)
+	}
+}
+
+// deferringSystem queues the given functions as defers every frame
+type deferringSystem struct {
+	defers []func()
+}
+
+func (s *deferringSystem) Execute(frame *ecs.UpdateFrame) {
+	for _, fn := range s.defers {
+		frame.Commands.Defer(fn)
+	}
+}
+
+func TestCommandsDeferPanics(t *testing.T) {
+	registry := ecs.NewComponentRegistry()
+	ecs.RegisterComponent[Position](registry)
+
+	var ran []int
+	failure := errors.New("callback failed")
+	system := &deferringSystem{defers: []func(){
+		func() { ran = append(ran, 1) },
+		func() { panic(failure) },
+		func() { ran = append(ran, 3) },
+	}}
+
+	t.Run("reported to the scheduler", func(t *testing.T) {
+		ran = nil
+		scheduler := ecs.NewScheduler(ecs.NewStorage(registry))
+		var reported []error
+		scheduler.OnError(func(err error) { reported = append(reported, err) })
+		scheduler.Register(system)
+
+		scheduler.Once(0)
+		if !slices.Equal(ran, []int{1, 3}) {
+			t.Errorf("expected the other defers to still run, got %v", ran)
+		}
+		if len(reported) != 1 {
+			t.Fatalf("expected 1 reported error, got %d", len(reported))
+		}
+		var panicErr *ecs.PanicError
+		if !errors.As(reported[0], &panicErr) {
+			t.Fatalf("expected a *PanicError, got %T", reported[0])
+		}
+		if panicErr.Value != failure || len(panicErr.Stack) == 0 {
+			t.Errorf("expected the panic value and its stack, got %v", panicErr.Value)
+		}
+		if !errors.Is(reported[0], failure) {
+			t.Error("expected the error to unwrap to the panic value")
+		}
+	})
+
+	t.Run("raised again without a handler", func(t *testing.T) {
+		ran = nil
+		commands := &ecs.Commands{}
+		for _, fn := range system.defers {
+			commands.Defer(fn)
+		}
+
+		if recovered := recoverFlush(commands, ecs.NewStorage(registry)); recovered != failure {
+			t.Errorf("expected the panic to be raised again, got %v", recovered)
+		}
+		if !slices.Equal(ran, []int{1, 3}) {
+			t.Errorf("expected the other defers to run before the panic is raised, got %v", ran)
+		}
+	})
+
+	t.Run("scheduler recovers from the panic", func(t *testing.T) {
+		scheduler := ecs.NewScheduler(ecs.NewStorage(registry))
+		failing := &deferringSystem{defers: []func(){func() { panic(failure) }}}
+		scheduler.Register(failing)
+
+		func() {
+			defer func() {
+				if recovered := recover(); recovered != failure {
+					t.Errorf("expected the deferred panic to reach the caller, got %v", recovered)
+				}
+			}()
+			scheduler.Once(0)
+		}()
+
+		ran = nil
+		scheduler.Register(&deferringSystem{defers: []func(){func() { ran = append(ran, 1) }}})
+		if !scheduler.Remove(failing) {
+			t.Error("expected the failing system to be removed")
+		}
+		if stats := scheduler.GetStats(); stats.SystemCount != 1 || stats.Systems[0].Name != "deferringSystem#2" {
+			t.Errorf("expected Register and Remove to apply after the panic, got %+v", stats.Systems)
+		}
+		scheduler.Once(0)
+		if !slices.Equal(ran, []int{1}) {
+			t.Errorf("expected the next frame to run normally, got %v", ran)
+		}
+	})
+}
+
+// recoverFlush flushes commands into storage and returns the value of the panic it raised, if any
+func recoverFlush(commands *ecs.Commands, storage *ecs.Storage) (recovered any) {
+	defer func() { recovered = recover() }()
+	commands.Flush(storage)
+	return nil
+}
@@ -0,0 +1,81 @@
+package ecs
+
+import (
+	"iter"
+	"reflect"
+)
+
+// ComponentView provides iteration over every entity with a single component type T.
+// It avoids the wrapper struct a View requires for single-component queries and walks
+// the typed storage blocks directly instead of populating a view struct per entity.
+type ComponentView[T any] struct {
+	storage       *Storage
+	componentType reflect.Type
+}
+
+// NewComponentView creates a view over all entities that have a component of type T
+func NewComponentView[T any](storage *Storage) *ComponentView[T] {
+	componentType := reflect.TypeFor[T]()
+	if storage.registry.getFactory(componentType) == nil {
+		panic("component type " + componentType.String() + " not registered")
+	}
+
+	return &ComponentView[T]{
+		storage:       storage,
+		componentType: componentType,
+	}
+}
+
+// Iter returns an iterator over the id and component of every matching entity.
+// The component pointers are subject to the same lifetime rules as View pointers.
+func (v *ComponentView[T]) Iter() iter.Seq2[EntityId, *T] {
+	return func(yield func(EntityId, *T) bool) {
+		for _, archetype := range v.storage.archetypeOrder {
+			componentStorage := archetype.storageFor(v.componentType)
+			if componentStorage == nil {
+				continue
+			}
+
+			typed, ok := componentStorage.(*genericComponentStorage[T])
+			if !ok {
+				for index := range archetype.enabledIndices() {
+					if !yield(NewEntityId(archetype.id, uint32(index)), componentStorage.Get(index).(*T)) {
+						return
+					}
+				}
+				continue
+			}
+
+			for blockIdx := range typed.filled {
+				if blockIdx*genericBlockSize >= typed.nextIndex {
+					break
+				}
+
+				filled := &typed.filled[blockIdx]
+				block := &typed.blocks[blockIdx]
+				for slotIdx := range filled {
+					index := blockIdx*genericBlockSize + slotIdx
+					if !filled[slotIdx] || archetype.isDisabled(index) {
+						continue
+					}
+					if !yield(NewEntityId(archetype.id, uint32(index)), &block[slotIdx]) {
+						return
+					}
+				}
+			}
+		}
+	}
+}
+
+// Each calls fn for every matching entity. fn must not add, remove, or delete
+// entities or components.
+func (v *ComponentView[T]) Each(fn func(id EntityId, component *T)) {
+	for id, component := range v.Iter() {
+		fn(id, component)
+	}
+}
+
+// Count returns the number of enabled entities that have a component of type T
+func (v *ComponentView[T]) Count() int {
+	return v.storage.countComponent(v.componentType)
+}
@@ -0,0 +1,81 @@
+package ecs_test
+
+import (
+	"testing"
+
+	"github.com/plus3/ooftn/ecs"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestComponentView(t *testing.T) {
+	storage := ecs.NewStorage(newTestRegistry())
+
+	ids := make(map[ecs.EntityId]bool)
+	var positionOnly []ecs.EntityId
+	for i := 0; i < 100; i++ {
+		id := storage.Spawn(Position{X: float32(i)})
+		ids[id] = true
+		positionOnly = append(positionOnly, id)
+	}
+	velocities := 0
+	for i := 0; i < 10; i++ {
+		ids[storage.Spawn(Position{X: float32(i)}, Velocity{DX: 1})] = true
+		velocities++
+	}
+	storage.Spawn(Velocity{DX: 1000})
+	velocities++
+
+	// Delete a Position-only entity so the Velocity counts don't depend on which one it is
+	deleted := positionOnly[50]
+	storage.Delete(deleted)
+	delete(ids, deleted)
+
+	view := ecs.NewComponentView[Position](storage)
+
+	t.Run("iter", func(t *testing.T) {
+		seen := 0
+		for id, pos := range view.Iter() {
+			assert.True(t, ids[id], "unexpected entity %d", id)
+			pos.Y = 5
+			seen++
+		}
+		assert.Equal(t, len(ids), seen)
+
+		for id := range ids {
+			assert.Equal(t, float32(5), ecs.ReadComponent[Position](storage, id).Y)
+		}
+	})
+
+	t.Run("iter break", func(t *testing.T) {
+		seen := 0
+		for range view.Iter() {
+			seen++
+			if seen == 3 {
+				break
+			}
+		}
+		assert.Equal(t, 3, seen)
+	})
+
+	t.Run("each", func(t *testing.T) {
+		seen := 0
+		view.Each(func(id ecs.EntityId, pos *Position) {
+			assert.True(t, ids[id], "unexpected entity %d", id)
+			seen++
+		})
+		assert.Equal(t, len(ids), seen)
+	})
+
+	t.Run("count", func(t *testing.T) {
+		assert.Equal(t, len(ids), view.Count())
+		assert.Equal(t, velocities, ecs.NewComponentView[Velocity](storage).Count())
+		assert.Equal(t, 0, ecs.NewComponentView[Health](storage).Count())
+		assert.Equal(t, len(ids), ecs.Count[Position](storage))
+		assert.Equal(t, velocities, ecs.Count[Velocity](storage))
+	})
+
+	t.Run("unregistered", func(t *testing.T) {
+		type unregistered struct{}
+		assert.Panics(t, func() { ecs.NewComponentView[unregistered](storage) })
+	})
+}
@@ -0,0 +1,157 @@
+package ecs
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// ApplyConfigPatch updates live components from a JSON document, which is useful for tuning
+// simulation values without restarting. The document maps component type names to objects
+// holding the fields to change:
+//
+//	{
+//	    "main.Resource":     {"RegrowthRate": 0.02},
+//	    "ColonyTraits":      {"Aggression": 0.8}
+//	}
+//
+// Type names may be canonical names as returned by TypeName ("main.Resource", or the name
+// registered with RegisterTypeName) or bare Go type names ("Resource") when unambiguous. Each
+// patch is applied to the singleton of that type, if one exists, and to every entity with that
+// component. Fields missing from the patch keep their current values.
+//
+// The document is checked completely before any component is modified, so an unknown type
+// or a malformed patch returns an error and leaves storage untouched. When validation is
+// enabled, the validators run on patched copies of the entity components first, and a value
+// they reject is reported the same way rather than panicking.
+func (s *Storage) ApplyConfigPatch(data []byte) error {
+	var patches map[string]json.RawMessage
+	if err := json.Unmarshal(data, &patches); err != nil {
+		return fmt.Errorf("invalid config patch: %w", err)
+	}
+
+	names := make([]string, 0, len(patches))
+	for name := range patches {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	types := make([]reflect.Type, len(names))
+	for i, name := range names {
+		t, err := s.patchTarget(name)
+		if err != nil {
+			return err
+		}
+
+		// Decode into a scratch value first so malformed patches fail before anything changes
+		if err := json.Unmarshal(patches[name], reflect.New(t).Interface()); err != nil {
+			return fmt.Errorf("invalid config patch for %s: %w", t, err)
+		}
+		types[i] = t
+	}
+
+	if debugChecks && s.validation {
+		for i, t := range types {
+			if err := s.validatePatch(t, patches[names[i]]); err != nil {
+				return err
+			}
+		}
+	}
+
+	for i, t := range types {
+		patch := patches[names[i]]
+
+		if entry := s.singletons[t]; entry != nil {
+			if err := json.Unmarshal(patch, reflect.NewAt(t, entry.dataPtr).Interface()); err != nil {
+				return fmt.Errorf("applying config patch to singleton %s: %w", t, err)
+			}
+		}
+
+		for _, archetype := range s.archetypes {
+			componentStorage := archetype.storageFor(t)
+			if componentStorage == nil {
+				continue
+			}
+
+			for index := range componentStorage.Iter() {
+				if err := json.Unmarshal(patch, componentStorage.Get(index)); err != nil {
+					return fmt.Errorf("applying config patch to %s: %w", t, err)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// validatePatch applies patch to a copy of every entity component of type t and runs the
+// validators on the copies. The copies reference none of the components' memory, so the
+// components are left untouched.
+func (s *Storage) validatePatch(t reflect.Type, patch []byte) error {
+	if len(s.registry.validators[t]) == 0 {
+		return nil
+	}
+
+	cloner := &storageCloner{
+		source: s,
+		clone:  s,
+		copies: make(map[clonedPointer]reflect.Value),
+		deep:   make(map[reflect.Type]bool),
+	}
+	for _, archetype := range s.archetypes {
+		componentStorage := archetype.storageFor(t)
+		if componentStorage == nil {
+			continue
+		}
+
+		for index := range componentStorage.Iter() {
+			patched := reflect.New(t)
+			patched.Elem().Set(cloner.detached(reflect.ValueOf(componentStorage.Get(index)).Elem()))
+			if err := json.Unmarshal(patch, patched.Interface()); err != nil {
+				return fmt.Errorf("applying config patch to %s: %w", t, err)
+			}
+			if err := s.validate(t, patched.Interface()); err != nil {
+				id := NewEntityId(archetype.id, uint32(index))
+				return fmt.Errorf("config patch makes %s on entity %d invalid: %w", t, id, err)
+			}
+		}
+	}
+	return nil
+}
+
+// patchTarget resolves a config patch key to a registered component or singleton type
+func (s *Storage) patchTarget(name string) (reflect.Type, error) {
+	var matches []reflect.Type
+	seen := make(map[reflect.Type]bool)
+	consider := func(t reflect.Type) {
+		if seen[t] {
+			return
+		}
+		seen[t] = true
+		if TypeName(t) == name || t.Name() == name {
+			matches = append(matches, t)
+		}
+	}
+
+	for t := range s.registry.factories {
+		consider(t)
+	}
+	for t := range s.singletons {
+		consider(t)
+	}
+
+	// A canonical name match always wins over bare-name matches
+	for _, t := range matches {
+		if TypeName(t) == name {
+			return t, nil
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("config patch references unknown component type %q", name)
+	case 1:
+		return matches[0], nil
+	}
+	return nil, fmt.Errorf("config patch type %q is ambiguous; use the canonical type name", name)
+}
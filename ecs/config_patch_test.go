@@ -0,0 +1,59 @@
+package ecs_test
+
+import (
+	"testing"
+
+	"github.com/plus3/ooftn/ecs"
+	"github.com/stretchr/testify/assert"
+)
+
+type tuning struct {
+	SpawnRate float64
+	MaxUnits  int
+}
+
+func TestApplyConfigPatch(t *testing.T) {
+	setup := func() (*ecs.Storage, ecs.EntityId, ecs.EntityId) {
+		storage := ecs.NewStorage(newTestRegistry())
+		ecs.NewSingleton[tuning](storage, tuning{SpawnRate: 1, MaxUnits: 10})
+		a := storage.Spawn(Health{Current: 5, Max: 10}, Position{X: 1})
+		b := storage.Spawn(Health{Current: 7, Max: 10})
+		return storage, a, b
+	}
+
+	t.Run("patches entities and singletons", func(t *testing.T) {
+		storage, a, b := setup()
+
+		err := storage.ApplyConfigPatch([]byte(`{
+			"ecs_test.Health": {"Max": 50},
+			"tuning": {"SpawnRate": 2.5}
+		}`))
+		assert.NoError(t, err)
+
+		assert.Equal(t, Health{Current: 5, Max: 50}, *ecs.ReadComponent[Health](storage, a))
+		assert.Equal(t, Health{Current: 7, Max: 50}, *ecs.ReadComponent[Health](storage, b))
+		assert.Equal(t, float32(1), ecs.ReadComponent[Position](storage, a).X)
+
+		var config *tuning
+		storage.ReadSingleton(&config)
+		assert.Equal(t, tuning{SpawnRate: 2.5, MaxUnits: 10}, *config)
+	})
+
+	t.Run("unknown type leaves storage untouched", func(t *testing.T) {
+		storage, a, _ := setup()
+
+		err := storage.ApplyConfigPatch([]byte(`{"Health": {"Max": 50}, "Missing": {}}`))
+		assert.ErrorContains(t, err, `unknown component type "Missing"`)
+		assert.Equal(t, 10, ecs.ReadComponent[Health](storage, a).Max)
+	})
+
+	t.Run("malformed patch leaves storage untouched", func(t *testing.T) {
+		storage, a, _ := setup()
+
+		err := storage.ApplyConfigPatch([]byte(`{"Health": {"Max": 50}, "Position": {"X": "fast"}}`))
+		assert.Error(t, err)
+		assert.Equal(t, 10, ecs.ReadComponent[Health](storage, a).Max)
+
+		assert.Error(t, storage.ApplyConfigPatch([]byte(`not json`)))
+	})
+}
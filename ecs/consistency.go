@@ -0,0 +1,143 @@
+package ecs
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+)
+
+// Validate checks the internal bookkeeping of the storage and returns an error describing
+// every inconsistency found, or nil if the storage is sound. It verifies that:
+//
+//   - each component storage's filled slots, free list and next index agree
+//   - all component storages within an archetype hold the same set of live slots
+//   - every tracked EntityRef belongs to a live slot and points back at it
+//   - each archetype is stored under the id derived from its component types
+//
+// Validate walks every slot of every archetype, so it is intended for tests and debug
+// builds (for example after each frame while chasing corruption) rather than production.
+func (s *Storage) Validate() error {
+	var errs []error
+	for key, archetype := range s.archetypes {
+		if err := archetype.checkConsistency(key); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// checkConsistency verifies an archetype's storages and refs; key is its id in the storage map
+func (a *Archetype) checkConsistency(key uint32) error {
+	var errs []error
+	fail := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf("archetype %d: "+format, append([]any{a.id}, args...)...))
+	}
+
+	if key != a.id {
+		fail("stored under id %d", key)
+	}
+	sortedTypes := slices.Clone(a.types)
+	sort.Sort(byTypeName(sortedTypes))
+	if expected := hashTypesToUint32(sortedTypes); expected != a.id {
+		fail("id does not match its component types %v (expected %d)", a.types, expected)
+	}
+	if len(a.storages) != len(a.types) {
+		fail("has %d storages for %d component types", len(a.storages), len(a.types))
+		return errors.Join(errs...)
+	}
+
+	var live []int
+	for i, storage := range a.storages {
+		if err := storage.checkConsistency(); err != nil {
+			fail("%s storage: %v", a.types[i], err)
+		}
+
+		indices := slices.Collect(storage.Iter())
+		if i == 0 {
+			live = indices
+		} else if !slices.Equal(live, indices) {
+			fail("%s storage live slots differ from %s storage", a.types[i], a.types[0])
+		}
+	}
+
+	disabled := 0
+	for word, bits := range a.disabled {
+		for bit := range 64 {
+			if bits&(1<<bit) == 0 {
+				continue
+			}
+			disabled++
+			if index := word*64 + bit; !a.has(uint32(index)) {
+				fail("slot %d is marked disabled but holds no entity", index)
+			}
+		}
+	}
+	if disabled != a.disabledCount {
+		fail("has %d disabled slots but counts %d", disabled, a.disabledCount)
+	}
+
+	for id, weakPtr := range a.refs.All() {
+		if id.ArchetypeId() != a.id {
+			fail("tracks ref for entity %d of another archetype", id)
+			continue
+		}
+		if !a.has(id.Index()) {
+			fail("tracks ref for dead entity %d", id)
+			continue
+		}
+
+		ref := weakPtr.Value()
+		if ref == nil {
+			continue
+		}
+		if ref.Id != id {
+			fail("ref tracked for entity %d points at %d", id, ref.Id)
+		}
+		if ref.Archetype != a {
+			fail("ref for entity %d points at another archetype", id)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// checkConsistency verifies that the filled flags, free list and next index agree
+func (cs *genericComponentStorage[T]) checkConsistency() error {
+	if len(cs.blocks) != len(cs.filled) {
+		return fmt.Errorf("%d value blocks but %d filled blocks", len(cs.blocks), len(cs.filled))
+	}
+	if cs.nextIndex > len(cs.blocks)*genericBlockSize {
+		return fmt.Errorf("next index %d exceeds capacity %d", cs.nextIndex, len(cs.blocks)*genericBlockSize)
+	}
+
+	filledCount := 0
+	for index := range len(cs.filled) * genericBlockSize {
+		if !cs.filled[index/genericBlockSize][index%genericBlockSize] {
+			continue
+		}
+		if index >= cs.nextIndex {
+			return fmt.Errorf("slot %d is filled beyond next index %d", index, cs.nextIndex)
+		}
+		filledCount++
+	}
+
+	free := make(map[int]bool, len(cs.freeSlots))
+	for _, index := range cs.freeSlots {
+		if index < 0 || index >= cs.nextIndex {
+			return fmt.Errorf("free slot %d is outside 0..%d", index, cs.nextIndex)
+		}
+		if free[index] {
+			return fmt.Errorf("free slot %d is listed twice", index)
+		}
+		if cs.filled[index/genericBlockSize][index%genericBlockSize] {
+			return fmt.Errorf("free slot %d is filled", index)
+		}
+		free[index] = true
+	}
+
+	if filledCount+len(cs.freeSlots) != cs.nextIndex {
+		return fmt.Errorf("%d filled and %d free slots do not account for next index %d", filledCount, len(cs.freeSlots), cs.nextIndex)
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package ecs
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestStorageValidate(t *testing.T) {
+	newStorage := func() (*Storage, []EntityId) {
+		registry := NewComponentRegistry()
+		RegisterComponent[int](registry)
+		RegisterComponent[string](registry)
+		RegisterComponent[float64](registry)
+
+		storage := NewStorage(registry)
+		var ids []EntityId
+		for i := range 100 {
+			ids = append(ids, storage.Spawn(i, "entity"))
+		}
+		return storage, ids
+	}
+
+	expectError := func(t *testing.T, err error, contains string) {
+		t.Helper()
+		if err == nil {
+			t.Fatalf("expected an error containing %q", contains)
+		}
+		if !strings.Contains(err.Error(), contains) {
+			t.Errorf("expected error containing %q, got %v", contains, err)
+		}
+	}
+
+	t.Run("valid after structural changes", func(t *testing.T) {
+		storage, ids := newStorage()
+
+		refs := make([]*EntityRef, 0, len(ids))
+		for _, id := range ids {
+			refs = append(refs, storage.CreateEntityRef(id))
+		}
+		for i := 0; i < len(ids); i += 3 {
+			storage.Delete(ids[i])
+		}
+		for i := 1; i < len(ids); i += 3 {
+			storage.AddComponent(ids[i], 1.5)
+		}
+		for i := 2; i < len(ids); i += 6 {
+			storage.RemoveComponent(ids[i], refs[i].Archetype.types[0])
+		}
+		for _, archetype := range storage.archetypes {
+			archetype.Compact()
+		}
+
+		if err := storage.Validate(); err != nil {
+			t.Errorf("expected valid storage, got %v", err)
+		}
+	})
+
+	t.Run("mismatched live slots", func(t *testing.T) {
+		storage, ids := newStorage()
+		archetype := storage.ArchetypeOf(ids[0])
+		archetype.storages[1].Vacate(5)
+
+		expectError(t, storage.Validate(), "live slots differ")
+	})
+
+	t.Run("corrupt free list", func(t *testing.T) {
+		storage, ids := newStorage()
+		cs := storage.ArchetypeOf(ids[0]).storages[0].(*genericComponentStorage[int])
+		cs.freeSlots = append(cs.freeSlots, 3)
+
+		expectError(t, storage.Validate(), "free slot 3 is filled")
+	})
+
+	t.Run("stale ref", func(t *testing.T) {
+		storage, ids := newStorage()
+		ref := storage.CreateEntityRef(ids[0])
+		ref.Id = ids[1]
+
+		expectError(t, storage.Validate(), "points at")
+	})
+
+	t.Run("wrong archetype id", func(t *testing.T) {
+		storage, ids := newStorage()
+		archetype := storage.ArchetypeOf(ids[0])
+		storage.archetypes[archetype.id+1] = archetype
+
+		expectError(t, storage.Validate(), "stored under id")
+	})
+}
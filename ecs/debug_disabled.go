@@ -0,0 +1,14 @@
+//go:build !ecs_debug
+
+package ecs
+
+// debugChecks enables sanity checks and bookkeeping that are too costly for release builds.
+// Build with `-tags ecs_debug` to turn them on; without it they compile out of the hot paths
+// and their switches have no effect. The debug-only features are:
+//
+//   - Queuing structural commands on a read-only UpdateFrame panics
+//   - Archetype move counting, see Storage.SetMoveTracking
+//   - Component validation, see Storage.SetValidation
+//   - Query pass counts, entity counts and durations, see Query.Stats
+//   - Stale Query.At results panic, see Query.At
+const debugChecks = false
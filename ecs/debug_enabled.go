@@ -0,0 +1,6 @@
+//go:build ecs_debug
+
+package ecs
+
+// debugChecks is set when building with the ecs_debug tag, see debug_disabled.go
+const debugChecks = true
@@ -16,11 +16,16 @@ type ArchetypeInfo struct {
 	ComponentCount int
 }
 
+// ArchetypeViewerCache holds the rows shown by the archetype viewer. Entity counts are
+// kept up to date incrementally from the storage's structural change notifications.
 type ArchetypeViewerCache struct {
-	archetypes         []ArchetypeInfo
-	lastArchetypeCount int
-	sortColumn         int
-	sortAscending      bool
+	archetypes    []ArchetypeInfo
+	indexById     map[uint32]int
+	storage       *ecs.Storage
+	unsubscribe   func()
+	needsSort     bool
+	sortColumn    int
+	sortAscending bool
 }
 
 func NewArchetypeViewerComponent() ArchetypeViewerComponent {
@@ -40,6 +45,7 @@ func (av *ArchetypeViewerComponent) Render(storage *ecs.Storage) *uint32 {
 		return nil
 	}
 
+	av.target.render()
 	av.rebuildCacheIfNeeded(storage)
 
 	maxEntityCount := 0
@@ -111,26 +117,54 @@ func (av *ArchetypeViewerComponent) Render(storage *ecs.Storage) *uint32 {
 }
 
 func (av *ArchetypeViewerComponent) rebuildCacheIfNeeded(storage *ecs.Storage) {
-	currentArchetypeCount := len(storage.GetArchetypes())
-	if av.cache.lastArchetypeCount != currentArchetypeCount {
-		av.cache.archetypes = nil
-		av.cache.lastArchetypeCount = currentArchetypeCount
-	}
+	av.cache.bind(storage)
 
 	if av.cache.archetypes == nil {
-		av.rebuildCache(storage)
-	} else {
-		av.updateEntityCounts(storage)
+		av.cache.rebuild(storage)
+	}
+
+	if av.cache.needsSort {
+		av.sortArchetypes()
+	}
+}
+
+// bind subscribes the cache to structural changes of the storage, dropping any state
+// built for a previously bound storage.
+func (c *ArchetypeViewerCache) bind(storage *ecs.Storage) {
+	if c.storage == storage {
+		return
+	}
+
+	c.unbind()
+	c.storage = storage
+	c.unsubscribe = storage.OnStructuralChange(c.applyChange)
+}
+
+// unbind unsubscribes the cache from its storage and drops its rows
+func (c *ArchetypeViewerCache) unbind() {
+	if c.unsubscribe != nil {
+		c.unsubscribe()
+	}
+	c.storage = nil
+	c.unsubscribe = nil
+	c.archetypes = nil
+}
+
+// Dispose unsubscribes the viewer's cache from storage changes once the viewer is deleted
+func (av *ArchetypeViewerComponent) Dispose() {
+	if av.cache != nil {
+		av.cache.unbind()
 	}
 }
 
-func (av *ArchetypeViewerComponent) rebuildCache(storage *ecs.Storage) {
-	av.cache.archetypes = make([]ArchetypeInfo, 0, len(storage.GetArchetypes()))
+func (c *ArchetypeViewerCache) rebuild(storage *ecs.Storage) {
+	c.archetypes = make([]ArchetypeInfo, 0, len(storage.GetArchetypes()))
+	c.indexById = make(map[uint32]int, len(storage.GetArchetypes()))
 
 	for _, archetype := range storage.GetArchetypes() {
 		componentTypes := make([]string, len(archetype.Types()))
 		for i, t := range archetype.Types() {
-			componentTypes[i] = t.String()
+			componentTypes[i] = ecs.TypeName(t)
 		}
 
 		entityCount := 0
@@ -138,7 +172,8 @@ func (av *ArchetypeViewerComponent) rebuildCache(storage *ecs.Storage) {
 			entityCount++
 		}
 
-		av.cache.archetypes = append(av.cache.archetypes, ArchetypeInfo{
+		c.indexById[archetype.ID()] = len(c.archetypes)
+		c.archetypes = append(c.archetypes, ArchetypeInfo{
 			ID:             archetype.ID(),
 			ComponentTypes: componentTypes,
 			EntityCount:    entityCount,
@@ -146,30 +181,41 @@ func (av *ArchetypeViewerComponent) rebuildCache(storage *ecs.Storage) {
 		})
 	}
 
-	av.sortArchetypes()
+	c.needsSort = true
 }
 
-func (av *ArchetypeViewerComponent) updateEntityCounts(storage *ecs.Storage) {
-	archetypeMap := make(map[uint32]*ecs.Archetype)
-	for _, archetype := range storage.GetArchetypes() {
-		archetypeMap[archetype.ID()] = archetype
+// applyChange adjusts the entity counts for a single spawn, delete or move. A change
+// involving an archetype the cache has not seen yet triggers a full rebuild.
+func (c *ArchetypeViewerCache) applyChange(change ecs.StructuralChange) {
+	if c.archetypes == nil {
+		return
 	}
 
-	for i := range av.cache.archetypes {
-		archetype, ok := archetypeMap[av.cache.archetypes[i].ID]
-		if !ok {
-			continue
-		}
+	switch change.Kind {
+	case ecs.EntitySpawned:
+		c.adjustCount(change.Id.ArchetypeId(), 1)
+	case ecs.EntityDeleted:
+		c.adjustCount(change.Id.ArchetypeId(), -1)
+	case ecs.EntityMoved:
+		c.adjustCount(change.OldId.ArchetypeId(), -1)
+		c.adjustCount(change.Id.ArchetypeId(), 1)
+	}
+}
 
-		entityCount := 0
-		for range archetype.Iter() {
-			entityCount++
-		}
-		av.cache.archetypes[i].EntityCount = entityCount
+func (c *ArchetypeViewerCache) adjustCount(archetypeId uint32, delta int) {
+	if c.archetypes == nil {
+		return
 	}
 
-	if av.sortColumn == 3 {
-		av.sortArchetypes()
+	idx, ok := c.indexById[archetypeId]
+	if !ok {
+		c.archetypes = nil
+		return
+	}
+
+	c.archetypes[idx].EntityCount += delta
+	if c.sortColumn == 3 {
+		c.needsSort = true
 	}
 }
 
@@ -196,4 +242,10 @@ func (av *ArchetypeViewerComponent) sortArchetypes() {
 		}
 		return less
 	})
+
+	av.cache.indexById = make(map[uint32]int, len(av.cache.archetypes))
+	for i, arch := range av.cache.archetypes {
+		av.cache.indexById[arch.ID] = i
+	}
+	av.cache.needsSort = false
 }
@@ -0,0 +1,145 @@
+package debugui
+
+import (
+	"cmp"
+	"slices"
+	"testing"
+
+	"github.com/plus3/ooftn/ecs"
+)
+
+type cachePosition struct{ X, Y float32 }
+type cacheVelocity struct{ DX, DY float32 }
+
+func newCacheStorage() *ecs.Storage {
+	registry := ecs.NewComponentRegistry()
+	ecs.RegisterComponent[cachePosition](registry)
+	ecs.RegisterComponent[cacheVelocity](registry)
+	ecs.RegisterComponent[EntityBrowserComponent](registry)
+	ecs.RegisterComponent[ArchetypeViewerComponent](registry)
+	return ecs.NewStorage(registry)
+}
+
+func TestEntityBrowserCacheIncremental(t *testing.T) {
+	storage := newCacheStorage()
+	a := storage.Spawn(cachePosition{})
+	b := storage.Spawn(cachePosition{})
+
+	cache := &EntityBrowserCache{}
+	cache.bind(storage)
+	cache.rebuild(storage)
+
+	// Spawn one entity and delete another so the archetype count stays the same
+	c := storage.Spawn(cachePosition{})
+	storage.Delete(a)
+	moved := storage.AddComponent(b, cacheVelocity{})
+
+	if !cache.needsSort {
+		t.Error("expected cache to need sorting after changes")
+	}
+	if len(cache.entities) != 2 {
+		t.Fatalf("expected 2 cached entities, got %d", len(cache.entities))
+	}
+
+	found := make(map[ecs.EntityId]EntityInfo)
+	for _, entity := range cache.entities {
+		found[entity.ID] = entity
+	}
+	if _, ok := found[c]; !ok {
+		t.Error("expected spawned entity to be cached")
+	}
+	if info, ok := found[moved]; !ok || info.ComponentCount != 2 || info.ArchetypeID != moved.ArchetypeId() {
+		t.Errorf("expected moved entity to be cached with its new archetype, got %+v", info)
+	}
+
+	// Rebinding to another storage drops the old subscription
+	cache.bind(newCacheStorage())
+	storage.Spawn(cachePosition{})
+	if cache.entities != nil {
+		t.Error("expected cache to be reset after binding a new storage")
+	}
+}
+
+func TestEntityBrowserCacheKeepsOrder(t *testing.T) {
+	storage := newCacheStorage()
+	for range 3 {
+		storage.Spawn(cachePosition{})
+	}
+
+	browser := NewEntityBrowserComponent(100)
+	browser.cache.sortAscending = false
+	browser.rebuildCacheIfNeeded(storage)
+
+	spawned := storage.Spawn(cachePosition{})
+	storage.AddComponent(storage.Spawn(cachePosition{}), cacheVelocity{})
+	if browser.cache.needsSort {
+		t.Error("expected changes to a sorted cache not to require sorting")
+	}
+	if !slices.IsSortedFunc(browser.cache.entities, func(a, b EntityInfo) int { return cmp.Compare(b.ID, a.ID) }) {
+		t.Errorf("expected rows to stay sorted by descending id, got %+v", browser.cache.entities)
+	}
+	if idx, ok := browser.cache.indexById[spawned]; !ok || browser.cache.entities[idx].ID != spawned {
+		t.Error("expected the index to point at the inserted row")
+	}
+
+	// Deleting the browser stops its cache from following the storage
+	entity := storage.Spawn(browser)
+	storage.Delete(entity)
+	if browser.cache.storage != nil {
+		t.Fatal("expected deleting the browser to unbind its cache")
+	}
+	browser.cache.rebuild(storage)
+	rows := len(browser.cache.entities)
+	storage.Spawn(cachePosition{})
+	if len(browser.cache.entities) != rows {
+		t.Error("expected the disposed cache to receive no more changes")
+	}
+}
+
+func TestArchetypeViewerCacheIncremental(t *testing.T) {
+	storage := newCacheStorage()
+	a := storage.Spawn(cachePosition{})
+	storage.Spawn(cachePosition{})
+
+	cache := &ArchetypeViewerCache{}
+	cache.bind(storage)
+	cache.rebuild(storage)
+
+	countFor := func(archetypeId uint32) int {
+		return cache.archetypes[cache.indexById[archetypeId]].EntityCount
+	}
+
+	storage.Delete(a)
+	storage.Spawn(cachePosition{})
+	storage.Spawn(cachePosition{})
+	if got := countFor(a.ArchetypeId()); got != 3 {
+		t.Errorf("expected 3 entities, got %d", got)
+	}
+
+	// Moving into an unseen archetype forces a rebuild
+	storage.AddComponent(storage.Spawn(cachePosition{}), cacheVelocity{})
+	if cache.archetypes != nil {
+		t.Fatal("expected cache to be invalidated by a new archetype")
+	}
+
+	cache.rebuild(storage)
+	if len(cache.archetypes) != 2 {
+		t.Errorf("expected 2 archetypes, got %d", len(cache.archetypes))
+	}
+	if got := countFor(a.ArchetypeId()); got != 3 {
+		t.Errorf("expected 3 entities, got %d", got)
+	}
+
+	// Deleting the viewer stops its cache from following the storage
+	viewer := NewArchetypeViewerComponent()
+	viewer.rebuildCacheIfNeeded(storage)
+	storage.Delete(storage.Spawn(viewer))
+	if viewer.cache.storage != nil {
+		t.Fatal("expected deleting the viewer to unbind its cache")
+	}
+	viewer.cache.rebuild(storage)
+	storage.Spawn(cachePosition{})
+	if got := viewer.cache.archetypes[viewer.cache.indexById[a.ArchetypeId()]].EntityCount; got != 3 {
+		t.Errorf("expected the disposed cache to receive no more changes, got %d entities", got)
+	}
+}
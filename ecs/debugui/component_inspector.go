@@ -12,24 +12,28 @@ func NewComponentInspectorComponent() ComponentInspectorComponent {
 	return ComponentInspectorComponent{}
 }
 
-func (ci *ComponentInspectorComponent) Render(storage *ecs.Storage, selectedEntityId ecs.EntityId) {
+// Render draws the components of the entity selected in selection, in the storage it was
+// selected from
+func (ci *ComponentInspectorComponent) Render(selection *Selection) {
 	if !imgui.BeginV("Component Inspector", nil, imgui.WindowFlagsNone) {
 		imgui.End()
 		return
 	}
 
+	selectedEntityId, ok := selection.Selected()
 	ci.selectedEntityId = selectedEntityId
+	storage := selection.Storage()
 
-	if ci.selectedEntityId == 0 {
+	if !ok {
 		imgui.Text("No entity selected")
 		imgui.End()
 		return
 	}
 
 	archetypeId := ci.selectedEntityId.ArchetypeId()
-	archetype := storage.GetArchetypeById(archetypeId)
+	archetype := storage.ArchetypeOf(ci.selectedEntityId)
 	if archetype == nil {
-		imgui.Text(fmt.Sprintf("Entity %d not found (invalid archetype)", ci.selectedEntityId))
+		imgui.Text(fmt.Sprintf("Entity %d not found", ci.selectedEntityId))
 		imgui.End()
 		return
 	}
@@ -44,7 +48,7 @@ func (ci *ComponentInspectorComponent) Render(storage *ecs.Storage, selectedEnti
 			continue
 		}
 
-		if imgui.TreeNodeStr(compType.String()) {
+		if imgui.TreeNodeStr(ecs.TypeName(compType)) {
 			ci.renderComponent(component, compType, storage, ci.selectedEntityId)
 			imgui.TreePop()
 		}
@@ -82,6 +86,11 @@ func (ci *ComponentInspectorComponent) renderField(name string, val reflect.Valu
 		return
 	}
 
+	if !field.Exported || !val.CanInterface() {
+		ci.renderReadOnlyField(name, val, field)
+		return
+	}
+
 	switch val.Kind() {
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
 		v := int32(val.Int())
@@ -151,6 +160,67 @@ func (ci *ComponentInspectorComponent) renderField(name string, val reflect.Valu
 	}
 }
 
+// renderReadOnlyField displays a field that cannot be edited, such as an unexported field
+// or a field nested inside one. It never calls Interface on the value.
+func (ci *ComponentInspectorComponent) renderReadOnlyField(name string, val reflect.Value, field FieldInfo) {
+	label := name
+	if !field.Exported {
+		label = name + " (unexported)"
+	}
+
+	if val.Kind() == reflect.Struct {
+		if imgui.TreeNodeStr(label) {
+			for _, nf := range globalReflectionCache.GetFields(val.Type()) {
+				nestedVal := val.Field(nf.Index)
+				if nf.IsPointer && !nestedVal.IsNil() {
+					nestedVal = nestedVal.Elem()
+				}
+				ci.renderReadOnlyField(nf.Name, nestedVal, nf)
+			}
+			imgui.TreePop()
+		}
+		return
+	}
+
+	imgui.TextDisabled(fmt.Sprintf("%s: %s", label, formatValue(val)))
+}
+
+// formatValue formats a value for display without calling Interface, so it is safe for unexported fields.
+func formatValue(val reflect.Value) string {
+	if !val.IsValid() {
+		return "<invalid>"
+	}
+
+	switch val.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return fmt.Sprintf("%d", val.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return fmt.Sprintf("%d", val.Uint())
+	case reflect.Float32, reflect.Float64:
+		return fmt.Sprintf("%g", val.Float())
+	case reflect.Bool:
+		return fmt.Sprintf("%t", val.Bool())
+	case reflect.String:
+		return val.String()
+	case reflect.Slice, reflect.Array:
+		return fmt.Sprintf("[%d items]", val.Len())
+	case reflect.Map:
+		return fmt.Sprintf("map[%d items]", val.Len())
+	case reflect.Ptr, reflect.Interface, reflect.Func, reflect.Chan:
+		if val.IsNil() {
+			return "nil"
+		}
+		return "<" + val.Type().String() + ">"
+	case reflect.Struct:
+		return "{...}"
+	default:
+		if val.CanInterface() {
+			return fmt.Sprintf("%v", val.Interface())
+		}
+		return "<" + val.Type().String() + ">"
+	}
+}
+
 func (ci *ComponentInspectorComponent) updateIntField(storage *ecs.Storage, entityId ecs.EntityId, compType reflect.Type, fieldIdx int, value int64, fieldType reflect.Type) {
 	component := storage.GetComponent(entityId, compType)
 	if component == nil {
@@ -0,0 +1,93 @@
+package debugui
+
+import (
+	"reflect"
+	"testing"
+)
+
+type inspectorNested struct {
+	Count int
+	label string
+}
+
+type inspectorMixedComponent struct {
+	Visible  int
+	pending  map[int]bool
+	secret   *inspectorNested
+	Nested   inspectorNested
+	private  inspectorNested
+	callback func()
+}
+
+func TestReflectionCacheUnexportedFields(t *testing.T) {
+	fields := NewReflectionCache().GetFields(reflect.TypeFor[inspectorMixedComponent]())
+
+	exported := map[string]bool{}
+	for _, f := range fields {
+		exported[f.Name] = f.Exported
+	}
+
+	expected := map[string]bool{
+		"Visible":  true,
+		"pending":  false,
+		"secret":   false,
+		"Nested":   true,
+		"private":  false,
+		"callback": false,
+	}
+	if !reflect.DeepEqual(exported, expected) {
+		t.Errorf("expected fields %v, got %v", expected, exported)
+	}
+}
+
+func TestFormatValueUnexportedFields(t *testing.T) {
+	component := inspectorMixedComponent{
+		Visible: 3,
+		pending: map[int]bool{1: true, 2: true},
+		secret:  &inspectorNested{Count: 7, label: "inner"},
+		private: inspectorNested{Count: 9, label: "private"},
+	}
+	val := reflect.ValueOf(&component).Elem()
+
+	var walk func(val reflect.Value, fields []FieldInfo) []string
+	walk = func(val reflect.Value, fields []FieldInfo) []string {
+		var out []string
+		for _, f := range fields {
+			fieldVal := val.Field(f.Index)
+			if f.IsPointer && !fieldVal.IsNil() {
+				fieldVal = fieldVal.Elem()
+			}
+			if fieldVal.Kind() == reflect.Struct {
+				out = append(out, walk(fieldVal, globalReflectionCache.GetFields(fieldVal.Type()))...)
+				continue
+			}
+			out = append(out, f.Name+"="+formatValue(fieldVal))
+		}
+		return out
+	}
+
+	got := walk(val, globalReflectionCache.GetFields(val.Type()))
+	expected := []string{
+		"Visible=3",
+		"pending=map[2 items]",
+		"Count=7", "label=inner",
+		"Count=0", "label=",
+		"Count=9", "label=private",
+		"callback=nil",
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+}
+
+func TestFormatComponent(t *testing.T) {
+	component := inspectorNested{Count: 4, label: "crate"}
+	if got := formatComponent(reflect.ValueOf(component)); got != "Count: 4, label: crate" {
+		t.Errorf("expected struct fields to be listed, got %q", got)
+	}
+
+	type score int
+	if got := formatComponent(reflect.ValueOf(score(12))); got != "12" {
+		t.Errorf("expected non-struct components to be formatted as values, got %q", got)
+	}
+}
@@ -6,11 +6,11 @@ import (
 
 type EntityBrowserComponent struct {
 	cache              *EntityBrowserCache
-	selectedEntityId   ecs.EntityId
 	filterText         string
 	filterArchetypeId  *uint32
 	maxEntitiesPerPage int
 	currentPage        int
+	target             storageSelector
 }
 
 type ComponentInspectorComponent struct {
@@ -22,15 +22,24 @@ type ArchetypeViewerComponent struct {
 	selectedArchId *uint32
 	sortColumn     int
 	sortAscending  bool
+	target         storageSelector
 }
 
 type PerformanceStatsComponent struct {
-	historyFrames int
-	frameHistory  []float32
-	frameIndex    int
+	historyFrames  int
+	frameHistory   []float32
+	frameIndex     int
+	lastMoveCounts map[[2]uint32]int64
 }
 
 type QueryDebuggerComponent struct {
 	selectedComponentTypes map[string]bool
 	cache                  *QueryDebuggerCache
+	target                 storageSelector
+}
+
+type TimeControlPanel struct {
+	scheduler      *ecs.Scheduler
+	advanceOptions []float64
+	stepDelta      float64
 }
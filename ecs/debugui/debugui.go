@@ -22,48 +22,76 @@ type ImguiInputState struct {
 
 // ImguiSystem queries all ImguiItem components and defers their render functions.
 // It also updates the ImguiInputState singleton with current input capture state.
+//
+// The entity browser, archetype viewer and query debugger inspect the storage the system runs
+// on by default. When the scheduler has named storages, or storages are listed in the
+// DebugStorages singleton, each of these windows shows a dropdown to switch between them.
 type ImguiSystem struct {
 	Items               ecs.Query[struct{ *ImguiItem }]
 	InputState          ecs.Singleton[ImguiInputState]
+	Selection           ecs.Singleton[Selection]
+	Storages            ecs.Singleton[DebugStorages]
 	EntityBrowsers      ecs.Query[struct{ *EntityBrowserComponent }]
 	ComponentInspectors ecs.Query[struct{ *ComponentInspectorComponent }]
 	ArchetypeViewers    ecs.Query[struct{ *ArchetypeViewerComponent }]
 	PerformanceStats    ecs.Query[struct{ *PerformanceStatsComponent }]
 	QueryDebuggers      ecs.Query[struct{ *QueryDebuggerComponent }]
+	TimeControlPanels   ecs.Query[struct{ *TimeControlPanel }]
 	FrameTimer          ecs.Singleton[FrameTimer]
 }
 
+// RunsWhilePaused keeps the debug UI responsive while the scheduler is paused.
+func (i *ImguiSystem) RunsWhilePaused() bool {
+	return true
+}
+
 // Execute updates input state and queues all ImGui render functions for execution.
 func (i *ImguiSystem) Execute(frame *ecs.UpdateFrame) {
-	state := i.InputState.Get()
+	state := i.InputState.GetOrCreate()
 	state.WantCaptureMouse = imgui.CurrentIO().WantCaptureMouse()
 	state.WantCaptureKeyboard = imgui.CurrentIO().WantCaptureKeyboard()
 
-	var selectedEntityId ecs.EntityId
+	selection := i.Selection.GetOrCreate()
+	storages := collectStorages(frame, i.Storages.Get())
 	var filterArchetypeId *uint32
+	var filterStorage *ecs.Storage
 
 	for browser := range i.EntityBrowsers.Iter() {
 		frame.Commands.Defer(func() {
-			browser.Render(frame.Storage)
-			selectedEntityId = browser.GetSelectedEntity()
+			storage := browser.target.bind(storages)
+			browser.Render(storage, selection)
 			if browser.filterArchetypeId != nil {
-				filterArchetypeId = browser.filterArchetypeId
+				filterArchetypeId, filterStorage = browser.filterArchetypeId, storage
 			}
 		})
 	}
 
 	for viewer := range i.ArchetypeViewers.Iter() {
 		frame.Commands.Defer(func() {
-			clickedArchId := viewer.Render(frame.Storage)
+			storage := viewer.target.bind(storages)
+			clickedArchId := viewer.Render(storage)
 			if clickedArchId != nil {
-				filterArchetypeId = clickedArchId
+				filterArchetypeId, filterStorage = clickedArchId, storage
 			}
 		})
 	}
 
+	// Archetype ids are only meaningful within a storage, so a clicked archetype only filters
+	// the browsers showing the same storage
+	frame.Commands.Defer(func() {
+		if filterArchetypeId == nil {
+			return
+		}
+		for browser := range i.EntityBrowsers.Iter() {
+			if browser.target.bind(storages) == filterStorage {
+				browser.filterArchetypeId = filterArchetypeId
+			}
+		}
+	})
+
 	for inspector := range i.ComponentInspectors.Iter() {
 		frame.Commands.Defer(func() {
-			inspector.Render(frame.Storage, selectedEntityId)
+			inspector.Render(selection)
 		})
 	}
 
@@ -81,17 +109,16 @@ func (i *ImguiSystem) Execute(frame *ecs.UpdateFrame) {
 
 	for debugger := range i.QueryDebuggers.Iter() {
 		frame.Commands.Defer(func() {
-			debugger.Render(frame.Storage)
+			debugger.Render(debugger.target.bind(storages), selection)
 		})
 	}
 
+	for panel := range i.TimeControlPanels.Iter() {
+		frame.Commands.Defer(panel.Render)
+	}
+
 	for item := range i.Items.Iter() {
 		frame.Commands.Defer(item.Render)
 	}
 
-	if filterArchetypeId != nil {
-		for browser := range i.EntityBrowsers.Iter() {
-			browser.filterArchetypeId = filterArchetypeId
-		}
-	}
 }
@@ -79,7 +79,7 @@ func Example() {
 	game := &Game{
 		storage:      storage,
 		scheduler:    scheduler,
-		imguiBackend: ecs.NewSingleton[debugui_ebiten.ImguiBackend](storage),
+		imguiBackend: ecs.BindSingleton[debugui_ebiten.ImguiBackend](storage),
 	}
 
 	// Run the game
@@ -2,6 +2,7 @@ package debugui
 
 import (
 	"fmt"
+	"slices"
 	"sort"
 	"strings"
 
@@ -16,11 +17,17 @@ type EntityInfo struct {
 	ComponentCount int
 }
 
+// EntityBrowserCache holds the rows shown by the entity browser. It is built once and then
+// kept up to date incrementally from the storage's structural change notifications.
 type EntityBrowserCache struct {
-	entities           []EntityInfo
-	lastArchetypeCount int
-	sortColumn         int
-	sortAscending      bool
+	entities       []EntityInfo
+	indexById      map[ecs.EntityId]int
+	componentTypes map[uint32][]string
+	storage        *ecs.Storage
+	unsubscribe    func()
+	needsSort      bool
+	sortColumn     int
+	sortAscending  bool
 }
 
 func NewEntityBrowserComponent(maxEntitiesPerPage int) EntityBrowserComponent {
@@ -33,12 +40,14 @@ func NewEntityBrowserComponent(maxEntitiesPerPage int) EntityBrowserComponent {
 	}
 }
 
-func (eb *EntityBrowserComponent) Render(storage *ecs.Storage) {
+// Render draws the browser. Clicking an entity makes it the selected entity of selection.
+func (eb *EntityBrowserComponent) Render(storage *ecs.Storage, selection *Selection) {
 	if !imgui.BeginV("Entity Browser", nil, imgui.WindowFlagsNone) {
 		imgui.End()
 		return
 	}
 
+	eb.target.render()
 	eb.rebuildCacheIfNeeded(storage)
 
 	imgui.InputTextWithHint("##search", "Search...", &eb.filterText, imgui.InputTextFlagsNone, nil)
@@ -78,10 +87,7 @@ func (eb *EntityBrowserComponent) Render(storage *ecs.Storage) {
 			imgui.TableNextRow()
 
 			imgui.TableNextColumn()
-			isSelected := eb.selectedEntityId == entity.ID
-			if imgui.SelectableBoolV(fmt.Sprintf("%d", entity.ID), isSelected, imgui.SelectableFlagsSpanAllColumns, imgui.NewVec2(0, 0)) {
-				eb.selectedEntityId = entity.ID
-			}
+			selection.Selectable(storage, entity.ID, fmt.Sprintf("%d", entity.ID))
 
 			imgui.TableNextColumn()
 			imgui.Text(fmt.Sprintf("0x%X", entity.ArchetypeID))
@@ -117,62 +123,170 @@ func (eb *EntityBrowserComponent) Render(storage *ecs.Storage) {
 }
 
 func (eb *EntityBrowserComponent) rebuildCacheIfNeeded(storage *ecs.Storage) {
-	currentArchetypeCount := len(storage.GetArchetypes())
-	if eb.cache.lastArchetypeCount != currentArchetypeCount {
-		eb.cache.entities = nil
-		eb.cache.lastArchetypeCount = currentArchetypeCount
-	}
+	eb.cache.bind(storage)
 
 	if eb.cache.entities == nil {
-		eb.rebuildCache(storage)
+		eb.cache.rebuild(storage)
+	}
+
+	if eb.cache.needsSort {
+		eb.sortEntities()
+	}
+}
+
+// bind subscribes the cache to structural changes of the storage, dropping any state
+// built for a previously bound storage.
+func (c *EntityBrowserCache) bind(storage *ecs.Storage) {
+	if c.storage == storage {
+		return
+	}
+
+	c.unbind()
+	c.storage = storage
+	c.unsubscribe = storage.OnStructuralChange(func(change ecs.StructuralChange) {
+		c.applyChange(storage, change)
+	})
+}
+
+// unbind unsubscribes the cache from its storage and drops its rows
+func (c *EntityBrowserCache) unbind() {
+	if c.unsubscribe != nil {
+		c.unsubscribe()
+	}
+	c.storage = nil
+	c.unsubscribe = nil
+	c.entities = nil
+}
+
+// Dispose unsubscribes the browser's cache from storage changes once the browser is deleted
+func (eb *EntityBrowserComponent) Dispose() {
+	if eb.cache != nil {
+		eb.cache.unbind()
 	}
 }
 
-func (eb *EntityBrowserComponent) rebuildCache(storage *ecs.Storage) {
-	eb.cache.entities = make([]EntityInfo, 0, 1024)
+func (c *EntityBrowserCache) rebuild(storage *ecs.Storage) {
+	c.entities = make([]EntityInfo, 0, 1024)
+	c.indexById = make(map[ecs.EntityId]int)
+	c.componentTypes = make(map[uint32][]string)
 
 	for _, archetype := range storage.GetArchetypes() {
-		componentTypes := make([]string, len(archetype.Types()))
-		for i, t := range archetype.Types() {
-			componentTypes[i] = t.String()
+		for entityId := range archetype.Iter() {
+			c.add(archetype, entityId)
 		}
+	}
 
-		for entityId := range archetype.Iter() {
-			eb.cache.entities = append(eb.cache.entities, EntityInfo{
-				ID:             entityId,
-				ArchetypeID:    archetype.ID(),
-				ComponentTypes: componentTypes,
-				ComponentCount: len(componentTypes),
-			})
+	c.needsSort = true
+}
+
+// applyChange patches the cached rows for a single spawn, delete or move. Once the rows are
+// sorted, new rows are inserted in order so the table doesn't have to be sorted again.
+func (c *EntityBrowserCache) applyChange(storage *ecs.Storage, change ecs.StructuralChange) {
+	if c.entities == nil {
+		return
+	}
+
+	switch change.Kind {
+	case ecs.EntitySpawned:
+		c.insert(storage.ArchetypeOf(change.Id), change.Id)
+	case ecs.EntityDeleted:
+		c.remove(change.Id)
+	case ecs.EntityMoved:
+		c.remove(change.OldId)
+		c.insert(storage.ArchetypeOf(change.Id), change.Id)
+	}
+}
+
+// info returns the row of an entity in archetype
+func (c *EntityBrowserCache) info(archetype *ecs.Archetype, entityId ecs.EntityId) EntityInfo {
+	componentTypes, ok := c.componentTypes[archetype.ID()]
+	if !ok {
+		componentTypes = make([]string, len(archetype.Types()))
+		for i, t := range archetype.Types() {
+			componentTypes[i] = ecs.TypeName(t)
 		}
+		c.componentTypes[archetype.ID()] = componentTypes
 	}
 
-	eb.sortEntities()
+	return EntityInfo{
+		ID:             entityId,
+		ArchetypeID:    archetype.ID(),
+		ComponentTypes: componentTypes,
+		ComponentCount: len(componentTypes),
+	}
+}
+
+// add appends the row of an entity, leaving the rows to be sorted
+func (c *EntityBrowserCache) add(archetype *ecs.Archetype, entityId ecs.EntityId) {
+	if archetype == nil {
+		return
+	}
+
+	c.indexById[entityId] = len(c.entities)
+	c.entities = append(c.entities, c.info(archetype, entityId))
+}
+
+// insert adds the row of an entity at its place in the sort order, or appends it if the rows
+// are waiting to be sorted anyway
+func (c *EntityBrowserCache) insert(archetype *ecs.Archetype, entityId ecs.EntityId) {
+	if archetype == nil {
+		return
+	}
+	if c.needsSort {
+		c.add(archetype, entityId)
+		return
+	}
+
+	info := c.info(archetype, entityId)
+	at := sort.Search(len(c.entities), func(i int) bool { return c.less(info, c.entities[i]) })
+	c.entities = slices.Insert(c.entities, at, info)
+	c.reindex(at)
+}
+
+// remove drops the row of an entity, keeping the other rows in order
+func (c *EntityBrowserCache) remove(entityId ecs.EntityId) {
+	idx, ok := c.indexById[entityId]
+	if !ok {
+		return
+	}
+
+	c.entities = slices.Delete(c.entities, idx, idx+1)
+	delete(c.indexById, entityId)
+	c.reindex(idx)
+}
+
+// reindex updates indexById for the rows starting at from
+func (c *EntityBrowserCache) reindex(from int) {
+	for i := from; i < len(c.entities); i++ {
+		c.indexById[c.entities[i].ID] = i
+	}
+}
+
+// less reports whether row a is listed before row b in the current sort order
+func (c *EntityBrowserCache) less(a, b EntityInfo) bool {
+	if !c.sortAscending {
+		a, b = b, a
+	}
+
+	switch c.sortColumn {
+	case 1:
+		return a.ArchetypeID < b.ArchetypeID
+	case 2:
+		return strings.Join(a.ComponentTypes, ",") < strings.Join(b.ComponentTypes, ",")
+	case 3:
+		return a.ComponentCount < b.ComponentCount
+	default:
+		return a.ID < b.ID
+	}
 }
 
 func (eb *EntityBrowserComponent) sortEntities() {
 	sort.Slice(eb.cache.entities, func(i, j int) bool {
-		a, b := eb.cache.entities[i], eb.cache.entities[j]
-		var less bool
-
-		switch eb.cache.sortColumn {
-		case 0:
-			less = a.ID < b.ID
-		case 1:
-			less = a.ArchetypeID < b.ArchetypeID
-		case 2:
-			less = strings.Join(a.ComponentTypes, ",") < strings.Join(b.ComponentTypes, ",")
-		case 3:
-			less = a.ComponentCount < b.ComponentCount
-		default:
-			less = a.ID < b.ID
-		}
-
-		if !eb.cache.sortAscending {
-			return !less
-		}
-		return less
+		return eb.cache.less(eb.cache.entities[i], eb.cache.entities[j])
 	})
+
+	eb.cache.reindex(0)
+	eb.cache.needsSort = false
 }
 
 func (eb *EntityBrowserComponent) getFilteredEntities() []EntityInfo {
@@ -205,7 +319,3 @@ func (eb *EntityBrowserComponent) getFilteredEntities() []EntityInfo {
 
 	return filtered
 }
-
-func (eb *EntityBrowserComponent) GetSelectedEntity() ecs.EntityId {
-	return eb.selectedEntityId
-}
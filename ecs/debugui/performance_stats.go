@@ -2,6 +2,7 @@ package debugui
 
 import (
 	"fmt"
+	"sort"
 	"time"
 
 	"github.com/AllenDang/cimgui-go/imgui"
@@ -66,6 +67,10 @@ func (ps *PerformanceStatsComponent) Render(storage *ecs.Storage, deltaTime floa
 		imgui.TreePop()
 	}
 
+	if stats.MoveTrackingEnabled {
+		ps.renderArchetypeMoves(stats)
+	}
+
 	if imgui.TreeNodeStr("Singleton Details") {
 		for _, singletonType := range stats.SingletonTypes {
 			imgui.BulletText(singletonType)
@@ -76,6 +81,60 @@ func (ps *PerformanceStatsComponent) Render(storage *ecs.Storage, deltaTime floa
 	imgui.End()
 }
 
+// renderArchetypeMoves shows the archetype moves made since the previous frame, highlighting
+// transitions that happen often enough to suggest components are added and removed every frame.
+func (ps *PerformanceStatsComponent) renderArchetypeMoves(stats *ecs.StorageStats) {
+	const hotspotMovesPerFrame = 100
+
+	perFrame := make([]ecs.ArchetypeMoveStats, 0, len(stats.MoveTransitions))
+	counts := make(map[[2]uint32]int64, len(stats.MoveTransitions))
+	var totalPerFrame int64
+	for _, move := range stats.MoveTransitions {
+		key := [2]uint32{move.From, move.To}
+		counts[key] = move.Count
+
+		delta := move.Count - ps.lastMoveCounts[key]
+		if delta > 0 {
+			perFrame = append(perFrame, ecs.ArchetypeMoveStats{From: move.From, To: move.To, Count: delta})
+			totalPerFrame += delta
+		}
+	}
+	ps.lastMoveCounts = counts
+
+	sort.Slice(perFrame, func(i, j int) bool {
+		return perFrame[i].Count > perFrame[j].Count
+	})
+
+	imgui.Text(fmt.Sprintf("Archetype Moves: %d total, %d this frame", stats.ArchetypeMoves, totalPerFrame))
+
+	if imgui.TreeNodeStr("Archetype Moves") {
+		const tableFlags = imgui.TableFlagsBorders | imgui.TableFlagsRowBg
+		if imgui.BeginTableV("ArchMovesTable", 3, tableFlags, imgui.NewVec2(0, 0), 0) {
+			imgui.TableSetupColumn("From")
+			imgui.TableSetupColumn("To")
+			imgui.TableSetupColumn("Moves/Frame")
+			imgui.TableHeadersRow()
+
+			for _, move := range perFrame {
+				imgui.TableNextRow()
+				imgui.TableNextColumn()
+				imgui.Text(fmt.Sprintf("0x%X", move.From))
+				imgui.TableNextColumn()
+				imgui.Text(fmt.Sprintf("0x%X", move.To))
+				imgui.TableNextColumn()
+				if move.Count >= hotspotMovesPerFrame {
+					imgui.TextColored(imgui.NewVec4(1.0, 0.4, 0.4, 1.0), fmt.Sprintf("%d", move.Count))
+				} else {
+					imgui.Text(fmt.Sprintf("%d", move.Count))
+				}
+			}
+
+			imgui.EndTable()
+		}
+		imgui.TreePop()
+	}
+}
+
 type FrameTimer struct {
 	lastFrameTime time.Time
 }
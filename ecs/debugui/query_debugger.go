@@ -4,11 +4,15 @@ import (
 	"fmt"
 	"reflect"
 	"sort"
+	"strings"
 
 	"github.com/AllenDang/cimgui-go/imgui"
 	"github.com/plus3/ooftn/ecs"
 )
 
+// maxQueryRows caps the entities listed in the Query Debugger's table
+const maxQueryRows = 200
+
 type QueryDebuggerCache struct {
 	componentTypes     []string
 	lastArchetypeCount int
@@ -23,12 +27,15 @@ func NewQueryDebuggerComponent() QueryDebuggerComponent {
 	}
 }
 
-func (qd *QueryDebuggerComponent) Render(storage *ecs.Storage) {
+// Render draws the debugger. Clicking an entity in the table of matching entities makes it
+// the selected entity of selection.
+func (qd *QueryDebuggerComponent) Render(storage *ecs.Storage, selection *Selection) {
 	if !imgui.BeginV("Query Debugger", nil, imgui.WindowFlagsNone) {
 		imgui.End()
 		return
 	}
 
+	qd.target.render()
 	qd.rebuildCacheIfNeeded(storage)
 
 	imgui.Text("Select Component Types:")
@@ -56,7 +63,7 @@ func (qd *QueryDebuggerComponent) Render(storage *ecs.Storage) {
 
 	for _, archetype := range storage.GetArchetypes() {
 		for _, t := range archetype.Types() {
-			typeMap[t.String()] = t
+			typeMap[ecs.TypeName(t)] = t
 		}
 	}
 
@@ -65,6 +72,9 @@ func (qd *QueryDebuggerComponent) Render(storage *ecs.Storage) {
 			selectedTypes = append(selectedTypes, t)
 		}
 	}
+	sort.Slice(selectedTypes, func(a, b int) bool {
+		return ecs.TypeName(selectedTypes[a]) < ecs.TypeName(selectedTypes[b])
+	})
 
 	if len(selectedTypes) == 0 {
 		imgui.Text("No component types selected")
@@ -100,16 +110,12 @@ func (qd *QueryDebuggerComponent) Render(storage *ecs.Storage) {
 				imgui.TableSetColumnIndex(1)
 				componentNames := make([]string, len(arch.Types()))
 				for i, t := range arch.Types() {
-					componentNames[i] = t.String()
+					componentNames[i] = ecs.TypeName(t)
 				}
 				imgui.Text(fmt.Sprintf("%v", componentNames))
 
 				imgui.TableSetColumnIndex(2)
-				entityCount := 0
-				for range arch.Iter() {
-					entityCount++
-				}
-				imgui.Text(fmt.Sprintf("%d", entityCount))
+				imgui.Text(fmt.Sprintf("%d", arch.Len()))
 			}
 
 			imgui.EndTable()
@@ -117,9 +123,64 @@ func (qd *QueryDebuggerComponent) Render(storage *ecs.Storage) {
 		imgui.TreePop()
 	}
 
+	qd.renderEntities(storage, selection, selectedTypes, totalEntities)
+
 	imgui.End()
 }
 
+// renderEntities draws a table of the matching entities with a column per selected type
+func (qd *QueryDebuggerComponent) renderEntities(storage *ecs.Storage, selection *Selection, selectedTypes []reflect.Type, totalEntities int) {
+	if !imgui.TreeNodeExStrV("Entities", imgui.TreeNodeFlagsDefaultOpen) {
+		return
+	}
+	defer imgui.TreePop()
+
+	if totalEntities > maxQueryRows {
+		imgui.TextDisabled(fmt.Sprintf("Showing the first %d entities", maxQueryRows))
+	}
+
+	const tableFlags = imgui.TableFlagsBorders | imgui.TableFlagsRowBg | imgui.TableFlagsResizable
+	if !imgui.BeginTableV("QueryEntityTable", int32(len(selectedTypes)+1), tableFlags, imgui.NewVec2(0, 0), 0) {
+		return
+	}
+	imgui.TableSetupColumn("Entity ID")
+	for _, t := range selectedTypes {
+		imgui.TableSetupColumn(ecs.TypeName(t))
+	}
+	imgui.TableHeadersRow()
+
+	rows := 0
+	for id, components := range storage.IterDynamic(selectedTypes...) {
+		if rows == maxQueryRows {
+			break
+		}
+		rows++
+
+		imgui.TableNextRow()
+		imgui.TableNextColumn()
+		selection.Selectable(storage, id, fmt.Sprintf("%d", id))
+		for _, t := range selectedTypes {
+			imgui.TableNextColumn()
+			imgui.Text(formatComponent(reflect.ValueOf(components[t]).Elem()))
+		}
+	}
+
+	imgui.EndTable()
+}
+
+// formatComponent summarizes a component on one line, listing the fields of struct components
+func formatComponent(val reflect.Value) string {
+	if val.Kind() != reflect.Struct {
+		return formatValue(val)
+	}
+
+	fields := make([]string, 0, val.NumField())
+	for i := range val.NumField() {
+		fields = append(fields, val.Type().Field(i).Name+": "+formatValue(val.Field(i)))
+	}
+	return strings.Join(fields, ", ")
+}
+
 func (qd *QueryDebuggerComponent) rebuildCacheIfNeeded(storage *ecs.Storage) {
 	currentArchetypeCount := len(storage.GetArchetypes())
 	if qd.cache.lastArchetypeCount != currentArchetypeCount {
@@ -137,7 +198,7 @@ func (qd *QueryDebuggerComponent) rebuildCache(storage *ecs.Storage) {
 
 	for _, archetype := range storage.GetArchetypes() {
 		for _, t := range archetype.Types() {
-			typeMap[t.String()] = true
+			typeMap[ecs.TypeName(t)] = true
 		}
 	}
 
@@ -9,6 +9,7 @@ type FieldInfo struct {
 	Name      string
 	Type      reflect.Type
 	Index     int
+	Exported  bool
 	IsPointer bool
 	IsStruct  bool
 	IsSlice   bool
@@ -26,6 +27,8 @@ func NewReflectionCache() *ReflectionCache {
 	}
 }
 
+// GetFields returns the fields of a struct type, including unexported ones.
+// Unexported fields have Exported set to false and must only be displayed read-only.
 func (rc *ReflectionCache) GetFields(t reflect.Type) []FieldInfo {
 	rc.mu.RLock()
 	cached, ok := rc.fieldCache[t]
@@ -45,10 +48,6 @@ func (rc *ReflectionCache) GetFields(t reflect.Type) []FieldInfo {
 	if t.Kind() == reflect.Struct {
 		for i := 0; i < t.NumField(); i++ {
 			field := t.Field(i)
-			if !field.IsExported() {
-				continue
-			}
-
 			fieldType := field.Type
 			isPointer := fieldType.Kind() == reflect.Ptr
 			if isPointer {
@@ -59,6 +58,7 @@ func (rc *ReflectionCache) GetFields(t reflect.Type) []FieldInfo {
 				Name:      field.Name,
 				Type:      fieldType,
 				Index:     i,
+				Exported:  field.IsExported(),
 				IsPointer: isPointer,
 				IsStruct:  fieldType.Kind() == reflect.Struct,
 				IsSlice:   fieldType.Kind() == reflect.Slice,
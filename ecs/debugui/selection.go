@@ -0,0 +1,70 @@
+package debugui
+
+import (
+	"github.com/AllenDang/cimgui-go/imgui"
+	"github.com/plus3/ooftn/ecs"
+)
+
+// Selection is a singleton holding the selected entity, shared by the entity browser, the
+// component inspector and any gameplay code that lets the player pick an entity. The entity
+// is held as an EntityRef, so the selection follows it when it moves to another archetype
+// and is cleared when it is deleted. The selection remembers the storage the entity belongs
+// to, so the inspector shows it whichever storage the entity browser was switched to.
+type Selection struct {
+	ref     *ecs.EntityRef
+	storage *ecs.Storage
+}
+
+// Select makes id of storage the selected entity. Selecting an entity that doesn't exist
+// clears the selection.
+func (s *Selection) Select(storage *ecs.Storage, id ecs.EntityId) {
+	s.ref = storage.CreateEntityRef(id)
+	s.storage = storage
+}
+
+// Clear deselects the selected entity
+func (s *Selection) Clear() {
+	s.ref = nil
+	s.storage = nil
+}
+
+// Storage returns the storage of the selected entity, or nil if nothing is selected or the
+// selected entity was deleted
+func (s *Selection) Storage() *ecs.Storage {
+	if _, ok := s.Selected(); !ok {
+		return nil
+	}
+	return s.storage
+}
+
+// Selected returns the current id of the selected entity, or false if nothing is selected
+// or the selected entity was deleted
+func (s *Selection) Selected() (ecs.EntityId, bool) {
+	if s.ref == nil || !s.ref.Id.IsValid() {
+		return ecs.InvalidEntityId, false
+	}
+	return s.ref.Id, true
+}
+
+// IsSelected reports whether id is the selected entity. Ids are only unique within a storage,
+// use IsSelectedIn when several storages are inspected.
+func (s *Selection) IsSelected(id ecs.EntityId) bool {
+	selected, ok := s.Selected()
+	return ok && selected == id
+}
+
+// IsSelectedIn reports whether id of storage is the selected entity
+func (s *Selection) IsSelectedIn(storage *ecs.Storage, id ecs.EntityId) bool {
+	return s.storage == storage && s.IsSelected(id)
+}
+
+// Selectable renders an ImGui selectable for the entity that is highlighted while the entity
+// is selected and selects it when clicked. In a table row it spans all columns. Returns true
+// if it was clicked.
+func (s *Selection) Selectable(storage *ecs.Storage, id ecs.EntityId, label string) bool {
+	if !imgui.SelectableBoolV(label, s.IsSelectedIn(storage, id), imgui.SelectableFlagsSpanAllColumns, imgui.NewVec2(0, 0)) {
+		return false
+	}
+	s.Select(storage, id)
+	return true
+}
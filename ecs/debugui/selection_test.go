@@ -0,0 +1,57 @@
+package debugui
+
+import "testing"
+
+func TestSelection(t *testing.T) {
+	storage := newCacheStorage()
+	id := storage.Spawn(cachePosition{})
+	other := storage.Spawn(cachePosition{})
+
+	var selection Selection
+	if _, ok := selection.Selected(); ok {
+		t.Fatal("expected an empty selection")
+	}
+
+	selection.Select(storage, id)
+	if !selection.IsSelected(id) || selection.IsSelected(other) {
+		t.Errorf("expected only %d to be selected", id)
+	}
+
+	// The selection follows the entity when its archetype changes
+	moved := storage.AddComponent(id, cacheVelocity{})
+	if selected, ok := selection.Selected(); !ok || selected != moved {
+		t.Errorf("expected the selection to follow the entity to %d, got %d", moved, selected)
+	}
+
+	storage.Delete(moved)
+	if _, ok := selection.Selected(); ok {
+		t.Error("expected deleting the entity to clear the selection")
+	}
+
+	selection.Select(storage, other)
+	selection.Clear()
+	if selection.IsSelected(other) {
+		t.Error("expected Clear to deselect the entity")
+	}
+
+	t.Run("storages", func(t *testing.T) {
+		other := newCacheStorage()
+		id := storage.Spawn(cachePosition{})
+		otherId := other.Spawn(cachePosition{})
+
+		var selection Selection
+		selection.Select(other, otherId)
+		if selection.Storage() != other {
+			t.Error("expected the selection to remember the entity's storage")
+		}
+		if selection.IsSelectedIn(storage, otherId) || !selection.IsSelectedIn(other, otherId) {
+			t.Error("expected the entity to be selected in its own storage only")
+		}
+
+		selection.Select(storage, id)
+		storage.Delete(id)
+		if selection.Storage() != nil {
+			t.Error("expected no storage once the selected entity is deleted")
+		}
+	})
+}
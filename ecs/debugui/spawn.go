@@ -16,5 +16,6 @@ func RegisterDebugUIComponents(registry *ecs.ComponentRegistry) {
 	ecs.RegisterComponent[ArchetypeViewerComponent](registry)
 	ecs.RegisterComponent[PerformanceStatsComponent](registry)
 	ecs.RegisterComponent[QueryDebuggerComponent](registry)
+	ecs.RegisterComponent[TimeControlPanel](registry)
 	ecs.RegisterComponent[FrameTimer](registry)
 }
@@ -0,0 +1,95 @@
+package debugui
+
+import (
+	"github.com/AllenDang/cimgui-go/imgui"
+	"github.com/plus3/ooftn/ecs"
+)
+
+// mainStorageName labels the storage the ImguiSystem runs on in storage dropdowns
+const mainStorageName = "main"
+
+// DebugStorages is a singleton listing storages the debug windows can inspect besides the
+// ones managed by the scheduler, e.g. a storage owned by another scheduler. The scheduler's
+// main storage and the storages added to it with AddStorage are always listed.
+type DebugStorages struct {
+	names    []string
+	storages map[string]*ecs.Storage
+}
+
+// Add makes storage selectable in the debug windows under name. Adding a name again replaces
+// its storage. Panics if name is empty.
+func (d *DebugStorages) Add(name string, storage *ecs.Storage) {
+	if name == "" {
+		panic("debugui: storage name cannot be empty")
+	}
+	if d.storages == nil {
+		d.storages = make(map[string]*ecs.Storage)
+	}
+	if _, exists := d.storages[name]; !exists {
+		d.names = append(d.names, name)
+	}
+	d.storages[name] = storage
+}
+
+// storageChoices lists the storages selectable during one frame, the main storage first
+type storageChoices struct {
+	names    []string
+	storages []*ecs.Storage
+}
+
+// collectStorages lists the frame's storages followed by the extra ones, skipping names
+// already taken by the scheduler
+func collectStorages(frame *ecs.UpdateFrame, extra *DebugStorages) storageChoices {
+	choices := storageChoices{
+		names:    []string{mainStorageName},
+		storages: []*ecs.Storage{frame.Storage},
+	}
+	for _, name := range frame.StorageNames() {
+		choices.names = append(choices.names, name)
+		choices.storages = append(choices.storages, frame.StorageNamed(name))
+	}
+	if extra != nil {
+		for _, name := range extra.names {
+			if frame.StorageNamed(name) == nil {
+				choices.names = append(choices.names, name)
+				choices.storages = append(choices.storages, extra.storages[name])
+			}
+		}
+	}
+	return choices
+}
+
+// storageSelector is the storage a debug window inspects. The zero value selects the main storage.
+type storageSelector struct {
+	name    string
+	choices storageChoices
+}
+
+// bind sets the storages to choose from and returns the selected one. The main storage is
+// selected again if the selected storage is no longer listed.
+func (s *storageSelector) bind(choices storageChoices) *ecs.Storage {
+	s.choices = choices
+	for i, name := range choices.names {
+		if name == s.name {
+			return choices.storages[i]
+		}
+	}
+	s.name = mainStorageName
+	return choices.storages[0]
+}
+
+// render draws a dropdown to switch storage when there is more than one to choose from. The
+// new storage is inspected from the next frame on.
+func (s *storageSelector) render() {
+	if len(s.choices.names) < 2 {
+		return
+	}
+	if imgui.BeginCombo("Storage", s.name) {
+		for _, name := range s.choices.names {
+			if imgui.SelectableBoolV(name, name == s.name, imgui.SelectableFlagsNone, imgui.NewVec2(0, 0)) {
+				s.name = name
+			}
+		}
+		imgui.EndCombo()
+	}
+}
@@ -0,0 +1,42 @@
+package debugui
+
+import (
+	"testing"
+
+	"github.com/plus3/ooftn/ecs"
+)
+
+func TestStorageSelector(t *testing.T) {
+	primary, sim := newCacheStorage(), newCacheStorage()
+	choices := storageChoices{
+		names:    []string{mainStorageName, "sim"},
+		storages: []*ecs.Storage{primary, sim},
+	}
+
+	var selector storageSelector
+	if selector.bind(choices) != primary {
+		t.Error("expected the main storage to be selected by default")
+	}
+
+	selector.name = "sim"
+	if selector.bind(choices) != sim {
+		t.Error("expected the named storage to be selected")
+	}
+
+	// A storage that is no longer listed falls back to the main storage
+	choices.names, choices.storages = choices.names[:1], choices.storages[:1]
+	if selector.bind(choices) != primary || selector.name != mainStorageName {
+		t.Errorf("expected to fall back to the main storage, got %q", selector.name)
+	}
+}
+
+func TestDebugStorages(t *testing.T) {
+	first, second := newCacheStorage(), newCacheStorage()
+
+	var storages DebugStorages
+	storages.Add("replay", first)
+	storages.Add("replay", second)
+	if len(storages.names) != 1 || storages.storages["replay"] != second {
+		t.Errorf("expected adding a name again to replace its storage, got %v", storages.names)
+	}
+}
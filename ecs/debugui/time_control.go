@@ -0,0 +1,121 @@
+package debugui
+
+import (
+	"fmt"
+
+	"github.com/AllenDang/cimgui-go/imgui"
+	"github.com/plus3/ooftn/ecs"
+)
+
+// NewTimeControlPanel creates a panel that pauses, single-steps and fast-forwards the given scheduler.
+// Systems that should keep running while paused (such as ImguiSystem) must implement ecs.PauseExempt.
+func NewTimeControlPanel(scheduler *ecs.Scheduler) TimeControlPanel {
+	return TimeControlPanel{
+		scheduler:      scheduler,
+		advanceOptions: []float64{1, 5, 60},
+		stepDelta:      1.0 / 60.0,
+	}
+}
+
+func (tc *TimeControlPanel) Render() {
+	if !imgui.BeginV("Time Control", nil, imgui.WindowFlagsNone) {
+		imgui.End()
+		return
+	}
+
+	scheduler := tc.scheduler
+	if scheduler.Paused() {
+		pushButtonColors(imgui.NewVec4(0.2, 0.7, 0.2, 1.0), imgui.NewVec4(0.3, 0.8, 0.3, 1.0), imgui.NewVec4(0.1, 0.6, 0.1, 1.0))
+		if imgui.Button("Resume") {
+			scheduler.Resume()
+		}
+		imgui.PopStyleColorV(3)
+
+		imgui.TextColored(imgui.NewVec4(1.0, 0.8, 0.0, 1.0), "PAUSED")
+
+		if advanced, target := scheduler.AdvanceProgress(); target > 0 {
+			progress := float32(advanced / target)
+			imgui.ProgressBarV(progress, imgui.NewVec2(-1, 0), fmt.Sprintf("%.1f/%.1fs", advanced, target))
+		}
+
+		imgui.Separator()
+		imgui.Text("Step Forward:")
+
+		if imgui.Button("1 Tick") {
+			scheduler.StepOnce()
+		}
+
+		for _, seconds := range tc.advanceOptions {
+			imgui.SameLine()
+			if imgui.Button(formatAdvance(seconds)) {
+				scheduler.Advance(seconds)
+			}
+		}
+	} else {
+		pushButtonColors(imgui.NewVec4(0.7, 0.2, 0.2, 1.0), imgui.NewVec4(0.8, 0.3, 0.3, 1.0), imgui.NewVec4(0.6, 0.1, 0.1, 1.0))
+		if imgui.Button("Pause") {
+			scheduler.Pause()
+		}
+		imgui.PopStyleColorV(3)
+
+		imgui.TextColored(imgui.NewVec4(0.0, 1.0, 0.0, 1.0), "RUNNING")
+	}
+
+	stats := scheduler.GetStats()
+	imgui.Separator()
+	imgui.Text(fmt.Sprintf("Systems: %d", stats.SystemCount))
+	imgui.Text(fmt.Sprintf("Total Executions: %d", stats.TotalExecutions))
+
+	if imgui.TreeNodeStr("System Timings") {
+		const tableFlags = imgui.TableFlagsBorders | imgui.TableFlagsRowBg
+		if imgui.BeginTableV("TimeControlSystemsTable", 4, tableFlags, imgui.NewVec2(0, 0), 0) {
+			imgui.TableSetupColumn("System")
+			imgui.TableSetupColumn("Last (ms)")
+			imgui.TableSetupColumn("Avg (ms)")
+			imgui.TableSetupColumn("")
+			imgui.TableHeadersRow()
+
+			for _, sys := range stats.Systems {
+				imgui.TableNextRow()
+				imgui.TableNextColumn()
+				imgui.Text(sys.Name)
+				imgui.TableNextColumn()
+				imgui.Text(fmt.Sprintf("%.3f", float64(sys.LastDuration.Microseconds())/1000.0))
+				imgui.TableNextColumn()
+				imgui.Text(fmt.Sprintf("%.3f", float64(sys.AvgDuration.Microseconds())/1000.0))
+				imgui.TableNextColumn()
+
+				// Stepping a single system only makes sense while the rest of the world is paused
+				if scheduler.Paused() && imgui.SmallButton("Run##"+sys.Name) {
+					scheduler.RunSystemByName(sys.Name, tc.stepDelta)
+				}
+			}
+
+			imgui.EndTable()
+		}
+		imgui.TreePop()
+	}
+
+	imgui.End()
+}
+
+func pushButtonColors(normal, hovered, active imgui.Vec4) {
+	imgui.PushStyleColorVec4(imgui.ColButton, normal)
+	imgui.PushStyleColorVec4(imgui.ColButtonHovered, hovered)
+	imgui.PushStyleColorVec4(imgui.ColButtonActive, active)
+}
+
+func formatAdvance(seconds float64) string {
+	switch {
+	case seconds >= 60 && int(seconds)%60 == 0:
+		minutes := int(seconds) / 60
+		if minutes == 1 {
+			return "1 Minute"
+		}
+		return fmt.Sprintf("%d Minutes", minutes)
+	case seconds == 1:
+		return "1 Second"
+	default:
+		return fmt.Sprintf("%g Seconds", seconds)
+	}
+}
@@ -0,0 +1,128 @@
+package ecs
+
+import (
+	"iter"
+	"math/bits"
+)
+
+// SpawnDisabled creates a new entity like Spawn, but disabled. Disabled entities occupy
+// archetype slots and have valid ids and refs, and can be read and modified by id, but
+// are skipped by View, Query, ReadView, ComponentView and IterMut2 iteration until they are
+// enabled with SetEnabled. This suits object pools that are filled ahead of time and
+// activated on demand without paying the spawn cost during gameplay.
+func (s *Storage) SpawnDisabled(components ...any) EntityId {
+	id := s.SpawnSlice(components)
+	s.ArchetypeOf(id).setDisabled(int(id.Index()), true)
+	return id
+}
+
+// SetEnabled enables or disables an existing entity. It doesn't move the entity, so its id
+// and refs are unaffected. Returns false if the entity doesn't exist.
+func (s *Storage) SetEnabled(id EntityId, enabled bool) bool {
+	archetype := s.ArchetypeOf(id)
+	if archetype == nil {
+		return false
+	}
+	archetype.setDisabled(int(id.Index()), !enabled)
+	return true
+}
+
+// IsEnabled reports whether the entity exists and is enabled
+func (s *Storage) IsEnabled(id EntityId) bool {
+	archetype := s.ArchetypeOf(id)
+	return archetype != nil && !archetype.isDisabled(int(id.Index()))
+}
+
+// DisabledCount returns the number of disabled entities in this archetype
+func (a *Archetype) DisabledCount() int {
+	return a.disabledCount
+}
+
+func (a *Archetype) isDisabled(index int) bool {
+	if a.disabledCount == 0 {
+		return false
+	}
+	word := index / 64
+	return word < len(a.disabled) && a.disabled[word]&(1<<(index%64)) != 0
+}
+
+func (a *Archetype) setDisabled(index int, disabled bool) {
+	if a.isDisabled(index) == disabled {
+		return
+	}
+	a.version++
+
+	word := index / 64
+	if disabled {
+		for word >= len(a.disabled) {
+			a.disabled = append(a.disabled, 0)
+		}
+		a.disabled[word] |= 1 << (index % 64)
+		a.disabledCount++
+		return
+	}
+	a.disabled[word] &^= 1 << (index % 64)
+	a.disabledCount--
+}
+
+// enabledIndices iterates the live slots of the archetype, skipping disabled entities
+func (a *Archetype) enabledIndices() iter.Seq[int] {
+	return a.enabledIndicesFrom(0)
+}
+
+// enabledIndicesFrom is like enabledIndices but starts at slot start
+func (a *Archetype) enabledIndicesFrom(start int) iter.Seq[int] {
+	if len(a.storages) == 0 {
+		return func(func(int) bool) {}
+	}
+
+	indices := a.storages[0].IterFrom(start)
+	if a.disabledCount == 0 {
+		return indices
+	}
+
+	return func(yield func(int) bool) {
+		for index := range indices {
+			if a.isDisabled(index) {
+				continue
+			}
+			if !yield(index) {
+				return
+			}
+		}
+	}
+}
+
+// disabledIndices iterates the slots of the archetype flagged as disabled
+func (a *Archetype) disabledIndices() iter.Seq[int] {
+	return func(yield func(int) bool) {
+		if a.disabledCount == 0 {
+			return
+		}
+		for word, mask := range a.disabled {
+			for mask != 0 {
+				index := word*64 + bits.TrailingZeros64(mask)
+				if !yield(index) {
+					return
+				}
+				mask &= mask - 1
+			}
+		}
+	}
+}
+
+// remapDisabled moves disabled flags to their new slots after compaction
+func (a *Archetype) remapDisabled(indexMap map[int]int) {
+	if a.disabledCount == 0 {
+		return
+	}
+
+	old := a.disabled
+	a.disabled = nil
+	a.disabledCount = 0
+	for oldIdx, newIdx := range indexMap {
+		if word := oldIdx / 64; word < len(old) && old[word]&(1<<(oldIdx%64)) != 0 {
+			a.setDisabled(newIdx, true)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package ecs
+
+import (
+	"iter"
+	"reflect"
+)
+
+// IterDynamic returns an iterator over the enabled entities that have every component type
+// in types, paired with a map from each of those types to a pointer to the entity's
+// component. It is meant for tooling that picks component types at runtime, such as the
+// debug UI; systems should use a View or Query, which don't allocate a map per entity.
+// Entities are visited in the same order as by a View. With no types every entity is visited.
+func (s *Storage) IterDynamic(types ...reflect.Type) iter.Seq2[EntityId, map[reflect.Type]any] {
+	return func(yield func(EntityId, map[reflect.Type]any) bool) {
+		var matching []*Archetype
+		for _, archetype := range s.archetypeOrder {
+			if archetype.hasAll(types) {
+				matching = append(matching, archetype)
+			}
+		}
+
+		storages := make([]iComponentStorage, len(types))
+		for _, archetype := range matching {
+			for i, t := range types {
+				storages[i] = archetype.storageFor(t)
+			}
+			for index := range archetype.enabledIndices() {
+				components := make(map[reflect.Type]any, len(types))
+				for i, t := range types {
+					components[t] = storages[i].Get(index)
+				}
+				if !yield(NewEntityId(archetype.id, uint32(index)), components) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// hasAll reports whether the archetype has every one of the given component types
+func (a *Archetype) hasAll(types []reflect.Type) bool {
+	for _, t := range types {
+		if !a.HasComponent(t) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,38 @@
+package ecs
+
+import "reflect"
+
+// emptyEntity is the marker component held by entities without any other component, see
+// Storage.SpawnEmpty. It is dropped as soon as a real component is added.
+type emptyEntity struct{}
+
+var emptyEntityType = reflect.TypeFor[emptyEntity]()
+
+// registerEmptyEntity registers the marker component the first time an empty entity is
+// needed, so registries that never use empty entities keep their component bits unchanged
+func (r *ComponentRegistry) registerEmptyEntity() {
+	if r.getFactory(emptyEntityType) == nil {
+		RegisterComponent[emptyEntity](r)
+	}
+}
+
+// SpawnEmpty creates an entity without components. The entity exists, so refs to it stay
+// valid, but it is not matched by any view until components are added with AddComponent.
+func (s *Storage) SpawnEmpty() EntityId {
+	s.registry.registerEmptyEntity()
+	return s.SpawnSlice([]any{emptyEntity{}})
+}
+
+// SetRetainEmptyEntities controls what RemoveComponent does when it removes an entity's last
+// component. By default the entity is deleted and its refs are invalidated; when retain is
+// set the entity is kept as an empty entity, as created by SpawnEmpty, so its refs stay valid
+// and components can be added to it again later, e.g. for pooled entities.
+func (s *Storage) SetRetainEmptyEntities(retain bool) {
+	s.retainEmpty = retain
+}
+
+// IsEmpty reports whether the entity exists and has no components
+func (s *Storage) IsEmpty(id EntityId) bool {
+	archetype := s.ArchetypeOf(id)
+	return archetype != nil && len(archetype.types) == 1 && archetype.types[0] == emptyEntityType
+}
@@ -3,6 +3,11 @@ package ecs
 // EntityId encodes both the archetype ID (upper 32 bits) and the entity index (lower 32 bits)
 type EntityId uint64
 
+// InvalidEntityId is the zero EntityId and never refers to an entity.
+// Archetype ID 0 is reserved and never assigned, so any EntityId in that
+// archetype (including NewEntityId(0, 0)) is invalid.
+const InvalidEntityId EntityId = 0
+
 // NewEntityId creates an EntityId from an archetype ID and entity index
 func NewEntityId(archetypeId uint32, index uint32) EntityId {
 	return EntityId(uint64(archetypeId)<<32 | uint64(index))
@@ -13,6 +18,12 @@ func (e EntityId) ArchetypeId() uint32 {
 	return uint32(e >> 32)
 }
 
+// IsValid reports whether the id could refer to an entity, i.e. it is not in the reserved archetype 0.
+// It does not check whether the entity is still alive in a storage.
+func (e EntityId) IsValid() bool {
+	return e.ArchetypeId() != 0
+}
+
 // Index extracts the entity index from the entity ID
 func (e EntityId) Index() uint32 {
 	return uint32(e & 0xFFFFFFFF)
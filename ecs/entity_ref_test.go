@@ -99,3 +99,25 @@ func TestEntityRefInvalidBeforeCreate(t *testing.T) {
 	ok = storage.InvalidateEntityRef(nil)
 	assert.False(t, ok)
 }
+
+func TestResolveRefs(t *testing.T) {
+	storage := ecs.NewStorage(newTestRegistry())
+
+	first := storage.Spawn(Position{X: 1})
+	second := storage.Spawn(Position{X: 2})
+	firstRef := storage.CreateEntityRef(first)
+	secondRef := storage.CreateEntityRef(second)
+
+	moved := storage.AddComponent(second, Velocity{})
+	storage.Delete(first)
+	storage.InvalidateEntityRef(firstRef)
+
+	refs := []*ecs.EntityRef{firstRef, secondRef, nil}
+	out := make([]ecs.EntityId, len(refs))
+	resolved := storage.ResolveRefs(refs, out)
+
+	assert.Equal(t, []bool{false, true, false}, resolved)
+	assert.Equal(t, []ecs.EntityId{ecs.InvalidEntityId, moved, ecs.InvalidEntityId}, out)
+
+	assert.Panics(t, func() { storage.ResolveRefs(refs, out[:1]) })
+}
@@ -0,0 +1,79 @@
+package ecs
+
+import (
+	"iter"
+	"reflect"
+)
+
+// eventBus double-buffers the events emitted with UpdateFrame.Emit. Events emitted during one
+// frame are readable through Events fields during the next frame and dropped after it.
+type eventBus struct {
+	current  map[reflect.Type][]any
+	previous map[reflect.Type][]any
+}
+
+func (b *eventBus) emit(event any) {
+	if event == nil {
+		panic("cannot emit a nil event")
+	}
+	if b.current == nil {
+		b.current = make(map[reflect.Type][]any)
+	}
+	eventType := reflect.TypeOf(event)
+	b.current[eventType] = append(b.current[eventType], event)
+}
+
+// swap makes the events emitted so far readable and starts buffering the next frame's events,
+// reusing the slices of the events that were just dropped
+func (b *eventBus) swap() {
+	b.previous, b.current = b.current, b.previous
+	for eventType, events := range b.current {
+		clear(events)
+		b.current[eventType] = events[:0]
+	}
+}
+
+// Events gives a system the events of type T emitted during the previous frame. Declare it as
+// a system field and the scheduler initializes it on registration, like Query and Singleton
+// fields:
+//
+//	type DeathSystem struct {
+//		Deaths ecs.Events[DeathEvent]
+//	}
+//
+// Events are buffered by the scheduler and swapped between frames, so producers and consumers
+// see the same events whichever order they run in, at the cost of one frame of latency. Events
+// are matched by their dynamic type: an event emitted as *DeathEvent is not read by an
+// Events[DeathEvent] field.
+type Events[T any] struct {
+	bus *eventBus
+}
+
+// Init binds the field to the scheduler's events.
+// Called by the Scheduler during system registration.
+func (e *Events[T]) Init(scheduler *Scheduler) {
+	e.bus = &scheduler.events
+}
+
+// Iter returns an iterator over the events of type T emitted during the previous frame, in
+// the order they were emitted
+func (e *Events[T]) Iter() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		if e.bus == nil {
+			return
+		}
+		for _, event := range e.bus.previous[reflect.TypeFor[T]()] {
+			if !yield(event.(T)) {
+				return
+			}
+		}
+	}
+}
+
+// Len returns the number of events of type T emitted during the previous frame
+func (e *Events[T]) Len() int {
+	if e.bus == nil {
+		return 0
+	}
+	return len(e.bus.previous[reflect.TypeFor[T]()])
+}
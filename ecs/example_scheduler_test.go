@@ -195,3 +195,64 @@ func ExampleScheduler_withSingletons() {
 	// Frames: 3, Time: 0.048
 	// Score: 90 points
 }
+
+type DeathEvent struct {
+	Entity ecs.EntityId
+}
+
+type CombatSystem struct {
+	Units ecs.Query[struct {
+		ecs.EntityId
+		*Hitpoints
+	}]
+}
+
+func (s *CombatSystem) Execute(frame *ecs.UpdateFrame) {
+	for unit := range s.Units.Iter() {
+		// A dying unit is only deleted once its death event is read on the next frame, so
+		// the event is emitted when its hitpoints run out rather than while they are out
+		if unit.Hitpoints.Current <= 0 {
+			continue
+		}
+		unit.Hitpoints.Current -= 40
+		if unit.Hitpoints.Current <= 0 {
+			frame.Emit(DeathEvent{Entity: unit.EntityId})
+		}
+	}
+}
+
+type DeathSystem struct {
+	Deaths ecs.Events[DeathEvent]
+}
+
+func (s *DeathSystem) Execute(frame *ecs.UpdateFrame) {
+	for death := range s.Deaths.Iter() {
+		fmt.Printf("Frame %.0f: entity %d died\n", frame.DeltaTime, death.Entity.Index())
+		frame.Commands.Delete(death.Entity)
+	}
+}
+
+func ExampleEvents() {
+	registry := ecs.NewComponentRegistry()
+	ecs.RegisterComponent[Hitpoints](registry)
+	storage := ecs.NewStorage(registry)
+	storage.Spawn(Hitpoints{Current: 50, Max: 100})
+	storage.Spawn(Hitpoints{Current: 100, Max: 100})
+
+	// DeathSystem reads the events CombatSystem emitted on the previous frame, so it can be
+	// registered first
+	scheduler := ecs.NewScheduler(storage)
+	scheduler.Register(&DeathSystem{})
+	scheduler.Register(&CombatSystem{})
+
+	// The delta time doubles as a frame number for the output
+	for frame := 1; frame <= 4; frame++ {
+		scheduler.Once(float64(frame))
+	}
+	fmt.Println("Remaining:", ecs.Count[Hitpoints](storage))
+
+	// Output:
+	// Frame 3: entity 0 died
+	// Frame 4: entity 1 died
+	// Remaining: 0
+}
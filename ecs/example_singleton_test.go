@@ -35,8 +35,8 @@ func ExampleNewSingleton() {
 	config.Get().Difficulty = "Hard"
 	fmt.Printf("Updated difficulty: %s\n", config.Get().Difficulty)
 
-	// Create another reference to the same singleton
-	sameConfig := ecs.NewSingleton[GameConfig](storage)
+	// Bind another handle to the same singleton
+	sameConfig := ecs.BindSingleton[GameConfig](storage)
 	fmt.Printf("Same config: %s difficulty\n", sameConfig.Get().Difficulty)
 
 	// Output:
@@ -59,8 +59,8 @@ func ExampleSingleton_multipleReferences() {
 	score1.Get().Points = 100
 	score1.Get().Level = 2
 
-	// Create second reference to same singleton
-	score2 := ecs.NewSingleton[GameScore](storage)
+	// Bind a second reference to the same singleton
+	score2 := ecs.BindSingleton[GameScore](storage)
 	fmt.Printf("Score2: %d points, Level %d\n", score2.Get().Points, score2.Get().Level)
 
 	// Both references point to the same data
@@ -2,6 +2,7 @@ package ecs_test
 
 import (
 	"fmt"
+	"math"
 
 	"github.com/plus3/ooftn/ecs"
 )
@@ -136,3 +137,62 @@ func ExampleView_optional() {
 	// Entity at (20, 20) with health 75/100
 	// Invulnerable entity at (30, 30)
 }
+
+// CameraFocus is the point the camera looks at
+type CameraFocus struct {
+	X, Y float32
+}
+
+// LODAssignSystem assigns each entity's level of detail from its distance to the camera
+type LODAssignSystem struct {
+	Camera   ecs.Singleton[CameraFocus] `ecs:"reads"`
+	Entities ecs.Query[struct {
+		*Position
+		*ecs.LODLevel
+	}]
+}
+
+func (s *LODAssignSystem) Execute(frame *ecs.UpdateFrame) {
+	camera := s.Camera.Get()
+	for item := range s.Entities.Iter() {
+		distance := float32(math.Hypot(float64(item.Position.X-camera.X), float64(item.Position.Y-camera.Y)))
+		item.LODLevel.Level = ecs.LODForDistance(distance, 50, 200)
+	}
+}
+
+// DetailedPhysicsSystem only simulates entities at level of detail 0 or 1
+type DetailedPhysicsSystem struct {
+	Entities ecs.Query[struct {
+		*Position
+		LOD *ecs.LODLevel `ecs:"lod<=1"`
+	}]
+}
+
+func (s *DetailedPhysicsSystem) Execute(frame *ecs.UpdateFrame) {
+	for item := range s.Entities.Iter() {
+		fmt.Printf("simulating entity at x=%.0f\n", item.Position.X)
+	}
+}
+
+// ExampleLODLevel shows a system assigning levels of detail by distance to the camera and a
+// system that skips the entities too far away to matter.
+func ExampleLODLevel() {
+	registry := ecs.NewComponentRegistry()
+	ecs.RegisterComponent[Position](registry)
+	ecs.RegisterComponent[ecs.LODLevel](registry)
+	storage := ecs.NewStorage(registry)
+	ecs.NewSingleton(storage, CameraFocus{})
+
+	for _, x := range []float32{10, 100, 1000} {
+		storage.Spawn(Position{X: x}, ecs.LODLevel{})
+	}
+
+	scheduler := ecs.NewScheduler(storage)
+	scheduler.Register(&LODAssignSystem{})
+	scheduler.Register(&DetailedPhysicsSystem{})
+	scheduler.Once(1.0 / 60)
+
+	// Unordered output:
+	// simulating entity at x=10
+	// simulating entity at x=100
+}
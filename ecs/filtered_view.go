@@ -0,0 +1,58 @@
+package ecs
+
+import "iter"
+
+// FilteredView wraps a View and only yields entities that pass a predicate.
+// Archetype matching is shared with the parent view and the predicate is evaluated lazily during iteration.
+type FilteredView[T any] struct {
+	view *View[T]
+	pred func(T) bool
+}
+
+// Filter returns a reusable view that yields only the entities for which pred returns true.
+func (v *View[T]) Filter(pred func(T) bool) *FilteredView[T] {
+	return &FilteredView[T]{
+		view: v,
+		pred: pred,
+	}
+}
+
+// Filter returns a new filtered view that yields entities passing both this view's predicate and pred.
+func (f *FilteredView[T]) Filter(pred func(T) bool) *FilteredView[T] {
+	parent := f.pred
+	return &FilteredView[T]{
+		view: f.view,
+		pred: func(item T) bool {
+			return parent(item) && pred(item)
+		},
+	}
+}
+
+// Iter returns an iterator over all entities matching the parent view that pass the predicate.
+func (f *FilteredView[T]) Iter() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for item := range f.view.Iter() {
+			if !f.pred(item) {
+				continue
+			}
+			if !yield(item) {
+				return
+			}
+		}
+	}
+}
+
+// Get returns a populated view struct for the given entity, or nil if the entity
+// doesn't match the parent view or fails the predicate.
+func (f *FilteredView[T]) Get(id EntityId) *T {
+	item := f.view.Get(id)
+	if item == nil || !f.pred(*item) {
+		return nil
+	}
+	return item
+}
+
+// View returns the unfiltered parent view.
+func (f *FilteredView[T]) View() *View[T] {
+	return f.view
+}
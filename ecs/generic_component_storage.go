@@ -3,36 +3,230 @@ package ecs
 import (
 	"iter"
 	"reflect"
+	"slices"
 )
 
 // ComponentRegistry manages component type registration for an ECS instance.
 // Each Storage instance has its own ComponentRegistry, allowing multiple
 // independent ECS systems to coexist without interference.
+//
+// Components may be registered at any time, including after entities have been spawned.
+// Registration never changes existing entities: archetype ids are derived from the TypeNames
+// of the component types rather than from registration order, mask bits are only ever
+// appended, and archetypes keep the storage layout and slot policy they were created with.
+// Registration settings, such as the storage kind or slot policy, apply to archetypes created
+// afterward. Archetype ids are the same in every process that uses the same type names, but
+// entity ids depend on the order entities were spawned and deleted, so saved worlds refer to
+// components by TypeName and entity ids are reassigned on load.
 type ComponentRegistry struct {
-	factories map[reflect.Type]func() iComponentStorage
+	factories  map[reflect.Type]func(slots slotConfig) iComponentStorage
+	bits       map[reflect.Type]int
+	validators map[reflect.Type][]componentValidator
+	priorities map[reflect.Type]int
+	keyed      map[reflect.Type]reflect.Type
+	references map[reflect.Type]bool // types registered with RegisterReferenceComponent
+	slotPolicy SlotPolicy
+	slots      map[reflect.Type]slotConfig
+	typesById  map[uint32]reflect.Type // built by ComponentTypeById
+}
+
+// SlotPolicy controls how component storages choose the index for a newly appended
+// component, which is also the index portion of the entity's EntityId.
+type SlotPolicy int
+
+const (
+	// SlotReuseLIFO reuses the most recently freed slot first. This is the default and
+	// keeps storage dense and cache-friendly.
+	SlotReuseLIFO SlotPolicy = iota
+	// SlotReuseFIFO reuses the oldest freed slot first, so a deleted entity's id is not
+	// handed out again until every other free slot has been used. This makes stale
+	// EntityIds easier to catch while debugging.
+	SlotReuseFIFO
+	// SlotMonotonic never reuses freed slots, so indices only grow until the archetype is
+	// compacted. Deleted slots keep occupying memory, which means storage grows with the
+	// total number of spawns rather than the number of live entities. Use it for debugging
+	// and deterministic tests rather than long-running worlds.
+	SlotMonotonic
+	// SlotReuseLowest reuses the lowest freed slot first, which keeps live entities packed
+	// towards the front of the storage, and releases free slots at the end of the storage so
+	// iteration stops at the last live entity. This makes iteration visit fewer empty slots in
+	// archetypes with heavy churn. Freeing a slot costs O(n) in the number of free slots.
+	SlotReuseLowest
+)
+
+// slotConfig is the slot policy shared by every component storage of an archetype. All of an
+// archetype's storages must allocate the same index for an entity, so they are configured alike.
+type slotConfig struct {
+	policy SlotPolicy
+	// threshold is the number of freed slots that must accumulate before they are reused
+	threshold int
 }
 
 // NewComponentRegistry creates a new component registry.
 func NewComponentRegistry() *ComponentRegistry {
 	return &ComponentRegistry{
-		factories: make(map[reflect.Type]func() iComponentStorage),
+		factories:  make(map[reflect.Type]func(slots slotConfig) iComponentStorage),
+		bits:       make(map[reflect.Type]int),
+		validators: make(map[reflect.Type][]componentValidator),
+		priorities: make(map[reflect.Type]int),
+		keyed:      make(map[reflect.Type]reflect.Type),
+		references: make(map[reflect.Type]bool),
+		slots:      make(map[reflect.Type]slotConfig),
 	}
 }
 
+// SetSlotPolicy sets the slot allocation policy used by component storages. It applies to
+// archetypes created afterward, so it should be called before any entities are spawned.
+func (r *ComponentRegistry) SetSlotPolicy(policy SlotPolicy) {
+	r.slotPolicy = policy
+}
+
+// StorageKind selects how the storages of a component type lay out their components, see
+// RegisterComponentWith.
+type StorageKind int
+
+const (
+	// StorageBlocks stores components in fixed-size blocks of 64, with a bool per slot marking
+	// the filled ones. This is the default.
+	StorageBlocks StorageKind = iota
+	// StorageBitset stores components in a single growable slice and marks the filled slots in
+	// a bitset, one bit per slot instead of one byte, without per-block overhead. Iteration
+	// skips 64 empty slots at a time. Growing the storage reallocates the whole slice, so
+	// Reserve matters more than with StorageBlocks. It suits archetypes with many small
+	// components. ComponentView and IterMut2 read it through the generic path.
+	StorageBitset
+)
+
 // RegisterComponent registers a new component type with the given registry.
-// This must be called for each component type before it can be used.
+// This must be called for each component type before it can be used. It may be called at
+// any time; see ComponentRegistry for what late registration affects. Registering a type
+// again keeps its mask bit.
 func RegisterComponent[T any](r *ComponentRegistry) {
+	RegisterComponentWith[T](r, StorageBlocks)
+}
+
+// RegisterComponentWith registers a new component type like RegisterComponent, storing it
+// with the given storage kind. Applies to archetypes created afterward.
+func RegisterComponentWith[T any](r *ComponentRegistry, kind StorageKind) {
 	t := reflect.TypeOf((*T)(nil)).Elem()
-	r.factories[t] = func() iComponentStorage {
-		return &genericComponentStorage[T]{
-			nextIndex: 0,
+	_, disposable := any((*T)(nil)).(Disposer)
+	r.factories[t] = func(slots slotConfig) iComponentStorage {
+		allocator := slotAllocator{policy: slots.policy, threshold: slots.threshold}
+		if kind == StorageBitset {
+			return &bitsetComponentStorage[T]{disposable: disposable, slotAllocator: allocator}
 		}
+		return &genericComponentStorage[T]{disposable: disposable, slotAllocator: allocator}
+	}
+	if _, ok := r.bits[t]; !ok {
+		r.bits[t] = len(r.bits)
 	}
+	r.typesById = nil
+}
+
+// RegisterReferenceComponent registers a component type that is itself a reference: a
+// pointer, map, channel or function type, such as a map holding a sparse grid. Components of
+// other types are values, so these are rejected unless registered with this function. The
+// storage holds the reference itself, and a view field or ReadComponent for the type is a
+// pointer to it, e.g. *SparseGrid for a map type SparseGrid or **Shared for a *Shared component.
+//
+// A pointer of a type registered here is the component itself when passed to Spawn or
+// AddComponent, rather than a pointer to the component as for other types, so pass a **Shared
+// to supply a *Shared component by pointer. Don't register both T and *T as components.
+//
+// Reference components alias: copying one copies the reference, not the data. A reference
+// taken out of the component and kept elsewhere still sees every later change, and two entities
+// given the same reference share its data. Deleting the entity drops its reference but not
+// the data, which stays alive while anything else refers to it. SaveWorld and Snapshot encode
+// the referenced data as JSON, so a loaded or restored entity gets its own copy; channel and
+// function components cannot be saved. Panics if T is not a pointer, map, channel or function
+// type.
+func RegisterReferenceComponent[T any](r *ComponentRegistry) {
+	t := reflect.TypeFor[T]()
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Chan, reflect.Func:
+	default:
+		panic("reference component type must be a pointer, map, channel or function: " + t.String())
+	}
+	RegisterComponent[T](r)
+	r.references[t] = true
+}
+
+// componentTypeOf returns the component type of a value passed to Spawn or AddComponent: the
+// type it points to for a pointer, unless the pointer is itself a reference component
+func (r *ComponentRegistry) componentTypeOf(component any) reflect.Type {
+	t := reflect.TypeOf(component)
+	if t.Kind() == reflect.Ptr && !r.references[t] {
+		t = t.Elem()
+	}
+	return t
+}
+
+// RegisterComponentSlotPolicy sets the slot policy of archetypes containing component type T,
+// overriding SetSlotPolicy for them. Freed slots are only reused once at least threshold of
+// them have accumulated, and then all of them are reused before waiting for the next batch;
+// a threshold of 0 or 1 reuses slots immediately. Combined with SlotReuseLowest this keeps
+// churny archetypes dense without a full Archetype.Compact, at the cost of slower deletes.
+// Since every storage of an archetype must allocate the same slots, an archetype containing
+// several types with their own policies uses the policy of the type that sorts first by
+// TypeName. Applies to archetypes created afterward.
+func RegisterComponentSlotPolicy[T any](r *ComponentRegistry, policy SlotPolicy, threshold int) {
+	t := reflect.TypeFor[T]()
+	if r.getFactory(t) == nil {
+		panic("cannot set slot policy of unregistered component type " + t.String())
+	}
+	r.slots[t] = slotConfig{policy: policy, threshold: threshold}
+}
+
+// slotConfigFor returns the slot policy of an archetype with the given name-sorted types
+func (r *ComponentRegistry) slotConfigFor(types []reflect.Type) slotConfig {
+	for _, t := range types {
+		if slots, ok := r.slots[t]; ok {
+			return slots
+		}
+	}
+	return slotConfig{policy: r.slotPolicy}
+}
+
+// SetComponentPriority sets the layout priority of component type T. Archetypes created
+// afterward order their component storages by descending priority instead of by type name,
+// so the storages systems read most are allocated first and drive iteration. Components
+// default to priority 0 and ties keep type name order. Archetype ids are unaffected.
+func SetComponentPriority[T any](r *ComponentRegistry, priority int) {
+	t := reflect.TypeFor[T]()
+	if r.getFactory(t) == nil {
+		panic("cannot set priority of unregistered component type " + t.String())
+	}
+	r.priorities[t] = priority
+}
+
+// Disposer can be implemented by components that hold external resources such as file
+// handles or pooled memory. Dispose is called when the component is deleted, either with
+// its entity or through RemoveComponent, before its slot is zeroed and added to the free
+// list. It is not called when the component is copied to another archetype because a
+// different component was added or removed, nor when storage is compacted.
+type Disposer interface {
+	Dispose()
+}
+
+// ComponentBit returns the bit assigned to the given component type within a ComponentMask.
+// Bits are assigned in registration order. Returns false if the type is not registered.
+func (r *ComponentRegistry) ComponentBit(t reflect.Type) (int, bool) {
+	bit, ok := r.bits[t]
+	return bit, ok
+}
+
+// ComponentBitOf returns the ComponentMask bit for component type T, or -1 if T is not registered.
+func ComponentBitOf[T any](r *ComponentRegistry) int {
+	bit, ok := r.bits[reflect.TypeFor[T]()]
+	if !ok {
+		return -1
+	}
+	return bit
 }
 
 // getFactory returns the factory function for a given component type.
 // Returns nil if the type is not registered.
-func (r *ComponentRegistry) getFactory(t reflect.Type) func() iComponentStorage {
+func (r *ComponentRegistry) getFactory(t reflect.Type) func(slots slotConfig) iComponentStorage {
 	return r.factories[t]
 }
 
@@ -43,10 +237,10 @@ const (
 // genericComponentStorage is a generic implementation of iComponentStorage.
 // It stores components of a specific type `T` in blocks.
 type genericComponentStorage[T any] struct {
-	blocks    [][genericBlockSize]T
-	filled    [][genericBlockSize]bool
-	freeSlots []int
-	nextIndex int
+	blocks     [][genericBlockSize]T
+	filled     [][genericBlockSize]bool
+	disposable bool
+	slotAllocator
 }
 
 // Append adds a component to storage and returns its index.
@@ -60,21 +254,29 @@ func (cs *genericComponentStorage[T]) Append(item any) int {
 		return -1 // Invalid type
 	}
 
-	if len(cs.freeSlots) > 0 {
-		index := cs.freeSlots[len(cs.freeSlots)-1]
-		cs.freeSlots = cs.freeSlots[:len(cs.freeSlots)-1]
+	return cs.appendValue(concreteItem)
+}
 
-		blockIdx := index / genericBlockSize
-		slotIdx := index % genericBlockSize
+// AppendFrom copies the component at index in src into this storage and returns its new index.
+// When src holds the same component type the value is copied directly without boxing.
+func (cs *genericComponentStorage[T]) AppendFrom(src iComponentStorage, index int) int {
+	typed, ok := src.(*genericComponentStorage[T])
+	if !ok {
+		return cs.Append(src.Get(index))
+	}
 
-		cs.blocks[blockIdx][slotIdx] = concreteItem
-		cs.filled[blockIdx][slotIdx] = true
-		return index
+	blockIdx := index / genericBlockSize
+	slotIdx := index % genericBlockSize
+	if index < 0 || blockIdx >= len(typed.blocks) || !typed.filled[blockIdx][slotIdx] {
+		return -1
 	}
 
-	index := cs.nextIndex
-	cs.nextIndex++
+	return cs.appendValue(typed.blocks[blockIdx][slotIdx])
+}
 
+// appendValue stores a value in the next free slot and returns its index.
+func (cs *genericComponentStorage[T]) appendValue(concreteItem T) int {
+	index := cs.allocate()
 	blockIdx := index / genericBlockSize
 	slotIdx := index % genericBlockSize
 
@@ -108,8 +310,18 @@ func (cs *genericComponentStorage[T]) Get(index int) any {
 	return &cs.blocks[blockIdx][slotIdx]
 }
 
-// Delete marks a component slot as empty.
+// Delete disposes of the component (if it implements Disposer) and marks its slot as empty.
 func (cs *genericComponentStorage[T]) Delete(index int) {
+	cs.clearSlot(index, cs.disposable)
+}
+
+// Vacate marks a component slot as empty without disposing of the component.
+// It is used once the value has been copied into another storage.
+func (cs *genericComponentStorage[T]) Vacate(index int) {
+	cs.clearSlot(index, false)
+}
+
+func (cs *genericComponentStorage[T]) clearSlot(index int, dispose bool) {
 	if index < 0 {
 		return
 	}
@@ -122,10 +334,13 @@ func (cs *genericComponentStorage[T]) Delete(index int) {
 	}
 
 	if cs.filled[blockIdx][slotIdx] {
+		if dispose {
+			any(&cs.blocks[blockIdx][slotIdx]).(Disposer).Dispose()
+		}
 		cs.filled[blockIdx][slotIdx] = false
 		var zero T
 		cs.blocks[blockIdx][slotIdx] = zero // Zero out the value
-		cs.freeSlots = append(cs.freeSlots, index)
+		cs.freeSlot(index)
 	}
 }
 
@@ -145,19 +360,56 @@ func (cs *genericComponentStorage[T]) Has(index int) bool {
 	return cs.filled[blockIdx][slotIdx]
 }
 
-// Compact reorganizes component storage to remove empty slots.
-func (cs *genericComponentStorage[T]) Compact() map[int]int {
+// Len returns the number of live components in O(1).
+func (cs *genericComponentStorage[T]) Len() int {
+	return cs.nextIndex - len(cs.freeSlots)
+}
+
+// Reserve grows the block slices so that count more components can be appended
+// without reallocating them. Free slots are reused first and count towards the total,
+// unless the storage uses SlotMonotonic.
+func (cs *genericComponentStorage[T]) Reserve(count int) {
+	needed := cs.growthFor(count)
+	if needed <= 0 {
+		return
+	}
+
+	blocks := (cs.nextIndex + needed + genericBlockSize - 1) / genericBlockSize
+	if extra := blocks - len(cs.blocks); extra > 0 {
+		cs.blocks = slices.Grow(cs.blocks, extra)
+		cs.filled = slices.Grow(cs.filled, extra)
+	}
+}
+
+// clone returns a copy of the storage with the same slots filled. Components are copied by
+// value, so anything they point to is shared with the original.
+func (cs *genericComponentStorage[T]) clone() iComponentStorage {
+	return &genericComponentStorage[T]{
+		blocks:        slices.Clone(cs.blocks),
+		filled:        slices.Clone(cs.filled),
+		disposable:    cs.disposable,
+		slotAllocator: cs.cloneSlots(),
+	}
+}
+
+// Compact reorganizes component storage to remove empty slots. It returns the mapping of
+// old to new indices and whether the storage was compacted; when the storage is already
+// dense it returns early with a nil map and false.
+func (cs *genericComponentStorage[T]) Compact() (map[int]int, bool) {
+	if cs.dense() {
+		return nil, false
+	}
+	totalComponents := cs.nextIndex - len(cs.freeSlots)
+
 	indexMap := make(map[int]int)
 	writePos := 0
 
-	totalComponents := cs.nextIndex - len(cs.freeSlots)
-	if cs.nextIndex == 0 || totalComponents == 0 {
+	if totalComponents == 0 {
 		// Reset to a single block if empty
 		cs.blocks = make([][genericBlockSize]T, 1)
 		cs.filled = make([][genericBlockSize]bool, 1)
-		cs.freeSlots = nil
-		cs.nextIndex = 0
-		return indexMap
+		cs.resetSlots(0)
+		return indexMap, true
 	}
 
 	numNewBlocks := (totalComponents + genericBlockSize - 1) / genericBlockSize
@@ -184,15 +436,19 @@ func (cs *genericComponentStorage[T]) Compact() map[int]int {
 
 	cs.blocks = newBlocks
 	cs.filled = newFilled
-	cs.freeSlots = nil
-	cs.nextIndex = writePos
+	cs.resetSlots(writePos)
 
-	return indexMap
+	return indexMap, true
 }
 
 func (cs *genericComponentStorage[T]) Iter() iter.Seq[int] {
+	return cs.IterFrom(0)
+}
+
+// IterFrom iterates the filled slots with an index of at least start
+func (cs *genericComponentStorage[T]) IterFrom(start int) iter.Seq[int] {
 	return func(yield func(int) bool) {
-		for i := 0; i < cs.nextIndex; i++ {
+		for i := max(start, 0); i < cs.nextIndex; i++ {
 			blockIdx := i / genericBlockSize
 			slotIdx := i % genericBlockSize
 
@@ -0,0 +1,85 @@
+package ecs
+
+import "testing"
+
+func TestGenericComponentStorageCompact(t *testing.T) {
+	newStorage := func(n int) *genericComponentStorage[int] {
+		cs := &genericComponentStorage[int]{}
+		for i := range n {
+			cs.Append(i)
+		}
+		return cs
+	}
+
+	t.Run("dense storage is a no-op", func(t *testing.T) {
+		cs := newStorage(100)
+		blocks := &cs.blocks[0]
+
+		indexMap, moved := cs.Compact()
+		if moved {
+			t.Error("expected dense storage to report no moves")
+		}
+		if indexMap != nil {
+			t.Errorf("expected nil index map, got %v", indexMap)
+		}
+		if &cs.blocks[0] != blocks {
+			t.Error("expected blocks to be left untouched")
+		}
+	})
+
+	t.Run("empty storage is a no-op", func(t *testing.T) {
+		cs := &genericComponentStorage[int]{}
+		if _, moved := cs.Compact(); moved {
+			t.Error("expected empty storage to report no moves")
+		}
+	})
+
+	t.Run("fragmented storage reports moves", func(t *testing.T) {
+		cs := newStorage(10)
+		cs.Delete(2)
+		cs.Delete(5)
+
+		indexMap, moved := cs.Compact()
+		if !moved {
+			t.Fatal("expected fragmented storage to report moves")
+		}
+		if len(indexMap) != 8 {
+			t.Errorf("expected 8 entries in index map, got %d", len(indexMap))
+		}
+		if indexMap[9] != 7 {
+			t.Errorf("expected index 9 to move to 7, got %d", indexMap[9])
+		}
+
+		if _, moved := cs.Compact(); moved {
+			t.Error("expected second compaction to be a no-op")
+		}
+	})
+}
+
+func TestGenericComponentStorageReserve(t *testing.T) {
+	cs := &genericComponentStorage[int]{}
+	cs.Reserve(1000)
+
+	blocks := cap(cs.blocks)
+	if blocks < 16 {
+		t.Fatalf("expected capacity for at least 16 blocks, got %d", blocks)
+	}
+	if len(cs.blocks) != 0 || cs.nextIndex != 0 {
+		t.Error("expected reserve to not add any components")
+	}
+
+	for i := range 1000 {
+		cs.Append(i)
+	}
+	if cap(cs.blocks) != blocks {
+		t.Errorf("expected no block reallocation, capacity changed from %d to %d", blocks, cap(cs.blocks))
+	}
+
+	// Free slots are reused before new blocks are needed
+	cs.Delete(0)
+	cs.Delete(1)
+	cs.Reserve(2)
+	if cap(cs.blocks) != blocks {
+		t.Error("expected reserving into free slots to not grow blocks")
+	}
+}
@@ -0,0 +1,161 @@
+package ecs
+
+import (
+	"iter"
+	"reflect"
+	"slices"
+)
+
+// Parent is the component holding an entity's parent, see Storage.SetParent. It is maintained
+// by the storage and should not be modified directly.
+type Parent struct {
+	Ref *EntityRef
+}
+
+// Children is the component listing an entity's children in the order they were parented, see
+// Storage.SetParent. It is maintained by the storage and should not be modified directly.
+// Systems reach the children of an entity through a *ecs.Children view field:
+//
+//	for colony := range colonies.Iter() {
+//		for member := range colony.Children.Iter() {
+//			...
+//		}
+//	}
+type Children struct {
+	Refs []*EntityRef
+}
+
+var (
+	parentType   = reflect.TypeFor[Parent]()
+	childrenType = reflect.TypeFor[Children]()
+)
+
+// Iter returns an iterator over the ids of the children
+func (c *Children) Iter() iter.Seq[EntityId] {
+	return func(yield func(EntityId) bool) {
+		for _, ref := range c.Refs {
+			if ref != nil && ref.Id.IsValid() && !yield(ref.Id) {
+				return
+			}
+		}
+	}
+}
+
+// Len returns the number of children
+func (c *Children) Len() int {
+	count := 0
+	for range c.Iter() {
+		count++
+	}
+	return count
+}
+
+// remove drops the child from the list, along with the refs of children deleted without Delete
+func (c *Children) remove(child EntityId) {
+	c.Refs = slices.DeleteFunc(c.Refs, func(ref *EntityRef) bool {
+		return ref == nil || ref.Id == child || !ref.Id.IsValid()
+	})
+}
+
+// registerHierarchy registers the Parent and Children components the first time an entity is
+// parented, so registries that never use them keep their component bits unchanged
+func (r *ComponentRegistry) registerHierarchy() {
+	if r.getFactory(parentType) == nil {
+		RegisterComponent[Parent](r)
+	}
+	if r.getFactory(childrenType) == nil {
+		RegisterComponent[Children](r)
+	}
+}
+
+// SetParent makes parent the parent of child, detaching child from its previous parent. The
+// child is given a Parent component and the parent a Children component listing the child, so
+// either may move to another archetype as with AddComponent; their new ids are returned:
+//
+//	member, colony = storage.SetParent(member, colony)
+//
+// The relationship is kept up to date by the storage: Delete removes a deleted child from its
+// parent's Children, and a deleted parent's children keep a Parent whose ref no longer
+// resolves, so ParentOf reports that they have none. DeleteRecursive deletes an entity along
+// with all its descendants. Removing Parent or Children with RemoveComponent or
+// ReplaceComponents bypasses this bookkeeping; use RemoveParent instead.
+//
+// The components are registered with the storage's registry on first use; a registry used to
+// load a saved world holding them must register them with RegisterComponent beforehand. Panics
+// if either entity doesn't exist, or if parent is child or one of its descendants.
+func (s *Storage) SetParent(child, parent EntityId) (EntityId, EntityId) {
+	if !s.Exists(child) || !s.Exists(parent) {
+		panic("cannot parent entities that don't exist")
+	}
+	for ancestor, ok := parent, true; ok; ancestor, ok = s.ParentOf(ancestor) {
+		if ancestor == child {
+			panic("cannot make an entity a descendant of itself")
+		}
+	}
+
+	s.registry.registerHierarchy()
+	childRef := s.CreateEntityRef(child)
+	parentRef := s.CreateEntityRef(parent)
+
+	if current := ReadComponent[Parent](s, child); current != nil {
+		if current.Ref != nil && current.Ref.Id == parent {
+			return child, parent
+		}
+		s.detachChild(current, child)
+		current.Ref = parentRef
+	} else {
+		s.AddComponent(child, Parent{Ref: parentRef})
+	}
+
+	if children := ReadComponent[Children](s, parentRef.Id); children != nil {
+		children.Refs = append(children.Refs, childRef)
+	} else {
+		s.AddComponent(parentRef.Id, Children{Refs: []*EntityRef{childRef}})
+	}
+	return childRef.Id, parentRef.Id
+}
+
+// RemoveParent detaches child from its parent and removes its Parent component, returning the
+// child's new id. As with RemoveComponent, the child is deleted if Parent was its last
+// component, unless empty entities are retained. Entities without a parent are left untouched.
+func (s *Storage) RemoveParent(child EntityId) EntityId {
+	current := ReadComponent[Parent](s, child)
+	if current == nil {
+		return child
+	}
+	s.detachChild(current, child)
+	return s.RemoveComponent(child, parentType)
+}
+
+// ParentOf returns the parent of an entity, or false if it has none or its parent was deleted
+func (s *Storage) ParentOf(id EntityId) (EntityId, bool) {
+	parent := ReadComponent[Parent](s, id)
+	if parent == nil {
+		return InvalidEntityId, false
+	}
+	return s.ResolveEntityRef(parent.Ref)
+}
+
+// DeleteRecursive deletes an entity along with its children, their children and so on, e.g. a
+// colony and its members. Descendants are deleted before their parents.
+func (s *Storage) DeleteRecursive(id EntityId) {
+	if children := ReadComponent[Children](s, id); children != nil {
+		// Deleting a child removes it from the list
+		for _, ref := range slices.Clone(children.Refs) {
+			if ref != nil && ref.Id.IsValid() {
+				s.DeleteRecursive(ref.Id)
+			}
+		}
+	}
+	s.Delete(id)
+}
+
+// detachChild removes child from the Children of the parent it holds
+func (s *Storage) detachChild(parent *Parent, child EntityId) {
+	if parent.Ref == nil || !parent.Ref.Id.IsValid() {
+		return
+	}
+	if children := ReadComponent[Children](s, parent.Ref.Id); children != nil {
+		children.remove(child)
+	}
+}
@@ -0,0 +1,103 @@
+package ecs_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/plus3/ooftn/ecs"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHierarchy(t *testing.T) {
+	// newColony spawns a colony with two members, the first of which has a pet
+	newColony := func(storage *ecs.Storage) (colony, first, second, pet ecs.EntityId) {
+		colony = storage.Spawn(Name("colony"))
+		first = storage.Spawn(Name("first"))
+		second = storage.Spawn(Name("second"))
+		pet = storage.Spawn(Name("pet"))
+		first, colony = storage.SetParent(first, colony)
+		second, colony = storage.SetParent(second, colony)
+		pet, first = storage.SetParent(pet, first)
+		return colony, first, second, pet
+	}
+
+	t.Run("parent and children", func(t *testing.T) {
+		storage := ecs.NewStorage(newTestRegistry())
+		colony, first, second, pet := newColony(storage)
+
+		parent, ok := storage.ParentOf(first)
+		assert.True(t, ok)
+		assert.Equal(t, colony, parent)
+		_, ok = storage.ParentOf(colony)
+		assert.False(t, ok)
+
+		children := ecs.ReadComponent[ecs.Children](storage, colony)
+		assert.Equal(t, []ecs.EntityId{first, second}, slices.Collect(children.Iter()))
+		assert.Equal(t, 2, children.Len())
+
+		view := ecs.NewView[struct {
+			*Name
+			*ecs.Children
+		}](storage)
+		parents := map[Name][]ecs.EntityId{}
+		for item := range view.Iter() {
+			parents[*item.Name] = slices.Collect(item.Children.Iter())
+		}
+		assert.Equal(t, map[Name][]ecs.EntityId{"colony": {first, second}, "first": {pet}}, parents)
+	})
+
+	t.Run("reparenting", func(t *testing.T) {
+		storage := ecs.NewStorage(newTestRegistry())
+		colony, first, second, pet := newColony(storage)
+
+		pet, second = storage.SetParent(pet, second)
+		assert.Zero(t, ecs.ReadComponent[ecs.Children](storage, first).Len())
+		assert.Equal(t, []ecs.EntityId{pet}, slices.Collect(ecs.ReadComponent[ecs.Children](storage, second).Iter()))
+
+		assert.Panics(t, func() { storage.SetParent(colony, pet) }, "cycles are rejected")
+		assert.Panics(t, func() { storage.SetParent(pet, pet) })
+
+		pet = storage.RemoveParent(pet)
+		_, ok := storage.ParentOf(pet)
+		assert.False(t, ok)
+		assert.Zero(t, ecs.ReadComponent[ecs.Children](storage, second).Len())
+		assert.Nil(t, ecs.ReadComponent[ecs.Parent](storage, pet))
+	})
+
+	t.Run("delete detaches", func(t *testing.T) {
+		storage := ecs.NewStorage(newTestRegistry())
+		colony, first, second, pet := newColony(storage)
+
+		storage.Delete(second)
+		assert.Equal(t, []ecs.EntityId{first}, slices.Collect(ecs.ReadComponent[ecs.Children](storage, colony).Iter()))
+		assert.NotPanics(t, func() { storage.Delete(second) }, "deleting twice is a no-op")
+		assert.Equal(t, []ecs.EntityId{first}, slices.Collect(ecs.ReadComponent[ecs.Children](storage, colony).Iter()))
+
+		storage.Delete(first)
+		_, ok := storage.ParentOf(pet)
+		assert.False(t, ok, "the parent of an orphan resolves to nothing")
+		assert.True(t, storage.Exists(pet))
+	})
+
+	t.Run("delete recursive", func(t *testing.T) {
+		storage := ecs.NewStorage(newTestRegistry())
+		colony, first, second, pet := newColony(storage)
+		other := storage.Spawn(Name("other"))
+
+		storage.DeleteRecursive(colony)
+		for _, id := range []ecs.EntityId{colony, first, second, pet} {
+			assert.False(t, storage.Exists(id))
+		}
+		assert.True(t, storage.Exists(other))
+	})
+
+	t.Run("cloned hierarchies", func(t *testing.T) {
+		storage := ecs.NewStorage(newTestRegistry())
+		colony, first, _, _ := newColony(storage)
+
+		clone := storage.Clone()
+		clone.DeleteRecursive(first)
+		assert.Equal(t, 1, ecs.ReadComponent[ecs.Children](clone, colony).Len())
+		assert.Equal(t, 2, ecs.ReadComponent[ecs.Children](storage, colony).Len())
+	})
+}
@@ -5,9 +5,18 @@ import "iter"
 // iComponentStorage is an interface for a type-erased component storage.
 type iComponentStorage interface {
 	Append(item any) int
+	AppendFrom(src iComponentStorage, index int) int
 	Delete(index int)
+	Vacate(index int)
 	Get(index int) any
 	Has(index int) bool
-	Compact() map[int]int
+	Len() int
+	Reserve(count int)
+	Compact() (map[int]int, bool)
 	Iter() iter.Seq[int]
+	IterFrom(start int) iter.Seq[int]
+	clone() iComponentStorage
+	dense() bool
+	freeSlotCount() int
+	checkConsistency() error
 }
@@ -0,0 +1,197 @@
+package ecs
+
+import "iter"
+
+// IncrementalGrid is a uniform grid spatial index over the entities matched by a query.
+// Instead of being cleared and rebuilt every frame, Update only visits the entities whose
+// view components changed since the previous update (see Query.IterChanged) and moves an
+// entity between cells only when its cell actually changed. Deleted entities and entities
+// that move to another archetype are dropped from their cell as the storage reports them,
+// and the latter are reinserted under their new id by the next Update if they still match.
+// Entities disabled with Storage.SetEnabled are dropped by the next Update and reinserted by
+// the Update following their re-enabling, so the grid holds the entities its view iterates.
+//
+// Cells hold entity ids in no particular order; sort or compare them by Storage.BirthOrder
+// when the order matters, e.g. to process each pair of neighbours once. The compactions of
+// Scheduler.SetCompactionPolicy are reported like other moves, but the grid must be rebuilt
+// with Rebuild after calling Archetype.Compact on any archetype it indexes.
+type IncrementalGrid[T any] struct {
+	query    *Query[T]
+	position func(T) (x, y int)
+	cellSize int
+
+	cells   map[[2]int][]EntityId
+	entries map[EntityId]gridEntry
+
+	// versions holds the version of each matching archetype at the last Update, so only the
+	// archetypes whose entities may have been disabled since are scanned
+	versions map[uint32]uint64
+
+	unsubscribe func()
+}
+
+// gridEntry records where an entity is stored in the grid
+type gridEntry struct {
+	cell  [2]int
+	index int
+}
+
+// NewIncrementalGrid creates a grid indexing the entities matched by the view struct T in
+// storage, with square cells of cellSize units. position returns an entity's coordinates
+// from its view. The grid is empty until the first Update. Panics if cellSize is not positive.
+func NewIncrementalGrid[T any](storage *Storage, cellSize int, position func(T) (x, y int)) *IncrementalGrid[T] {
+	if cellSize <= 0 {
+		panic("incremental grid cell size must be positive")
+	}
+
+	g := &IncrementalGrid[T]{
+		query:    NewQuery[T](storage),
+		position: position,
+		cellSize: cellSize,
+		cells:    make(map[[2]int][]EntityId),
+		entries:  make(map[EntityId]gridEntry),
+		versions: make(map[uint32]uint64),
+	}
+	g.unsubscribe = storage.OnStructuralChange(func(change StructuralChange) {
+		switch change.Kind {
+		case EntityDeleted:
+			g.forget(change.Id)
+		case EntityMoved:
+			g.forget(change.OldId)
+		}
+	})
+	return g
+}
+
+// Update moves every changed entity into the cell matching its current position, drops the
+// entities that have been disabled and reinserts those that have been enabled again
+func (g *IncrementalGrid[T]) Update() {
+	g.dropDisabled()
+	for id, item := range g.query.IterChanged() {
+		x, y := g.position(item)
+		g.set(id, g.CellOf(x, y))
+	}
+}
+
+// Rebuild empties the grid and reinserts every matching entity
+func (g *IncrementalGrid[T]) Rebuild() {
+	clear(g.cells)
+	clear(g.entries)
+	clear(g.versions)
+	g.query.changes = changeSnapshot{}
+	g.Update()
+}
+
+// Close stops the grid from tracking the storage's structural changes
+func (g *IncrementalGrid[T]) Close() {
+	g.unsubscribe()
+}
+
+// CellSize returns the width and height of a cell
+func (g *IncrementalGrid[T]) CellSize() int {
+	return g.cellSize
+}
+
+// CellOf returns the coordinates of the cell containing the point (x, y)
+func (g *IncrementalGrid[T]) CellOf(x, y int) [2]int {
+	return [2]int{floorDiv(x, g.cellSize), floorDiv(y, g.cellSize)}
+}
+
+// Cell returns the entities in the cell at the given cell coordinates. The slice is owned
+// by the grid and only valid until the next Update.
+func (g *IncrementalGrid[T]) Cell(cellX, cellY int) []EntityId {
+	return g.cells[[2]int{cellX, cellY}]
+}
+
+// InRect returns an iterator over the entities in the cells overlapping the rectangle from
+// (minX, minY) to (maxX, maxY), bounds included. Entities are selected by cell, so those in
+// the cells along the edges may lie slightly outside the rectangle. Only the cells are
+// visited, so the cost depends on the size of the rectangle rather than on the entity count.
+func (g *IncrementalGrid[T]) InRect(minX, minY, maxX, maxY int) iter.Seq[EntityId] {
+	return func(yield func(EntityId) bool) {
+		minCell, maxCell := g.CellOf(minX, minY), g.CellOf(maxX, maxY)
+		for x := minCell[0]; x <= maxCell[0]; x++ {
+			for y := minCell[1]; y <= maxCell[1]; y++ {
+				for _, id := range g.Cell(x, y) {
+					if !yield(id) {
+						return
+					}
+				}
+			}
+		}
+	}
+}
+
+// Len returns the number of entities in the grid
+func (g *IncrementalGrid[T]) Len() int {
+	return len(g.entries)
+}
+
+func (g *IncrementalGrid[T]) set(id EntityId, cell [2]int) {
+	if entry, ok := g.entries[id]; ok {
+		if entry.cell == cell {
+			return
+		}
+		g.remove(id)
+	}
+
+	g.entries[id] = gridEntry{cell: cell, index: len(g.cells[cell])}
+	g.cells[cell] = append(g.cells[cell], id)
+}
+
+// forget drops an entity that no longer exists under id. Its change snapshot is dropped too,
+// so an entity that later reuses the slot is reported by IterChanged even if it looks identical.
+func (g *IncrementalGrid[T]) forget(id EntityId) {
+	g.remove(id)
+	g.query.forgetChanges(id)
+}
+
+// dropDisabled forgets the disabled entities still in the grid. Their change snapshot goes
+// with them, so IterChanged reports them again once they are enabled.
+func (g *IncrementalGrid[T]) dropDisabled() {
+	for _, archetype := range g.query.view.matchingArchetypes() {
+		if version, ok := g.versions[archetype.id]; ok && version == archetype.version {
+			continue
+		}
+		g.versions[archetype.id] = archetype.version
+
+		for index := range archetype.disabledIndices() {
+			id := NewEntityId(archetype.id, uint32(index))
+			if _, ok := g.entries[id]; ok {
+				g.forget(id)
+			}
+		}
+	}
+}
+
+func (g *IncrementalGrid[T]) remove(id EntityId) {
+	entry, ok := g.entries[id]
+	if !ok {
+		return
+	}
+	delete(g.entries, id)
+
+	// Swap the last entity of the cell into the vacated position
+	ids := g.cells[entry.cell]
+	last := len(ids) - 1
+	if entry.index != last {
+		moved := ids[last]
+		ids[entry.index] = moved
+		g.entries[moved] = gridEntry{cell: entry.cell, index: entry.index}
+	}
+
+	if last == 0 {
+		delete(g.cells, entry.cell)
+	} else {
+		g.cells[entry.cell] = ids[:last]
+	}
+}
+
+// floorDiv divides rounding towards negative infinity, so negative coordinates map to negative cells
+func floorDiv(a, b int) int {
+	q := a / b
+	if (a%b != 0) && ((a < 0) != (b < 0)) {
+		q--
+	}
+	return q
+}
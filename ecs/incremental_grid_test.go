@@ -0,0 +1,107 @@
+package ecs_test
+
+import (
+	"testing"
+
+	"github.com/plus3/ooftn/ecs"
+	"github.com/stretchr/testify/assert"
+)
+
+type gridEntity struct {
+	*Position
+}
+
+func gridPosition(e gridEntity) (int, int) {
+	return int(e.Position.X), int(e.Position.Y)
+}
+
+func TestIncrementalGrid(t *testing.T) {
+	storage := ecs.NewStorage(newTestRegistry())
+	grid := ecs.NewIncrementalGrid(storage, 10, gridPosition)
+	defer grid.Close()
+
+	a := storage.Spawn(Position{X: 1, Y: 1})
+	b := storage.Spawn(Position{X: 5, Y: 5})
+	c := storage.Spawn(Position{X: -3, Y: 25})
+
+	grid.Update()
+	assert.Equal(t, 3, grid.Len())
+	assert.ElementsMatch(t, []ecs.EntityId{a, b}, grid.Cell(0, 0))
+	assert.Equal(t, []ecs.EntityId{c}, grid.Cell(-1, 2))
+	assert.Equal(t, [2]int{-1, 2}, grid.CellOf(-3, 25))
+
+	t.Run("in rect", func(t *testing.T) {
+		inRect := func(minX, minY, maxX, maxY int) []ecs.EntityId {
+			var ids []ecs.EntityId
+			for id := range grid.InRect(minX, minY, maxX, maxY) {
+				ids = append(ids, id)
+			}
+			return ids
+		}
+
+		assert.ElementsMatch(t, []ecs.EntityId{a, b}, inRect(2, 2, 9, 9), "selected by cell")
+		assert.ElementsMatch(t, []ecs.EntityId{a, b, c}, inRect(-1, 0, 0, 20))
+		assert.Empty(t, inRect(30, 30, 40, 40))
+	})
+
+	t.Run("moves between cells", func(t *testing.T) {
+		ecs.ReadComponent[Position](storage, a).X = 15
+		ecs.ReadComponent[Position](storage, b).Y = 6
+		grid.Update()
+
+		assert.Equal(t, []ecs.EntityId{b}, grid.Cell(0, 0))
+		assert.Equal(t, []ecs.EntityId{a}, grid.Cell(1, 0))
+	})
+
+	t.Run("structural changes", func(t *testing.T) {
+		storage.Delete(b)
+		assert.Empty(t, grid.Cell(0, 0))
+
+		moved := storage.AddComponent(a, Velocity{})
+		assert.Empty(t, grid.Cell(1, 0), "moved entities are dropped until the next update")
+		grid.Update()
+		assert.Equal(t, []ecs.EntityId{moved}, grid.Cell(1, 0))
+
+		// New entities reusing freed slots are picked up even when they match the old data
+		reused := storage.Spawn(Position{X: 5, Y: 6})
+		assert.Contains(t, []ecs.EntityId{a, b}, reused)
+		grid.Update()
+		assert.Equal(t, []ecs.EntityId{reused}, grid.Cell(0, 0))
+		assert.Equal(t, 3, grid.Len())
+	})
+
+	t.Run("disabled entities", func(t *testing.T) {
+		storage.SetEnabled(c, false)
+		hidden := storage.SpawnDisabled(Position{X: -5, Y: 21})
+		grid.Update()
+		assert.Empty(t, grid.Cell(-1, 2))
+		assert.Equal(t, 2, grid.Len())
+
+		storage.SetEnabled(c, true)
+		storage.SetEnabled(hidden, true)
+		grid.Update()
+		assert.ElementsMatch(t, []ecs.EntityId{c, hidden}, grid.Cell(-1, 2))
+		assert.Equal(t, 4, grid.Len())
+
+		storage.Delete(hidden)
+		assert.Equal(t, 3, grid.Len())
+	})
+
+	t.Run("rebuild", func(t *testing.T) {
+		storage.Delete(storage.Spawn(Position{X: 50}))
+		storage.ArchetypeOf(c).Compact()
+		grid.Rebuild()
+
+		assert.Equal(t, 3, grid.Len())
+		for cellX := -1; cellX <= 1; cellX++ {
+			for cellY := 0; cellY <= 2; cellY++ {
+				for _, id := range grid.Cell(cellX, cellY) {
+					pos := ecs.ReadComponent[Position](storage, id)
+					assert.Equal(t, [2]int{cellX, cellY}, grid.CellOf(int(pos.X), int(pos.Y)))
+				}
+			}
+		}
+	})
+
+	assert.Panics(t, func() { ecs.NewIncrementalGrid(storage, 0, gridPosition) })
+}
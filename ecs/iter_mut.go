@@ -0,0 +1,57 @@
+package ecs
+
+import "reflect"
+
+// IterMut2 calls fn for every entity that has both an A and a B component.
+// For each matching archetype the two typed storages are looked up once and walked
+// directly, avoiding the per-entity view struct population done by View and Query.
+// The pointers passed to fn are only valid for the duration of the call, and fn
+// must not add, remove, or delete entities or components. When validation is enabled,
+// both components are validated after each call to fn.
+func IterMut2[A any, B any](storage *Storage, fn func(id EntityId, a *A, b *B)) {
+	typeA := reflect.TypeFor[A]()
+	typeB := reflect.TypeFor[B]()
+	validate := storage.validation && (len(storage.registry.validators[typeA]) > 0 || len(storage.registry.validators[typeB]) > 0)
+
+	for _, archetype := range storage.archetypeOrder {
+		storageA := archetype.storageFor(typeA)
+		storageB := archetype.storageFor(typeB)
+		if storageA == nil || storageB == nil {
+			continue
+		}
+
+		typedA, okA := storageA.(*genericComponentStorage[A])
+		typedB, okB := storageB.(*genericComponentStorage[B])
+		if !okA || !okB {
+			for index := range archetype.enabledIndices() {
+				id := NewEntityId(archetype.id, uint32(index))
+				fn(id, storageA.Get(index).(*A), storageB.Get(index).(*B))
+				if validate {
+					storage.validateComponents(id, typeA, typeB)
+				}
+			}
+			continue
+		}
+
+		for blockIdx := range typedA.filled {
+			if blockIdx*genericBlockSize >= typedA.nextIndex {
+				break
+			}
+
+			filled := &typedA.filled[blockIdx]
+			blockA := &typedA.blocks[blockIdx]
+			blockB := &typedB.blocks[blockIdx]
+			for slotIdx := range filled {
+				index := blockIdx*genericBlockSize + slotIdx
+				if !filled[slotIdx] || archetype.isDisabled(index) {
+					continue
+				}
+				id := NewEntityId(archetype.id, uint32(index))
+				fn(id, &blockA[slotIdx], &blockB[slotIdx])
+				if validate {
+					storage.validateComponents(id, typeA, typeB)
+				}
+			}
+		}
+	}
+}
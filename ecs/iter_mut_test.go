@@ -0,0 +1,44 @@
+package ecs_test
+
+import (
+	"testing"
+
+	"github.com/plus3/ooftn/ecs"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIterMut2(t *testing.T) {
+	storage := ecs.NewStorage(newTestRegistry())
+
+	ids := make(map[ecs.EntityId]bool)
+	for i := 0; i < 100; i++ {
+		ids[storage.Spawn(Position{X: float32(i)}, Velocity{DX: 1, DY: 2})] = true
+	}
+	for i := 0; i < 10; i++ {
+		ids[storage.Spawn(Position{X: float32(i)}, Velocity{DX: 1, DY: 2}, Health{Current: 1})] = true
+	}
+	storage.Spawn(Position{X: 1000})
+	storage.Spawn(Velocity{DX: 1000})
+
+	var deleted ecs.EntityId
+	for id := range ids {
+		deleted = id
+		break
+	}
+	storage.Delete(deleted)
+	delete(ids, deleted)
+
+	seen := 0
+	ecs.IterMut2(storage, func(id ecs.EntityId, pos *Position, vel *Velocity) {
+		assert.True(t, ids[id], "unexpected entity %d", id)
+		pos.X += vel.DX
+		pos.Y += vel.DY
+		seen++
+	})
+	assert.Equal(t, len(ids), seen)
+
+	for id := range ids {
+		pos := ecs.ReadComponent[Position](storage, id)
+		assert.Equal(t, float32(2), pos.Y)
+	}
+}
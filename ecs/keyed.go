@@ -0,0 +1,208 @@
+package ecs
+
+import (
+	"encoding/json"
+	"iter"
+	"reflect"
+	"slices"
+	"unsafe"
+)
+
+// Keyed holds several components of type T on a single entity, each addressed by a string
+// key, e.g. an entity's weapons keyed by mount point. An entity can otherwise only have one
+// component of each type; Keyed[T] is itself an ordinary component, so views and queries
+// address it with a *Keyed[T] field, or a single key with a *T field tagged `ecs:"key=Name"`
+// (see NewView), and entities without it keep the single component paths.
+//
+// Register keyed components with RegisterKeyedComponent and add them with
+// Storage.AddKeyedComponent. Adding or removing keys of a Keyed the entity already has is not
+// a structural change, so systems may call Set and Delete directly. Values are allocated
+// individually, so pointers returned by Get stay valid until their key is deleted.
+type Keyed[T any] struct {
+	entries []keyedEntry[T]
+}
+
+type keyedEntry[T any] struct {
+	key   string
+	value *T
+}
+
+// keyedComponent is implemented by every *Keyed[T] so storage can update them without knowing T
+type keyedComponent interface {
+	setAny(key string, value any)
+	pointer(key string) unsafe.Pointer
+	Delete(key string) bool
+	Len() int
+	// valueType returns T, so hasEntityRefs can look inside the unexported entries
+	valueType() reflect.Type
+	// remapEntityRefs applies remapEntityRefs to every stored value
+	remapEntityRefs(remap func(*EntityRef) *EntityRef)
+}
+
+var keyedComponentType = reflect.TypeFor[keyedComponent]()
+
+// RegisterKeyedComponent registers Keyed[T] as a component type, so entities can hold
+// several T components distinguished by key. T itself doesn't need to be registered.
+func RegisterKeyedComponent[T any](r *ComponentRegistry) {
+	RegisterComponent[Keyed[T]](r)
+	r.keyed[reflect.TypeFor[T]()] = reflect.TypeFor[Keyed[T]]()
+}
+
+// Get returns the component stored under key, or nil if there is none
+func (k *Keyed[T]) Get(key string) *T {
+	for _, entry := range k.entries {
+		if entry.key == key {
+			return entry.value
+		}
+	}
+	return nil
+}
+
+// Set stores value under key, replacing any existing value, and returns a pointer to it.
+// New keys are added after the existing ones.
+func (k *Keyed[T]) Set(key string, value T) *T {
+	if existing := k.Get(key); existing != nil {
+		*existing = value
+		return existing
+	}
+
+	ptr := new(T)
+	*ptr = value
+	k.entries = append(k.entries, keyedEntry[T]{key: key, value: ptr})
+	return ptr
+}
+
+// Delete removes the component stored under key, reporting whether there was one
+func (k *Keyed[T]) Delete(key string) bool {
+	for i, entry := range k.entries {
+		if entry.key == key {
+			k.entries = slices.Delete(k.entries, i, i+1)
+			return true
+		}
+	}
+	return false
+}
+
+// Len returns the number of keys
+func (k *Keyed[T]) Len() int {
+	return len(k.entries)
+}
+
+// All returns an iterator over the keys and components in the order they were added
+func (k *Keyed[T]) All() iter.Seq2[string, *T] {
+	return func(yield func(string, *T) bool) {
+		for _, entry := range k.entries {
+			if !yield(entry.key, entry.value) {
+				return
+			}
+		}
+	}
+}
+
+// pointer returns the component stored under key as an unsafe.Pointer, for views
+func (k *Keyed[T]) pointer(key string) unsafe.Pointer {
+	return unsafe.Pointer(k.Get(key))
+}
+
+func (k *Keyed[T]) setAny(key string, value any) {
+	if ptr, ok := value.(*T); ok {
+		k.Set(key, *ptr)
+		return
+	}
+	k.Set(key, value.(T))
+}
+
+func (k *Keyed[T]) valueType() reflect.Type {
+	return reflect.TypeFor[T]()
+}
+
+func (k *Keyed[T]) remapEntityRefs(remap func(*EntityRef) *EntityRef) {
+	for _, entry := range k.entries {
+		remapEntityRefs(reflect.ValueOf(entry.value).Elem(), remap)
+	}
+}
+
+// keyedJSONEntry is the JSON layout of one key, kept in a list to preserve key order
+type keyedJSONEntry[T any] struct {
+	Key   string `json:"key"`
+	Value *T     `json:"value"`
+}
+
+// MarshalJSON encodes the keys and their components in order, so keyed components are
+// included by Storage.SaveWorld
+func (k Keyed[T]) MarshalJSON() ([]byte, error) {
+	entries := make([]keyedJSONEntry[T], len(k.entries))
+	for i, entry := range k.entries {
+		entries[i] = keyedJSONEntry[T]{Key: entry.key, Value: entry.value}
+	}
+	return json.Marshal(entries)
+}
+
+// UnmarshalJSON decodes keys written by MarshalJSON, replacing any existing keys
+func (k *Keyed[T]) UnmarshalJSON(data []byte) error {
+	var entries []keyedJSONEntry[T]
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return err
+	}
+
+	k.entries = k.entries[:0]
+	for _, entry := range entries {
+		var value T
+		if entry.Value != nil {
+			value = *entry.Value
+		}
+		k.Set(entry.Key, value)
+	}
+	return nil
+}
+
+// keyedTypeFor returns the Keyed[T] type registered for component type T. Panics if T was
+// not registered with RegisterKeyedComponent.
+func (r *ComponentRegistry) keyedTypeFor(compType reflect.Type) reflect.Type {
+	keyedType, ok := r.keyed[compType]
+	if !ok {
+		panic("component type " + compType.String() + " is not registered as a keyed component")
+	}
+	return keyedType
+}
+
+// AddKeyedComponent stores component under key in the entity's Keyed component of the
+// component's type, replacing any component already stored under that key. If the entity
+// doesn't have the Keyed component yet it is added, which moves the entity to a new archetype.
+// Returns the entity's id, which is new if it moved.
+func (s *Storage) AddKeyedComponent(id EntityId, key string, component any) EntityId {
+	compType := s.registry.componentTypeOf(component)
+	keyedType := s.registry.keyedTypeFor(compType)
+
+	if keyed, ok := s.GetComponent(id, keyedType).(keyedComponent); ok {
+		keyed.setAny(key, component)
+		return id
+	}
+
+	keyed := reflect.New(keyedType)
+	keyed.Interface().(keyedComponent).setAny(key, component)
+	return s.AddComponent(id, keyed.Elem().Interface())
+}
+
+// RemoveKeyedComponent removes the component of type compType stored under key. Removing the
+// last key removes the Keyed component itself, as with RemoveComponent. Returns the entity's
+// id, which is new if it moved and invalid if it was deleted.
+func (s *Storage) RemoveKeyedComponent(id EntityId, key string, compType reflect.Type) EntityId {
+	keyedType := s.registry.keyedTypeFor(compType)
+
+	keyed, ok := s.GetComponent(id, keyedType).(keyedComponent)
+	if !ok || !keyed.Delete(key) || keyed.Len() > 0 {
+		return id
+	}
+	return s.RemoveComponent(id, keyedType)
+}
+
+// ReadKeyed returns the component of type T stored under key on an entity, or nil if the
+// entity has no component under that key
+func ReadKeyed[T any](reader ComponentReader, entityId EntityId, key string) *T {
+	keyed, _ := reader.GetComponent(entityId, reflect.TypeFor[Keyed[T]]()).(*Keyed[T])
+	if keyed == nil {
+		return nil
+	}
+	return keyed.Get(key)
+}
@@ -0,0 +1,228 @@
+package ecs_test
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/plus3/ooftn/ecs"
+	"github.com/stretchr/testify/assert"
+)
+
+type weapon struct {
+	Damage int
+}
+
+type keyedLink struct {
+	Target *ecs.EntityRef
+}
+
+func TestKeyedComponents(t *testing.T) {
+	newStorage := func() *ecs.Storage {
+		registry := newTestRegistry()
+		ecs.RegisterKeyedComponent[weapon](registry)
+		ecs.RegisterKeyedComponent[keyedLink](registry)
+		return ecs.NewStorage(registry)
+	}
+
+	t.Run("add and read by key", func(t *testing.T) {
+		storage := newStorage()
+		id := storage.Spawn(Position{X: 1})
+
+		id = storage.AddKeyedComponent(id, "left", weapon{Damage: 5})
+		sameId := storage.AddKeyedComponent(id, "right", &weapon{Damage: 7})
+		assert.Equal(t, id, sameId, "adding a second key should not move the entity")
+
+		assert.Equal(t, 5, ecs.ReadKeyed[weapon](storage, id, "left").Damage)
+		assert.Equal(t, 7, ecs.ReadKeyed[weapon](storage, id, "right").Damage)
+		assert.Nil(t, ecs.ReadKeyed[weapon](storage, id, "back"))
+
+		storage.AddKeyedComponent(id, "left", weapon{Damage: 9})
+		assert.Equal(t, 9, ecs.ReadKeyed[weapon](storage, id, "left").Damage)
+
+		var keys []string
+		for key := range ecs.ReadComponent[ecs.Keyed[weapon]](storage, id).All() {
+			keys = append(keys, key)
+		}
+		assert.Equal(t, []string{"left", "right"}, keys)
+	})
+
+	t.Run("views", func(t *testing.T) {
+		storage := newStorage()
+		armed := storage.AddKeyedComponent(storage.Spawn(Position{}), "main", weapon{Damage: 3})
+		storage.Spawn(Position{})
+
+		view := ecs.NewView[struct {
+			*Position
+			Weapons *ecs.Keyed[weapon]
+		}](storage)
+
+		count := 0
+		for item := range view.Iter() {
+			count++
+			item.Weapons.Get("main").Damage *= 2
+			item.Weapons.Set("spare", weapon{Damage: 1})
+		}
+		assert.Equal(t, 1, count)
+		assert.Equal(t, 6, ecs.ReadKeyed[weapon](storage, armed, "main").Damage)
+		assert.Equal(t, 1, ecs.ReadKeyed[weapon](storage, armed, "spare").Damage)
+	})
+
+	t.Run("views by key", func(t *testing.T) {
+		storage := newStorage()
+		both := storage.AddKeyedComponent(storage.Spawn(Position{X: 1}), "left", weapon{Damage: 3})
+		both = storage.AddKeyedComponent(both, "right", weapon{Damage: 4})
+		leftOnly := storage.AddKeyedComponent(storage.Spawn(Position{X: 2}), "left", weapon{Damage: 5})
+		rightOnly := storage.AddKeyedComponent(storage.Spawn(Position{X: 3}), "right", weapon{Damage: 6})
+		storage.Spawn(Position{X: 4})
+
+		view := ecs.NewView[struct {
+			Id ecs.EntityId
+			*Position
+			Left  *weapon `ecs:"key=left"`
+			Right *weapon `ecs:"key=right,optional"`
+		}](storage)
+
+		damage := map[ecs.EntityId][2]int{}
+		for item := range view.Iter() {
+			right := 0
+			if item.Right != nil {
+				right = item.Right.Damage
+			}
+			damage[item.Id] = [2]int{item.Left.Damage, right}
+			item.Left.Damage++
+		}
+		assert.Equal(t, map[ecs.EntityId][2]int{both: {3, 4}, leftOnly: {5, 0}}, damage)
+		assert.Equal(t, 2, view.Count())
+		assert.Equal(t, 6, ecs.ReadKeyed[weapon](storage, leftOnly, "left").Damage, "fields point into storage")
+
+		assert.Nil(t, view.Get(rightOnly), "entities without a required key are skipped")
+		item := view.Get(both)
+		if assert.NotNil(t, item) {
+			assert.Equal(t, [2]int{4, 4}, [2]int{item.Left.Damage, item.Right.Damage})
+		}
+
+		assert.Panics(t, func() {
+			ecs.NewView[struct {
+				Main *Position `ecs:"key=main"`
+			}](storage)
+		}, "fields must address a keyed component type")
+		assert.Panics(t, func() {
+			ecs.NewView[struct {
+				Main *weapon `ecs:"key=main,added"`
+			}](storage)
+		})
+	})
+
+	t.Run("remove", func(t *testing.T) {
+		storage := newStorage()
+		id := storage.Spawn(Position{})
+		id = storage.AddKeyedComponent(id, "a", weapon{})
+		id = storage.AddKeyedComponent(id, "b", weapon{})
+
+		weaponType := reflect.TypeFor[weapon]()
+		keyedType := reflect.TypeFor[ecs.Keyed[weapon]]()
+
+		id = storage.RemoveKeyedComponent(id, "a", weaponType)
+		assert.True(t, storage.HasComponent(id, keyedType))
+		id = storage.RemoveKeyedComponent(id, "missing", weaponType)
+		assert.True(t, storage.HasComponent(id, keyedType))
+
+		id = storage.RemoveKeyedComponent(id, "b", weaponType)
+		assert.True(t, id.IsValid())
+		assert.False(t, storage.HasComponent(id, keyedType), "removing the last key should remove the component")
+	})
+
+	t.Run("commands", func(t *testing.T) {
+		storage := newStorage()
+		id := storage.Spawn(Position{})
+
+		commands := &ecs.Commands{}
+		commands.AddComponent(id, Velocity{})
+		commands.AddKeyedComponent(id, "a", weapon{Damage: 1})
+		commands.AddKeyedComponent(id, "b", weapon{Damage: 2})
+		commands.RemoveKeyedComponent(id, "a", reflect.TypeFor[weapon]())
+		commands.Flush(storage)
+
+		view := ecs.NewView[struct {
+			*Velocity
+			Weapons *ecs.Keyed[weapon]
+		}](storage)
+		count := 0
+		for item := range view.Iter() {
+			count++
+			assert.Equal(t, 1, item.Weapons.Len())
+			assert.Equal(t, 2, item.Weapons.Get("b").Damage)
+		}
+		assert.Equal(t, 1, count)
+	})
+
+	t.Run("save and load", func(t *testing.T) {
+		storage := newStorage()
+		id := storage.AddKeyedComponent(storage.Spawn(Position{}), "z", weapon{Damage: 1})
+		storage.AddKeyedComponent(id, "a", weapon{Damage: 2})
+
+		var buf bytes.Buffer
+		if !assert.NoError(t, storage.SaveWorld(&buf)) {
+			return
+		}
+		loaded := newStorage()
+		if !assert.NoError(t, loaded.LoadWorld(&buf)) {
+			return
+		}
+
+		view := ecs.NewView[struct{ Weapons *ecs.Keyed[weapon] }](loaded)
+		count := 0
+		for item := range view.Iter() {
+			count++
+			var keys []string
+			for key, w := range item.Weapons.All() {
+				keys = append(keys, key)
+				assert.Equal(t, ecs.ReadKeyed[weapon](storage, id, key).Damage, w.Damage)
+			}
+			assert.Equal(t, []string{"z", "a"}, keys)
+		}
+		assert.Equal(t, 1, count)
+	})
+
+	t.Run("save and load entity refs", func(t *testing.T) {
+		storage := newStorage()
+		target := storage.Spawn(Score(1))
+		storage.AddKeyedComponent(storage.Spawn(Position{}), "main", keyedLink{Target: storage.CreateEntityRef(target)})
+
+		var buf bytes.Buffer
+		if !assert.NoError(t, storage.SaveWorld(&buf)) {
+			return
+		}
+		// An unrelated entity takes the saved target's id in the loaded storage
+		loaded := newStorage()
+		assert.Equal(t, target, loaded.Spawn(Score(99)))
+		if !assert.NoError(t, loaded.LoadWorld(&buf)) {
+			return
+		}
+
+		view := ecs.NewView[struct{ Links *ecs.Keyed[keyedLink] }](loaded)
+		count := 0
+		for item := range view.Iter() {
+			count++
+			ref := item.Links.Get("main").Target
+			id, ok := loaded.ResolveEntityRef(ref)
+			if !assert.True(t, ok) {
+				return
+			}
+			assert.Equal(t, Score(1), *ecs.ReadComponent[Score](loaded, id), "keyed refs are rebound to the loaded entities")
+			assert.Same(t, loaded.CreateEntityRef(id), ref, "loaded keyed refs are tracked by the storage")
+
+			loaded.Delete(id)
+			_, ok = loaded.ResolveEntityRef(ref)
+			assert.False(t, ok)
+		}
+		assert.Equal(t, 1, count)
+	})
+
+	t.Run("unregistered type panics", func(t *testing.T) {
+		storage := newStorage()
+		id := storage.Spawn(Position{})
+		assert.Panics(t, func() { storage.AddKeyedComponent(id, "a", Velocity{}) })
+	})
+}
@@ -0,0 +1,34 @@
+package ecs
+
+import "reflect"
+
+var lodLevelType = reflect.TypeFor[LODLevel]()
+
+// LODLevel is a component holding an entity's level of detail, 0 being the most detailed, for
+// simulations that process distant or unimportant entities less often or not at all. A system
+// assigns the levels, typically from the distance to the camera with LODForDistance, and other
+// systems declare which levels they process with a *LODLevel view field tagged `ecs:"lod<=N"`,
+// which skips entities whose level is above N. Entities without a LODLevel are treated as
+// level 0, so only entities that opt in are ever skipped. Register it like any component with
+// RegisterComponent[ecs.LODLevel].
+//
+// Levels are plain component values, so changing them is an in-place write rather than a
+// structural change, and skipping an entity costs a comparison during iteration.
+type LODLevel struct {
+	Level int
+}
+
+// LODForDistance returns the level of detail for an entity at the given distance from the
+// point of interest: the number of thresholds, in ascending order, that the distance exceeds.
+// For example with thresholds 50 and 200, entities up to 50 away get level 0, up to 200
+// level 1, and farther ones level 2.
+func LODForDistance(distance float32, thresholds ...float32) int {
+	level := 0
+	for _, threshold := range thresholds {
+		if distance <= threshold {
+			break
+		}
+		level++
+	}
+	return level
+}
@@ -0,0 +1,74 @@
+package ecs
+
+import "reflect"
+
+// ComponentMask is a bitset of component types, using the bits assigned by a ComponentRegistry.
+// Each archetype computes its mask once on creation, so testing which components an entity
+// has is a bit test rather than a type lookup.
+type ComponentMask []uint64
+
+// NewComponentMask builds a mask containing the given component types.
+// Unregistered types are ignored.
+func NewComponentMask(registry *ComponentRegistry, types ...reflect.Type) ComponentMask {
+	var mask ComponentMask
+	for _, t := range types {
+		if bit, ok := registry.ComponentBit(t); ok {
+			mask.set(bit)
+		}
+	}
+	return mask
+}
+
+func (m *ComponentMask) set(bit int) {
+	word := bit / 64
+	for len(*m) <= word {
+		*m = append(*m, 0)
+	}
+	(*m)[word] |= 1 << (bit % 64)
+}
+
+// Has reports whether the given component bit is set in the mask.
+func (m ComponentMask) Has(bit int) bool {
+	if bit < 0 {
+		return false
+	}
+	word := bit / 64
+	if word >= len(m) {
+		return false
+	}
+	return m[word]&(1<<(bit%64)) != 0
+}
+
+// ContainsAll reports whether every bit set in other is also set in m.
+func (m ComponentMask) ContainsAll(other ComponentMask) bool {
+	for i, w := range other {
+		if w == 0 {
+			continue
+		}
+		if i >= len(m) || m[i]&w != w {
+			return false
+		}
+	}
+	return true
+}
+
+// ContainsAny reports whether m and other share at least one bit.
+func (m ComponentMask) ContainsAny(other ComponentMask) bool {
+	n := min(len(m), len(other))
+	for i := 0; i < n; i++ {
+		if m[i]&other[i] != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// ComponentMask returns the component mask of the entity's archetype, or nil if the
+// archetype does not exist. The returned mask is shared and must not be modified.
+func (s *Storage) ComponentMask(id EntityId) ComponentMask {
+	archetype, ok := s.archetypes[id.ArchetypeId()]
+	if !ok {
+		return nil
+	}
+	return archetype.mask
+}
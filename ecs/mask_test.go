@@ -0,0 +1,47 @@
+package ecs_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/plus3/ooftn/ecs"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestComponentMask(t *testing.T) {
+	registry := newTestRegistry()
+	storage := ecs.NewStorage(registry)
+
+	posBit := ecs.ComponentBitOf[Position](registry)
+	velBit := ecs.ComponentBitOf[Velocity](registry)
+	healthBit := ecs.ComponentBitOf[Health](registry)
+	assert.NotEqual(t, -1, posBit)
+	assert.NotEqual(t, posBit, velBit)
+
+	id := storage.Spawn(Position{}, Velocity{})
+	mask := storage.ComponentMask(id)
+	assert.True(t, mask.Has(posBit))
+	assert.True(t, mask.Has(velBit))
+	assert.False(t, mask.Has(healthBit))
+
+	want := ecs.NewComponentMask(registry, reflect.TypeFor[Position](), reflect.TypeFor[Velocity]())
+	assert.True(t, mask.ContainsAll(want))
+	assert.False(t, mask.ContainsAll(ecs.NewComponentMask(registry, reflect.TypeFor[Health]())))
+	assert.True(t, mask.ContainsAny(ecs.NewComponentMask(registry, reflect.TypeFor[Health](), reflect.TypeFor[Position]())))
+
+	// Masks are per archetype, so moving the entity changes its mask
+	id = storage.AddComponent(id, Health{})
+	assert.True(t, storage.ComponentMask(id).Has(healthBit))
+
+	assert.Nil(t, storage.ComponentMask(ecs.NewEntityId(12345, 0)))
+	assert.Equal(t, -1, ecs.ComponentBitOf[Temperature](ecs.NewComponentRegistry()))
+}
+
+func TestComponentMaskMultipleWords(t *testing.T) {
+	mask := ecs.ComponentMask{0, 1 << 3}
+	assert.True(t, mask.Has(67))
+	assert.False(t, mask.Has(3))
+	assert.False(t, mask.Has(200))
+	assert.True(t, mask.ContainsAll(ecs.ComponentMask{0, 1 << 3}))
+	assert.False(t, ecs.ComponentMask{1}.ContainsAll(mask))
+}
@@ -0,0 +1,225 @@
+// Package metrics exports scheduler and storage statistics in the Prometheus text exposition
+// format, for long-running simulations that report to a metrics backend. It has no
+// dependencies beyond the standard library, and the ecs package does not depend on it.
+//
+// An Exporter samples its scheduler and storage from the scheduler's goroutine, by being
+// registered as a system, and serves the latest sample over HTTP:
+//
+//	exporter := metrics.NewExporter(scheduler, storage, 10*time.Second)
+//	scheduler.Register(exporter)
+//	http.Handle("/metrics", exporter)
+package metrics
+
+import (
+	"bytes"
+	"cmp"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/plus3/ooftn/ecs"
+)
+
+// DefaultBuckets are the upper bounds, in seconds, of the system duration histogram buckets
+var DefaultBuckets = []float64{
+	0.00001, 0.000025, 0.00005, 0.0001, 0.00025, 0.0005,
+	0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1,
+}
+
+// Exporter exposes the statistics of a scheduler and a storage as Prometheus metrics:
+//
+//   - ecs_system_duration_seconds, a histogram of system execution time per system
+//   - ecs_system_executions_total, a counter of executions per system
+//   - ecs_entities, ecs_archetypes and ecs_singletons gauges
+//   - ecs_archetype_entities, a gauge of the entity count per archetype id
+//   - ecs_archetype_moves_total, a counter that is only exported while move tracking is enabled
+//
+// Systems are labelled with the names reported by Scheduler.GetStats, which are unique even
+// when several systems share a type. Either the scheduler or the storage may be nil.
+//
+// Neither the scheduler nor the storage is safe for concurrent use, so samples must be taken
+// on the goroutine running the scheduler: register the exporter as a system, or call Sample
+// between frames. ServeHTTP and WriteTo may be called from any goroutine.
+type Exporter struct {
+	scheduler *ecs.Scheduler
+	storage   *ecs.Storage
+	interval  time.Duration
+	buckets   []float64
+
+	lastSample time.Time
+	systems    map[string]*systemMetrics
+
+	mu     sync.Mutex
+	latest []byte
+}
+
+// systemMetrics accumulates the histogram of one system between samples
+type systemMetrics struct {
+	executions int64
+	total      time.Duration
+
+	counts []uint64 // cumulative counts per bucket, plus one for +Inf
+	sum    float64
+}
+
+// NewExporter creates an exporter that samples scheduler and storage at most once per
+// interval when run as a system
+func NewExporter(scheduler *ecs.Scheduler, storage *ecs.Storage, interval time.Duration) *Exporter {
+	return &Exporter{
+		scheduler: scheduler,
+		storage:   storage,
+		interval:  interval,
+		buckets:   DefaultBuckets,
+		systems:   make(map[string]*systemMetrics),
+	}
+}
+
+// SetBuckets replaces the upper bounds, in seconds, of the system duration histogram buckets.
+// It must be called before the first sample. Panics if the bounds are not increasing.
+func (e *Exporter) SetBuckets(bounds []float64) {
+	if len(e.systems) > 0 {
+		panic("metrics: buckets must be set before the first sample")
+	}
+	for i := 1; i < len(bounds); i++ {
+		if bounds[i] <= bounds[i-1] {
+			panic("metrics: bucket bounds must be increasing")
+		}
+	}
+	e.buckets = slices.Clone(bounds)
+}
+
+// Execute samples the scheduler and storage if the interval has passed since the last sample
+func (e *Exporter) Execute(frame *ecs.UpdateFrame) {
+	now := time.Now()
+	if !e.lastSample.IsZero() && now.Sub(e.lastSample) < e.interval {
+		return
+	}
+	e.lastSample = now
+	e.Sample()
+}
+
+// Sample reads the current statistics and makes them the ones served by the exporter.
+//
+// The scheduler only keeps aggregate durations, so each sample attributes the executions of a
+// system since the previous sample to the bucket of their mean duration. The histogram's sum
+// and count are exact, while its distribution has the resolution of the sampling interval.
+func (e *Exporter) Sample() {
+	var buf bytes.Buffer
+	if e.scheduler != nil {
+		e.writeSystems(&buf, e.scheduler.GetStats())
+	}
+	if e.storage != nil {
+		writeStorage(&buf, e.storage.CollectStats())
+	}
+
+	e.mu.Lock()
+	e.latest = buf.Bytes()
+	e.mu.Unlock()
+}
+
+// WriteTo writes the latest sample in the Prometheus text exposition format
+func (e *Exporter) WriteTo(w io.Writer) (int64, error) {
+	e.mu.Lock()
+	latest := e.latest
+	e.mu.Unlock()
+
+	n, err := w.Write(latest)
+	return int64(n), err
+}
+
+// ServeHTTP serves the latest sample in the Prometheus text exposition format
+func (e *Exporter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	e.WriteTo(w)
+}
+
+func (e *Exporter) writeSystems(buf *bytes.Buffer, stats *ecs.SchedulerStats) {
+	systems := slices.Clone(stats.Systems)
+	slices.SortFunc(systems, func(a, b ecs.SystemStats) int { return strings.Compare(a.Name, b.Name) })
+
+	for _, system := range systems {
+		metrics := e.systems[system.Name]
+		if metrics == nil {
+			metrics = &systemMetrics{counts: make([]uint64, len(e.buckets)+1)}
+			e.systems[system.Name] = metrics
+		}
+		metrics.observe(system, e.buckets)
+	}
+
+	buf.WriteString("# HELP ecs_system_duration_seconds Execution time of each system.\n")
+	buf.WriteString("# TYPE ecs_system_duration_seconds histogram\n")
+	for _, system := range systems {
+		metrics := e.systems[system.Name]
+		label := `system="` + escapeLabel(system.Name) + `"`
+		for i, bound := range e.buckets {
+			fmt.Fprintf(buf, "ecs_system_duration_seconds_bucket{%s,le=\"%s\"} %d\n", label, formatFloat(bound), metrics.counts[i])
+		}
+		fmt.Fprintf(buf, "ecs_system_duration_seconds_bucket{%s,le=\"+Inf\"} %d\n", label, metrics.counts[len(e.buckets)])
+		fmt.Fprintf(buf, "ecs_system_duration_seconds_sum{%s} %s\n", label, formatFloat(metrics.sum))
+		fmt.Fprintf(buf, "ecs_system_duration_seconds_count{%s} %d\n", label, metrics.counts[len(e.buckets)])
+	}
+
+	buf.WriteString("# HELP ecs_system_executions_total Number of times each system has executed.\n")
+	buf.WriteString("# TYPE ecs_system_executions_total counter\n")
+	for _, system := range systems {
+		fmt.Fprintf(buf, "ecs_system_executions_total{system=\"%s\"} %d\n", escapeLabel(system.Name), system.ExecutionCount)
+	}
+}
+
+// observe adds the executions since the previous sample to the histogram
+func (m *systemMetrics) observe(system ecs.SystemStats, buckets []float64) {
+	executions := system.ExecutionCount - m.executions
+	total := system.TotalDuration - m.total
+	m.executions = system.ExecutionCount
+	m.total = system.TotalDuration
+	if executions <= 0 {
+		return
+	}
+
+	mean := total.Seconds() / float64(executions)
+	for i, bound := range buckets {
+		if mean <= bound {
+			m.counts[i] += uint64(executions)
+		}
+	}
+	m.counts[len(buckets)] += uint64(executions)
+	m.sum += total.Seconds()
+}
+
+func writeStorage(buf *bytes.Buffer, stats *ecs.StorageStats) {
+	writeGauge(buf, "ecs_entities", "Number of live entities.", stats.TotalEntityCount)
+	writeGauge(buf, "ecs_archetypes", "Number of archetypes.", stats.ArchetypeCount)
+	writeGauge(buf, "ecs_singletons", "Number of singletons.", stats.SingletonCount)
+
+	archetypes := slices.Clone(stats.ArchetypeBreakdown)
+	slices.SortFunc(archetypes, func(a, b ecs.ArchetypeStats) int { return cmp.Compare(a.ID, b.ID) })
+	buf.WriteString("# HELP ecs_archetype_entities Number of live entities per archetype.\n")
+	buf.WriteString("# TYPE ecs_archetype_entities gauge\n")
+	for _, archetype := range archetypes {
+		fmt.Fprintf(buf, "ecs_archetype_entities{archetype=\"%d\"} %d\n", archetype.ID, archetype.EntityCount)
+	}
+
+	if stats.MoveTrackingEnabled {
+		buf.WriteString("# HELP ecs_archetype_moves_total Number of entities moved between archetypes.\n")
+		buf.WriteString("# TYPE ecs_archetype_moves_total counter\n")
+		fmt.Fprintf(buf, "ecs_archetype_moves_total %d\n", stats.ArchetypeMoves)
+	}
+}
+
+func writeGauge(buf *bytes.Buffer, name, help string, value int) {
+	fmt.Fprintf(buf, "# HELP %s %s\n# TYPE %s gauge\n%s %d\n", name, help, name, name, value)
+}
+
+// escapeLabel escapes a label value as required by the text exposition format
+func escapeLabel(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
+
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
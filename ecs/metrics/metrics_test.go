@@ -0,0 +1,74 @@
+package metrics_test
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/plus3/ooftn/ecs"
+	"github.com/plus3/ooftn/ecs/metrics"
+	"github.com/stretchr/testify/assert"
+)
+
+type position struct {
+	X, Y float32
+}
+
+type sleepSystem struct{}
+
+func (s *sleepSystem) Execute(frame *ecs.UpdateFrame) {
+	time.Sleep(time.Millisecond)
+}
+
+func TestExporter(t *testing.T) {
+	registry := ecs.NewComponentRegistry()
+	ecs.RegisterComponent[position](registry)
+	storage := ecs.NewStorage(registry)
+	storage.Spawn(position{})
+	storage.Spawn(position{X: 1})
+
+	scheduler := ecs.NewScheduler(storage)
+	scheduler.Register(&sleepSystem{})
+	exporter := metrics.NewExporter(scheduler, storage, time.Hour)
+	exporter.SetBuckets([]float64{0.0001, 1})
+	scheduler.Register(exporter)
+
+	scheduler.Once(0)
+	scheduler.Once(0)
+	exporter.Sample()
+
+	recorder := httptest.NewRecorder()
+	exporter.ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+	output := recorder.Body.String()
+	assert.Contains(t, recorder.Header().Get("Content-Type"), "text/plain; version=0.0.4")
+
+	for _, line := range []string{
+		"# TYPE ecs_system_duration_seconds histogram",
+		`ecs_system_duration_seconds_bucket{system="sleepSystem",le="0.0001"} 0`,
+		`ecs_system_duration_seconds_bucket{system="sleepSystem",le="1"} 2`,
+		`ecs_system_duration_seconds_bucket{system="sleepSystem",le="+Inf"} 2`,
+		`ecs_system_duration_seconds_count{system="sleepSystem"} 2`,
+		`ecs_system_executions_total{system="sleepSystem"} 2`,
+		"ecs_entities 2",
+		"ecs_archetypes 1",
+	} {
+		assert.Contains(t, output, line+"\n")
+	}
+	assert.Regexp(t, `\necs_archetype_entities\{archetype="\d+"\} 2\n`, output)
+	assert.NotContains(t, output, "ecs_archetype_moves_total", "moves are only exported while tracked")
+
+	t.Run("interval", func(t *testing.T) {
+		storage.Spawn(position{})
+		scheduler.Once(0)
+
+		var sb strings.Builder
+		exporter.WriteTo(&sb)
+		assert.Contains(t, sb.String(), "ecs_entities 2\n", "the exporter should not sample again within the interval")
+	})
+
+	t.Run("invalid buckets panic", func(t *testing.T) {
+		assert.Panics(t, func() { metrics.NewExporter(scheduler, nil, time.Second).SetBuckets([]float64{1, 1}) })
+		assert.Panics(t, func() { exporter.SetBuckets([]float64{1}) })
+	})
+}
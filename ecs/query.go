@@ -2,93 +2,215 @@ package ecs
 
 import (
 	"iter"
+	"slices"
 	"unsafe"
 )
 
-// Query wraps a View with caching optimizations for repeated iteration.
-// Queries cache matching archetypes to avoid re-calculating this on every run.
+// Query wraps a View with an API geared towards repeated iteration from systems.
+// The view caches matching archetypes to avoid re-calculating this on every run.
 type Query[T any] struct {
-	view               *View[T]
-	storage            *Storage
-	cachedArchetypes   []*Archetype
-	lastArchetypeCount int
+	view    *View[T]
+	storage *Storage
+	changes changeSnapshot
+	budget  budgetCursor
+	stats   queryStats
+	indexed indexedResults[T]
+}
+
+// indexedResults caches a query's results for Len and At
+type indexedResults[T any] struct {
+	ids   []EntityId
+	items []T
+	// versions holds the version of each matching archetype when the results were collected
+	versions []uint64
+	valid    bool
 }
 
 // NewQuery creates a new Query with archetype-level caching.
 func NewQuery[T any](storage *Storage) *Query[T] {
+	return NewQueryFromView(NewView[T](storage))
+}
+
+// NewQueryFromView creates a Query backed by an existing view. The query and the view share
+// their matching archetype and storage index caches, so a system using both styles on the
+// same component set only builds them once.
+func NewQueryFromView[T any](view *View[T]) *Query[T] {
 	return &Query[T]{
-		view:               NewView[T](storage),
-		storage:            storage,
-		lastArchetypeCount: -1,
+		view:    view,
+		storage: view.storage,
 	}
 }
 
+// NewViewFromQuery returns the view backing a query, sharing its caches as with NewQueryFromView
+func NewViewFromQuery[T any](query *Query[T]) *View[T] {
+	return query.view
+}
+
 // Init initializes or re-initializes the Query with a storage.
 // Called by the Scheduler during system registration.
 func (q *Query[T]) Init(storage *Storage) {
 	q.view = NewView[T](storage)
 	q.storage = storage
-	q.lastArchetypeCount = -1
+	q.changes = changeSnapshot{}
+	q.budget = budgetCursor{}
+	q.stats = queryStats{}
+	q.indexed = indexedResults[T]{}
 }
 
-func (q *Query[T]) iterArchetype(archetype *Archetype) iter.Seq[T] {
-	return func(yield func(T) bool) {
+func (q *Query[T]) iterArchetype(archetype *Archetype) iter.Seq2[EntityId, T] {
+	return q.iterArchetypeFrom(archetype, 0)
+}
+
+// iterArchetypeFrom is like iterArchetype but skips slots before start
+func (q *Query[T]) iterArchetypeFrom(archetype *Archetype, start int) iter.Seq2[EntityId, T] {
+	return func(yield func(EntityId, T) bool) {
 		if len(archetype.storages) == 0 {
 			return
 		}
 
-		storageIndices := q.view.buildStorageIndices(archetype)
-		firstStorage := archetype.storages[0]
+		storageIndices := q.view.storageIndicesFor(archetype)
 
 		var result T
 		resultPtr := unsafe.Pointer(&result)
 
-		for entityIndex := range firstStorage.Iter() {
+		for entityIndex := range archetype.enabledIndicesFrom(start) {
 			entityId := NewEntityId(archetype.id, uint32(entityIndex))
 			if !q.view.populateResult(resultPtr, archetype, entityIndex, storageIndices, entityId) {
 				continue
 			}
+			if debugChecks {
+				q.stats.visited++
+			}
 
-			if !yield(result) {
+			if !yield(entityId, result) {
 				return
 			}
 		}
 	}
 }
 
-func (q *Query[T]) invalidateIfNeeded() {
-	currentCount := len(q.storage.archetypes)
-	if currentCount != q.lastArchetypeCount {
-		q.cachedArchetypes = nil
-		q.lastArchetypeCount = currentCount
+// Iter returns an iterator over component data.
+func (q *Query[T]) Iter() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for _, item := range q.iterEntities() {
+			if !yield(item) {
+				return
+			}
+		}
 	}
 }
 
-func (q *Query[T]) ensureArchetypeCache() {
-	if q.cachedArchetypes != nil {
-		return
+func (q *Query[T]) iterEntities() iter.Seq2[EntityId, T] {
+	return func(yield func(EntityId, T) bool) {
+		defer q.endPass(q.beginPass())
+		for _, archetype := range q.view.matchingArchetypes() {
+			for id, item := range q.iterArchetype(archetype) {
+				if !yield(id, item) {
+					return
+				}
+			}
+		}
 	}
+}
 
-	q.cachedArchetypes = make([]*Archetype, 0)
-	for _, archetype := range q.storage.archetypes {
-		if q.view.matchesArchetype(archetype) {
-			q.cachedArchetypes = append(q.cachedArchetypes, archetype)
+// IterByArchetype returns an iterator over the matching archetypes that hold entities, each
+// paired with an iterator over its entities. See View.IterByArchetype.
+func (q *Query[T]) IterByArchetype() iter.Seq2[*Archetype, iter.Seq2[EntityId, T]] {
+	return func(yield func(*Archetype, iter.Seq2[EntityId, T]) bool) {
+		defer q.endPass(q.beginPass())
+		for _, archetype := range q.view.matchingArchetypes() {
+			if archetype.Len() == 0 {
+				continue
+			}
+			if !yield(archetype, q.iterArchetype(archetype)) {
+				return
+			}
 		}
 	}
 }
 
-// Iter returns an iterator over component data.
-func (q *Query[T]) Iter() iter.Seq[T] {
-	return func(yield func(T) bool) {
-		q.invalidateIfNeeded()
-		q.ensureArchetypeCache()
+func (q *Query[T]) lenHint() int {
+	return q.view.lenHint()
+}
 
-		for _, archetype := range q.cachedArchetypes {
-			for item := range q.iterArchetype(archetype) {
-				if !yield(item) {
-					return
-				}
-			}
+// ExecuteInto appends the results of the query to dst, reusing its capacity.
+// The caller owns the resulting slice; truncate it with (*dst)[:0] before reuse
+// to avoid accumulating results from previous calls.
+func (q *Query[T]) ExecuteInto(dst *[]T) {
+	defer q.endPass(q.beginPass())
+	*dst = slices.Grow(*dst, q.lenHint())
+
+	for _, archetype := range q.view.matchingArchetypes() {
+		for _, item := range q.iterArchetype(archetype) {
+			*dst = append(*dst, item)
+		}
+	}
+}
+
+// Len returns the number of entities the query matches, collecting its results so they can
+// be accessed by index with At, e.g. to visit every pair of entities once:
+//
+//	n := fighters.Len()
+//	for i := range n {
+//		_, a := fighters.At(i)
+//		for j := i + 1; j < n; j++ {
+//			_, b := fighters.At(j)
+//			resolveCombat(a, b)
+//		}
+//	}
+//
+// The results are kept until an entity is added to, removed from, enabled or disabled in one
+// of the matching archetypes, so repeated calls are cheap while the storage doesn't change.
+// Fields tagged `added`, `removed`, `lod<=N` or with a required `via` filter entities on
+// values that can change without such a change, so Len collects the results again on every
+// call for queries using them.
+func (q *Query[T]) Len() int {
+	if !q.indexed.valid || q.view.filtersEntities() || q.indexedStale() {
+		q.collectIndexed()
+	}
+	return len(q.indexed.ids)
+}
+
+// At returns the entity at index i of the results collected by the latest Len call, in the
+// order Iter yields them. At doesn't check whether the storage changed since, so call Len
+// again after spawning, deleting, moving, enabling or disabling entities; builds with the
+// ecs_debug tag panic if the matching archetypes changed. Panics if i is out of range.
+func (q *Query[T]) At(i int) (EntityId, T) {
+	if !q.indexed.valid {
+		q.collectIndexed()
+	} else if debugChecks && q.indexedStale() {
+		panic("query results changed since Len was called; call Len again before At")
+	}
+	return q.indexed.ids[i], q.indexed.items[i]
+}
+
+// indexedStale reports whether the matching archetypes changed since the results were collected
+func (q *Query[T]) indexedStale() bool {
+	archetypes := q.view.matchingArchetypes()
+	if len(archetypes) != len(q.indexed.versions) {
+		return true
+	}
+	for i, archetype := range archetypes {
+		if archetype.version != q.indexed.versions[i] {
+			return true
 		}
 	}
+	return false
+}
+
+func (q *Query[T]) collectIndexed() {
+	indexed := &q.indexed
+	clear(indexed.items)
+	indexed.ids = indexed.ids[:0]
+	indexed.items = indexed.items[:0]
+	for id, item := range q.iterEntities() {
+		indexed.ids = append(indexed.ids, id)
+		indexed.items = append(indexed.items, item)
+	}
+
+	indexed.versions = indexed.versions[:0]
+	for _, archetype := range q.view.matchingArchetypes() {
+		indexed.versions = append(indexed.versions, archetype.version)
+	}
+	indexed.valid = true
 }
@@ -0,0 +1,98 @@
+package ecs
+
+import (
+	"iter"
+	"slices"
+)
+
+// budgetCursor remembers where IterBudget stopped. It is a position rather than an entity id,
+// so it stays meaningful when the entity it was on is deleted or moved.
+type budgetCursor struct {
+	archetype uint32 // id of the archetype holding the next slot to visit
+	index     int    // next slot to visit
+}
+
+// IterBudget returns an iterator over at most n matching entities, paired with their ids,
+// continuing where the previous IterBudget call on this query stopped. Calling it once per
+// frame spreads the work of visiting every entity over several frames, e.g. re-planning 200
+// of a large population per frame.
+//
+// Entities are visited in cycles, in the order Iter visits them. When a call
+// reaches the end of a cycle it starts the next one from the beginning, but never yields the
+// same entity twice in one call, so a call yields min(n, matching entities) entities.
+// Structural changes between calls are handled as follows:
+//   - entities deleted before they were reached are skipped;
+//   - entities spawned ahead of the cursor are visited in the current cycle, and entities
+//     spawned behind it (including into freed slots or into a new archetype ordered before
+//     the cursor's) wait for the next cycle;
+//   - disabled entities are skipped;
+//   - an entity that moves to another archetype gets a new id and position, so depending on
+//     where it lands it may be visited again or not at all in the cycle during which it moved;
+//   - compacting an archetype reassigns its slots, with the same effect on its entities.
+//
+// Each query has a single cursor, shared by every IterBudget call and reset by Init.
+// Breaking out of the loop early leaves the cursor after the last entity yielded.
+func (q *Query[T]) IterBudget(n int) iter.Seq2[EntityId, T] {
+	return func(yield func(EntityId, T) bool) {
+		defer q.endPass(q.beginPass())
+		if n <= 0 {
+			return
+		}
+
+		order := q.view.matchingArchetypes()
+		if len(order) == 0 {
+			return
+		}
+
+		cursor := &q.budget
+		start := slices.IndexFunc(order, func(a *Archetype) bool { return a.id == cursor.archetype })
+		startIndex := cursor.index
+		if start == -1 {
+			start, startIndex = 0, 0
+		}
+
+		remaining := n
+		// visit yields the entities of archetype from slot from up to, but excluding, slot until
+		visit := func(archetype *Archetype, from, until int) bool {
+			for id, item := range q.iterArchetypeFrom(archetype, from) {
+				index := int(id.Index())
+				if until >= 0 && index >= until {
+					if debugChecks {
+						q.stats.visited-- // read past the end of the range, but not yielded
+					}
+					return true
+				}
+
+				cursor.archetype, cursor.index = archetype.id, index+1
+				remaining--
+				if !yield(id, item) || remaining == 0 {
+					return false
+				}
+			}
+			return true
+		}
+
+		// Finish the current cycle
+		for k := start; k < len(order); k++ {
+			from := 0
+			if k == start {
+				from = startIndex
+			}
+			if !visit(order[k], from, -1) {
+				return
+			}
+		}
+
+		// Start the next cycle, stopping where this call began
+		cursor.archetype, cursor.index = 0, 0
+		for k := 0; k <= start; k++ {
+			until := -1
+			if k == start {
+				until = startIndex
+			}
+			if !visit(order[k], 0, until) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,202 @@
+package ecs
+
+import (
+	"bytes"
+	"iter"
+	"maps"
+	"slices"
+	"unsafe"
+	"weak"
+)
+
+// changeSnapshot holds a copy of the view components of every entity a query has yielded
+// from IterChanged, kept per archetype and indexed by slot like the component storages.
+type changeSnapshot struct {
+	recordSize int
+	archetypes map[uint32]*archetypeSnapshot
+	// marks holds the storage's mark sequence when the last pass started, see Storage.MarkChanged
+	marks *markReader
+}
+
+// archetypeSnapshot holds fixed size records for the slots of one archetype. Each record has a
+// presence byte per component so optional fields appearing or disappearing count as changes.
+type archetypeSnapshot struct {
+	data []byte
+	seen []bool
+}
+
+// IterChanged returns an iterator over the entities whose view components changed since the
+// previous IterChanged pass over this query, paired with their ids. The first pass yields every
+// matching entity, as do entities that are new to the query or moved to another archetype.
+//
+// Changes are detected by comparing each component's memory with a copy taken when the entity
+// was last yielded, so any write through a View, Query, IterMut2 or command counts, and writes
+// that store the same value don't. The comparison is shallow: modifying data behind a pointer,
+// slice or map inside a component is not detected. Components are copied after the loop body
+// runs, so writes made while handling a changed entity are not reported again.
+//
+// A system that calls IterChanged once per frame therefore sees everything written since it
+// last ran, i.e. during the previous frame and by systems earlier in the current one. Breaking
+// out of the loop early leaves the entities that weren't visited to be compared on the next pass.
+// Copies are kept per slot, so an entity spawned into the slot of a deleted one is only reported
+// if its components differ from the deleted entity's. Changes the comparison can't see, such as
+// writes to a slice held by a component, can be reported with Storage.MarkChanged.
+func (q *Query[T]) IterChanged() iter.Seq2[EntityId, T] {
+	return func(yield func(EntityId, T) bool) {
+		defer q.endPass(q.beginPass())
+		snapshot := &q.changes
+		if snapshot.archetypes == nil {
+			snapshot.archetypes = make(map[uint32]*archetypeSnapshot)
+			for _, componentType := range q.view.types {
+				snapshot.recordSize += 1 + int(componentType.Size())
+			}
+		}
+		size := snapshot.recordSize
+		q.applyMarks()
+
+		for _, archetype := range q.view.matchingArchetypes() {
+			archetypeSnap := snapshot.archetypes[archetype.id]
+			if archetypeSnap == nil {
+				archetypeSnap = &archetypeSnapshot{}
+				snapshot.archetypes[archetype.id] = archetypeSnap
+			}
+
+			for id, item := range q.iterArchetype(archetype) {
+				index := int(id.Index())
+				if index >= len(archetypeSnap.seen) {
+					archetypeSnap.grow(index+1, size)
+				}
+
+				record := archetypeSnap.data[index*size : (index+1)*size]
+				if archetypeSnap.seen[index] && q.recordEquals(record, &item) {
+					continue
+				}
+
+				more := yield(id, item)
+				q.writeRecord(record, &item)
+				archetypeSnap.seen[index] = true
+				if !more {
+					return
+				}
+			}
+		}
+	}
+}
+
+// markReader records the mark sequence a query has applied. The storage only holds it weakly,
+// so the marks a query no longer needs are dropped once it is garbage collected.
+type markReader struct {
+	seq uint64
+}
+
+// MarkChanged reports the entity as changed to the next IterChanged pass of every query
+// matching it, whether or not its components look different. Use it after changes that
+// IterChanged can't detect, such as writes behind a pointer, slice or map held by a component.
+// Marks follow the entity to its new id when it moves to another archetype, so it can be marked
+// before or after queued commands are applied, and are dropped when it is deleted or once every
+// query has passed over it.
+func (s *Storage) MarkChanged(id EntityId) {
+	// Queries start reading marks from the current sequence, so only existing ones need them
+	if len(s.changes.markReaders) == 0 {
+		return
+	}
+	if s.changes.marks == nil {
+		s.changes.marks = make(map[EntityId]uint64)
+	}
+	s.changes.markSeq++
+	s.changes.marks[id] = s.changes.markSeq
+}
+
+// applyMarks forgets the copies of the entities marked changed since the previous pass, so
+// they are reported by this one. A query's first pass reports every entity, so it starts
+// reading marks from the current sequence.
+func (q *Query[T]) applyMarks() {
+	storage := q.view.storage
+	reader := q.changes.marks
+	if reader == nil {
+		reader = &markReader{seq: storage.changes.markSeq}
+		storage.changes.markReaders = append(storage.changes.markReaders, weak.Make(reader))
+		q.changes.marks = reader
+	}
+	if reader.seq == storage.changes.markSeq {
+		return
+	}
+	for id, seq := range storage.changes.marks {
+		if seq > reader.seq {
+			q.forgetChanges(id)
+		}
+	}
+	reader.seq = storage.changes.markSeq
+	storage.pruneMarks()
+}
+
+// pruneMarks drops the marks every query has applied, along with the readers of the queries
+// that were garbage collected
+func (s *Storage) pruneMarks() {
+	applied := s.changes.markSeq
+	s.changes.markReaders = slices.DeleteFunc(s.changes.markReaders, func(p weak.Pointer[markReader]) bool {
+		reader := p.Value()
+		if reader != nil {
+			applied = min(applied, reader.seq)
+		}
+		return reader == nil
+	})
+	maps.DeleteFunc(s.changes.marks, func(_ EntityId, seq uint64) bool {
+		return seq <= applied
+	})
+}
+
+// forgetChanges drops the copy of an entity's components, so it is reported by the next
+// IterChanged pass even if an entity with identical components takes its slot
+func (q *Query[T]) forgetChanges(id EntityId) {
+	if archetypeSnap := q.changes.archetypes[id.ArchetypeId()]; archetypeSnap != nil {
+		if index := int(id.Index()); index < len(archetypeSnap.seen) {
+			archetypeSnap.seen[index] = false
+		}
+	}
+}
+
+// grow makes room for at least slots records
+func (s *archetypeSnapshot) grow(slots, recordSize int) {
+	slots = max(slots, 2*len(s.seen))
+	s.seen = append(s.seen, make([]bool, slots-len(s.seen))...)
+	s.data = append(s.data, make([]byte, slots*recordSize-len(s.data))...)
+}
+
+// recordEquals reports whether record holds a copy of the view components referenced by item
+func (q *Query[T]) recordEquals(record []byte, item *T) bool {
+	offset := 0
+	for i, componentType := range q.view.types {
+		size := int(componentType.Size())
+		componentPtr := *(*unsafe.Pointer)(unsafe.Add(unsafe.Pointer(item), q.view.fieldOffset[i]))
+
+		present := componentPtr != nil
+		if (record[offset] == 1) != present {
+			return false
+		}
+		if present && size > 0 && !bytes.Equal(record[offset+1:offset+1+size], unsafe.Slice((*byte)(componentPtr), size)) {
+			return false
+		}
+		offset += 1 + size
+	}
+	return true
+}
+
+// writeRecord copies the view components referenced by item into record
+func (q *Query[T]) writeRecord(record []byte, item *T) {
+	offset := 0
+	for i, componentType := range q.view.types {
+		size := int(componentType.Size())
+		componentPtr := *(*unsafe.Pointer)(unsafe.Add(unsafe.Pointer(item), q.view.fieldOffset[i]))
+
+		if componentPtr == nil {
+			clear(record[offset : offset+1+size])
+		} else {
+			record[offset] = 1
+			if size > 0 {
+				copy(record[offset+1:offset+1+size], unsafe.Slice((*byte)(componentPtr), size))
+			}
+		}
+		offset += 1 + size
+	}
+}
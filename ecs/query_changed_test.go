@@ -0,0 +1,49 @@
+package ecs
+
+import (
+	"runtime"
+	"testing"
+)
+
+func TestMarkChangedPruning(t *testing.T) {
+	registry := NewComponentRegistry()
+	RegisterComponent[int](registry)
+	storage := NewStorage(registry)
+	ids := []EntityId{storage.Spawn(1), storage.Spawn(2), storage.Spawn(3)}
+
+	pass := func(query *Query[struct{ *int }]) {
+		for range query.IterChanged() {
+		}
+	}
+
+	storage.MarkChanged(ids[0])
+	if len(storage.changes.marks) != 0 {
+		t.Errorf("expected no marks to be kept without a query reading them, got %v", storage.changes.marks)
+	}
+
+	first := NewQuery[struct{ *int }](storage)
+	second := NewQuery[struct{ *int }](storage)
+	pass(first)
+	pass(second)
+
+	storage.MarkChanged(ids[0])
+	storage.MarkChanged(ids[1])
+	pass(first)
+	if len(storage.changes.marks) != 2 {
+		t.Errorf("expected the marks to be kept until every query applied them, got %v", storage.changes.marks)
+	}
+	pass(second)
+	if len(storage.changes.marks) != 0 {
+		t.Errorf("expected the marks to be dropped once every query applied them, got %v", storage.changes.marks)
+	}
+
+	// A collected query no longer holds marks back
+	storage.MarkChanged(ids[2])
+	second = nil
+	runtime.GC()
+	pass(first)
+	if len(storage.changes.marks) != 0 || len(storage.changes.markReaders) != 1 {
+		t.Errorf("expected the collected query's reader and marks to be dropped, got %d readers and marks %v",
+			len(storage.changes.markReaders), storage.changes.marks)
+	}
+}
@@ -0,0 +1,71 @@
+//go:build ecs_debug
+
+package ecs_test
+
+import (
+	"testing"
+
+	"github.com/plus3/ooftn/ecs"
+)
+
+func TestQueryStats(t *testing.T) {
+	registry := ecs.NewComponentRegistry()
+	ecs.RegisterComponent[Position](registry)
+	ecs.RegisterComponent[Velocity](registry)
+	storage := ecs.NewStorage(registry)
+	query := ecs.NewQuery[struct{ *Position }](storage)
+
+	for i := 0; i < 3; i++ {
+		storage.Spawn(Position{})
+	}
+	storage.Spawn(Position{}, Velocity{})
+	storage.Spawn(Velocity{})
+
+	if stats := query.Stats(); stats.Passes != 0 || stats.MatchedArchetypes != 2 {
+		t.Errorf("unexpected stats before the first pass: %+v", stats)
+	}
+
+	for range query.Iter() {
+	}
+	stats := query.Stats()
+	if stats.Passes != 1 || stats.Entities != 4 {
+		t.Errorf("expected one pass over 4 entities, got %+v", stats)
+	}
+
+	for range query.IterBudget(2) {
+	}
+	stats = query.Stats()
+	if stats.Passes != 2 || stats.Entities != 2 || stats.TotalDuration < stats.LastDuration {
+		t.Errorf("expected a second pass over 2 entities, got %+v", stats)
+	}
+
+	// Nested passes are counted as part of the outer pass
+	for range query.Iter() {
+		for range query.Iter() {
+		}
+		break
+	}
+	stats = query.Stats()
+	if stats.Passes != 3 || stats.Entities != 5 {
+		t.Errorf("expected a third pass over 5 entities, got %+v", stats)
+	}
+}
+
+func TestQueryAtStale(t *testing.T) {
+	registry := ecs.NewComponentRegistry()
+	ecs.RegisterComponent[Position](registry)
+	storage := ecs.NewStorage(registry)
+	query := ecs.NewQuery[struct{ *Position }](storage)
+	storage.Spawn(Position{})
+
+	query.Len()
+	query.At(0)
+	storage.Spawn(Position{})
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected At to panic when the results changed since Len")
+		}
+	}()
+	query.At(0)
+}
@@ -0,0 +1,72 @@
+package ecs
+
+import "time"
+
+// QueryStats describes the cost of a query. A pass is one call to Iter, ExecuteInto,
+// IterChanged, IterBudget or IterByArchetype, one use of the query as a CollectMap source, or
+// one collection of the results accessed with Len and At.
+// Passes are only measured in builds with the ecs_debug tag; elsewhere only
+// MatchedArchetypes is reported.
+type QueryStats struct {
+	// Name is the query's field name when reported by the scheduler, see Scheduler.SetQueryStats
+	Name string
+	// MatchedArchetypes is the number of archetypes that have the query's required components
+	MatchedArchetypes int
+	// Entities is the number of entities visited by the last pass
+	Entities int
+	// LastDuration is the duration of the last pass. For iterators this includes the loop
+	// body, so it measures the time spent processing the query's results.
+	LastDuration  time.Duration
+	Passes        int64
+	TotalDuration time.Duration
+}
+
+// queryStats accumulates the cost of a query's passes. depth tracks nested passes over the
+// same query, e.g. pairwise loops, which are counted as part of the outermost pass.
+type queryStats struct {
+	depth    int
+	visited  int
+	entities int
+	passes   int64
+	last     time.Duration
+	total    time.Duration
+}
+
+// Stats returns the cost of the query's passes since it was created or last initialized
+func (q *Query[T]) Stats() QueryStats {
+	return QueryStats{
+		MatchedArchetypes: len(q.view.matchingArchetypes()),
+		Entities:          q.stats.entities,
+		LastDuration:      q.stats.last,
+		Passes:            q.stats.passes,
+		TotalDuration:     q.stats.total,
+	}
+}
+
+// beginPass starts timing a pass. Call endPass with the result when the pass is done.
+func (q *Query[T]) beginPass() time.Time {
+	if !debugChecks {
+		return time.Time{}
+	}
+	if q.stats.depth == 0 {
+		q.stats.visited = 0
+	}
+	q.stats.depth++
+	return time.Now()
+}
+
+func (q *Query[T]) endPass(start time.Time) {
+	if !debugChecks {
+		return
+	}
+	q.stats.depth--
+	if q.stats.depth > 0 {
+		return
+	}
+
+	duration := time.Since(start)
+	q.stats.entities = q.stats.visited
+	q.stats.last = duration
+	q.stats.total += duration
+	q.stats.passes++
+}
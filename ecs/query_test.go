@@ -1,6 +1,7 @@
 package ecs_test
 
 import (
+	"slices"
 	"testing"
 
 	"github.com/plus3/ooftn/ecs"
@@ -101,4 +102,394 @@ func TestQuery(t *testing.T) {
 			t.Errorf("expected 3 entities, got %d", count)
 		}
 	})
+
+	t.Run("execute into", func(t *testing.T) {
+		_, query := setupQueryTest()
+
+		var results []struct {
+			Id ecs.EntityId
+			*Position
+			*Velocity
+		}
+		query.ExecuteInto(&results)
+		if len(results) != 3 {
+			t.Fatalf("expected 3 results, got %d", len(results))
+		}
+		for _, item := range results {
+			if item.Position == nil || item.Velocity == nil {
+				t.Error("expected non-nil components")
+			}
+		}
+
+		capacity := cap(results)
+		results = results[:0]
+		query.ExecuteInto(&results)
+		if len(results) != 3 {
+			t.Errorf("expected 3 results after reuse, got %d", len(results))
+		}
+		if cap(results) != capacity {
+			t.Errorf("expected capacity %d to be reused, got %d", capacity, cap(results))
+		}
+
+		query.ExecuteInto(&results)
+		if len(results) != 6 {
+			t.Errorf("expected results to be appended, got %d", len(results))
+		}
+	})
+	t.Run("collect map", func(t *testing.T) {
+		storage, query := setupQueryTest()
+
+		collected := ecs.CollectMap(query)
+		if len(collected) != 3 {
+			t.Fatalf("expected 3 entries, got %d", len(collected))
+		}
+		for id, item := range collected {
+			if item.Id != id {
+				t.Errorf("expected entry keyed by %d to hold id %d", id, item.Id)
+			}
+			item.Position.X = 100
+		}
+		for id := range collected {
+			if ecs.ReadComponent[Position](storage, id).X != 100 {
+				t.Error("expected collected entries to hold live component pointers")
+			}
+		}
+
+		view := ecs.NewView[struct{ *Position }](storage)
+		if positions := ecs.CollectMap(view); len(positions) != 4 {
+			t.Errorf("expected 4 entries from view, got %d", len(positions))
+		}
+	})
+
+	t.Run("collect map into", func(t *testing.T) {
+		storage, query := setupQueryTest()
+
+		dst := ecs.CollectMap(query)
+		stale := storage.Spawn(Velocity{})
+		dst[stale] = dst[stale]
+
+		ecs.CollectMapInto(query, dst)
+		if len(dst) != 3 {
+			t.Errorf("expected map to be cleared and refilled with 3 entries, got %d", len(dst))
+		}
+		if _, ok := dst[stale]; ok {
+			t.Error("expected stale entry to be cleared")
+		}
+	})
+
+	t.Run("aggregates", func(t *testing.T) {
+		storage, query := setupQueryTest()
+		type item = struct {
+			Id ecs.EntityId
+			*Position
+			*Velocity
+		}
+		x := func(i item) float32 { return i.Position.X }
+
+		if sum := ecs.Sum(query, x); sum != 9 {
+			t.Errorf("expected sum 9, got %f", sum)
+		}
+		if low, ok := ecs.Min(query, x); !ok || low != 1 {
+			t.Errorf("expected min 1, got %f", low)
+		}
+		if high, ok := ecs.Max(query, x); !ok || high != 5 {
+			t.Errorf("expected max 5, got %f", high)
+		}
+		if avg, ok := ecs.Avg(query, func(i item) int { return int(i.Position.Y) }); !ok || avg != 4 {
+			t.Errorf("expected avg 4, got %f", avg)
+		}
+
+		view := ecs.NewView[struct{ *Position }](storage)
+		if sum := ecs.Sum(view, func(i struct{ *Position }) float32 { return i.X }); sum != 16 {
+			t.Errorf("expected view sum 16, got %f", sum)
+		}
+
+		empty := ecs.NewView[struct{ *Health }](ecs.NewStorage(ecs.NewComponentRegistry()))
+		health := func(i struct{ *Health }) int { return i.Current }
+		if _, ok := ecs.Max(empty, health); ok {
+			t.Error("expected no max without entities")
+		}
+		if _, ok := ecs.Avg(empty, health); ok {
+			t.Error("expected no average without entities")
+		}
+	})
+}
+
+func TestQueryIterChanged(t *testing.T) {
+	storage, query := setupQueryTest()
+
+	changed := func() map[ecs.EntityId]bool {
+		ids := make(map[ecs.EntityId]bool)
+		for id := range query.IterChanged() {
+			ids[id] = true
+		}
+		return ids
+	}
+
+	if got := len(changed()); got != 3 {
+		t.Fatalf("first pass should yield every entity, got %d", got)
+	}
+	if got := len(changed()); got != 0 {
+		t.Fatalf("expected no changes, got %d", got)
+	}
+
+	var moved ecs.EntityId
+	for item := range query.Iter() {
+		if item.Position.X == 3 {
+			item.Position.X = 30
+			moved = item.Id
+		}
+		if item.Position.X == 1 {
+			item.Velocity.DX = 0.5 // same value, not a change
+		}
+	}
+
+	ids := changed()
+	if len(ids) != 1 || !ids[moved] {
+		t.Errorf("expected only entity %d to change, got %v", moved, ids)
+	}
+
+	t.Run("writes in loop body", func(t *testing.T) {
+		for _, item := range query.IterChanged() {
+			item.Position.X++
+		}
+		ecs.ReadComponent[Position](storage, moved).X = 40
+		for _, item := range query.IterChanged() {
+			item.Position.X++
+		}
+		if got := len(changed()); got != 0 {
+			t.Errorf("writes made while handling changes should not be reported, got %d", got)
+		}
+	})
+
+	t.Run("new entities", func(t *testing.T) {
+		id := storage.Spawn(Position{}, Velocity{})
+		ids := changed()
+		if len(ids) != 1 || !ids[id] {
+			t.Errorf("expected new entity %d to be reported, got %v", id, ids)
+		}
+	})
+
+	t.Run("early break", func(t *testing.T) {
+		for item := range query.Iter() {
+			item.Position.Y += 10
+		}
+		for range query.IterChanged() {
+			break
+		}
+		if got := len(changed()); got != 3 {
+			t.Errorf("entities not visited before break should be reported again, got %d", got)
+		}
+	})
+
+	t.Run("marked changes", func(t *testing.T) {
+		other := ecs.NewQuery[struct{ *Position }](storage)
+		for range other.IterChanged() {
+		}
+		changed()
+
+		storage.MarkChanged(moved)
+		ids := changed()
+		if len(ids) != 1 || !ids[moved] {
+			t.Errorf("expected marked entity %d to be reported, got %v", moved, ids)
+		}
+		if got := len(changed()); got != 0 {
+			t.Errorf("expected a mark to be reported once, got %d", got)
+		}
+
+		reported := 0
+		for range other.IterChanged() {
+			reported++
+		}
+		if reported != 1 {
+			t.Errorf("expected the mark to be reported to every query, got %d", reported)
+		}
+
+		storage.MarkChanged(moved)
+		storage.Delete(moved)
+		if got := len(changed()); got != 0 {
+			t.Errorf("expected the mark of a deleted entity to be dropped, got %d", got)
+		}
+	})
+}
+
+func TestQueryFromView(t *testing.T) {
+	storage, _ := setupQueryTest()
+
+	type posVel struct {
+		*Position
+		*Velocity
+	}
+
+	view := ecs.NewView[posVel](storage)
+	query := ecs.NewQueryFromView(view)
+	if ecs.NewViewFromQuery(query) != view {
+		t.Fatal("expected the query to be backed by the same view")
+	}
+
+	count := func() int {
+		n := 0
+		for range query.Iter() {
+			n++
+		}
+		return n
+	}
+	if got := count(); got != 3 {
+		t.Errorf("expected 3 results, got %d", got)
+	}
+
+	// A new archetype created after the first iteration is picked up by both
+	id := storage.Spawn(Position{}, Velocity{}, Health{})
+	storage.Spawn(Position{}, Health{})
+	if got := count(); got != 4 {
+		t.Errorf("expected 4 results after spawning, got %d", got)
+	}
+	if view.Get(id) == nil {
+		t.Error("expected the view to find the new entity")
+	}
+}
+
+func TestQueryIndexed(t *testing.T) {
+	storage, query := setupQueryTest()
+
+	var iterated []ecs.EntityId
+	for item := range query.Iter() {
+		iterated = append(iterated, item.Id)
+	}
+
+	n := query.Len()
+	if n != 3 {
+		t.Fatalf("expected 3 results, got %d", n)
+	}
+	var indexed []ecs.EntityId
+	for i := range n {
+		id, item := query.At(i)
+		if id != item.Id {
+			t.Errorf("expected At to return the id of its item, got %v and %v", id, item.Id)
+		}
+		indexed = append(indexed, id)
+	}
+	if !slices.Equal(iterated, indexed) {
+		t.Errorf("expected At to follow iteration order %v, got %v", iterated, indexed)
+	}
+
+	pairs := 0
+	for i := range n {
+		_, a := query.At(i)
+		for j := i + 1; j < n; j++ {
+			_, b := query.At(j)
+			if a.Position == b.Position {
+				t.Errorf("expected distinct entities at %d and %d", i, j)
+			}
+			pairs++
+		}
+	}
+	if pairs != 3 {
+		t.Errorf("expected 3 pairs, got %d", pairs)
+	}
+
+	t.Run("follows storage changes", func(t *testing.T) {
+		_, first := query.At(0)
+		first.Position.X = 42
+		if _, again := query.At(0); again.Position.X != 42 {
+			t.Errorf("expected At to return the stored components, got %v", again.Position.X)
+		}
+
+		storage.SetEnabled(iterated[0], false)
+		if got := query.Len(); got != 2 {
+			t.Errorf("expected disabling an entity to shrink the results to 2, got %d", got)
+		}
+		storage.SetEnabled(iterated[0], true)
+		storage.Delete(iterated[1])
+		id := storage.Spawn(Position{X: -1}, Velocity{})
+		if got := query.Len(); got != 3 {
+			t.Errorf("expected 3 results after deleting and spawning, got %d", got)
+		}
+		found := false
+		for i := range query.Len() {
+			if at, item := query.At(i); at == id && item.Position.X == -1 {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("expected the spawned entity %v in the results", id)
+		}
+	})
+
+	t.Run("out of range", func(t *testing.T) {
+		defer func() {
+			if recover() == nil {
+				t.Error("expected At to panic past the last result")
+			}
+		}()
+		query.At(query.Len())
+	})
+}
+
+func TestQueryIterBudget(t *testing.T) {
+	t.Run("round robin", func(t *testing.T) {
+		_, query := setupQueryTest()
+
+		var all []ecs.EntityId
+		for id := range query.IterBudget(10) {
+			all = append(all, id)
+		}
+		if len(all) != 3 {
+			t.Fatalf("expected a large budget to yield every entity once, got %v", all)
+		}
+
+		var got []ecs.EntityId
+		for range 3 {
+			for id := range query.IterBudget(2) {
+				got = append(got, id)
+			}
+		}
+		want := []ecs.EntityId{all[0], all[1], all[2], all[0], all[1], all[2]}
+		if !slices.Equal(got, want) {
+			t.Errorf("expected calls to continue round robin, got %v want %v", got, want)
+		}
+
+		for range query.IterBudget(0) {
+			t.Error("expected a zero budget to yield nothing")
+		}
+	})
+
+	t.Run("structural changes between calls", func(t *testing.T) {
+		registry := ecs.NewComponentRegistry()
+		ecs.RegisterComponent[Position](registry)
+		storage := ecs.NewStorage(registry)
+		query := ecs.NewQuery[struct{ *Position }](storage)
+
+		e := make([]ecs.EntityId, 4)
+		for i := range e {
+			e[i] = storage.Spawn(Position{X: float32(i)})
+		}
+
+		next := func(n int) []ecs.EntityId {
+			var ids []ecs.EntityId
+			for id := range query.IterBudget(n) {
+				ids = append(ids, id)
+			}
+			return ids
+		}
+
+		if got := next(2); !slices.Equal(got, e[:2]) {
+			t.Fatalf("expected %v, got %v", e[:2], got)
+		}
+
+		// Free a slot behind the cursor and one ahead of it, then refill both
+		storage.Delete(e[2])
+		storage.Delete(e[0])
+		behind := storage.Spawn(Position{})
+		ahead := storage.Spawn(Position{})
+		if behind.Index() != e[0].Index() || ahead.Index() != e[2].Index() {
+			t.Fatalf("expected freed slots to be reused, got %d and %d", behind.Index(), ahead.Index())
+		}
+
+		// The rest of the cycle includes the entity spawned ahead, the next one the entity behind
+		want := []ecs.EntityId{ahead, e[3], behind, e[1]}
+		if got := next(10); !slices.Equal(got, want) {
+			t.Errorf("expected %v, got %v", want, got)
+		}
+	})
 }
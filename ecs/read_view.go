@@ -0,0 +1,209 @@
+package ecs
+
+import (
+	"iter"
+	"reflect"
+	"unsafe"
+
+	"golang.org/x/tools/container/intsets"
+)
+
+// ReadOnly provides read-only access to a component that stays shared with storage.
+// Use it in ReadView structs for large components that should not be copied when the
+// view is populated. The zero value is returned for components that are not present.
+type ReadOnly[T any] struct {
+	ptr *T
+}
+
+// Get returns a copy of the component
+func (r ReadOnly[T]) Get() T {
+	if r.ptr == nil {
+		var zero T
+		return zero
+	}
+	return *r.ptr
+}
+
+// Present reports whether the component exists. It is only false for optional fields.
+func (r ReadOnly[T]) Present() bool {
+	return r.ptr != nil
+}
+
+func (ReadOnly[T]) readOnlyComponentType() reflect.Type {
+	return reflect.TypeFor[T]()
+}
+
+// readOnlyField is implemented by every ReadOnly[T] and lets NewReadView discover T
+type readOnlyField interface {
+	readOnlyComponentType() reflect.Type
+}
+
+// readViewField describes how a single ReadView struct field is populated
+type readViewField struct {
+	componentType reflect.Type
+	fieldOffset   uintptr
+	shared        bool
+	optional      bool
+}
+
+// ReadView provides read-only access to entity components. Unlike View, the populated
+// struct never holds mutable pointers into storage, so results can be handed to other
+// code (such as a renderer) without the risk of accidental writes.
+type ReadView[T any] struct {
+	storage             *Storage
+	fields              []readViewField
+	types               []reflect.Type
+	typeSet             *intsets.Sparse
+	entityIdFieldOffset *uintptr
+	storageIndicesCache map[uint32][]int
+}
+
+// NewReadView creates a new read-only view for the given struct type T.
+// Component fields are declared by value (e.g. `Position Position`) and are copied
+// when the view is populated, which suits small components. Large components can be
+// declared as ReadOnly[C] instead, which shares the stored component and only copies
+// it when Get is called. ReadOnly fields may be tagged `ecs:"optional"`.
+//
+// Results remain valid snapshots after the storage changes, but ReadOnly fields are
+// subject to the same lifetime rules as View pointers.
+func NewReadView[T any](storage *Storage) *ReadView[T] {
+	structType := reflect.TypeFor[T]()
+	if structType.Kind() != reflect.Struct {
+		panic("ReadView type parameter must be a struct")
+	}
+
+	var fields []readViewField
+	var types []reflect.Type
+	typeSet := &intsets.Sparse{}
+	var entityIdFieldOffset *uintptr
+
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+
+		if field.Type == reflect.TypeOf(EntityId(0)) {
+			offset := field.Offset
+			entityIdFieldOffset = &offset
+			continue
+		}
+
+		if field.Type.Kind() == reflect.Ptr {
+			panic("ReadView struct fields must be component values or ecs.ReadOnly, not pointers")
+		}
+
+		tag := viewTag{maxLOD: -1}
+		if !field.Anonymous {
+			tag = parseViewTag(field.Tag.Get("ecs"))
+		}
+		if tag.via != "" || tag.key != "" || tag.added || tag.removed || tag.maxLOD >= 0 {
+			panic("invalid ecs tag value on ReadView field " + field.Name + " (only \"optional\" is supported)")
+		}
+
+		readField := readViewField{
+			componentType: field.Type,
+			fieldOffset:   field.Offset,
+			optional:      tag.optional,
+		}
+		if ro, ok := reflect.Zero(field.Type).Interface().(readOnlyField); ok {
+			readField.componentType = ro.readOnlyComponentType()
+			readField.shared = true
+		} else if tag.optional {
+			panic("optional ReadView field " + field.Name + " must use ecs.ReadOnly")
+		}
+
+		if !readField.optional {
+			typeSet.Insert(typeId(readField.componentType))
+		}
+		fields = append(fields, readField)
+		types = append(types, readField.componentType)
+	}
+
+	return &ReadView[T]{
+		storage:             storage,
+		fields:              fields,
+		types:               types,
+		typeSet:             typeSet,
+		entityIdFieldOffset: entityIdFieldOffset,
+		storageIndicesCache: make(map[uint32][]int),
+	}
+}
+
+// Get returns a populated copy of the view struct for the given entity. Returns false
+// if the entity doesn't exist or is missing a required component.
+func (v *ReadView[T]) Get(id EntityId) (T, bool) {
+	var result T
+
+	archetype, ok := v.storage.archetypes[id.ArchetypeId()]
+	if !ok || !archetype.has(id.Index()) {
+		return result, false
+	}
+
+	if !v.populate(unsafe.Pointer(&result), archetype, int(id.Index()), v.storageIndicesFor(archetype), id) {
+		var zero T
+		return zero, false
+	}
+	return result, true
+}
+
+// Iter returns an iterator over populated copies of the view struct for all matching entities
+func (v *ReadView[T]) Iter() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for _, archetype := range v.storage.archetypeOrder {
+			if !v.typeSet.SubsetOf(archetype.typeSet) || len(archetype.storages) == 0 {
+				continue
+			}
+
+			storageIndices := v.storageIndicesFor(archetype)
+			for entityIndex := range archetype.enabledIndices() {
+				var result T
+				entityId := NewEntityId(archetype.id, uint32(entityIndex))
+				if !v.populate(unsafe.Pointer(&result), archetype, entityIndex, storageIndices, entityId) {
+					continue
+				}
+
+				if !yield(result) {
+					return
+				}
+			}
+		}
+	}
+}
+
+func (v *ReadView[T]) storageIndicesFor(archetype *Archetype) []int {
+	storageIndices, ok := v.storageIndicesCache[archetype.id]
+	if !ok {
+		storageIndices = buildStorageIndices(archetype, v.types)
+		v.storageIndicesCache[archetype.id] = storageIndices
+	}
+	return storageIndices
+}
+
+func (v *ReadView[T]) populate(resultPtr unsafe.Pointer, archetype *Archetype, entityIndex int, storageIndices []int, entityId EntityId) bool {
+	for i, field := range v.fields {
+		fieldPtr := unsafe.Pointer(uintptr(resultPtr) + field.fieldOffset)
+
+		var component any
+		if storageIdx := storageIndices[i]; storageIdx != -1 {
+			component = archetype.storages[storageIdx].Get(entityIndex)
+		}
+
+		if component == nil {
+			if !field.optional {
+				return false
+			}
+			continue
+		}
+
+		componentPtr := (*iface)(unsafe.Pointer(&component)).data
+		if field.shared {
+			*(*unsafe.Pointer)(fieldPtr) = componentPtr
+			continue
+		}
+
+		reflect.NewAt(field.componentType, fieldPtr).Elem().Set(reflect.NewAt(field.componentType, componentPtr).Elem())
+	}
+
+	if v.entityIdFieldOffset != nil {
+		*(*EntityId)(unsafe.Pointer(uintptr(resultPtr) + *v.entityIdFieldOffset)) = entityId
+	}
+	return true
+}
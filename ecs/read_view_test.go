@@ -0,0 +1,88 @@
+package ecs_test
+
+import (
+	"testing"
+
+	"github.com/plus3/ooftn/ecs"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadView(t *testing.T) {
+	storage := ecs.NewStorage(newTestRegistry())
+	moving := storage.Spawn(Position{X: 1, Y: 2}, Velocity{DX: 3, DY: 4}, Inventory{Items: []string{"sword"}})
+	still := storage.Spawn(Position{X: 5, Y: 6}, Inventory{Items: []string{"shield"}})
+
+	t.Run("copies value fields", func(t *testing.T) {
+		view := ecs.NewReadView[struct {
+			ecs.EntityId
+			Position Position
+			Velocity Velocity
+		}](storage)
+
+		item, ok := view.Get(moving)
+		assert.True(t, ok)
+		assert.Equal(t, moving, item.EntityId)
+		assert.Equal(t, Position{X: 1, Y: 2}, item.Position)
+		assert.Equal(t, Velocity{DX: 3, DY: 4}, item.Velocity)
+
+		// Writes to the copy never reach storage
+		item.Position.X = 100
+		again, _ := view.Get(moving)
+		assert.Equal(t, float32(1), again.Position.X)
+
+		_, ok = view.Get(still)
+		assert.False(t, ok)
+		_, ok = view.Get(ecs.InvalidEntityId)
+		assert.False(t, ok)
+
+		count := 0
+		for range view.Iter() {
+			count++
+		}
+		assert.Equal(t, 1, count)
+	})
+
+	t.Run("shares read only fields", func(t *testing.T) {
+		view := ecs.NewReadView[struct {
+			Position  Position
+			Inventory ecs.ReadOnly[Inventory]
+			Velocity  ecs.ReadOnly[Velocity] `ecs:"optional"`
+		}](storage)
+
+		items := make(map[float32][]string)
+		for item := range view.Iter() {
+			assert.True(t, item.Inventory.Present())
+			items[item.Position.X] = item.Inventory.Get().Items
+
+			if item.Position.X == 1 {
+				assert.True(t, item.Velocity.Present())
+				assert.Equal(t, float32(3), item.Velocity.Get().DX)
+			} else {
+				assert.False(t, item.Velocity.Present())
+				assert.Equal(t, Velocity{}, item.Velocity.Get())
+			}
+		}
+		assert.Equal(t, map[float32][]string{1: {"sword"}, 5: {"shield"}}, items)
+
+		// Shared fields observe later updates to the stored component
+		item, _ := view.Get(still)
+		ecs.NewView[struct{ *Inventory }](storage).Get(still).Inventory.Items = []string{"bow"}
+		assert.Equal(t, []string{"bow"}, item.Inventory.Get().Items)
+	})
+
+	t.Run("rejects invalid fields", func(t *testing.T) {
+		assert.Panics(t, func() {
+			ecs.NewReadView[struct{ *Position }](storage)
+		})
+		assert.Panics(t, func() {
+			ecs.NewReadView[struct {
+				Position Position `ecs:"optional"`
+			}](storage)
+		})
+		assert.Panics(t, func() {
+			ecs.NewReadView[struct {
+				Position Position `ecs:"added"`
+			}](storage)
+		})
+	})
+}
@@ -0,0 +1,34 @@
+package ecs
+
+import "iter"
+
+// IterInRect returns an iterator over the matching entities whose position, as returned by
+// pos, lies inside the rectangle from (minX, minY) to (maxX, maxY), bounds included, paired
+// with their ids. This is the culling a renderer does to skip off-screen entities.
+//
+// Every matching entity is visited and rejected with a cheap bounds test. When only a small
+// part of a large world is visible, index the entities with an IncrementalGrid and visit the
+// cells overlapping the rectangle with IncrementalGrid.InRect instead. Entities are yielded in
+// no particular order.
+func (v *View[T]) IterInRect(minX, minY, maxX, maxY float32, pos func(T) (x, y float32)) iter.Seq2[EntityId, T] {
+	return inRect(v.iterEntities(), minX, minY, maxX, maxY, pos)
+}
+
+// IterInRect returns an iterator over the matching entities inside a rectangle. See View.IterInRect.
+func (q *Query[T]) IterInRect(minX, minY, maxX, maxY float32, pos func(T) (x, y float32)) iter.Seq2[EntityId, T] {
+	return inRect(q.iterEntities(), minX, minY, maxX, maxY, pos)
+}
+
+func inRect[T any](items iter.Seq2[EntityId, T], minX, minY, maxX, maxY float32, pos func(T) (x, y float32)) iter.Seq2[EntityId, T] {
+	return func(yield func(EntityId, T) bool) {
+		for id, item := range items {
+			x, y := pos(item)
+			if x < minX || x > maxX || y < minY || y > maxY {
+				continue
+			}
+			if !yield(id, item) {
+				return
+			}
+		}
+	}
+}
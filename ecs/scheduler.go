@@ -3,6 +3,8 @@ package ecs
 import (
 	"context"
 	"reflect"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -12,6 +14,13 @@ type SchedulerStats struct {
 	SystemCount     int
 	TotalExecutions int64
 	Systems         []SystemStats
+
+	// LastFlush summarizes the commands applied at the end of the most recent frame, across
+	// the main storage and the named storages. Spawned lists the main storage's entities first.
+	LastFlush FlushResult
+
+	// Compaction reports the work of the compaction policy set with SetCompactionPolicy
+	Compaction CompactionStats
 }
 
 // SystemStats provides execution statistics for a single system.
@@ -23,6 +32,27 @@ type SystemStats struct {
 	AvgDuration    time.Duration
 	LastDuration   time.Duration
 	TotalDuration  time.Duration
+
+	// Disabled is set while the system is disabled with Scheduler.Enabled
+	Disabled bool
+
+	// Queries holds the stats of the system's Query fields, in field order, when query
+	// stats are enabled with Scheduler.SetQueryStats
+	Queries []QueryStats
+}
+
+// SystemTiming is the execution time of a single system within one call to OnceTimed.
+type SystemTiming struct {
+	Name     string
+	Duration time.Duration
+}
+
+// FrameTimings reports the time spent in a single call to OnceTimed. Systems lists every
+// system execution in order; a fast-forward may list the same system several times.
+type FrameTimings struct {
+	Systems []SystemTiming
+	Flush   time.Duration
+	Total   time.Duration
 }
 
 type systemStatsInternal struct {
@@ -32,48 +62,295 @@ type systemStatsInternal struct {
 	maxDuration    time.Duration
 	totalDuration  time.Duration
 	lastDuration   time.Duration
+	queries        []systemQuery
+	disabled       bool
+}
+
+// systemQuery is a Query field of a system, reported in SystemStats.Queries
+type systemQuery struct {
+	name  string
+	stats func() QueryStats
+}
+
+// snapshot converts the internal stats into the public SystemStats form, including the
+// stats of the system's queries if includeQueries is set.
+func (st *systemStatsInternal) snapshot(includeQueries bool) SystemStats {
+	avgDuration := time.Duration(0)
+	if st.executionCount > 0 {
+		avgDuration = st.totalDuration / time.Duration(st.executionCount)
+	}
+
+	stats := SystemStats{
+		Name:           st.name,
+		ExecutionCount: st.executionCount,
+		MinDuration:    st.minDuration,
+		MaxDuration:    st.maxDuration,
+		AvgDuration:    avgDuration,
+		LastDuration:   st.lastDuration,
+		TotalDuration:  st.totalDuration,
+		Disabled:       st.disabled,
+	}
+
+	if includeQueries && len(st.queries) > 0 {
+		stats.Queries = make([]QueryStats, len(st.queries))
+		for i, query := range st.queries {
+			stats.Queries[i] = query.stats()
+			stats.Queries[i].Name = query.name
+		}
+	}
+	return stats
+}
+
+// scheduledSystem holds a registered system along with its scheduling state.
+type scheduledSystem struct {
+	system      System
+	stats       *systemStatsInternal
+	once        bool
+	done        bool
+	removed     bool
+	whilePaused bool
+
+	// seq is the registration order, used to break ties when ordering by singleton access
+	// and dependencies
+	seq          int
+	reads        []singletonAccess
+	writes       []singletonAccess
+	systemType   reflect.Type
+	dependencies []reflect.Type
+
+	// replacement is the system passed to Replace while a frame was executing, and
+	// replacementDisabled whether it was disabled with Enabled before taking effect
+	replacement         System
+	replacementDisabled bool
+}
+
+// pendingRegistration is a Register or RegisterOnce call made while a frame was executing.
+type pendingRegistration struct {
+	system   System
+	once     bool
+	disabled bool
+}
+
+// pendingRun is a RunSystem request made while a frame was executing.
+type pendingRun struct {
+	entry *scheduledSystem
+	dt    float64
 }
 
 // Scheduler manages and executes systems in order.
 type Scheduler struct {
-	storage     *Storage
-	systems     []System
-	systemStats []*systemStatsInternal
+	storage      *Storage
+	storages     map[string]*Storage
+	storageNames []string
+	systems      []*scheduledSystem
+	statsByName  map[string]*systemStatsInternal
+	registered   int
+	accessOrder  bool
+
+	frameBudget      time.Duration
+	onBudgetExceeded func(frameDuration time.Duration, worst SystemStats)
+	onError          func(err error)
+
+	inFrame              bool
+	pendingRuns          []pendingRun
+	pendingRegistrations []pendingRegistration
+	pendingReplacements  []*scheduledSystem
+	removing             bool
+	readOnly             bool
+	tasks                taskRunner
+	queryStats           bool
+	lastFlush            FlushResult
+	middlewares          []Middleware
+	events               eventBus
+	compaction           compactionPolicy
+
+	paused          bool
+	pendingSteps    int
+	advanceTarget   float64
+	advanced        float64
+	fastForwardRate int
 }
 
 // NewScheduler creates a new scheduler for the given storage.
 func NewScheduler(storage *Storage) *Scheduler {
 	return &Scheduler{
-		storage: storage,
-		systems: make([]System, 0),
+		storage:         storage,
+		systems:         make([]*scheduledSystem, 0),
+		statsByName:     make(map[string]*systemStatsInternal),
+		fastForwardRate: 10,
+	}
+}
+
+// AddStorage registers an additional named storage with the scheduler. Query and
+// Singleton fields tagged with `ecs:"storage=<name>"` are bound to it instead of the
+// scheduler's main storage, and systems can reach it through UpdateFrame.StorageNamed
+// and UpdateFrame.CommandsFor. Storages must be added before registering systems that use them.
+func (s *Scheduler) AddStorage(name string, storage *Storage) {
+	if name == "" {
+		panic("storage name cannot be empty")
+	}
+	if _, exists := s.storages[name]; exists {
+		panic("storage already registered: " + name)
+	}
+	if s.storages == nil {
+		s.storages = make(map[string]*Storage)
 	}
+	s.storages[name] = storage
+	s.storageNames = append(s.storageNames, name)
 }
 
-// Register adds a system to the scheduler and initializes its Query fields.
+// Storage returns the named storage, or the scheduler's main storage if name is empty.
+// Returns nil if no storage with that name exists.
+func (s *Scheduler) Storage(name string) *Storage {
+	if name == "" {
+		return s.storage
+	}
+	return s.storages[name]
+}
+
+// StorageNames returns the names of the storages added with AddStorage, in the order they
+// were added. The main storage is not included.
+func (s *Scheduler) StorageNames() []string {
+	return slices.Clone(s.storageNames)
+}
+
+// Register adds a system to the scheduler and initializes its Query, Singleton and Events
+// fields. Plain *T fields are set to the singleton of type T if it already exists in storage,
+// or created as a zero value if the field is tagged `ecs:"singleton"`. The injected pointer
+// is not updated if the singleton is later replaced with AddSingleton.
+//
+// Systems run in registration order, except that Singleton and *T fields may be tagged
+// `ecs:"writes"` or `ecs:"reads"` to declare how the system uses the singleton. Every system
+// that writes a singleton then runs before all systems that read it, wherever they were
+// registered. Systems implementing DependentSystem likewise run after the systems whose types
+// they list, so CombatSystem can depend on FighterGridSystem wherever each is registered.
+// Register panics if the declarations form a cycle, naming the systems involved.
+//
+// Systems can register other systems through UpdateFrame.Scheduler, e.g. to start a
+// boss-phase system. A system registered while a frame is executing is registered once the
+// frame has been flushed, so it first executes on the next frame.
 func (s *Scheduler) Register(system System) {
-	s.initializeQueries(system)
-	s.systems = append(s.systems, system)
+	s.register(system, false)
+}
+
+// RegisterOnce adds a system that executes on the next call to Once and is then
+// automatically unregistered. The system participates in stats collection and its
+// commands are flushed with the rest of the frame. A frame in which a middleware skips the
+// system doesn't count, so it stays registered until it has actually executed.
+func (s *Scheduler) RegisterOnce(system System) {
+	s.register(system, true)
+}
 
-	systemType := reflect.TypeOf(system)
-	if systemType.Kind() == reflect.Ptr {
-		systemType = systemType.Elem()
+// register adds a system and returns its entry, or nil if registration is deferred until
+// the current frame has been flushed
+func (s *Scheduler) register(system System, once bool) *scheduledSystem {
+	if s.inFrame {
+		s.pendingRegistrations = append(s.pendingRegistrations, pendingRegistration{system: system, once: once})
+		return nil
 	}
-	systemName := systemType.Name()
 
-	s.systemStats = append(s.systemStats, &systemStatsInternal{
-		name:        systemName,
+	entry := s.newEntry(system, once, s.registered)
+	s.schedule(append(slices.Clone(s.systems), entry), entry, nil)
+	s.registered++
+	return entry
+}
+
+// newEntry initializes a system's fields and returns its scheduling state
+func (s *Scheduler) newEntry(system System, once bool, seq int) *scheduledSystem {
+	reads, writes, queries := s.initializeQueries(system)
+
+	whilePaused := false
+	if exempt, ok := system.(PauseExempt); ok {
+		whilePaused = exempt.RunsWhilePaused()
+	}
+
+	stats := &systemStatsInternal{
 		minDuration: time.Duration(1<<63 - 1),
-	})
+		queries:     queries,
+	}
+
+	var dependencies []reflect.Type
+	if dependent, ok := system.(DependentSystem); ok {
+		for _, dependency := range dependent.Dependencies() {
+			dependencies = append(dependencies, derefType(dependency))
+		}
+	}
+
+	return &scheduledSystem{
+		system:       system,
+		stats:        stats,
+		once:         once,
+		whilePaused:  whilePaused,
+		seq:          seq,
+		reads:        reads,
+		writes:       writes,
+		systemType:   derefType(reflect.TypeOf(system)),
+		dependencies: dependencies,
+	}
 }
 
-func (s *Scheduler) initializeQueries(system System) {
+// schedule orders systems, a copy of the registered systems with entry added, and makes them
+// the registered systems, dropping the stats of the system entry replaces, if any. Ordering
+// a copy leaves the registered systems untouched if it panics on a cycle.
+func (s *Scheduler) schedule(systems []*scheduledSystem, entry, replaced *scheduledSystem) {
+	if len(entry.reads) > 0 || len(entry.writes) > 0 || len(entry.dependencies) > 0 || s.accessOrder {
+		slices.SortFunc(systems, func(a, b *scheduledSystem) int { return a.seq - b.seq })
+		systems = orderSystems(systems)
+		s.accessOrder = true
+	}
+
+	if replaced != nil {
+		delete(s.statsByName, replaced.stats.name)
+	}
+	entry.stats.name = s.uniqueSystemName(systemNameOf(entry.system))
+	s.statsByName[entry.stats.name] = entry.stats
+	s.systems = systems
+}
+
+// systemNameOf returns the name reported by a NamedSystem, falling back to the system's type name
+func systemNameOf(system System) string {
+	if named, ok := system.(NamedSystem); ok {
+		if name := named.Name(); name != "" {
+			return name
+		}
+	}
+
+	return derefType(reflect.TypeOf(system)).Name()
+}
+
+// derefType returns the element type of a pointer type, or t itself otherwise
+func derefType(t reflect.Type) reflect.Type {
+	if t != nil && t.Kind() == reflect.Ptr {
+		return t.Elem()
+	}
+	return t
+}
+
+// uniqueSystemName returns name if no registered system uses it yet, otherwise the
+// name with the first free instance suffix ("MovementSystem#2", "MovementSystem#3", ...).
+func (s *Scheduler) uniqueSystemName(name string) string {
+	if _, taken := s.statsByName[name]; !taken {
+		return name
+	}
+
+	for i := 2; ; i++ {
+		candidate := name + "#" + strconv.Itoa(i)
+		if _, taken := s.statsByName[candidate]; !taken {
+			return candidate
+		}
+	}
+}
+
+// initializeQueries binds a system's Query, Singleton and Events fields and returns the
+// singletons it declared reading and writing, along with its queries
+func (s *Scheduler) initializeQueries(system System) (reads, writes []singletonAccess, queries []systemQuery) {
 	systemValue := reflect.ValueOf(system)
 	if systemValue.Kind() == reflect.Ptr {
 		systemValue = systemValue.Elem()
 	}
 
 	if systemValue.Kind() != reflect.Struct {
-		return
+		return nil, nil, nil
 	}
 
 	systemType := systemValue.Type()
@@ -86,6 +363,25 @@ func (s *Scheduler) initializeQueries(system System) {
 			continue
 		}
 
+		tag := parseSystemFieldTag(fieldType)
+		if tag.reads || tag.writes {
+			access := singletonAccess{storage: s.fieldStorage(fieldType), componentType: singletonFieldType(field)}
+			if access.componentType == nil {
+				panic("ecs \"reads\" and \"writes\" tags are only supported on Singleton and pointer fields: " + fieldType.Name)
+			}
+			if tag.reads {
+				reads = append(reads, access)
+			}
+			if tag.writes {
+				writes = append(writes, access)
+			}
+		}
+
+		if field.Kind() == reflect.Ptr {
+			s.injectSingletonPointer(field, fieldType)
+			continue
+		}
+
 		if field.Kind() != reflect.Struct {
 			continue
 		}
@@ -100,8 +396,12 @@ func (s *Scheduler) initializeQueries(system System) {
 			}
 
 			initMethod.Call([]reflect.Value{
-				reflect.ValueOf(s.storage),
+				reflect.ValueOf(s.fieldStorage(fieldType)),
 			})
+
+			if query, ok := field.Addr().Interface().(interface{ Stats() QueryStats }); ok {
+				queries = append(queries, systemQuery{name: fieldType.Name, stats: query.Stats})
+			}
 			continue
 		}
 
@@ -113,36 +413,617 @@ func (s *Scheduler) initializeQueries(system System) {
 			}
 
 			initMethod.Call([]reflect.Value{
-				reflect.ValueOf(s.storage),
+				reflect.ValueOf(s.fieldStorage(fieldType)),
 			})
 			continue
 		}
+
+		// Initialize Events fields
+		if strings.HasPrefix(typeName, "Events[") {
+			initMethod := field.Addr().MethodByName("Init")
+			if !initMethod.IsValid() {
+				panic("Init method not found on Events field: " + fieldType.Name)
+			}
+
+			initMethod.Call([]reflect.Value{reflect.ValueOf(s)})
+			continue
+		}
+	}
+	return reads, writes, queries
+}
+
+// singletonFieldType returns T for a Singleton[T] or *T system field, or nil for other fields
+func singletonFieldType(field reflect.Value) reflect.Type {
+	if field.Kind() == reflect.Ptr {
+		return field.Type().Elem()
+	}
+	if singleton, ok := field.Addr().Interface().(singletonField); ok {
+		return singleton.singletonType()
+	}
+	return nil
+}
+
+// systemFieldTag holds the options of an `ecs:"..."` tag on a system field
+type systemFieldTag struct {
+	storage   string
+	singleton bool
+	reads     bool
+	writes    bool
+}
+
+// parseSystemFieldTag parses a comma-separated system field tag such as `ecs:"singleton,storage=ui"`
+func parseSystemFieldTag(field reflect.StructField) systemFieldTag {
+	var tag systemFieldTag
+	raw := field.Tag.Get("ecs")
+	if raw == "" {
+		return tag
+	}
+
+	for _, part := range strings.Split(raw, ",") {
+		part = strings.TrimSpace(part)
+		switch part {
+		case "singleton":
+			tag.singleton = true
+			continue
+		case "reads":
+			tag.reads = true
+			continue
+		case "writes":
+			tag.writes = true
+			continue
+		}
+		if name, ok := strings.CutPrefix(part, "storage="); ok && name != "" {
+			tag.storage = name
+			continue
+		}
+		panic("invalid ecs tag on system field " + field.Name + ": \"" + raw + "\" (supported: \"storage=<name>\", \"singleton\", \"reads\", \"writes\")")
+	}
+	return tag
+}
+
+// fieldStorage returns the storage a system field should be bound to based on its `ecs:"storage=<name>"` tag.
+func (s *Scheduler) fieldStorage(field reflect.StructField) *Storage {
+	name := parseSystemFieldTag(field).storage
+	if name == "" {
+		return s.storage
+	}
+
+	storage, exists := s.storages[name]
+	if !exists {
+		panic("unknown storage \"" + name + "\" on system field " + field.Name)
+	}
+	return storage
+}
+
+// injectSingletonPointer sets a plain *T system field to the singleton of type T. Untagged
+// fields are only populated if the singleton already exists, so unrelated pointer fields
+// are left alone. Fields tagged `ecs:"singleton"` create a zero value singleton when missing.
+// Fields that are already set are never overwritten.
+func (s *Scheduler) injectSingletonPointer(field reflect.Value, fieldType reflect.StructField) {
+	tag := parseSystemFieldTag(fieldType)
+	if !field.IsNil() {
+		return
+	}
+
+	storage := s.fieldStorage(fieldType)
+	componentType := fieldType.Type.Elem()
+
+	entry := storage.getSingletonEntry(componentType)
+	if entry == nil {
+		if !tag.singleton {
+			return
+		}
+		storage.AddSingleton(reflect.New(componentType).Interface())
+		entry = storage.getSingletonEntry(componentType)
 	}
+
+	field.Set(reflect.NewAt(componentType, entry.dataPtr))
 }
 
 // Once executes all registered systems once with the given delta time.
+// While the scheduler is paused only PauseExempt systems execute, unless a step or
+// fast-forward has been requested. A fast-forward may execute the remaining systems
+// several times within a single call, each with its own frame and command flush.
 func (s *Scheduler) Once(dt float64) {
+	s.once(dt, nil)
+}
+
+// OnceTimed behaves like Once and also returns the execution time of each system and of
+// the command flushes during this call. Unlike the averages reported by GetStats, these
+// durations describe exactly this frame. Cumulative stats are updated as usual.
+func (s *Scheduler) OnceTimed(dt float64) FrameTimings {
+	var timings FrameTimings
+	s.once(dt, &timings)
+	return timings
+}
+
+func (s *Scheduler) once(dt float64, timings *FrameTimings) {
+	ticks := s.simulationTicks(dt)
+	frames := max(ticks, 1)
+	for i := 0; i < frames; i++ {
+		s.runFrame(dt, i < ticks, i == frames-1, timings)
+	}
+}
+
+// runFrame executes a single frame. Regular systems only execute when simulate is set
+// and PauseExempt systems only execute when exempt is set. When timings is non-nil the
+// frame's system and flush durations are appended to it.
+func (s *Scheduler) runFrame(dt float64, simulate bool, exempt bool, timings *FrameTimings) {
+	frame := s.newFrame(dt)
+	s.inFrame = true
+	// A panicking system or command that the caller recovers from must not leave the
+	// scheduler stuck in a frame, deferring every later Register and Remove
+	defer func() { s.inFrame = false }()
+
+	hasOnce := false
+	var frameDuration time.Duration
+	var worst *scheduledSystem
+	var worstDuration time.Duration
+	for _, entry := range s.systems {
+		if entry.whilePaused && !exempt || !entry.whilePaused && !simulate {
+			continue
+		}
+		if entry.stats.disabled || entry.removed {
+			continue
+		}
+
+		duration := s.execute(entry, frame)
+		if timings != nil {
+			timings.Systems = append(timings.Systems, SystemTiming{Name: entry.stats.name, Duration: duration})
+		}
+
+		frameDuration += duration
+		if worst == nil || duration > worstDuration {
+			worst = entry
+			worstDuration = duration
+		}
+		hasOnce = hasOnce || entry.once
+	}
+
+	if simulate {
+		s.tasks.tick(frame)
+	}
+
+	flushStart := time.Now()
+	s.flush(frame)
+	s.compactAfterFlush() // compaction is reported as part of the flush
+	flushDuration := time.Since(flushStart)
+	frameDuration += flushDuration
+
+	if timings != nil {
+		timings.Flush += flushDuration
+		timings.Total += frameDuration
+	}
+
+	if s.frameBudget > 0 && frameDuration > s.frameBudget && s.onBudgetExceeded != nil && worst != nil {
+		s.onBudgetExceeded(frameDuration, worst.stats.snapshot(s.queryStats))
+	}
+
+	s.events.swap()
+	if hasOnce || s.removing {
+		s.removeSystems()
+	}
+	s.inFrame = false
+	s.runPending()
+}
+
+// newFrame creates an update frame bound to the scheduler's storages.
+func (s *Scheduler) newFrame(dt float64) *UpdateFrame {
 	frame := newUpdateFrame(dt, s.storage)
+	frame.storages = s.storages
+	frame.storageNames = s.storageNames
+	frame.ReadOnly = s.readOnly
+	frame.Commands.readOnly = s.readOnly
+	frame.Commands.onError = s.onError
+	frame.tasks = &s.tasks
+	frame.scheduler = s
+	frame.events = &s.events
+	return frame
+}
 
-	for i, system := range s.systems {
+// execute runs a single system against the frame through the middlewares and records its stats.
+func (s *Scheduler) execute(entry *scheduledSystem, frame *UpdateFrame) time.Duration {
+	var duration time.Duration
+	executed := false
+	// started is set once the system is called, so a one-shot system that panicked into a
+	// recovering middleware is still unregistered while one skipped by a middleware is kept
+	started := false
+	if len(s.middlewares) == 0 {
+		// Time the system directly, without allocating the middleware chain
 		start := time.Now()
-		system.Execute(frame)
-		duration := time.Since(start)
+		entry.system.Execute(frame)
+		duration = time.Since(start)
+		executed = true
+		started = true
+	} else {
+		run := func() {
+			started = true
+			entry.system.Execute(frame)
+		}
+		timed := func() { timing(&duration, &executed)(entry.system, run) }
+		wrap(entry.system, timed, s.middlewares...)()
+	}
 
-		stats := s.systemStats[i]
-		stats.executionCount++
-		stats.lastDuration = duration
-		stats.totalDuration += duration
+	if entry.once && started {
+		entry.done = true
+	}
+	if !executed {
+		return 0
+	}
+
+	stats := entry.stats
+	stats.executionCount++
+	stats.lastDuration = duration
+	stats.totalDuration += duration
 
-		if duration < stats.minDuration {
-			stats.minDuration = duration
+	if duration < stats.minDuration {
+		stats.minDuration = duration
+	}
+	if duration > stats.maxDuration {
+		stats.maxDuration = duration
+	}
+	return duration
+}
+
+// flush applies the commands queued during a frame to every storage.
+func (s *Scheduler) flush(frame *UpdateFrame) {
+	result := frame.Commands.Flush(s.storage)
+	for _, name := range s.storageNames {
+		if commands, ok := frame.commands[name]; ok {
+			result.add(commands.Flush(s.storages[name]))
 		}
-		if duration > stats.maxDuration {
-			stats.maxDuration = duration
+	}
+	s.lastFlush = result
+}
+
+// RunSystem executes a single registered system with the given delta time and flushes
+// its commands, without running any other system. The system's stats are updated as in
+// a regular frame. It ignores pause state, the frame budget and Enabled, which makes it suitable
+// for stepping one system from a debugger or driving it from a test. When called from
+// inside a system the run is deferred until the current frame has been flushed.
+// Panics if the system is not registered.
+func (s *Scheduler) RunSystem(system System, dt float64) {
+	for _, entry := range s.systems {
+		if sameSystem(entry.system, system) {
+			s.runSingle(entry, dt)
+			return
 		}
 	}
+	panic("system not registered with scheduler: " + systemNameOf(system))
+}
 
-	frame.Commands.Flush(s.storage)
+// sameSystem reports whether a and b are the same system. Systems of a type that can't be
+// compared, such as a struct value holding a slice, never match; register such systems by
+// pointer to use them with RunSystem, Remove and Enabled.
+func sameSystem(a, b System) bool {
+	t := reflect.TypeOf(a)
+	if t != reflect.TypeOf(b) || !t.Comparable() {
+		return false
+	}
+	return a == b
+}
+
+// Remove unregisters a system and drops its stats, and returns false if it was not registered.
+// The remaining systems keep their order. Removing a system while a frame is executing, e.g.
+// from another system, stops it from executing during the rest of the frame, and it is
+// unregistered once the frame has been flushed.
+func (s *Scheduler) Remove(system System) bool {
+	for i, pending := range s.pendingRegistrations {
+		if sameSystem(pending.system, system) {
+			s.pendingRegistrations = slices.Delete(s.pendingRegistrations, i, i+1)
+			return true
+		}
+	}
+
+	for i, entry := range s.pendingReplacements {
+		if sameSystem(entry.replacement, system) {
+			// The replaced system is already removed, so it is unregistered after the frame
+			entry.replacement = nil
+			s.pendingReplacements = slices.Delete(s.pendingReplacements, i, i+1)
+			s.removing = true
+			return true
+		}
+	}
+
+	index := slices.IndexFunc(s.systems, func(entry *scheduledSystem) bool {
+		return sameSystem(entry.system, system) && !entry.removed
+	})
+	if index < 0 {
+		return false
+	}
+	s.systems[index].removed = true
+	s.removing = true
+	if !s.inFrame {
+		s.removeSystems()
+	}
+	return true
+}
+
+// Replace swaps a registered system for another, which takes over its place in the order
+// and runs once if old was registered with RegisterOnce, but starts with fresh stats. Returns
+// false if old was not registered. Replacing a system while a frame is executing stops old
+// from executing during the rest of the frame, and the replacement takes its place once the
+// frame has been flushed.
+func (s *Scheduler) Replace(old, replacement System) bool {
+	for i, pending := range s.pendingRegistrations {
+		if sameSystem(pending.system, old) {
+			s.pendingRegistrations[i] = pendingRegistration{system: replacement, once: pending.once}
+			return true
+		}
+	}
+	for _, entry := range s.pendingReplacements {
+		if sameSystem(entry.replacement, old) {
+			entry.replacement = replacement
+			entry.replacementDisabled = false
+			return true
+		}
+	}
+
+	index := slices.IndexFunc(s.systems, func(entry *scheduledSystem) bool {
+		return sameSystem(entry.system, old) && !entry.removed
+	})
+	if index < 0 {
+		return false
+	}
+	if s.inFrame {
+		entry := s.systems[index]
+		entry.removed = true
+		entry.replacement = replacement
+		s.pendingReplacements = append(s.pendingReplacements, entry)
+		return true
+	}
+	s.replace(index, replacement)
+	return true
+}
+
+// replace registers system in place of the system at index and returns its entry
+func (s *Scheduler) replace(index int, system System) *scheduledSystem {
+	replaced := s.systems[index]
+	entry := s.newEntry(system, replaced.once, replaced.seq)
+	systems := slices.Clone(s.systems)
+	systems[index] = entry
+	s.schedule(systems, entry, replaced)
+	return entry
+}
+
+// Enabled enables or disables a registered system. A disabled system is skipped by Once but
+// keeps its place in the order and its stats, so it can be toggled from a debugger while the
+// world runs. A system registered or swapped in with Replace while a frame is executing starts
+// out in the requested state once it takes effect. Returns false if the system is not
+// registered.
+func (s *Scheduler) Enabled(system System, enabled bool) bool {
+	found := false
+	for i, pending := range s.pendingRegistrations {
+		if sameSystem(pending.system, system) {
+			s.pendingRegistrations[i].disabled = !enabled
+			found = true
+		}
+	}
+	for _, entry := range s.pendingReplacements {
+		if sameSystem(entry.replacement, system) {
+			entry.replacementDisabled = !enabled
+			found = true
+		}
+	}
+	return s.setEnabled(enabled, func(entry *scheduledSystem) bool {
+		return sameSystem(entry.system, system)
+	}) || found
+}
+
+// EnabledByName enables or disables the registered system with the given stats name, as
+// Enabled does. Returns false if no system with that name is registered.
+func (s *Scheduler) EnabledByName(name string, enabled bool) bool {
+	return s.setEnabled(enabled, func(entry *scheduledSystem) bool {
+		return entry.stats.name == name
+	})
+}
+
+// setEnabled enables or disables every registered system that matches and hasn't been
+// removed, reporting whether there was one
+func (s *Scheduler) setEnabled(enabled bool, matches func(entry *scheduledSystem) bool) bool {
+	found := false
+	for _, entry := range s.systems {
+		if !entry.removed && matches(entry) {
+			entry.stats.disabled = !enabled
+			found = true
+		}
+	}
+	return found
+}
+
+// RunSystemByName executes the registered system with the given stats name, as RunSystem
+// does. Returns false if no system with that name is registered.
+func (s *Scheduler) RunSystemByName(name string, dt float64) bool {
+	for _, entry := range s.systems {
+		if entry.stats.name == name {
+			s.runSingle(entry, dt)
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Scheduler) runSingle(entry *scheduledSystem, dt float64) {
+	if s.inFrame {
+		s.pendingRuns = append(s.pendingRuns, pendingRun{entry: entry, dt: dt})
+		return
+	}
+
+	frame := s.newFrame(dt)
+	s.inFrame = true
+	defer func() { s.inFrame = false }()
+	s.execute(entry, frame)
+	s.flush(frame)
+
+	if entry.once || s.removing {
+		s.removeSystems()
+	}
+	s.inFrame = false
+	s.runPending()
+}
+
+// runPending registers the systems and executes the RunSystem requests that were deferred
+// while a frame was executing.
+func (s *Scheduler) runPending() {
+	replacements := s.pendingReplacements
+	s.pendingReplacements = nil
+	for _, replaced := range replacements {
+		if index := slices.Index(s.systems, replaced); index >= 0 {
+			system := replaced.replacement
+			replaced.replacement = nil
+			s.replace(index, system).stats.disabled = replaced.replacementDisabled
+		}
+	}
+
+	registrations := s.pendingRegistrations
+	s.pendingRegistrations = nil
+	for _, registration := range registrations {
+		s.register(registration.system, registration.once).stats.disabled = registration.disabled
+	}
+
+	pending := s.pendingRuns
+	s.pendingRuns = nil
+	for _, run := range pending {
+		if !run.entry.done && !run.entry.removed {
+			s.runSingle(run.entry, run.dt)
+		}
+	}
+}
+
+// simulationTicks returns how many frames regular systems should execute during the
+// next call to Once, consuming any pending step or fast-forward requests.
+func (s *Scheduler) simulationTicks(dt float64) int {
+	if !s.paused {
+		return 1
+	}
+
+	if s.advanceTarget > 0 {
+		ticks := 0
+		for ticks < s.fastForwardRate && s.advanced < s.advanceTarget {
+			s.advanced += dt
+			ticks++
+		}
+		if s.advanced >= s.advanceTarget {
+			s.advanceTarget = 0
+			s.advanced = 0
+		}
+		return ticks
+	}
+
+	if s.pendingSteps > 0 {
+		s.pendingSteps--
+		return 1
+	}
+
+	return 0
+}
+
+// SetFrameBudget sets the target duration for a single frame. When the summed execution
+// time of all systems plus the command flush exceeds the budget, the OnBudgetExceeded
+// callback is invoked. A budget of zero disables the check.
+func (s *Scheduler) SetFrameBudget(budget time.Duration) {
+	s.frameBudget = budget
+}
+
+// OnBudgetExceeded sets the callback invoked after a frame exceeds the frame budget.
+// It receives the frame duration and the stats of the slowest system in that frame.
+func (s *Scheduler) OnBudgetExceeded(fn func(frameDuration time.Duration, worst SystemStats)) {
+	s.onBudgetExceeded = fn
+}
+
+// OnError sets the handler receiving the errors recovered while running frames, such as a
+// *PanicError for each deferred function that panicked. Without a handler the first such
+// panic is raised again from the frame's flush, after every deferred function has run.
+func (s *Scheduler) OnError(fn func(err error)) {
+	s.onError = fn
+}
+
+// SetReadOnly marks the frames of this scheduler as read-only, which suits schedulers that
+// only render. Systems can check UpdateFrame.ReadOnly, and builds with the ecs_debug tag
+// panic when a system queues a structural command on a read-only frame.
+func (s *Scheduler) SetReadOnly(readOnly bool) {
+	s.readOnly = readOnly
+}
+
+// SetQueryStats controls whether SystemStats include the stats of each system's Query
+// fields, so the cost of a system can be broken down by query. Queries measure their passes
+// in builds with the ecs_debug tag; this only controls whether the scheduler reports them.
+func (s *Scheduler) SetQueryStats(enabled bool) {
+	s.queryStats = enabled
+}
+
+// Pause stops regular systems from executing. PauseExempt systems keep executing on every call to Once.
+func (s *Scheduler) Pause() {
+	s.paused = true
+}
+
+// Resume continues normal execution and discards any pending step or fast-forward requests.
+func (s *Scheduler) Resume() {
+	s.paused = false
+	s.pendingSteps = 0
+	s.advanceTarget = 0
+	s.advanced = 0
+}
+
+// Paused reports whether the scheduler is paused.
+func (s *Scheduler) Paused() bool {
+	return s.paused
+}
+
+// StepOnce requests that regular systems execute for a single frame on the next call to Once.
+// It has no effect unless the scheduler is paused.
+func (s *Scheduler) StepOnce() {
+	if s.paused {
+		s.pendingSteps++
+	}
+}
+
+// Advance fast-forwards a paused scheduler by the given amount of simulated time.
+// Each call to Once executes up to the fast-forward rate of frames until the
+// accumulated delta time reaches seconds. It has no effect unless the scheduler is paused.
+func (s *Scheduler) Advance(seconds float64) {
+	if s.paused && seconds > 0 {
+		s.advanceTarget = seconds
+		s.advanced = 0
+	}
+}
+
+// AdvanceProgress returns the simulated time advanced so far and the target of the
+// current fast-forward. Both are zero when no fast-forward is in progress.
+func (s *Scheduler) AdvanceProgress() (advanced float64, target float64) {
+	return s.advanced, s.advanceTarget
+}
+
+// SetFastForwardRate sets the maximum number of frames executed per call to Once while fast-forwarding.
+func (s *Scheduler) SetFastForwardRate(frames int) {
+	if frames < 1 {
+		panic("fast-forward rate must be at least 1")
+	}
+	s.fastForwardRate = frames
+}
+
+// removeSystems unregisters all one-shot systems that have executed and all systems removed
+// with Remove, except those waiting for their replacement.
+func (s *Scheduler) removeSystems() {
+	s.removing = false
+	remaining := s.systems[:0]
+	for _, entry := range s.systems {
+		// Replaced systems keep their place until runPending registers their replacement
+		if !entry.done && !entry.removed || entry.replacement != nil {
+			remaining = append(remaining, entry)
+			continue
+		}
+		delete(s.statsByName, entry.stats.name)
+	}
+	clear(s.systems[len(remaining):])
+	s.systems = remaining
+}
+
+// CancelTasks cancels every task started from this scheduler's frames that hasn't finished,
+// see Task.Cancel, so their goroutines exit once their deferred calls have run. A task calling
+// it stops at its next wait.
+func (s *Scheduler) CancelTasks() {
+	s.tasks.cancelAll()
 }
 
 // Run executes all systems repeatedly at the given interval until the context is cancelled.
@@ -164,32 +1045,32 @@ func (s *Scheduler) Run(ctx context.Context, interval time.Duration) {
 	}
 }
 
+// SystemStatsByName returns the current statistics of a single registered system. Systems
+// are named after their type; repeated registrations of the same type are suffixed with
+// an instance number, e.g. "MovementSystem#2".
+func (s *Scheduler) SystemStatsByName(name string) (SystemStats, bool) {
+	stats, ok := s.statsByName[name]
+	if !ok {
+		return SystemStats{}, false
+	}
+	return stats.snapshot(s.queryStats), true
+}
+
 // GetStats returns statistics about system execution.
 func (s *Scheduler) GetStats() *SchedulerStats {
 	stats := &SchedulerStats{
 		SystemCount: len(s.systems),
-		Systems:     make([]SystemStats, len(s.systemStats)),
+		Systems:     make([]SystemStats, len(s.systems)),
 	}
 
 	var totalExecs int64
-	for i, internal := range s.systemStats {
-		avgDuration := time.Duration(0)
-		if internal.executionCount > 0 {
-			avgDuration = internal.totalDuration / time.Duration(internal.executionCount)
-		}
-
-		stats.Systems[i] = SystemStats{
-			Name:           internal.name,
-			ExecutionCount: internal.executionCount,
-			MinDuration:    internal.minDuration,
-			MaxDuration:    internal.maxDuration,
-			AvgDuration:    avgDuration,
-			LastDuration:   internal.lastDuration,
-			TotalDuration:  internal.totalDuration,
-		}
-		totalExecs += internal.executionCount
+	for i, entry := range s.systems {
+		stats.Systems[i] = entry.stats.snapshot(s.queryStats)
+		totalExecs += entry.stats.executionCount
 	}
 
 	stats.TotalExecutions = totalExecs
+	stats.LastFlush = s.lastFlush
+	stats.Compaction = s.compaction.stats
 	return stats
 }
@@ -0,0 +1,107 @@
+package ecs
+
+import "slices"
+
+// CompactionStats reports the archetype compactions run by the scheduler's compaction policy,
+// see Scheduler.SetCompactionPolicy.
+type CompactionStats struct {
+	Runs           int64 // number of times the policy checked the storages
+	Archetypes     int64 // number of archetypes compacted
+	SlotsReclaimed int64 // number of empty slots removed by compaction
+	LastReclaimed  int   // number of empty slots removed by the most recent run
+}
+
+// compactionPolicy is the configuration and state of Scheduler.SetCompactionPolicy
+type compactionPolicy struct {
+	threshold float32
+	everyN    int
+	frames    int
+	stats     CompactionStats
+}
+
+// SetCompactionPolicy makes the scheduler compact fragmented archetypes every everyN frames,
+// at the end of the frame once its commands have been flushed. An archetype of the main or a
+// named storage is compacted when the ratio of its empty slots to all its slots exceeds
+// threshold, e.g. 0.25 compacts archetypes that are more than a quarter empty. An everyN of
+// zero or less disables compaction, which is the default. Panics if threshold is not between
+// 0 and 1.
+//
+// As with Archetype.Compact, compacting reassigns the ids of the entities it moves and updates
+// their EntityRefs. Views using `ecs:"added"` and `ecs:"removed"` and Storage.MarkChanged follow
+// the moved entities, and each move is reported to structural change listeners as EntityMoved,
+// but other EntityIds held across frames are not updated, so keep EntityRefs instead when
+// compaction is enabled.
+func (s *Scheduler) SetCompactionPolicy(threshold float32, everyN int) {
+	if threshold < 0 || threshold > 1 {
+		panic("compaction threshold must be between 0 and 1")
+	}
+	s.compaction.threshold = threshold
+	s.compaction.everyN = everyN
+	s.compaction.frames = 0
+}
+
+// compactAfterFlush runs the compaction policy at the end of a frame
+func (s *Scheduler) compactAfterFlush() {
+	policy := &s.compaction
+	if policy.everyN <= 0 {
+		return
+	}
+	policy.frames++
+	if policy.frames < policy.everyN {
+		return
+	}
+	policy.frames = 0
+
+	compacted, reclaimed := s.storage.compactFragmented(policy.threshold)
+	for _, name := range s.storageNames {
+		c, r := s.storages[name].compactFragmented(policy.threshold)
+		compacted += c
+		reclaimed += r
+	}
+
+	policy.stats.Runs++
+	policy.stats.Archetypes += int64(compacted)
+	policy.stats.SlotsReclaimed += int64(reclaimed)
+	policy.stats.LastReclaimed = reclaimed
+}
+
+// compactFragmented compacts the archetypes whose ratio of empty slots exceeds threshold and
+// returns how many archetypes were compacted and how many empty slots they reclaimed
+func (s *Storage) compactFragmented(threshold float32) (compacted, reclaimed int) {
+	for _, archetype := range s.archetypes {
+		empty := archetype.emptySlots()
+		if empty == 0 || float32(empty)/float32(empty+archetype.Len()) <= threshold {
+			continue
+		}
+
+		if indexMap := archetype.compact(); indexMap != nil {
+			s.recordCompacted(archetype.id, indexMap)
+			s.emitCompacted(archetype.id, indexMap)
+		}
+		compacted++
+		reclaimed += empty
+	}
+	return compacted, reclaimed
+}
+
+// emitCompacted reports every entity the compaction moved as EntityMoved. Entities only move
+// towards the front, so reporting them by ascending old index never reports a new id while
+// another entity still holds it, and listeners can re-key their entries one at a time.
+func (s *Storage) emitCompacted(archetypeId uint32, indexMap map[int]int) {
+	if len(s.structuralListeners) == 0 {
+		return
+	}
+
+	oldIndices := make([]int, 0, len(indexMap))
+	for oldIndex, newIndex := range indexMap {
+		if oldIndex != newIndex {
+			oldIndices = append(oldIndices, oldIndex)
+		}
+	}
+	slices.Sort(oldIndices)
+	for _, oldIndex := range oldIndices {
+		oldId := NewEntityId(archetypeId, uint32(oldIndex))
+		newId := NewEntityId(archetypeId, uint32(indexMap[oldIndex]))
+		s.emitStructuralChange(EntityMoved, newId, oldId)
+	}
+}
@@ -0,0 +1,57 @@
+//go:build ecs_debug
+
+package ecs_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/plus3/ooftn/ecs"
+)
+
+func TestReadOnlyFrameCommandsPanic(t *testing.T) {
+	registry := ecs.NewComponentRegistry()
+	ecs.RegisterComponent[Position](registry)
+	scheduler := ecs.NewScheduler(ecs.NewStorage(registry))
+	scheduler.Register(&readOnlyProbe{spawn: true})
+	scheduler.Once(0)
+
+	scheduler.SetReadOnly(true)
+	defer func() {
+		message, _ := recover().(string)
+		if !strings.Contains(message, "read-only frame") {
+			t.Errorf("expected read-only panic, got %q", message)
+		}
+	}()
+	scheduler.Once(0)
+}
+
+func TestSchedulerQueryStats(t *testing.T) {
+	registry := ecs.NewComponentRegistry()
+	ecs.RegisterComponent[Position](registry)
+	ecs.RegisterComponent[Velocity](registry)
+	ecs.RegisterComponent[Health](registry)
+	storage := ecs.NewStorage(registry)
+	scheduler := ecs.NewScheduler(storage)
+	scheduler.Register(&MovementSystem{})
+
+	storage.Spawn(Position{}, Velocity{})
+	storage.Spawn(Position{}, Velocity{}, Health{})
+	storage.Spawn(Position{})
+	scheduler.Once(1.0)
+
+	stats, _ := scheduler.SystemStatsByName("MovementSystem")
+	if stats.Queries != nil {
+		t.Errorf("expected no query stats by default, got %v", stats.Queries)
+	}
+
+	scheduler.SetQueryStats(true)
+	stats, _ = scheduler.SystemStatsByName("MovementSystem")
+	if len(stats.Queries) != 1 {
+		t.Fatalf("expected stats for one query, got %v", stats.Queries)
+	}
+	query := stats.Queries[0]
+	if query.Name != "Entities" || query.MatchedArchetypes != 2 || query.Entities != 2 || query.Passes != 1 {
+		t.Errorf("unexpected query stats %+v", query)
+	}
+}
@@ -0,0 +1,38 @@
+package ecs
+
+import "time"
+
+// Middleware wraps the execution of every system run by a Scheduler, for cross-cutting
+// concerns such as logging, profiling regions, recovering from panics or feature flags.
+// It must call next to execute the system, or can skip the system by not calling it.
+type Middleware func(system System, next func())
+
+// Use adds a middleware around the execution of every system, including systems run with
+// RunSystem. Middlewares wrap systems in the order they were added, the first one outermost,
+// so with Use(a) followed by Use(b) a system runs as a(b(system)). The scheduler's own
+// timing is the innermost middleware, so system stats don't include the time spent in the
+// ones added with Use, and a system skipped by a middleware doesn't count as executed.
+// A middleware added while a frame is executing applies from the next system on.
+func (s *Scheduler) Use(middleware Middleware) {
+	s.middlewares = append(s.middlewares, middleware)
+}
+
+// timing is the built-in middleware measuring how long a system takes to execute. It sets
+// executed only if the system returned, so a skipped or panicking system isn't recorded.
+func timing(duration *time.Duration, executed *bool) Middleware {
+	return func(system System, next func()) {
+		start := time.Now()
+		next()
+		*duration = time.Since(start)
+		*executed = true
+	}
+}
+
+// wrap returns a function executing the system through the middlewares, the first one outermost
+func wrap(system System, run func(), middlewares ...Middleware) func() {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		middleware, next := middlewares[i], run
+		run = func() { middleware(system, next) }
+	}
+	return run
+}
@@ -0,0 +1,143 @@
+package ecs
+
+import (
+	"reflect"
+	"strings"
+)
+
+// singletonAccess identifies a singleton type in a particular storage
+type singletonAccess struct {
+	storage       *Storage
+	componentType reflect.Type
+}
+
+// orderSystems returns systems ordered so that every system that writes a singleton runs
+// before the systems that read it, as declared with `ecs:"writes"` and `ecs:"reads"` tags,
+// and every system runs after the systems listed by its Dependencies. Systems are otherwise
+// kept in registration order. Panics if the declarations form a cycle.
+func orderSystems(systems []*scheduledSystem) []*scheduledSystem {
+	// after[i] lists the systems that must run after systems[i]
+	after := make([][]int, len(systems))
+	indegree := make([]int, len(systems))
+	for w, writer := range systems {
+		for r, reader := range systems {
+			if w == r || (!accessesOverlap(writer.writes, reader.reads) && !reader.dependsOn(writer)) {
+				continue
+			}
+			after[w] = append(after[w], r)
+			indegree[r]++
+		}
+	}
+
+	ordered := make([]*scheduledSystem, 0, len(systems))
+	placed := make([]bool, len(systems))
+	for len(ordered) < len(systems) {
+		next := -1
+		for i := range systems {
+			if !placed[i] && indegree[i] == 0 {
+				next = i
+				break
+			}
+		}
+		if next == -1 {
+			panic("system ordering cycle: " + describeOrderingCycle(systems, after, placed))
+		}
+
+		placed[next] = true
+		ordered = append(ordered, systems[next])
+		for _, r := range after[next] {
+			indegree[r]--
+		}
+	}
+	return ordered
+}
+
+func accessesOverlap(writes, reads []singletonAccess) bool {
+	for _, w := range writes {
+		for _, r := range reads {
+			if w == r {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// dependsOn reports whether s lists other's type in its Dependencies
+func (s *scheduledSystem) dependsOn(other *scheduledSystem) bool {
+	for _, dependency := range s.dependencies {
+		if dependency == other.systemType {
+			return true
+		}
+	}
+	return false
+}
+
+// name returns the system's stats name, or its plain name while it is still being registered
+func (s *scheduledSystem) name() string {
+	if s.stats.name != "" {
+		return s.stats.name
+	}
+	return systemNameOf(s.system)
+}
+
+// describeOrderingCycle finds a cycle among the systems that could not be placed and
+// formats it as "A -(T)-> B -> A", naming the singleton type that links two systems or
+// leaving it out when one depends on the other through Dependencies
+func describeOrderingCycle(systems []*scheduledSystem, after [][]int, placed []bool) string {
+	start := -1
+	for i := range systems {
+		if !placed[i] {
+			start = i
+			break
+		}
+	}
+
+	// Every unplaced system has an unplaced predecessor, so walking predecessors must revisit one
+	before := func(r int) int {
+		for w := range systems {
+			if placed[w] {
+				continue
+			}
+			for _, candidate := range after[w] {
+				if candidate == r {
+					return w
+				}
+			}
+		}
+		return -1
+	}
+
+	seen := make(map[int]int)
+	var path []int
+	for current := start; ; current = before(current) {
+		if at, ok := seen[current]; ok {
+			path = path[at:]
+			break
+		}
+		seen[current] = len(path)
+		path = append(path, current)
+	}
+
+	// path was built walking backwards, so reverse it into execution order
+	var sb strings.Builder
+	for i := len(path) - 1; i >= 0; i-- {
+		writer := systems[path[i]]
+		reader := systems[path[(i-1+len(path))%len(path)]]
+		sb.WriteString(writer.name())
+		sb.WriteString(" " + orderingLink(writer, reader) + " ")
+	}
+	sb.WriteString(systems[path[len(path)-1]].name())
+	return sb.String()
+}
+
+// orderingLink formats the reason reader runs after writer as "-(T)->" for a singleton of type
+// T, or as "->" for a dependency
+func orderingLink(writer, reader *scheduledSystem) string {
+	for _, w := range writer.writes {
+		if accessesOverlap([]singletonAccess{w}, reader.reads) {
+			return "-(" + w.componentType.String() + ")->"
+		}
+	}
+	return "->"
+}
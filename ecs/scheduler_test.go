@@ -2,6 +2,12 @@ package ecs_test
 
 import (
 	"context"
+	"fmt"
+	"math"
+	"reflect"
+	"runtime"
+	"slices"
+	"strings"
 	"testing"
 	"time"
 
@@ -40,6 +46,243 @@ func (s *HealthSystem) Execute(frame *ecs.UpdateFrame) {
 	}
 }
 
+type slowSystem struct {
+	delay time.Duration
+}
+
+func (s *slowSystem) Execute(frame *ecs.UpdateFrame) {
+	time.Sleep(s.delay)
+}
+
+type pauseExemptSystem struct {
+	ExecuteCount int
+}
+
+func (s *pauseExemptSystem) Execute(frame *ecs.UpdateFrame) {
+	s.ExecuteCount++
+}
+
+func (s *pauseExemptSystem) RunsWhilePaused() bool {
+	return true
+}
+
+type crossStorageSystem struct {
+	Positions  ecs.Query[struct{ *Position }]
+	Labels     ecs.Query[struct{ *Health }] `ecs:"storage=ui"`
+	LabelCount int
+}
+
+func (s *crossStorageSystem) Execute(frame *ecs.UpdateFrame) {
+	s.LabelCount = 0
+	for range s.Labels.Iter() {
+		s.LabelCount++
+	}
+
+	for range s.Positions.Iter() {
+		frame.CommandsFor("ui").Spawn(Health{Current: 1, Max: 1})
+	}
+}
+
+type runOtherSystem struct {
+	scheduler *ecs.Scheduler
+	target    ecs.System
+}
+
+func (s *runOtherSystem) Execute(frame *ecs.UpdateFrame) {
+	s.scheduler.RunSystem(s.target, frame.DeltaTime)
+}
+
+// phaseSystem registers the next phase's system through the frame on its first execution
+type phaseSystem struct {
+	next ecs.System
+	once bool
+}
+
+func (s *phaseSystem) Execute(frame *ecs.UpdateFrame) {
+	if s.next == nil {
+		return
+	}
+	if s.once {
+		frame.Scheduler().RegisterOnce(s.next)
+	} else {
+		frame.Scheduler().Register(s.next)
+	}
+	s.next = nil
+}
+
+// removeSystem removes its target from the scheduler when it executes
+type removeSystem struct {
+	target  ecs.System
+	removed bool
+}
+
+func (s *removeSystem) Execute(frame *ecs.UpdateFrame) {
+	s.removed = frame.Scheduler().Remove(s.target)
+}
+
+// replaceSystem replaces its target with another system when it executes
+type replaceSystem struct {
+	target, replacement ecs.System
+	replaced            bool
+}
+
+func (s *replaceSystem) Execute(frame *ecs.UpdateFrame) {
+	s.replaced = frame.Scheduler().Replace(s.target, s.replacement)
+}
+
+// callbackSystem calls its callback with the frame when it executes
+type callbackSystem struct {
+	callback func(frame *ecs.UpdateFrame)
+}
+
+func (s *callbackSystem) Execute(frame *ecs.UpdateFrame) {
+	s.callback(frame)
+}
+
+// sliceSystem is a value system whose type can't be compared with ==
+type sliceSystem struct {
+	names []string
+}
+
+func (s sliceSystem) Execute(frame *ecs.UpdateFrame) {}
+
+type panicSystem struct{}
+
+func (s *panicSystem) Execute(frame *ecs.UpdateFrame) {
+	panic("system failed")
+}
+
+type gameClock struct {
+	Ticks int
+}
+
+type clockWriter struct {
+	Clock *gameClock `ecs:"singleton,writes"`
+}
+
+func (s *clockWriter) Execute(frame *ecs.UpdateFrame) {
+	s.Clock.Ticks++
+}
+
+type clockReader struct {
+	Clock    ecs.Singleton[gameClock] `ecs:"reads"`
+	Observed []int
+}
+
+func (s *clockReader) Execute(frame *ecs.UpdateFrame) {
+	s.Observed = append(s.Observed, s.Clock.Get().Ticks)
+}
+
+type cycleA struct{}
+type cycleB struct{}
+
+type cycleFirst struct {
+	A *cycleA `ecs:"singleton,writes"`
+	B *cycleB `ecs:"singleton,reads"`
+}
+
+func (s *cycleFirst) Execute(frame *ecs.UpdateFrame) {}
+
+type cycleSecond struct {
+	A *cycleA `ecs:"singleton,reads"`
+	B *cycleB `ecs:"singleton,writes"`
+}
+
+func (s *cycleSecond) Execute(frame *ecs.UpdateFrame) {}
+
+type fighterGridSystem struct {
+	order *[]string
+}
+
+func (s *fighterGridSystem) Execute(frame *ecs.UpdateFrame) {
+	*s.order = append(*s.order, "grid")
+}
+
+type combatSystem struct {
+	order *[]string
+}
+
+func (s *combatSystem) Execute(frame *ecs.UpdateFrame) {
+	*s.order = append(*s.order, "combat")
+}
+
+func (s *combatSystem) Dependencies() []reflect.Type {
+	return []reflect.Type{reflect.TypeFor[*fighterGridSystem](), reflect.TypeFor[unregisteredSystem]()}
+}
+
+type unregisteredSystem struct{}
+
+type dependencyCycle struct{}
+
+func (s *dependencyCycle) Execute(frame *ecs.UpdateFrame) {}
+
+func (s *dependencyCycle) Dependencies() []reflect.Type {
+	return []reflect.Type{reflect.TypeFor[clockReader]()}
+}
+
+type dependentClockWriter struct {
+	Clock *gameClock `ecs:"singleton,writes"`
+}
+
+func (s *dependentClockWriter) Execute(frame *ecs.UpdateFrame) {}
+
+func (s *dependentClockWriter) Dependencies() []reflect.Type {
+	return []reflect.Type{reflect.TypeFor[dependencyCycle]()}
+}
+
+type pingEvent struct {
+	Value int
+}
+
+type pingEmitter struct {
+	next int
+}
+
+func (s *pingEmitter) Execute(frame *ecs.UpdateFrame) {
+	s.next++
+	frame.Emit(pingEvent{Value: s.next})
+	frame.Emit(&pingEvent{Value: -s.next})
+}
+
+type pingReader struct {
+	Pings    ecs.Events[pingEvent]
+	Received [][]int
+}
+
+func (s *pingReader) Execute(frame *ecs.UpdateFrame) {
+	var values []int
+	for ping := range s.Pings.Iter() {
+		values = append(values, ping.Value)
+	}
+	if len(values) != s.Pings.Len() {
+		panic("Len does not match Iter")
+	}
+	s.Received = append(s.Received, values)
+}
+
+type readOnlyProbe struct {
+	ReadOnly []bool
+	spawn    bool
+}
+
+func (s *readOnlyProbe) Execute(frame *ecs.UpdateFrame) {
+	s.ReadOnly = append(s.ReadOnly, frame.ReadOnly)
+	if s.spawn {
+		frame.Commands.Spawn(Position{})
+	}
+}
+
+type taskStarter struct {
+	body func(ctx ecs.TaskCtx)
+	task *ecs.Task
+}
+
+func (s *taskStarter) Execute(frame *ecs.UpdateFrame) {
+	if s.task == nil {
+		s.task = frame.StartTask(s.body)
+	}
+}
+
 func TestScheduler(t *testing.T) {
 	registry := ecs.NewComponentRegistry()
 	ecs.RegisterComponent[Position](registry)
@@ -183,4 +426,973 @@ func TestScheduler(t *testing.T) {
 			t.Error("expected spawned entity to be visible after command flush")
 		}
 	})
+
+	t.Run("register once", func(t *testing.T) {
+		storage := ecs.NewStorage(registry)
+		scheduler := ecs.NewScheduler(storage)
+
+		movement := &MovementSystem{}
+		spawner := &testSpawnSystem{}
+		scheduler.Register(movement)
+		scheduler.RegisterOnce(spawner)
+
+		if stats := scheduler.GetStats(); stats.SystemCount != 2 {
+			t.Fatalf("expected 2 systems before first frame, got %d", stats.SystemCount)
+		}
+
+		scheduler.Once(1.0)
+
+		if !spawner.executed {
+			t.Fatal("expected one-shot system to execute")
+		}
+
+		count := 0
+		for range movement.Entities.Iter() {
+			count++
+		}
+		if count != 1 {
+			t.Errorf("expected one-shot system commands to be flushed, got %d entities", count)
+		}
+
+		if stats := scheduler.GetStats(); stats.SystemCount != 1 {
+			t.Errorf("expected one-shot system to be unregistered, got %d systems", stats.SystemCount)
+		}
+
+		spawner.executed = false
+		scheduler.Once(1.0)
+		if spawner.executed {
+			t.Error("expected one-shot system not to execute again")
+		}
+		if movement.ExecuteCount != 2 {
+			t.Errorf("expected MovementSystem to keep running, got %d executions", movement.ExecuteCount)
+		}
+	})
+
+	t.Run("pause, step and advance", func(t *testing.T) {
+		storage := ecs.NewStorage(registry)
+		scheduler := ecs.NewScheduler(storage)
+
+		movement := &MovementSystem{}
+		exempt := &pauseExemptSystem{}
+		scheduler.Register(movement)
+		scheduler.Register(exempt)
+
+		scheduler.Pause()
+		if !scheduler.Paused() {
+			t.Fatal("expected scheduler to be paused")
+		}
+
+		scheduler.Once(1.0)
+		if movement.ExecuteCount != 0 {
+			t.Errorf("expected MovementSystem not to execute while paused, got %d", movement.ExecuteCount)
+		}
+		if exempt.ExecuteCount != 1 {
+			t.Errorf("expected pause exempt system to execute while paused, got %d", exempt.ExecuteCount)
+		}
+
+		scheduler.StepOnce()
+		scheduler.Once(1.0)
+		scheduler.Once(1.0)
+		if movement.ExecuteCount != 1 {
+			t.Errorf("expected a single step to execute once, got %d", movement.ExecuteCount)
+		}
+
+		scheduler.SetFastForwardRate(4)
+		scheduler.Advance(6.0)
+		scheduler.Once(1.0)
+		if movement.ExecuteCount != 5 {
+			t.Errorf("expected fast-forward to execute 4 frames, got %d total", movement.ExecuteCount-1)
+		}
+		if advanced, target := scheduler.AdvanceProgress(); advanced != 4.0 || target != 6.0 {
+			t.Errorf("expected progress 4/6, got %f/%f", advanced, target)
+		}
+		if exempt.ExecuteCount != 4 {
+			t.Errorf("expected pause exempt system to execute once per call, got %d", exempt.ExecuteCount)
+		}
+
+		scheduler.Once(1.0)
+		if movement.ExecuteCount != 7 {
+			t.Errorf("expected fast-forward to finish after 6 frames, got %d", movement.ExecuteCount-1)
+		}
+		if _, target := scheduler.AdvanceProgress(); target != 0 {
+			t.Errorf("expected fast-forward to be complete, got target %f", target)
+		}
+
+		scheduler.Resume()
+		scheduler.Once(1.0)
+		if movement.ExecuteCount != 8 {
+			t.Errorf("expected MovementSystem to execute after resume, got %d", movement.ExecuteCount)
+		}
+	})
+
+	t.Run("multiple storages", func(t *testing.T) {
+		sim := ecs.NewStorage(registry)
+		ui := ecs.NewStorage(registry)
+		scheduler := ecs.NewScheduler(sim)
+		scheduler.AddStorage("ui", ui)
+
+		if scheduler.Storage("") != sim || scheduler.Storage("ui") != ui {
+			t.Fatal("expected storages to be retrievable by name")
+		}
+		if names := scheduler.StorageNames(); len(names) != 1 || names[0] != "ui" {
+			t.Errorf("expected storage names [ui], got %v", names)
+		}
+
+		sim.Spawn(Position{X: 1, Y: 1})
+		sim.Spawn(Position{X: 2, Y: 2})
+		ui.Spawn(Position{X: 3, Y: 3})
+
+		system := &crossStorageSystem{}
+		scheduler.Register(system)
+
+		scheduler.Once(1.0)
+		if system.LabelCount != 0 {
+			t.Errorf("expected no labels before first flush, got %d", system.LabelCount)
+		}
+
+		scheduler.Once(1.0)
+		if system.LabelCount != 2 {
+			t.Errorf("expected 2 labels in ui storage, got %d", system.LabelCount)
+		}
+
+		if stats := sim.CollectStats(); stats.TotalEntityCount != 2 {
+			t.Errorf("expected sim storage to be untouched, got %d entities", stats.TotalEntityCount)
+		}
+		if stats := ui.CollectStats(); stats.TotalEntityCount != 5 {
+			t.Errorf("expected 5 entities in ui storage, got %d", stats.TotalEntityCount)
+		}
+	})
+
+	t.Run("unknown storage tag panics", func(t *testing.T) {
+		scheduler := ecs.NewScheduler(ecs.NewStorage(registry))
+
+		defer func() {
+			if r := recover(); r == nil {
+				t.Error("expected panic for unknown storage")
+			}
+		}()
+		scheduler.Register(&crossStorageSystem{})
+	})
+
+	t.Run("frame budget", func(t *testing.T) {
+		storage := ecs.NewStorage(registry)
+		scheduler := ecs.NewScheduler(storage)
+
+		scheduler.Register(&MovementSystem{})
+		scheduler.Register(&slowSystem{delay: 5 * time.Millisecond})
+
+		var overruns int
+		var worstName string
+		var overrunDuration time.Duration
+		scheduler.OnBudgetExceeded(func(frameDuration time.Duration, worst ecs.SystemStats) {
+			overruns++
+			worstName = worst.Name
+			overrunDuration = frameDuration
+		})
+
+		scheduler.Once(1.0)
+		if overruns != 0 {
+			t.Errorf("expected no overruns without a budget, got %d", overruns)
+		}
+
+		scheduler.SetFrameBudget(time.Millisecond)
+		scheduler.Once(1.0)
+		if overruns != 1 {
+			t.Fatalf("expected one overrun, got %d", overruns)
+		}
+		if worstName != "slowSystem" {
+			t.Errorf("expected slowSystem to be reported as worst, got %q", worstName)
+		}
+		if overrunDuration < 5*time.Millisecond {
+			t.Errorf("expected frame duration of at least 5ms, got %s", overrunDuration)
+		}
+
+		scheduler.SetFrameBudget(time.Second)
+		scheduler.Once(1.0)
+		if overruns != 1 {
+			t.Errorf("expected no overrun within budget, got %d", overruns)
+		}
+	})
+	t.Run("run system", func(t *testing.T) {
+		storage := ecs.NewStorage(registry)
+		scheduler := ecs.NewScheduler(storage)
+
+		storage.Spawn(Position{X: 0, Y: 0}, Velocity{DX: 1, DY: 2})
+		storage.Spawn(Health{Current: 10, Max: 10})
+
+		movement := &MovementSystem{}
+		health := &HealthSystem{}
+		spawn := &testSpawnSystem{}
+		scheduler.Register(movement)
+		scheduler.Register(health)
+		scheduler.Register(spawn)
+
+		scheduler.Pause()
+		scheduler.RunSystem(movement, 2.0)
+
+		if movement.ExecuteCount != 1 || health.ExecuteCount != 0 || spawn.executed {
+			t.Errorf("expected only movement to run, got movement=%d health=%d spawn=%v",
+				movement.ExecuteCount, health.ExecuteCount, spawn.executed)
+		}
+		for item := range movement.Entities.Iter() {
+			if item.Position.X != 2 || item.Position.Y != 4 {
+				t.Errorf("expected position (2, 4), got (%f, %f)", item.Position.X, item.Position.Y)
+			}
+		}
+		if stats, _ := scheduler.SystemStatsByName("MovementSystem"); stats.ExecutionCount != 1 {
+			t.Errorf("expected movement stats to record 1 execution, got %d", stats.ExecutionCount)
+		}
+
+		if !scheduler.RunSystemByName("testSpawnSystem", 1.0) {
+			t.Fatal("expected testSpawnSystem to be found by name")
+		}
+		if stats := storage.CollectStats(); stats.TotalEntityCount != 4 {
+			t.Errorf("expected spawn commands to be flushed, got %d entities", stats.TotalEntityCount)
+		}
+
+		if scheduler.RunSystemByName("MissingSystem", 1.0) {
+			t.Error("expected unknown system name to return false")
+		}
+
+		scheduler.Resume()
+		scheduler.RegisterOnce(&runOtherSystem{scheduler: scheduler, target: spawn})
+		scheduler.Once(1.0)
+		if stats, _ := scheduler.SystemStatsByName("testSpawnSystem"); stats.ExecutionCount != 3 {
+			t.Errorf("expected run requested during a frame to execute after it, got %d executions", stats.ExecutionCount)
+		}
+
+		defer func() {
+			if r := recover(); r == nil {
+				t.Error("expected panic for unregistered system")
+			}
+		}()
+		scheduler.RunSystem(&HealthSystem{}, 1.0)
+	})
+	t.Run("register from a system", func(t *testing.T) {
+		storage := ecs.NewStorage(registry)
+		storage.Spawn(Health{Current: 10, Max: 10})
+		scheduler := ecs.NewScheduler(storage)
+
+		health := &HealthSystem{}
+		effect := &testSpawnSystem{}
+		scheduler.Register(&phaseSystem{next: health})
+		scheduler.Register(&phaseSystem{next: effect, once: true})
+
+		scheduler.Once(1.0)
+		if health.ExecuteCount != 0 || effect.executed {
+			t.Error("expected systems registered during a frame to wait for the next frame")
+		}
+		if stats := scheduler.GetStats(); stats.SystemCount != 4 {
+			t.Errorf("expected the systems to be registered after the frame, got %d systems", stats.SystemCount)
+		}
+
+		scheduler.Once(1.0)
+		if health.ExecuteCount != 1 || !effect.executed {
+			t.Errorf("expected both systems to execute on the next frame, got health=%d effect=%v", health.ExecuteCount, effect.executed)
+		}
+
+		scheduler.Once(1.0)
+		if stats := scheduler.GetStats(); stats.SystemCount != 3 || health.ExecuteCount != 2 {
+			t.Errorf("expected the one-shot system to be removed, got %d systems", stats.SystemCount)
+		}
+	})
+	t.Run("remove and disable", func(t *testing.T) {
+		storage := ecs.NewStorage(registry)
+		storage.Spawn(Health{Current: 10, Max: 10})
+		scheduler := ecs.NewScheduler(storage)
+
+		health := &HealthSystem{}
+		movement := &MovementSystem{}
+		spawn := &testSpawnSystem{}
+		scheduler.Register(health)
+		scheduler.Register(movement)
+		scheduler.Register(spawn)
+		scheduler.Once(1.0)
+
+		scheduler.Enabled(health, false)
+		scheduler.Once(1.0)
+		stats, _ := scheduler.SystemStatsByName("HealthSystem")
+		if health.ExecuteCount != 1 || stats.ExecutionCount != 1 || !stats.Disabled {
+			t.Errorf("expected the disabled system to be skipped, got %d executions, disabled=%v", health.ExecuteCount, stats.Disabled)
+		}
+		scheduler.RunSystem(health, 1.0)
+		if !scheduler.EnabledByName("HealthSystem", true) || scheduler.EnabledByName("MissingSystem", true) {
+			t.Error("expected EnabledByName to report whether the system exists")
+		}
+		scheduler.Once(1.0)
+		if stats, _ := scheduler.SystemStatsByName("HealthSystem"); stats.ExecutionCount != 3 || stats.Disabled {
+			t.Errorf("expected the enabled system to keep its stats, got %d executions", stats.ExecutionCount)
+		}
+
+		if !scheduler.Remove(movement) || scheduler.Remove(movement) {
+			t.Error("expected Remove to report whether the system was registered")
+		}
+		if _, ok := scheduler.SystemStatsByName("MovementSystem"); ok {
+			t.Error("expected the removed system's stats to be dropped")
+		}
+
+		// Registered again after the remover, so it is removed before its turn comes
+		scheduler.Remove(spawn)
+		remover := &removeSystem{target: spawn}
+		scheduler.Register(remover)
+		scheduler.Register(spawn)
+		spawn.executed = false
+		scheduler.Once(1.0)
+		if !remover.removed || spawn.executed {
+			t.Error("expected the system removed during the frame not to execute")
+		}
+		names := []string{}
+		for _, system := range scheduler.GetStats().Systems {
+			names = append(names, system.Name)
+		}
+		if !slices.Equal(names, []string{"HealthSystem", "removeSystem"}) {
+			t.Errorf("expected the system to be removed after the frame, got %v", names)
+		}
+		if movement.ExecuteCount != 3 {
+			t.Errorf("expected the removed system to stop executing, got %d executions", movement.ExecuteCount)
+		}
+	})
+	t.Run("remove after a panic", func(t *testing.T) {
+		scheduler := ecs.NewScheduler(ecs.NewStorage(registry))
+		failing := &panicSystem{}
+		scheduler.Register(failing)
+		scheduler.Register(&HealthSystem{})
+
+		func() {
+			defer func() { recover() }()
+			scheduler.RunSystem(failing, 0)
+		}()
+
+		if !scheduler.Remove(failing) {
+			t.Fatal("expected the panicking system to be removed")
+		}
+		if stats := scheduler.GetStats(); stats.SystemCount != 1 || stats.Systems[0].Name != "HealthSystem" {
+			t.Errorf("expected the system to be removed right away, got %+v", stats.Systems)
+		}
+		scheduler.Once(0)
+	})
+
+	t.Run("replace", func(t *testing.T) {
+		storage := ecs.NewStorage(registry)
+		storage.Spawn(Health{Current: 10, Max: 10})
+		scheduler := ecs.NewScheduler(storage)
+
+		movement := &MovementSystem{}
+		health := &HealthSystem{}
+		scheduler.Register(movement)
+		scheduler.Register(health)
+		scheduler.Once(1.0)
+
+		names := func() []string {
+			names := []string{}
+			for _, system := range scheduler.GetStats().Systems {
+				names = append(names, system.Name)
+			}
+			return names
+		}
+
+		spawn := &testSpawnSystem{}
+		if !scheduler.Replace(movement, spawn) || scheduler.Replace(movement, spawn) {
+			t.Error("expected Replace to report whether the system was registered")
+		}
+		if !slices.Equal(names(), []string{"testSpawnSystem", "HealthSystem"}) {
+			t.Errorf("expected the replacement to take the replaced system's place, got %v", names())
+		}
+		if _, ok := scheduler.SystemStatsByName("MovementSystem"); ok {
+			t.Error("expected the replaced system's stats to be dropped")
+		}
+
+		// Replaced during the frame, after the replacing system has executed
+		replacement := &MovementSystem{}
+		replacer := &replaceSystem{target: health, replacement: replacement}
+		scheduler.Register(replacer)
+		scheduler.Once(1.0)
+		if health.ExecuteCount != 2 || replacement.ExecuteCount != 0 || !replacer.replaced {
+			t.Errorf("expected the replacement to wait for the next frame, got health=%d replacement=%d", health.ExecuteCount, replacement.ExecuteCount)
+		}
+		if !slices.Equal(names(), []string{"testSpawnSystem", "MovementSystem", "replaceSystem"}) {
+			t.Errorf("expected the replacement to be registered after the frame, got %v", names())
+		}
+
+		// Replaced during the frame, before its turn comes
+		replacer.target, replacer.replacement = spawn, health
+		scheduler.Remove(replacer)
+		scheduler.Register(replacer)
+		scheduler.Remove(spawn)
+		scheduler.Register(spawn)
+		spawn.executed = false
+		scheduler.Once(1.0)
+		if spawn.executed {
+			t.Error("expected the system replaced during the frame not to execute")
+		}
+		if !slices.Equal(names(), []string{"MovementSystem", "replaceSystem", "HealthSystem"}) {
+			t.Errorf("expected the replacement to take the replaced system's place, got %v", names())
+		}
+		scheduler.Once(1.0)
+		if health.ExecuteCount != 3 || replacement.ExecuteCount != 2 {
+			t.Errorf("expected both systems to execute, got health=%d replacement=%d", health.ExecuteCount, replacement.ExecuteCount)
+		}
+	})
+
+	t.Run("enable during a frame", func(t *testing.T) {
+		scheduler := ecs.NewScheduler(ecs.NewStorage(registry))
+		movement := &MovementSystem{}
+		health := &HealthSystem{}
+		replacement := &MovementSystem{}
+		toggled := map[string]bool{}
+		scheduler.Register(&callbackSystem{callback: func(frame *ecs.UpdateFrame) {
+			if len(toggled) > 0 {
+				return
+			}
+			frame.Scheduler().Register(health)
+			toggled["registered"] = frame.Scheduler().Enabled(health, false)
+			frame.Scheduler().Replace(movement, replacement)
+			toggled["replaced"] = frame.Scheduler().Enabled(movement, true)
+			toggled["replacement"] = frame.Scheduler().Enabled(replacement, false)
+		}})
+		scheduler.Register(movement)
+
+		scheduler.Once(1.0)
+		if !toggled["registered"] || toggled["replaced"] || !toggled["replacement"] {
+			t.Errorf("expected Enabled to find pending systems but not replaced ones, got %v", toggled)
+		}
+		if movement.ExecuteCount != 0 {
+			t.Errorf("expected the replaced system not to be re-enabled, got %d executions", movement.ExecuteCount)
+		}
+
+		scheduler.Once(1.0)
+		if health.ExecuteCount != 0 || replacement.ExecuteCount != 0 {
+			t.Errorf("expected the pending systems to start disabled, got health=%d replacement=%d", health.ExecuteCount, replacement.ExecuteCount)
+		}
+		for _, name := range []string{"HealthSystem", "MovementSystem"} {
+			if stats, ok := scheduler.SystemStatsByName(name); !ok || !stats.Disabled {
+				t.Errorf("expected %s to be registered disabled", name)
+			}
+		}
+
+		if !scheduler.Enabled(health, true) || !scheduler.EnabledByName("MovementSystem", true) {
+			t.Error("expected both systems to be enabled")
+		}
+		scheduler.Once(1.0)
+		if health.ExecuteCount != 1 || replacement.ExecuteCount != 1 {
+			t.Errorf("expected the enabled systems to execute, got health=%d replacement=%d", health.ExecuteCount, replacement.ExecuteCount)
+		}
+	})
+
+	t.Run("uncomparable value systems", func(t *testing.T) {
+		scheduler := ecs.NewScheduler(ecs.NewStorage(registry))
+		scheduler.Register(sliceSystem{names: []string{"a"}})
+
+		if scheduler.Remove(sliceSystem{names: []string{"a"}}) {
+			t.Error("expected an uncomparable system not to be found")
+		}
+		if scheduler.Enabled(sliceSystem{}, false) {
+			t.Error("expected Enabled to report an uncomparable system as not registered")
+		}
+		scheduler.Once(0)
+	})
+
+	t.Run("once timed", func(t *testing.T) {
+		storage := ecs.NewStorage(registry)
+		scheduler := ecs.NewScheduler(storage)
+
+		scheduler.Register(&MovementSystem{})
+		scheduler.Register(&slowSystem{delay: 2 * time.Millisecond})
+
+		timings := scheduler.OnceTimed(1.0)
+		if len(timings.Systems) != 2 {
+			t.Fatalf("expected 2 system timings, got %d", len(timings.Systems))
+		}
+		if timings.Systems[0].Name != "MovementSystem" || timings.Systems[1].Name != "slowSystem" {
+			t.Errorf("expected timings in execution order, got %q, %q", timings.Systems[0].Name, timings.Systems[1].Name)
+		}
+		if timings.Systems[1].Duration < 2*time.Millisecond {
+			t.Errorf("expected slowSystem to take at least 2ms, got %s", timings.Systems[1].Duration)
+		}
+
+		var sum time.Duration
+		for _, timing := range timings.Systems {
+			sum += timing.Duration
+		}
+		if timings.Total != sum+timings.Flush {
+			t.Errorf("expected total %s to equal systems plus flush %s", timings.Total, sum+timings.Flush)
+		}
+
+		if stats, _ := scheduler.SystemStatsByName("slowSystem"); stats.ExecutionCount != 1 {
+			t.Errorf("expected cumulative stats to be updated, got %d executions", stats.ExecutionCount)
+		}
+
+		scheduler.Pause()
+		scheduler.Advance(3.0)
+		if timings := scheduler.OnceTimed(1.0); len(timings.Systems) != 6 {
+			t.Errorf("expected each fast-forwarded frame to be reported, got %d timings", len(timings.Systems))
+		}
+	})
+	t.Run("singleton access ordering", func(t *testing.T) {
+		storage := ecs.NewStorage(registry)
+		scheduler := ecs.NewScheduler(storage)
+
+		reader := &clockReader{}
+		movement := &MovementSystem{}
+		scheduler.Register(reader)
+		scheduler.Register(movement)
+		scheduler.Register(&clockWriter{})
+
+		scheduler.Once(1.0)
+		scheduler.Once(1.0)
+		if len(reader.Observed) != 2 || reader.Observed[0] != 1 || reader.Observed[1] != 2 {
+			t.Errorf("expected the reader to observe each tick after the writer ran, got %v", reader.Observed)
+		}
+
+		timings := scheduler.OnceTimed(1.0)
+		var order []string
+		for _, timing := range timings.Systems {
+			order = append(order, timing.Name)
+		}
+		expected := []string{"MovementSystem", "clockWriter", "clockReader"}
+		if len(order) != 3 || order[0] != expected[0] || order[1] != expected[1] || order[2] != expected[2] {
+			t.Errorf("expected order %v, got %v", expected, order)
+		}
+	})
+
+	t.Run("singleton access cycle panics", func(t *testing.T) {
+		storage := ecs.NewStorage(registry)
+		scheduler := ecs.NewScheduler(storage)
+		scheduler.Register(&cycleFirst{})
+
+		defer func() {
+			r := recover()
+			message, _ := r.(string)
+			if !strings.Contains(message, "cycleFirst") || !strings.Contains(message, "cycleSecond") {
+				t.Errorf("expected cycle panic naming both systems, got %v", r)
+			}
+		}()
+		scheduler.Register(&cycleSecond{})
+	})
+
+	t.Run("dependencies", func(t *testing.T) {
+		storage := ecs.NewStorage(registry)
+		scheduler := ecs.NewScheduler(storage)
+
+		var order []string
+		scheduler.Register(&combatSystem{order: &order})
+		scheduler.Register(&MovementSystem{})
+		scheduler.Register(&fighterGridSystem{order: &order})
+		scheduler.Once(1.0)
+		if len(order) != 2 || order[0] != "grid" || order[1] != "combat" {
+			t.Errorf("expected the grid to run before combat, got %v", order)
+		}
+
+		var names []string
+		for _, stats := range scheduler.GetStats().Systems {
+			names = append(names, stats.Name)
+		}
+		expected := []string{"MovementSystem", "fighterGridSystem", "combatSystem"}
+		if !slices.Equal(names, expected) {
+			t.Errorf("expected registration order as a tiebreaker %v, got %v", expected, names)
+		}
+	})
+
+	t.Run("dependency cycle panics", func(t *testing.T) {
+		storage := ecs.NewStorage(registry)
+		scheduler := ecs.NewScheduler(storage)
+		scheduler.Register(&dependencyCycle{})
+		scheduler.Register(&clockReader{})
+
+		defer func() {
+			r := recover()
+			message, _ := r.(string)
+			expected := "dependentClockWriter -(ecs_test.gameClock)-> clockReader -> dependencyCycle -> dependentClockWriter"
+			if !strings.Contains(message, expected) {
+				t.Errorf("expected cycle panic %q, got %v", expected, r)
+			}
+		}()
+		scheduler.Register(&dependentClockWriter{})
+	})
+
+	t.Run("recovered cycle panic keeps the order", func(t *testing.T) {
+		storage := ecs.NewStorage(registry)
+		scheduler := ecs.NewScheduler(storage)
+
+		var order []string
+		scheduler.Register(&combatSystem{order: &order})
+		scheduler.Register(&fighterGridSystem{order: &order})
+		scheduler.Register(&cycleFirst{})
+		// Removing a system leaves spare capacity in the ordered systems for the next append
+		movement := &MovementSystem{}
+		scheduler.Register(movement)
+		scheduler.Remove(movement)
+
+		func() {
+			defer func() { recover() }()
+			scheduler.Register(&cycleSecond{})
+		}()
+
+		scheduler.Once(1.0)
+		if len(order) != 2 || order[0] != "grid" || order[1] != "combat" {
+			t.Errorf("expected the grid to still run before combat, got %v", order)
+		}
+		if stats := scheduler.GetStats(); stats.SystemCount != 3 {
+			t.Errorf("expected the rejected system not to be registered, got %d systems", stats.SystemCount)
+		}
+		if _, ok := scheduler.SystemStatsByName("cycleSecond"); ok {
+			t.Error("expected the rejected system not to take a name")
+		}
+	})
+	t.Run("events", func(t *testing.T) {
+		storage := ecs.NewStorage(registry)
+		scheduler := ecs.NewScheduler(storage)
+		reader := &pingReader{}
+		emitter := &pingEmitter{}
+		scheduler.Register(reader)
+		scheduler.Register(emitter)
+
+		scheduler.Once(1.0)
+		scheduler.Once(1.0)
+		scheduler.RunSystem(emitter, 1.0)
+		scheduler.Once(1.0)
+		scheduler.RunSystem(reader, 1.0)
+		expected := [][]int{nil, {1}, {2}, {3, 4}}
+		if fmt.Sprint(reader.Received) != fmt.Sprint(expected) {
+			t.Errorf("expected each frame to read the previous frame's events, got %v", reader.Received)
+		}
+
+		var unbound ecs.Events[pingEvent]
+		if unbound.Len() != 0 {
+			t.Errorf("expected no events on an uninitialized field, got %d", unbound.Len())
+		}
+
+		defer func() {
+			if recover() == nil {
+				t.Error("expected Emit to panic on a frame not created by a scheduler")
+			}
+		}()
+		(&ecs.UpdateFrame{}).Emit(pingEvent{})
+	})
+
+	t.Run("compaction policy", func(t *testing.T) {
+		storage := ecs.NewStorage(registry)
+		var fighters []ecs.EntityId
+		for i := range 8 {
+			fighters = append(fighters, storage.Spawn(Position{X: float32(i)}, Health{Current: 10}))
+		}
+		last := storage.CreateEntityRef(fighters[7])
+		recruit := storage.CreateEntityRef(storage.Spawn(Position{X: 100}))
+
+		changes := &changeSystem{queue: []func(cmd *ecs.Commands){
+			func(cmd *ecs.Commands) {},
+			func(cmd *ecs.Commands) {
+				for _, id := range fighters[:5] {
+					cmd.Delete(id)
+				}
+				cmd.AddComponent(recruit.Id, Health{Current: 10})
+			},
+		}}
+		// A cache keyed by entity id follows the compacted entities through EntityMoved
+		cached := map[ecs.EntityId]float32{}
+		for _, id := range fighters {
+			cached[id] = ecs.ReadComponent[Position](storage, id).X
+		}
+		storage.OnStructuralChange(func(change ecs.StructuralChange) {
+			switch change.Kind {
+			case ecs.EntityDeleted:
+				delete(cached, change.Id)
+			case ecs.EntityMoved:
+				if x, ok := cached[change.OldId]; ok {
+					delete(cached, change.OldId)
+					cached[change.Id] = x
+				}
+			}
+		})
+
+		scheduler := ecs.NewScheduler(storage)
+		scheduler.Register(changes)
+		scheduler.SetCompactionPolicy(0.25, 2)
+
+		scheduler.Once(1.0)
+		if stats := scheduler.GetStats().Compaction; stats.Runs != 0 {
+			t.Errorf("expected no compaction before the second frame, got %+v", stats)
+		}
+
+		scheduler.Once(1.0)
+		stats := scheduler.GetStats().Compaction
+
+		// The recruit reused a fighter's slot and left its own archetype empty
+		expected := ecs.CompactionStats{Runs: 1, Archetypes: 2, SlotsReclaimed: 5, LastReclaimed: 5}
+		if stats != expected {
+			t.Errorf("expected compaction stats %+v, got %+v", expected, stats)
+		}
+		if archetype := storage.ArchetypeOf(last.Id); archetype.Len() != 4 || last.Id.Index() >= 4 {
+			t.Errorf("expected %d live entities packed at the front, got index %d", archetype.Len(), last.Id.Index())
+		}
+		if position := ecs.ReadComponent[Position](storage, last.Id); position.X != 7 {
+			t.Errorf("expected refs to follow compacted entities, got %+v", position)
+		}
+		if len(cached) != 3 {
+			t.Errorf("expected the cache to hold the 3 remaining fighters, got %v", cached)
+		}
+		for id, x := range cached {
+			if position := ecs.ReadComponent[Position](storage, id); position == nil || position.X != x {
+				t.Errorf("expected moves to be reported for compacted entities, %v holds %+v instead of X %v", id, position, x)
+			}
+		}
+
+		scheduler.Once(1.0)
+		if len(changes.added) != 1 || changes.added[0] != recruit.Id {
+			t.Errorf("expected the added filter to follow the compacted recruit %v, got %v", recruit.Id, changes.added)
+		}
+	})
+
+	t.Run("read-only frames", func(t *testing.T) {
+		storage := ecs.NewStorage(registry)
+		scheduler := ecs.NewScheduler(storage)
+		probe := &readOnlyProbe{}
+		scheduler.Register(probe)
+
+		scheduler.Once(0)
+		scheduler.SetReadOnly(true)
+		scheduler.Once(0)
+		if len(probe.ReadOnly) != 2 || probe.ReadOnly[0] || !probe.ReadOnly[1] {
+			t.Errorf("expected frames to report the scheduler's read-only mode, got %v", probe.ReadOnly)
+		}
+	})
+
+	t.Run("tasks", func(t *testing.T) {
+		storage := ecs.NewStorage(registry)
+		scheduler := ecs.NewScheduler(storage)
+
+		frameNumber := 0
+		var log []string
+		starter := &taskStarter{body: func(ctx ecs.TaskCtx) {
+			log = append(log, fmt.Sprintf("start %d", frameNumber))
+			ctx.WaitFrames(2)
+			log = append(log, fmt.Sprintf("frames %d", frameNumber))
+			ctx.Wait(0.25)
+			log = append(log, fmt.Sprintf("wait %d", frameNumber))
+			ctx.Frame().Commands.Spawn(Position{})
+		}}
+		scheduler.Register(starter)
+
+		for frameNumber = 1; frameNumber <= 7; frameNumber++ {
+			scheduler.Once(0.1)
+			if frameNumber == 3 {
+				// Paused frames don't advance tasks
+				scheduler.Pause()
+				scheduler.Once(0.1)
+				scheduler.Resume()
+			}
+		}
+
+		expected := []string{"start 1", "frames 3", "wait 6"}
+		if !slices.Equal(log, expected) {
+			t.Errorf("expected task steps %v, got %v", expected, log)
+		}
+		if !starter.task.Done() {
+			t.Error("expected the task to be done")
+		}
+		if count := len(slices.Collect(ecs.NewView[struct{ *Position }](storage).Iter())); count != 1 {
+			t.Errorf("expected the task's spawn to be flushed, got %d entities", count)
+		}
+	})
+
+	t.Run("task cancellation", func(t *testing.T) {
+		scheduler := ecs.NewScheduler(ecs.NewStorage(registry))
+
+		steps := 0
+		cleanedUp := false
+		starter := &taskStarter{body: func(ctx ecs.TaskCtx) {
+			defer func() { cleanedUp = true }()
+			for {
+				steps++
+				ctx.WaitFrames(1)
+			}
+		}}
+		scheduler.Register(starter)
+
+		scheduler.Once(0)
+		scheduler.Once(0)
+		starter.task.Cancel()
+		scheduler.Once(0)
+
+		if steps != 2 || !cleanedUp || !starter.task.Done() {
+			t.Errorf("expected the task to stop after 2 steps and run its defers, got %d steps, cleaned up %v", steps, cleanedUp)
+		}
+	})
+
+	t.Run("cancel all tasks", func(t *testing.T) {
+		// goroutines waits for the task goroutines to exit after handing back control. Goroutines
+		// left by other tests may exit meanwhile, so it only waits for at most expected.
+		goroutines := func(expected int) int {
+			count := runtime.NumGoroutine()
+			for deadline := time.Now().Add(time.Second); count > expected && time.Now().Before(deadline); {
+				time.Sleep(time.Millisecond)
+				count = runtime.NumGoroutine()
+			}
+			return count
+		}
+		scheduler := ecs.NewScheduler(ecs.NewStorage(registry))
+		cleanedUp := 0
+		var starters []*taskStarter
+		for range 3 {
+			starter := &taskStarter{body: func(ctx ecs.TaskCtx) {
+				defer func() { cleanedUp++ }()
+				ctx.Wait(math.MaxFloat64)
+			}}
+			starters = append(starters, starter)
+			scheduler.Register(starter)
+		}
+		scheduler.Once(0)
+		running := runtime.NumGoroutine()
+
+		scheduler.CancelTasks()
+		if count := goroutines(running - 3); count > running-3 {
+			t.Errorf("expected the task goroutines to exit, got %d instead of %d", count, running-3)
+		}
+		if cleanedUp != 3 {
+			t.Errorf("expected every task to run its defers, got %d", cleanedUp)
+		}
+		for _, starter := range starters {
+			if !starter.task.Done() {
+				t.Error("expected cancelled tasks to be done")
+			}
+		}
+	})
+
+	t.Run("task panics reach the scheduler", func(t *testing.T) {
+		scheduler := ecs.NewScheduler(ecs.NewStorage(registry))
+		scheduler.Register(&taskStarter{body: func(ctx ecs.TaskCtx) {
+			ctx.WaitFrames(1)
+			panic("task failed")
+		}})
+
+		scheduler.Once(0)
+		defer func() {
+			if r := recover(); r != "task failed" {
+				t.Errorf("expected the task's panic, got %v", r)
+			}
+		}()
+		scheduler.Once(0)
+	})
+
+	t.Run("middleware", func(t *testing.T) {
+		scheduler := ecs.NewScheduler(ecs.NewStorage(registry))
+		movement := &MovementSystem{}
+		health := &HealthSystem{}
+		scheduler.Register(movement)
+		scheduler.Register(health)
+
+		var calls []string
+		trace := func(name string) ecs.Middleware {
+			return func(system ecs.System, next func()) {
+				calls = append(calls, name+" before "+fmt.Sprintf("%T", system))
+				next()
+				calls = append(calls, name+" after")
+			}
+		}
+		scheduler.Use(trace("outer"))
+		scheduler.Use(trace("inner"))
+		scheduler.Use(func(system ecs.System, next func()) {
+			if _, skip := system.(*HealthSystem); !skip {
+				next()
+			}
+		})
+
+		scheduler.Once(0)
+
+		expected := []string{
+			"outer before *ecs_test.MovementSystem", "inner before *ecs_test.MovementSystem", "inner after", "outer after",
+			"outer before *ecs_test.HealthSystem", "inner before *ecs_test.HealthSystem", "inner after", "outer after",
+		}
+		if !slices.Equal(calls, expected) {
+			t.Errorf("expected middlewares to wrap systems outermost-first, got %v", calls)
+		}
+		if movement.ExecuteCount != 1 || health.ExecuteCount != 0 {
+			t.Errorf("expected only the movement system to execute, got %d and %d", movement.ExecuteCount, health.ExecuteCount)
+		}
+
+		movementStats, _ := scheduler.SystemStatsByName("MovementSystem")
+		healthStats, _ := scheduler.SystemStatsByName("HealthSystem")
+		if movementStats.ExecutionCount != 1 || healthStats.ExecutionCount != 0 {
+			t.Errorf("expected a skipped system not to count as executed, got %d and %d", movementStats.ExecutionCount, healthStats.ExecutionCount)
+		}
+
+		calls = nil
+		scheduler.RunSystem(movement, 0)
+		if len(calls) != 4 {
+			t.Errorf("expected RunSystem to go through the middlewares, got %v", calls)
+		}
+	})
+
+	t.Run("panicking frame", func(t *testing.T) {
+		scheduler := ecs.NewScheduler(ecs.NewStorage(registry))
+		failing := &panicSystem{}
+		scheduler.Register(failing)
+
+		once := func() (recovered any) {
+			defer func() { recovered = recover() }()
+			scheduler.Once(0)
+			return nil
+		}
+		if recovered := once(); recovered != "system failed" {
+			t.Fatalf("expected the system's panic to reach the caller, got %v", recovered)
+		}
+
+		health := &HealthSystem{}
+		scheduler.Register(health)
+		if stats := scheduler.GetStats(); stats.SystemCount != 2 {
+			t.Errorf("expected Register to apply after a recovered panic, got %d systems", stats.SystemCount)
+		}
+		scheduler.RunSystem(health, 0)
+		if health.ExecuteCount != 1 {
+			t.Errorf("expected RunSystem to execute after a recovered panic, got %d executions", health.ExecuteCount)
+		}
+	})
+
+	t.Run("recovering middleware", func(t *testing.T) {
+		scheduler := ecs.NewScheduler(ecs.NewStorage(registry))
+		var recovered any
+		scheduler.Use(func(system ecs.System, next func()) {
+			defer func() {
+				if r := recover(); r != nil {
+					recovered = r
+				}
+			}()
+			next()
+		})
+		health := &HealthSystem{}
+		scheduler.Register(&panicSystem{})
+		scheduler.Register(health)
+		scheduler.Once(0)
+
+		if recovered != "system failed" || health.ExecuteCount != 1 {
+			t.Errorf("expected the panic to be recovered and the frame to go on, got %v and %d executions", recovered, health.ExecuteCount)
+		}
+	})
+
+	t.Run("gated once system", func(t *testing.T) {
+		scheduler := ecs.NewScheduler(ecs.NewStorage(registry))
+		enabled := false
+		scheduler.Use(func(system ecs.System, next func()) {
+			if enabled {
+				next()
+			}
+		})
+		health := &HealthSystem{}
+		scheduler.RegisterOnce(health)
+
+		scheduler.Once(0)
+		if stats := scheduler.GetStats(); stats.SystemCount != 1 {
+			t.Errorf("expected a skipped once system to stay registered, got %d systems", stats.SystemCount)
+		}
+
+		enabled = true
+		scheduler.Once(0)
+		scheduler.Once(0)
+		if health.ExecuteCount != 1 {
+			t.Errorf("expected the once system to execute once it was allowed to, got %d executions", health.ExecuteCount)
+		}
+		if stats := scheduler.GetStats(); stats.SystemCount != 0 {
+			t.Errorf("expected the once system to be unregistered after executing, got %d systems", stats.SystemCount)
+		}
+	})
 }
@@ -14,10 +14,19 @@ type Singleton[T any] struct {
 	componentType reflect.Type
 }
 
-// NewSingleton creates a new Singleton accessor for the given storage.
+// singletonField is implemented by every Singleton[T], so the scheduler can recognize
+// Singleton system fields and the type they access
+type singletonField interface {
+	singletonType() reflect.Type
+}
+
+// NewSingleton creates the singleton value in storage and returns an accessor for it.
 // If initializer is provided and the singleton doesn't exist in storage,
 // it will be created with the initializer value. Otherwise, a zero value is used.
+// If the singleton already exists the initializer is ignored.
 // This guarantees the singleton exists in storage after the call.
+//
+// To obtain a handle to a singleton that has already been created, use BindSingleton.
 func NewSingleton[T any](storage *Storage, initializer ...T) *Singleton[T] {
 	var zero T
 	componentType := reflect.TypeOf(zero)
@@ -41,27 +50,90 @@ func NewSingleton[T any](storage *Storage, initializer ...T) *Singleton[T] {
 	}
 }
 
+// TryNewSingleton adds value as the singleton of type T unless one already exists, and
+// returns a pointer to the singleton in storage. It returns false, leaving the existing value
+// untouched, if the singleton already existed, so setup code can detect being run twice
+// instead of silently replacing state as AddSingleton would.
+func TryNewSingleton[T any](storage *Storage, value T) (*T, bool) {
+	componentType := reflect.TypeFor[T]()
+	if entry := storage.getSingletonEntry(componentType); entry != nil {
+		return (*T)(entry.dataPtr), false
+	}
+	return (*T)(storage.AddSingleton(value)), true
+}
+
+// GetSingletonT returns a pointer to the singleton of type T, or false if it doesn't exist. It
+// is a typed form of Storage.GetSingleton for concrete singleton types; interfaces registered
+// with AddSingletonAs are read with ReadSingleton instead.
+//
+//	if perf, ok := ecs.GetSingletonT[PerformanceMetrics](storage); ok {
+//		perf.Frames++
+//	}
+func GetSingletonT[T any](storage *Storage) (*T, bool) {
+	singleton, ok := storage.GetSingleton(reflect.TypeFor[T]()).(*T)
+	return singleton, ok
+}
+
+// BindSingleton returns a handle to a singleton that already exists in storage, for use
+// outside of systems (where Singleton fields are bound automatically by the Scheduler).
+// Unlike NewSingleton it never creates the value, and panics if the singleton has not
+// been added to storage.
+func BindSingleton[T any](storage *Storage) *Singleton[T] {
+	componentType := reflect.TypeFor[T]()
+
+	entry := storage.getSingletonEntry(componentType)
+	if entry == nil {
+		panic("cannot bind singleton " + componentType.String() + ": singleton does not exist in storage")
+	}
+
+	return &Singleton[T]{
+		storage:       storage,
+		componentPtr:  entry.dataPtr,
+		componentType: componentType,
+	}
+}
+
 // Init initializes the Singleton with a storage reference.
 // This is called automatically by the Scheduler during system registration.
-// It ensures the singleton exists in storage, creating it with a zero value if needed.
+// It does not create the singleton; if it doesn't exist yet it is looked up again on access.
 func (s *Singleton[T]) Init(storage *Storage) {
-	var zero T
 	s.storage = storage
-	s.componentType = reflect.TypeOf(zero)
+	s.componentType = reflect.TypeFor[T]()
+	s.componentPtr = nil
 
-	// Ensure singleton exists in storage
-	entry := storage.getSingletonEntry(s.componentType)
-	if entry == nil {
-		// Create singleton with zero value
-		storage.AddSingleton(zero)
-		entry = storage.getSingletonEntry(s.componentType)
+	if entry := storage.getSingletonEntry(s.componentType); entry != nil {
+		s.componentPtr = entry.dataPtr
 	}
+}
 
-	s.componentPtr = entry.dataPtr
+// singletonType returns T, see singletonField
+func (s *Singleton[T]) singletonType() reflect.Type {
+	return reflect.TypeFor[T]()
 }
 
-// Get returns a pointer to the singleton component.
-// The singleton is guaranteed to exist (it's created automatically if needed).
+// Get returns a pointer to the singleton component, or nil if the singleton has not been
+// created. Use this when a missing singleton indicates missing setup that should be detected.
 func (s *Singleton[T]) Get() *T {
+	if s.componentPtr == nil && s.storage != nil {
+		if entry := s.storage.getSingletonEntry(s.componentType); entry != nil {
+			s.componentPtr = entry.dataPtr
+		}
+	}
+	return (*T)(s.componentPtr)
+}
+
+// GetOrCreate returns a pointer to the singleton component, creating it with a zero value
+// on first access if it doesn't exist. Use this for optional globals with a sensible zero
+// start, such as counters and accumulators.
+func (s *Singleton[T]) GetOrCreate() *T {
+	if ptr := s.Get(); ptr != nil {
+		return ptr
+	}
+	if s.storage == nil {
+		panic("singleton is not bound to a storage")
+	}
+
+	var zero T
+	s.componentPtr = s.storage.AddSingleton(zero)
 	return (*T)(s.componentPtr)
 }
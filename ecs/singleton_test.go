@@ -0,0 +1,237 @@
+package ecs_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/plus3/ooftn/ecs"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBindSingleton(t *testing.T) {
+	t.Run("binds an existing singleton", func(t *testing.T) {
+		storage := ecs.NewStorage(ecs.NewComponentRegistry())
+		created := ecs.NewSingleton[GameConfig](storage, GameConfig{MaxPlayers: 4})
+
+		bound := ecs.BindSingleton[GameConfig](storage)
+		assert.Equal(t, 4, bound.Get().MaxPlayers)
+
+		bound.Get().MaxPlayers = 8
+		assert.Equal(t, 8, created.Get().MaxPlayers)
+		assert.Same(t, created.Get(), bound.Get())
+	})
+
+	t.Run("binds a singleton added directly", func(t *testing.T) {
+		storage := ecs.NewStorage(ecs.NewComponentRegistry())
+		storage.AddSingleton(GameScore{Points: 10})
+
+		assert.Equal(t, 10, ecs.BindSingleton[GameScore](storage).Get().Points)
+	})
+
+	t.Run("panics when the singleton does not exist", func(t *testing.T) {
+		storage := ecs.NewStorage(ecs.NewComponentRegistry())
+
+		assert.PanicsWithValue(t, "cannot bind singleton ecs_test.GameScore: singleton does not exist in storage", func() {
+			ecs.BindSingleton[GameScore](storage)
+		})
+
+		var score *GameScore
+		assert.False(t, storage.ReadSingleton(&score), "binding must not create the singleton")
+	})
+
+	t.Run("new singleton does not overwrite an existing value", func(t *testing.T) {
+		storage := ecs.NewStorage(ecs.NewComponentRegistry())
+		ecs.NewSingleton[GameScore](storage, GameScore{Points: 1})
+		second := ecs.NewSingleton[GameScore](storage, GameScore{Points: 2})
+
+		assert.Equal(t, 1, second.Get().Points)
+	})
+}
+
+func TestTryNewSingleton(t *testing.T) {
+	storage := ecs.NewStorage(ecs.NewComponentRegistry())
+
+	score, created := ecs.TryNewSingleton(storage, GameScore{Points: 1})
+	assert.True(t, created)
+	assert.Equal(t, 1, score.Points)
+	score.Points = 5
+
+	again, created := ecs.TryNewSingleton(storage, GameScore{Points: 2})
+	assert.False(t, created)
+	assert.Same(t, score, again)
+	assert.Equal(t, 5, ecs.BindSingleton[GameScore](storage).Get().Points, "the existing value is kept")
+}
+
+func TestGetSingletonT(t *testing.T) {
+	storage := ecs.NewStorage(ecs.NewComponentRegistry())
+
+	missing, ok := ecs.GetSingletonT[GameScore](storage)
+	assert.False(t, ok)
+	assert.Nil(t, missing)
+
+	score := ecs.NewSingleton(storage, GameScore{Points: 3}).Get()
+	found, ok := ecs.GetSingletonT[GameScore](storage)
+	assert.True(t, ok)
+	assert.Same(t, score, found)
+}
+
+type singletonSystem struct {
+	Score  ecs.Singleton[GameScore]
+	Config ecs.Singleton[GameConfig]
+
+	sawScore  bool
+	sawConfig bool
+}
+
+func (s *singletonSystem) Execute(frame *ecs.UpdateFrame) {
+	s.sawConfig = s.Config.Get() != nil
+	s.Score.GetOrCreate().Points++
+	s.sawScore = s.Score.Get() != nil
+}
+
+func TestSingletonGetOrCreate(t *testing.T) {
+	storage := ecs.NewStorage(ecs.NewComponentRegistry())
+	scheduler := ecs.NewScheduler(storage)
+	system := &singletonSystem{}
+	scheduler.Register(system)
+
+	t.Run("get is strict", func(t *testing.T) {
+		scheduler.Once(1.0)
+		assert.False(t, system.sawConfig)
+
+		var config *GameConfig
+		assert.False(t, storage.ReadSingleton(&config), "registering a system must not create singletons")
+	})
+
+	t.Run("get or create starts from zero", func(t *testing.T) {
+		assert.True(t, system.sawScore)
+		scheduler.Once(1.0)
+		assert.Equal(t, 2, ecs.BindSingleton[GameScore](storage).Get().Points)
+	})
+
+	t.Run("get sees singletons created later", func(t *testing.T) {
+		ecs.NewSingleton[GameConfig](storage, GameConfig{MaxPlayers: 2})
+		scheduler.Once(1.0)
+		assert.True(t, system.sawConfig)
+		assert.Equal(t, 2, system.Config.Get().MaxPlayers)
+	})
+
+	t.Run("unbound singleton", func(t *testing.T) {
+		var unbound ecs.Singleton[GameScore]
+		assert.Nil(t, unbound.Get())
+		assert.Panics(t, func() { unbound.GetOrCreate() })
+	})
+}
+
+type pointerSingletonSystem struct {
+	Config *GameConfig
+	Score  *GameScore `ecs:"singleton"`
+	Custom *Position
+}
+
+func (s *pointerSingletonSystem) Execute(frame *ecs.UpdateFrame) {
+	s.Score.Points += s.Config.MaxPlayers
+}
+
+func TestSingletonPointerInjection(t *testing.T) {
+	t.Run("populates existing and tagged singletons", func(t *testing.T) {
+		storage := ecs.NewStorage(ecs.NewComponentRegistry())
+		ecs.NewSingleton[GameConfig](storage, GameConfig{MaxPlayers: 3})
+
+		scheduler := ecs.NewScheduler(storage)
+		system := &pointerSingletonSystem{}
+		scheduler.Register(system)
+
+		assert.Same(t, ecs.BindSingleton[GameConfig](storage).Get(), system.Config)
+		assert.Nil(t, system.Custom, "untagged fields without a singleton are left alone")
+
+		scheduler.Once(1.0)
+		scheduler.Once(1.0)
+		assert.Equal(t, 6, ecs.BindSingleton[GameScore](storage).Get().Points)
+	})
+
+	t.Run("existing field values are kept", func(t *testing.T) {
+		storage := ecs.NewStorage(ecs.NewComponentRegistry())
+		ecs.NewSingleton[GameConfig](storage, GameConfig{MaxPlayers: 3})
+
+		own := &GameConfig{MaxPlayers: 1}
+		system := &pointerSingletonSystem{Config: own}
+		ecs.NewScheduler(storage).Register(system)
+		assert.Same(t, own, system.Config)
+	})
+
+	t.Run("untagged missing singleton stays nil", func(t *testing.T) {
+		storage := ecs.NewStorage(ecs.NewComponentRegistry())
+		system := &pointerSingletonSystem{}
+		ecs.NewScheduler(storage).Register(system)
+
+		assert.Nil(t, system.Config)
+		assert.NotNil(t, system.Score)
+	})
+}
+
+type renderer interface {
+	Backend() string
+}
+
+type glRenderer struct{ Frames int }
+
+func (r *glRenderer) Backend() string { return "gl" }
+
+type vulkanRenderer struct{}
+
+func (r *vulkanRenderer) Backend() string { return "vulkan" }
+
+func TestSingletonInterface(t *testing.T) {
+	rendererType := reflect.TypeFor[renderer]()
+
+	t.Run("resolves the concrete singleton", func(t *testing.T) {
+		storage := ecs.NewStorage(ecs.NewComponentRegistry())
+		storage.AddSingletonAs(rendererType, glRenderer{Frames: 2})
+
+		r, ok := storage.GetSingleton(rendererType).(renderer)
+		assert.True(t, ok)
+		assert.Equal(t, "gl", r.Backend())
+		assert.Same(t, storage.GetSingleton(reflect.TypeFor[glRenderer]()), r)
+
+		var read renderer
+		assert.True(t, storage.ReadSingleton(&read))
+		assert.Same(t, r, read)
+		assert.Equal(t, 2, ecs.BindSingleton[glRenderer](storage).Get().Frames)
+	})
+
+	t.Run("last registration wins", func(t *testing.T) {
+		storage := ecs.NewStorage(ecs.NewComponentRegistry())
+		storage.AddSingletonAs(rendererType, glRenderer{})
+		storage.AddSingletonAs(rendererType, vulkanRenderer{})
+
+		var read renderer
+		assert.True(t, storage.ReadSingleton(&read))
+		assert.Equal(t, "vulkan", read.Backend())
+		assert.NotNil(t, storage.GetSingleton(reflect.TypeFor[glRenderer]()))
+	})
+
+	t.Run("replacing the concrete singleton", func(t *testing.T) {
+		storage := ecs.NewStorage(ecs.NewComponentRegistry())
+		storage.AddSingletonAs(rendererType, glRenderer{Frames: 1})
+		storage.AddSingleton(glRenderer{Frames: 5})
+
+		assert.Equal(t, 5, storage.GetSingleton(rendererType).(*glRenderer).Frames)
+	})
+
+	t.Run("unregistered interface", func(t *testing.T) {
+		storage := ecs.NewStorage(ecs.NewComponentRegistry())
+		storage.AddSingleton(glRenderer{})
+
+		var read renderer
+		assert.False(t, storage.ReadSingleton(&read))
+		assert.Nil(t, read)
+		assert.Nil(t, storage.GetSingleton(rendererType))
+	})
+
+	t.Run("panics on invalid registrations", func(t *testing.T) {
+		storage := ecs.NewStorage(ecs.NewComponentRegistry())
+		assert.Panics(t, func() { storage.AddSingletonAs(reflect.TypeFor[glRenderer](), glRenderer{}) })
+		assert.Panics(t, func() { storage.AddSingletonAs(rendererType, GameScore{}) })
+	})
+}
@@ -0,0 +1,95 @@
+package ecs
+
+import "slices"
+
+// slotAllocator hands out the slot indices of a component storage according to its slot
+// policy. Every storage of an archetype is configured alike, so they all allocate the same
+// index for an entity.
+type slotAllocator struct {
+	freeSlots []int
+	nextIndex int
+	policy    SlotPolicy
+	threshold int
+	// draining is set while a batch of free slots that reached the threshold is being reused
+	draining bool
+}
+
+// allocate returns the index for a new component, either a free slot or nextIndex
+func (s *slotAllocator) allocate() int {
+	if !s.reusesFreeSlot() {
+		index := s.nextIndex
+		s.nextIndex++
+		return index
+	}
+
+	var index int
+	if s.policy == SlotReuseFIFO || s.policy == SlotReuseLowest {
+		index = s.freeSlots[0]
+		s.freeSlots = s.freeSlots[1:]
+	} else {
+		index = s.freeSlots[len(s.freeSlots)-1]
+		s.freeSlots = s.freeSlots[:len(s.freeSlots)-1]
+	}
+	s.draining = len(s.freeSlots) > 0
+	return index
+}
+
+// dense reports whether every slot below nextIndex is occupied
+func (s *slotAllocator) dense() bool {
+	return len(s.freeSlots) == 0
+}
+
+// freeSlotCount returns the number of free slots below nextIndex
+func (s *slotAllocator) freeSlotCount() int {
+	return len(s.freeSlots)
+}
+
+// cloneSlots returns a copy of the allocator that hands out the same indices
+func (s *slotAllocator) cloneSlots() slotAllocator {
+	clone := *s
+	clone.freeSlots = slices.Clone(s.freeSlots)
+	return clone
+}
+
+// reusesFreeSlot reports whether the next append takes a free slot instead of growing storage
+func (s *slotAllocator) reusesFreeSlot() bool {
+	if len(s.freeSlots) == 0 || s.policy == SlotMonotonic {
+		return false
+	}
+	return s.draining || len(s.freeSlots) >= s.threshold
+}
+
+// freeSlot adds index to the free list. SlotReuseLowest keeps the list sorted and gives up
+// trailing free slots, so iteration stops at the last live slot.
+func (s *slotAllocator) freeSlot(index int) {
+	if s.policy != SlotReuseLowest {
+		s.freeSlots = append(s.freeSlots, index)
+		return
+	}
+
+	at, _ := slices.BinarySearch(s.freeSlots, index)
+	s.freeSlots = slices.Insert(s.freeSlots, at, index)
+	for last := len(s.freeSlots) - 1; last >= 0 && s.freeSlots[last] == s.nextIndex-1; last-- {
+		s.freeSlots = s.freeSlots[:last]
+		s.nextIndex--
+	}
+	if len(s.freeSlots) == 0 {
+		s.draining = false
+	}
+}
+
+// growthFor returns how many slots past nextIndex are needed to append count more components.
+// Free slots are reused first and count towards the total, unless they are not being reused.
+func (s *slotAllocator) growthFor(count int) int {
+	if s.reusesFreeSlot() {
+		return count - len(s.freeSlots)
+	}
+	return count
+}
+
+// resetSlots drops the free list after compaction packed n components at the front
+func (s *slotAllocator) resetSlots(n int) {
+	s.freeSlots = nil
+	s.draining = false
+	s.nextIndex = n
+}
@@ -0,0 +1,85 @@
+package ecs
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// Snapshot is a copy of the entities and singletons of a storage, taken with Storage.Snapshot
+// and restored with Storage.Restore, e.g. for save games or to rewind a simulation. It holds
+// the same data as SaveWorld writes, so the same rules apply: only exported fields are kept,
+// and *EntityRef fields are rebound to the restored entities. A Snapshot encodes to JSON, in
+// the format of SaveWorld, and to gob, so it can be written to disk.
+type Snapshot struct {
+	world *savedWorld
+	// singletonTypes lists the singleton types of the snapshotted storage, so Restore can
+	// create them in a fresh storage. It is not encoded.
+	singletonTypes map[string]reflect.Type
+}
+
+// Snapshot copies every entity and singleton in storage. Later changes to storage don't
+// affect the snapshot.
+func (s *Storage) Snapshot() (*Snapshot, error) {
+	world, err := s.saveWorld()
+	if err != nil {
+		return nil, err
+	}
+
+	singletonTypes := make(map[string]reflect.Type, len(s.singletons))
+	for t := range s.singletons {
+		singletonTypes[TypeName(t)] = t
+	}
+	return &Snapshot{world: world, singletonTypes: singletonTypes}, nil
+}
+
+// Restore spawns the entities of snapshot into storage and restores its singletons, as
+// LoadWorld does: entities receive new ids, refs between them are remapped, and existing
+// singletons are updated in place. To get back the snapshotted world, restore into a new
+// storage using the same registry. Singletons missing from storage are created when the
+// snapshot was taken in this process; a decoded snapshot can only restore singletons that
+// already exist in storage. On error storage is left untouched.
+func (s *Storage) Restore(snapshot *Snapshot) error {
+	if snapshot.world == nil {
+		return errors.New("empty snapshot")
+	}
+	return s.loadWorld(snapshot.world, snapshot.singletonTypes)
+}
+
+// Len returns the number of entities in the snapshot
+func (s *Snapshot) Len() int {
+	if s.world == nil {
+		return 0
+	}
+	return len(s.world.Entities)
+}
+
+// MarshalJSON encodes the snapshot in the format written by SaveWorld
+func (s *Snapshot) MarshalJSON() ([]byte, error) {
+	if s.world == nil {
+		return nil, errors.New("empty snapshot")
+	}
+	return json.Marshal(s.world)
+}
+
+// UnmarshalJSON decodes a snapshot encoded by MarshalJSON or written by SaveWorld
+func (s *Snapshot) UnmarshalJSON(data []byte) error {
+	var world savedWorld
+	if err := json.Unmarshal(data, &world); err != nil {
+		return fmt.Errorf("invalid snapshot: %w", err)
+	}
+	s.world = &world
+	s.singletonTypes = nil
+	return nil
+}
+
+// GobEncode encodes the snapshot for encoding/gob
+func (s *Snapshot) GobEncode() ([]byte, error) {
+	return s.MarshalJSON()
+}
+
+// GobDecode decodes a snapshot encoded by GobEncode
+func (s *Snapshot) GobDecode(data []byte) error {
+	return s.UnmarshalJSON(data)
+}
@@ -0,0 +1,194 @@
+package ecs
+
+import (
+	"cmp"
+	"encoding/binary"
+	"hash"
+	"hash/fnv"
+	"math"
+	"reflect"
+	"slices"
+)
+
+var entityIdType = reflect.TypeFor[EntityId]()
+
+// StateHash returns a hash of every entity's components, for detecting divergence between
+// worlds that should be identical, such as the peers of a lockstep simulation, or for golden
+// tests. Two storages holding equal components in entities spawned in the same relative order
+// hash alike, even if their ids differ, e.g. because one of them spawned and deleted more
+// entities along the way.
+//
+// Entities are visited in birth order, and each contributes its component TypeNames, its
+// disabled state and its component values, including unexported fields. Values are hashed
+// structurally: pointers and interfaces are followed, maps are hashed independently of their
+// iteration order, and functions and channels only contribute whether they are nil. EntityId,
+// EntityRef and *EntityRef values contribute the position of the entity they refer to in birth
+// order rather than its id, since LoadWorld reassigns ids, so a world restored with LoadWorld
+// hashes like the saved one. Singletons are not included. The hash is not
+// stable across versions of this package.
+func (s *Storage) StateHash() uint64 {
+	type entity struct {
+		birth     uint64
+		archetype *Archetype
+		index     int
+	}
+
+	var entities []entity
+	for _, archetype := range s.archetypes {
+		for id := range archetype.Iter() {
+			index := int(id.Index())
+			entities = append(entities, entity{archetype.birth(index), archetype, index})
+		}
+	}
+	slices.SortFunc(entities, func(a, b entity) int { return cmp.Compare(a.birth, b.birth) })
+
+	h := &stateHasher{
+		storage: s,
+		hash:    fnv.New64a(),
+		ranks:   make(map[uint64]uint64, len(entities)),
+		visited: make(map[uintptr]bool),
+	}
+	for i, e := range entities {
+		h.ranks[e.birth] = uint64(i + 1)
+	}
+
+	for _, e := range entities {
+		h.writeUint(uint64(len(e.archetype.types)))
+		h.writeBool(e.archetype.isDisabled(e.index))
+		for i, t := range e.archetype.types {
+			h.writeString(TypeName(t))
+			h.value(reflect.ValueOf(e.archetype.storages[i].Get(e.index)).Elem())
+		}
+	}
+	return h.hash.Sum64()
+}
+
+// stateHasher feeds component values into a hash for StateHash
+type stateHasher struct {
+	storage *Storage
+	hash    hash.Hash64
+	ranks   map[uint64]uint64 // birth order to position in the hashed order, starting at 1
+	visited map[uintptr]bool  // pointers being hashed, to stop at cycles
+	buf     [8]byte
+}
+
+func (h *stateHasher) writeUint(v uint64) {
+	binary.LittleEndian.PutUint64(h.buf[:], v)
+	h.hash.Write(h.buf[:])
+}
+
+func (h *stateHasher) writeBool(v bool) {
+	if v {
+		h.writeUint(1)
+	} else {
+		h.writeUint(0)
+	}
+}
+
+func (h *stateHasher) writeString(v string) {
+	h.writeUint(uint64(len(v)))
+	h.hash.Write([]byte(v))
+}
+
+// writeEntity hashes a reference to an entity as its position in birth order, or 0 if it doesn't exist
+func (h *stateHasher) writeEntity(id EntityId) {
+	h.writeUint(h.ranks[h.storage.BirthOrder(id)])
+}
+
+func (h *stateHasher) value(v reflect.Value) {
+	switch v.Type() {
+	case entityIdType:
+		h.writeEntity(EntityId(v.Uint()))
+		return
+	case entityRefType:
+		h.writeEntity(EntityId(v.FieldByName("Id").Uint()))
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		h.writeBool(v.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		h.writeUint(uint64(v.Int()))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		h.writeUint(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		h.writeUint(math.Float64bits(v.Float()))
+	case reflect.Complex64, reflect.Complex128:
+		h.writeUint(math.Float64bits(real(v.Complex())))
+		h.writeUint(math.Float64bits(imag(v.Complex())))
+	case reflect.String:
+		h.writeString(v.String())
+	case reflect.Array, reflect.Slice:
+		h.writeUint(uint64(v.Len()))
+		for i := range v.Len() {
+			h.value(v.Index(i))
+		}
+	case reflect.Struct:
+		for i := range v.NumField() {
+			h.value(v.Field(i))
+		}
+	case reflect.Map:
+		h.mapValue(v)
+	case reflect.Pointer:
+		h.pointer(v)
+	case reflect.Interface:
+		h.writeBool(v.IsNil())
+		if !v.IsNil() {
+			h.writeString(TypeName(v.Elem().Type()))
+			h.value(v.Elem())
+		}
+	default:
+		// Functions, channels and unsafe pointers have no value to compare between worlds
+		h.writeBool(v.IsNil())
+	}
+}
+
+func (h *stateHasher) pointer(v reflect.Value) {
+	if v.Type().Elem() == entityRefType {
+		// A nil ref and a ref to a deleted entity both refer to no entity
+		if v.IsNil() {
+			h.writeEntity(InvalidEntityId)
+		} else {
+			h.value(v.Elem())
+		}
+		return
+	}
+
+	h.writeBool(v.IsNil())
+	if v.IsNil() {
+		return
+	}
+
+	switch v.Type().Elem() {
+	case archetypeType, storageType:
+		// Handles into the engine, e.g. EntityRef.Archetype, not part of the world's state
+		return
+	}
+
+	addr := v.Pointer()
+	if h.visited[addr] {
+		return
+	}
+	h.visited[addr] = true
+	h.value(v.Elem())
+	delete(h.visited, addr)
+}
+
+// mapValue hashes every entry separately and combines the sorted entry hashes, so the result
+// doesn't depend on map iteration order
+func (h *stateHasher) mapValue(v reflect.Value) {
+	entries := make([]uint64, 0, v.Len())
+	for iter := v.MapRange(); iter.Next(); {
+		entry := &stateHasher{storage: h.storage, hash: fnv.New64a(), ranks: h.ranks, visited: h.visited}
+		entry.value(iter.Key())
+		entry.value(iter.Value())
+		entries = append(entries, entry.hash.Sum64())
+	}
+	slices.Sort(entries)
+
+	h.writeUint(uint64(len(entries)))
+	for _, entry := range entries {
+		h.writeUint(entry)
+	}
+}
@@ -0,0 +1,74 @@
+package ecs_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/plus3/ooftn/ecs"
+	"github.com/stretchr/testify/assert"
+)
+
+type hashedInventory struct {
+	Items  map[string]int
+	Owner  ecs.EntityId
+	Target *ecs.EntityRef
+	weight float32
+}
+
+func TestStateHash(t *testing.T) {
+	registry := newSaveRegistry()
+	ecs.RegisterComponent[hashedInventory](registry)
+
+	// build spawns the same world, after churning through churn throwaway entities
+	build := func(churn int) (*ecs.Storage, ecs.EntityId) {
+		storage := ecs.NewStorage(registry)
+		for range churn {
+			storage.Delete(storage.Spawn(Position{X: -1}, Velocity{}))
+		}
+		colony := storage.Spawn(savedColony{Name: "red", Food: 10})
+		member := storage.Spawn(savedMember{Colony: storage.CreateEntityRef(colony), Age: 30}, Position{X: 5})
+		storage.Spawn(hashedInventory{
+			Items:  map[string]int{"wood": 3, "stone": 1, "food": 7},
+			Owner:  member,
+			Target: storage.CreateEntityRef(colony),
+			weight: 2.5,
+		})
+		return storage, member
+	}
+
+	world, _ := build(0)
+	hash := world.StateHash()
+	other, _ := build(5)
+	assert.Equal(t, hash, world.StateHash(), "hashing is repeatable")
+	assert.Equal(t, hash, other.StateHash(), "ids and map order don't affect the hash")
+
+	t.Run("divergence", func(t *testing.T) {
+		changed, changedMember := build(0)
+		ecs.ReadComponent[Position](changed, changedMember).X = 5.5
+		assert.NotEqual(t, hash, changed.StateHash())
+
+		disabled, disabledMember := build(0)
+		disabled.SetEnabled(disabledMember, false)
+		assert.NotEqual(t, hash, disabled.StateHash())
+
+		moved, movedMember := build(0)
+		moved.AddComponent(movedMember, Velocity{})
+		assert.NotEqual(t, hash, moved.StateHash())
+	})
+
+	t.Run("save and load", func(t *testing.T) {
+		world.Delete(world.Spawn(Position{}))
+		var buf bytes.Buffer
+		if !assert.NoError(t, world.SaveWorld(&buf)) {
+			return
+		}
+		loaded := ecs.NewStorage(registry)
+		if assert.NoError(t, loaded.LoadWorld(&buf)) {
+			// Unexported fields aren't saved
+			for item := range ecs.NewView[struct{ *hashedInventory }](loaded).Iter() {
+				item.weight = 2.5
+			}
+			assert.Equal(t, world.StateHash(), loaded.StateHash())
+		}
+	})
+}
@@ -118,9 +118,10 @@ func TestSchedulerStats(t *testing.T) {
 		t.Errorf("expected 2 system stats, got %d", len(stats.Systems))
 	}
 
-	for _, sysStats := range stats.Systems {
-		if sysStats.Name != "TestSystem" {
-			t.Errorf("expected system name 'TestSystem', got '%s'", sysStats.Name)
+	expectedNames := []string{"TestSystem", "TestSystem#2"}
+	for i, sysStats := range stats.Systems {
+		if sysStats.Name != expectedNames[i] {
+			t.Errorf("expected system name '%s', got '%s'", expectedNames[i], sysStats.Name)
 		}
 
 		if sysStats.ExecutionCount != 3 {
@@ -164,3 +165,64 @@ func TestSchedulerStats(t *testing.T) {
 		t.Errorf("expected sys2 to execute 3 times, got %d", sys2.executeCount)
 	}
 }
+
+func TestSchedulerSystemStatsByName(t *testing.T) {
+	storage := NewStorage(NewComponentRegistry())
+	scheduler := NewScheduler(storage)
+
+	scheduler.Register(&TestSystem{})
+	scheduler.Register(&TestSystem{sleepDur: 1 * time.Millisecond})
+	scheduler.RegisterOnce(&TestSystem{})
+	scheduler.Once(0.016)
+
+	first, ok := scheduler.SystemStatsByName("TestSystem")
+	if !ok || first.ExecutionCount != 1 {
+		t.Errorf("expected TestSystem to be found with 1 execution, got %v %+v", ok, first)
+	}
+
+	second, ok := scheduler.SystemStatsByName("TestSystem#2")
+	if !ok || second.MinDuration < 1*time.Millisecond {
+		t.Errorf("expected TestSystem#2 to be the sleeping system, got %v %+v", ok, second)
+	}
+
+	// One-shot systems are unregistered after running and free their name
+	if _, ok := scheduler.SystemStatsByName("TestSystem#3"); ok {
+		t.Error("expected one-shot system stats to be removed")
+	}
+
+	scheduler.Register(&TestSystem{})
+	if _, ok := scheduler.SystemStatsByName("TestSystem#3"); !ok {
+		t.Error("expected freed name to be reused")
+	}
+
+	if _, ok := scheduler.SystemStatsByName("MissingSystem"); ok {
+		t.Error("expected unknown system to not be found")
+	}
+}
+
+type namedTestSystem struct {
+	TestSystem
+	name string
+}
+
+func (s *namedTestSystem) Name() string {
+	return s.name
+}
+
+func TestSchedulerNamedSystems(t *testing.T) {
+	storage := NewStorage(NewComponentRegistry())
+	scheduler := NewScheduler(storage)
+
+	scheduler.Register(&namedTestSystem{name: "Physics"})
+	scheduler.Register(&namedTestSystem{name: "Physics"})
+	scheduler.Register(&namedTestSystem{})
+	scheduler.Register(&TestSystem{})
+
+	stats := scheduler.GetStats()
+	expectedNames := []string{"Physics", "Physics#2", "namedTestSystem", "TestSystem"}
+	for i, sysStats := range stats.Systems {
+		if sysStats.Name != expectedNames[i] {
+			t.Errorf("expected system name '%s', got '%s'", expectedNames[i], sysStats.Name)
+		}
+	}
+}
@@ -1,7 +1,11 @@
 package ecs
 
 import (
+	"cmp"
+	"fmt"
 	"reflect"
+	"runtime/debug"
+	"slices"
 	"sort"
 	"unsafe"
 	"weak"
@@ -17,6 +21,20 @@ type StorageStats struct {
 	TotalStorageSlots  int
 	EmptyStorageSlots  int
 	StorageUtilization float32
+
+	// Archetype move counts, only collected while move tracking is enabled
+	MoveTrackingEnabled bool
+	ArchetypeMoves      int64
+	MoveTransitions     []ArchetypeMoveStats
+}
+
+// ArchetypeMoveStats counts how many entities moved from one archetype to another.
+// A pair of transitions with high counts in both directions usually means a system is
+// adding and removing the same component every frame.
+type ArchetypeMoveStats struct {
+	From  uint32
+	To    uint32
+	Count int64
 }
 
 // ArchetypeStats provides statistics for a single archetype.
@@ -35,10 +53,33 @@ type singletonEntry struct {
 // Storage is the main ECS storage interface
 type Storage struct {
 	archetypes map[uint32]*Archetype
-	registry   *ComponentRegistry
-	singletons map[reflect.Type]*singletonEntry
+	// archetypeOrder lists the archetypes sorted with compareArchetypes, the order in which
+	// views and the other entity iterators visit them
+	archetypeOrder []*Archetype
+	registry       *ComponentRegistry
+	singletons     map[reflect.Type]*singletonEntry
+	// singletonAliases maps interface types to the concrete singleton type registered for
+	// them, see AddSingletonAs
+	singletonAliases map[reflect.Type]reflect.Type
+	changes          changeTracker
+	validation       bool
+
+	// retainEmpty keeps entities that lose their last component, see SetRetainEmptyEntities
+	retainEmpty bool
+	// births is the birth order of the last spawned entity, see BirthOrder
+	births uint64
+
+	moveCounts          map[archetypeMove]int64
+	onArchetypeCreated  ArchetypeCreatedFunc
+	structuralListeners []structuralListener
+	nextListenerId      int
 }
 
+// ArchetypeCreatedFunc is called whenever a storage creates a new archetype.
+// The types slice is owned by the archetype and must not be modified. The stack
+// is the formatted stack trace of the goroutine that caused the archetype to be created.
+type ArchetypeCreatedFunc func(archetypeId uint32, types []reflect.Type, stack []byte)
+
 // NewStorage creates a new ECS storage system with the given component registry
 func NewStorage(registry *ComponentRegistry) *Storage {
 	return &Storage{
@@ -78,17 +119,32 @@ func (s *Storage) CreateEntityRef(id EntityId) *EntityRef {
 
 func (s *Storage) ResolveEntityRef(ref *EntityRef) (EntityId, bool) {
 	if ref == nil {
-		return 0, false
+		return InvalidEntityId, false
 	}
-	// Check if the ref has been invalidated (Id == 0 means deleted)
-	if ref.Id == 0 {
-		return 0, false
+	// Check if the ref has been invalidated (an invalid Id means deleted)
+	if !ref.Id.IsValid() {
+		return InvalidEntityId, false
 	}
 	return ref.Id, true
 }
 
+// ResolveRefs resolves a batch of refs in one call, writing each current id to the matching
+// position in out and reporting whether it resolved. Unresolved refs (nil or deleted) are
+// written as InvalidEntityId. Panics if out is shorter than refs.
+func (s *Storage) ResolveRefs(refs []*EntityRef, out []EntityId) []bool {
+	if len(out) < len(refs) {
+		panic("ResolveRefs: out must be at least as long as refs")
+	}
+
+	resolved := make([]bool, len(refs))
+	for i, ref := range refs {
+		out[i], resolved[i] = s.ResolveEntityRef(ref)
+	}
+	return resolved
+}
+
 func (s *Storage) InvalidateEntityRef(ref *EntityRef) bool {
-	if ref == nil || ref.Id == 0 {
+	if ref == nil || !ref.Id.IsValid() {
 		return false
 	}
 
@@ -98,14 +154,14 @@ func (s *Storage) InvalidateEntityRef(ref *EntityRef) bool {
 		archetype.refs.Del(ref.Id)
 	}
 
-	ref.Id = 0
+	ref.Id = InvalidEntityId
 	ref.Archetype = nil
 	return true
 }
 
 // GetArchetype returns an archetype storage (if one exists)
 func (s *Storage) GetArchetype(components ...any) *Archetype {
-	types := extractComponentTypes(components)
+	types := extractComponentTypes(s.registry, components)
 	archetypeId := hashTypesToUint32(types)
 	return s.archetypes[archetypeId]
 }
@@ -120,79 +176,166 @@ func (s *Storage) GetArchetypeById(id uint32) *Archetype {
 	return s.archetypes[id]
 }
 
+// ArchetypeOf returns the archetype the entity lives in, or nil if the id is invalid,
+// refers to an unknown archetype, or refers to a slot that is no longer occupied.
+func (s *Storage) ArchetypeOf(id EntityId) *Archetype {
+	archetype, ok := s.archetypes[id.ArchetypeId()]
+	if !ok || !archetype.has(id.Index()) {
+		return nil
+	}
+	return archetype
+}
+
+// Exists reports whether id refers to a live entity: its archetype exists and the slot at
+// id.Index() is occupied. It is the check to make on raw ids that may have gone stale, such
+// as ids kept in a spatial index. EntityIds carry no generation, so an id whose slot was
+// freed and then reused by a new entity of the same archetype reports true for the new
+// entity; hold an EntityRef to follow one particular entity instead.
+func (s *Storage) Exists(id EntityId) bool {
+	return s.ArchetypeOf(id) != nil
+}
+
 // GetArchetypeByTypes returns an archetype storage (if one exists) based on reflect.Type
 func (s *Storage) GetArchetypeByTypes(types []reflect.Type) *Archetype {
+	types = slices.Clone(types)
 	sort.Sort(byTypeName(types))
 	archetypeId := hashTypesToUint32(types)
 	return s.archetypes[archetypeId]
 }
 
+// OnArchetypeCreated sets a debug hook that is called each time this storage creates a new
+// archetype, which helps track down the code paths responsible for a growing archetype count.
+// Capturing the stack trace is only done while a hook is set. Pass nil to remove the hook.
+func (s *Storage) OnArchetypeCreated(fn ArchetypeCreatedFunc) {
+	s.onArchetypeCreated = fn
+}
+
+// getOrCreateArchetype returns the archetype with the given id, creating it from the sorted types if needed
+func (s *Storage) getOrCreateArchetype(archetypeId uint32, types []reflect.Type) *Archetype {
+	if archetype, exists := s.archetypes[archetypeId]; exists {
+		if !archetype.hasExactTypes(types) {
+			panic(fmt.Sprintf("archetype id %d of components %v collides with the archetype of components %v", archetypeId, types, archetype.types))
+		}
+		return archetype
+	}
+
+	archetype := NewArchetype(archetypeId, types, s.registry)
+	s.archetypes[archetypeId] = archetype
+	at, _ := slices.BinarySearchFunc(s.archetypeOrder, archetype, compareArchetypes)
+	s.archetypeOrder = slices.Insert(s.archetypeOrder, at, archetype)
+
+	if s.onArchetypeCreated != nil {
+		s.onArchetypeCreated(archetypeId, archetype.types, debug.Stack())
+	}
+	return archetype
+}
+
 // Spawn creates a new entity with the provided components
 func (s *Storage) Spawn(components ...any) EntityId {
+	return s.SpawnSlice(components)
+}
+
+// SpawnSlice creates a new entity from a prebuilt slice of components. This is useful
+// for programmatic spawners (prefabs, deserializers) that assemble components at runtime.
+// The slice is not retained after the call returns.
+func (s *Storage) SpawnSlice(components []any) EntityId {
 	if len(components) == 0 {
 		panic("cannot spawn entity without components")
 	}
 
-	types := extractComponentTypes(components)
+	types := extractComponentTypes(s.registry, components)
 	archetypeId := hashTypesToUint32(types)
 
-	archetype, exists := s.archetypes[archetypeId]
-	if !exists {
-		archetype = NewArchetype(archetypeId, types, s.registry)
-		s.archetypes[archetypeId] = archetype
+	archetype := s.getOrCreateArchetype(archetypeId, types)
+	return s.finishSpawn(archetype, archetype.Spawn(components), types)
+}
+
+// finishSpawn does the bookkeeping for an entity just spawned at index in archetype with
+// components of the given types, and returns its id
+func (s *Storage) finishSpawn(archetype *Archetype, index uint32, types []reflect.Type) EntityId {
+	s.assignBirth(archetype, index)
+	id := NewEntityId(archetype.id, index)
+	s.recordAdded(id, types...)
+	s.validateComponents(id, types...)
+	s.emitStructuralChange(EntitySpawned, id, InvalidEntityId)
+	return id
+}
+
+// Reserve pre-sizes the archetype for the given components so that count more entities
+// can be spawned into it without reallocating its storage. The components are only used
+// to determine the archetype and are not stored. This is purely a performance hint.
+func (s *Storage) Reserve(count int, components ...any) {
+	if len(components) == 0 {
+		panic("cannot reserve an archetype without components")
 	}
 
-	entityIndex := archetype.Spawn(components)
-	return NewEntityId(archetypeId, entityIndex)
+	types := extractComponentTypes(s.registry, components)
+	archetype := s.getOrCreateArchetype(hashTypesToUint32(types), types)
+	for _, storage := range archetype.storages {
+		storage.Reserve(count)
+	}
 }
 
-// Delete removes all data related to the entity ID
+// PrecreateArchetype creates the archetype for the given components without spawning an
+// entity, so its id is assigned and its storages exist before the first spawn. The components
+// are only used to determine the archetype and are not stored. Archetype ids are derived from
+// the component type names (see RegisterTypeName), so precreating the same sets on a client
+// and a server gives both the same ids. Returns the existing archetype if it was already created.
+func (s *Storage) PrecreateArchetype(components ...any) *Archetype {
+	if len(components) == 0 {
+		panic("cannot precreate an archetype without components")
+	}
+
+	types := extractComponentTypes(s.registry, components)
+	archetype := s.getOrCreateArchetype(hashTypesToUint32(types), types)
+	for _, storage := range archetype.storages {
+		storage.Reserve(genericBlockSize)
+	}
+	return archetype
+}
+
+// Delete removes all data related to the entity ID. An entity with a Parent is removed from
+// its parent's Children, see SetParent. Deleting an entity that no longer exists does nothing
+// and emits no structural change.
 func (s *Storage) Delete(id EntityId) {
 	archetypeId := id.ArchetypeId()
 	entityIndex := id.Index()
 
 	archetype, ok := s.archetypes[archetypeId]
-	if !ok {
+	if !ok || !archetype.has(entityIndex) {
 		return
 	}
 
+	if archetype.HasComponent(parentType) {
+		s.detachChild(archetype.GetComponent(entityIndex, parentType).(*Parent), id)
+	}
 	archetype.Delete(entityIndex)
+	s.recordDeleted(id)
+	s.emitStructuralChange(EntityDeleted, id, InvalidEntityId)
 }
 
 func (s *Storage) AddComponent(id EntityId, component any) EntityId {
 	oldArchetype := s.archetypes[id.ArchetypeId()]
 
-	compType := reflect.TypeOf(component)
-	if compType.Kind() == reflect.Ptr {
-		compType = compType.Elem()
-	}
+	compType := s.registry.componentTypeOf(component)
 
 	newTypes := make([]reflect.Type, 0, len(oldArchetype.types)+1)
-	newTypes = append(newTypes, oldArchetype.types...)
+	for _, typ := range oldArchetype.types {
+		// Empty entities drop their marker once they have a real component
+		if typ != emptyEntityType {
+			newTypes = append(newTypes, typ)
+		}
+	}
 	newTypes = append(newTypes, compType)
 	sort.Sort(byTypeName(newTypes))
 
 	newArchetypeId := hashTypesToUint32(newTypes)
-	newArchetype, exists := s.archetypes[newArchetypeId]
-	if !exists {
-		newArchetype = NewArchetype(newArchetypeId, newTypes, s.registry)
-		s.archetypes[newArchetypeId] = newArchetype
-	}
+	newArchetype := s.getOrCreateArchetype(newArchetypeId, newTypes)
 
 	// Get the weak pointer if it exists
 	weakPtr, hasRef := oldArchetype.refs.Get(id)
 
-	components := make([]any, 0, len(newTypes))
-	for _, typ := range newTypes {
-		if typ == compType {
-			components = append(components, component)
-		} else {
-			comp := oldArchetype.GetComponent(id.Index(), typ)
-			components = append(components, comp)
-		}
-	}
-
-	newIndex := newArchetype.Spawn(components)
+	newIndex := newArchetype.migrate(oldArchetype, id.Index(), component)
 	newId := NewEntityId(newArchetypeId, newIndex)
 
 	// Update EntityRef if it exists
@@ -205,11 +348,22 @@ func (s *Storage) AddComponent(id EntityId, component any) EntityId {
 		newArchetype.refs.Put(newId, weakPtr)
 	}
 
-	oldArchetype.Delete(id.Index())
+	oldArchetype.vacate(id.Index(), nil)
+	s.recordMoved(id, newId)
+	s.recordAdded(newId, compType)
+	s.validateComponents(newId, compType)
+	s.countMove(id.ArchetypeId(), newArchetypeId)
+	s.emitStructuralChange(EntityMoved, newId, id)
 	return newId
 }
 
 func (s *Storage) RemoveComponent(id EntityId, compType reflect.Type) EntityId {
+	return s.removeComponent(id, compType, true)
+}
+
+// removeComponent removes the component of type compType from the entity, disposing of it
+// unless dispose is false because its value now belongs to another entity
+func (s *Storage) removeComponent(id EntityId, compType reflect.Type, dispose bool) EntityId {
 	oldArchetype := s.archetypes[id.ArchetypeId()]
 
 	newTypes := make([]reflect.Type, 0, len(oldArchetype.types)-1)
@@ -218,36 +372,40 @@ func (s *Storage) RemoveComponent(id EntityId, compType reflect.Type) EntityId {
 			newTypes = append(newTypes, typ)
 		}
 	}
+	// Archetype types may be in priority order, but ids are derived from name order
+	sort.Sort(byTypeName(newTypes))
 
 	weakPtr, hasRef := oldArchetype.refs.Get(id)
 
-	if len(newTypes) == 0 {
+	var extra any
+	if len(newTypes) == 0 && s.retainEmpty {
+		// Keep the entity as an empty entity
+		s.registry.registerEmptyEntity()
+		newTypes = append(newTypes, emptyEntityType)
+		extra = emptyEntity{}
+	} else if len(newTypes) == 0 {
 		// Entity has no components left, delete it
 		if hasRef {
 			if ref := weakPtr.Value(); ref != nil {
-				ref.Id = 0
+				ref.Id = InvalidEntityId
 				ref.Archetype = nil
 			}
 			oldArchetype.refs.Del(id)
 		}
-		oldArchetype.Delete(id.Index())
-		return 0
+		if dispose {
+			oldArchetype.Delete(id.Index())
+		} else {
+			oldArchetype.vacate(id.Index(), nil)
+		}
+		s.recordDeleted(id)
+		s.emitStructuralChange(EntityDeleted, id, InvalidEntityId)
+		return InvalidEntityId
 	}
 
 	newArchetypeId := hashTypesToUint32(newTypes)
-	newArchetype, exists := s.archetypes[newArchetypeId]
-	if !exists {
-		newArchetype = NewArchetype(newArchetypeId, newTypes, s.registry)
-		s.archetypes[newArchetypeId] = newArchetype
-	}
+	newArchetype := s.getOrCreateArchetype(newArchetypeId, newTypes)
 
-	components := make([]any, 0, len(newTypes))
-	for _, typ := range newTypes {
-		comp := oldArchetype.GetComponent(id.Index(), typ)
-		components = append(components, comp)
-	}
-
-	newIndex := newArchetype.Spawn(components)
+	newIndex := newArchetype.migrate(oldArchetype, id.Index(), extra)
 	newId := NewEntityId(newArchetypeId, newIndex)
 
 	// Update EntityRef if it exists
@@ -260,7 +418,93 @@ func (s *Storage) RemoveComponent(id EntityId, compType reflect.Type) EntityId {
 		newArchetype.refs.Put(newId, weakPtr)
 	}
 
+	dropped := compType
+	if !dispose {
+		dropped = nil
+	}
+	oldArchetype.vacate(id.Index(), dropped)
+	s.recordMoved(id, newId)
+	s.recordRemoved(newId, compType)
+	s.countMove(id.ArchetypeId(), newArchetypeId)
+	s.emitStructuralChange(EntityMoved, newId, id)
+	return newId
+}
+
+// TransferComponent moves the component of type t, with its value, from one entity to
+// another, e.g. to pick up an item or steal a buff. The component is added to to before it
+// is removed from from, and it is not disposed of since it lives on in to. Both entities
+// change archetype, so their ids change: EntityRefs to them are updated as with AddComponent
+// and RemoveComponent, and from is deleted if t was its last component, unless empty entities
+// are retained (see SetRetainEmptyEntities). Returns false, leaving both entities untouched,
+// if either entity doesn't exist, they are the same entity, from has no t, or to already has one.
+func (s *Storage) TransferComponent(from, to EntityId, t reflect.Type) bool {
+	source, target := s.ArchetypeOf(from), s.ArchetypeOf(to)
+	if from == to || source == nil || target == nil || !source.HasComponent(t) || target.HasComponent(t) {
+		return false
+	}
+
+	value := reflect.ValueOf(source.GetComponent(from.Index(), t)).Elem().Interface()
+	s.AddComponent(to, value)
+	s.removeComponent(from, t, false)
+	return true
+}
+
+// ReplaceComponents discards all of the entity's components and gives it the provided set
+// instead, moving it to the matching archetype. Any EntityRef to the entity stays valid and
+// is updated to the new id, which makes this suitable for repurposing pooled entities.
+// Returns the entity's new id, or InvalidEntityId if the entity doesn't exist.
+func (s *Storage) ReplaceComponents(id EntityId, components ...any) EntityId {
+	if len(components) == 0 {
+		panic("cannot replace an entity's components with an empty set")
+	}
+
+	oldArchetype := s.ArchetypeOf(id)
+	if oldArchetype == nil {
+		return InvalidEntityId
+	}
+
+	types := extractComponentTypes(s.registry, components)
+	newArchetypeId := hashTypesToUint32(types)
+	newArchetype := s.getOrCreateArchetype(newArchetypeId, types)
+
+	// Detach the ref before deleting so the old archetype doesn't invalidate it
+	weakPtr, hasRef := oldArchetype.refs.Get(id)
+	if hasRef {
+		oldArchetype.refs.Del(id)
+	}
+	disabled := oldArchetype.isDisabled(int(id.Index()))
+	birth := oldArchetype.birth(int(id.Index()))
 	oldArchetype.Delete(id.Index())
+
+	newId := NewEntityId(newArchetypeId, newArchetype.Spawn(components))
+	newArchetype.setDisabled(int(newId.Index()), disabled)
+	newArchetype.setBirth(int(newId.Index()), birth)
+	if hasRef {
+		if ref := weakPtr.Value(); ref != nil {
+			ref.Id = newId
+			ref.Archetype = newArchetype
+		}
+		newArchetype.refs.Put(newId, weakPtr)
+	}
+
+	s.recordMoved(id, newId)
+	for _, typ := range oldArchetype.types {
+		if !slices.Contains(types, typ) {
+			s.recordRemoved(newId, typ)
+		}
+	}
+	for _, typ := range types {
+		if !slices.Contains(oldArchetype.types, typ) {
+			s.recordAdded(newId, typ)
+		}
+	}
+
+	s.validateComponents(newId, types...)
+
+	if oldArchetype != newArchetype {
+		s.countMove(oldArchetype.id, newArchetypeId)
+	}
+	s.emitStructuralChange(EntityMoved, newId, id)
 	return newId
 }
 
@@ -288,21 +532,19 @@ func (s *Storage) HasComponent(id EntityId, compType reflect.Type) bool {
 }
 
 // extractComponentTypes extracts and sorts component types from a slice of components
-func extractComponentTypes(components []any) []reflect.Type {
+func extractComponentTypes(registry *ComponentRegistry, components []any) []reflect.Type {
 	types := make([]reflect.Type, 0, len(components))
 	for _, comp := range components {
-		compType := reflect.TypeOf(comp)
-
-		// If it's a pointer, get the underlying type
-		if compType.Kind() == reflect.Ptr {
-			compType = compType.Elem()
-		}
+		compType := registry.componentTypeOf(comp)
 
 		// Components can be structs or primitives (int, string, etc.)
-		// But not pointers, maps, channels, or functions (those aren't value types)
-		if compType.Kind() == reflect.Ptr || compType.Kind() == reflect.Map ||
-			compType.Kind() == reflect.Chan || compType.Kind() == reflect.Func {
-			panic("components cannot be pointers, maps, channels, or functions")
+		// But not pointers, maps, channels, or functions (those aren't value types),
+		// unless registered with RegisterReferenceComponent
+		switch compType.Kind() {
+		case reflect.Ptr, reflect.Map, reflect.Chan, reflect.Func:
+			if !registry.references[compType] {
+				panic("components cannot be pointers, maps, channels, or functions unless registered with RegisterReferenceComponent: " + compType.String())
+			}
 		}
 
 		types = append(types, compType)
@@ -316,25 +558,26 @@ func typeId(t reflect.Type) int {
 	return int(uintptr(ptr))
 }
 
-// hashTypesToUint32 generates a uint32 hash for a sorted slice of types
+// hashTypesToUint32 generates a uint32 hash for a sorted slice of types from their TypeNames, so
+// the same component types get the same archetype id in every process.
+// It never returns 0, which is reserved so that InvalidEntityId cannot refer to a real archetype.
 func hashTypesToUint32(types []reflect.Type) uint32 {
 	var h uint32 = 2166136261     // FNV-1a 32-bit offset basis
 	const prime uint32 = 16777619 // FNV-1a 32-bit prime
 
 	for _, t := range types {
-		// Use the type's pointer as a unique identifier
-		ptr := (*iface)(unsafe.Pointer(&t)).data
-		val := uint32(uintptr(ptr))
-
-		// Mix in all 4 bytes if on 64-bit system
-		if unsafe.Sizeof(uintptr(0)) == 8 {
-			val ^= uint32(uintptr(ptr) >> 32)
+		name := TypeName(t)
+		for i := 0; i < len(name); i++ {
+			h ^= uint32(name[i])
+			h *= prime
 		}
-
-		h ^= val
+		// Separate the names so ["ab", "c"] and ["a", "bc"] hash differently
 		h *= prime
 	}
 
+	if h == 0 {
+		h = 1
+	}
 	return h
 }
 
@@ -342,13 +585,35 @@ type ComponentReader interface {
 	GetComponent(EntityId, reflect.Type) any
 }
 
+// ReadComponent returns the component of type T of an entity, or nil if the entity doesn't
+// exist or doesn't have one.
 func ReadComponent[T any](reader ComponentReader, entityId EntityId) *T {
-	return reader.GetComponent(entityId, reflect.TypeFor[T]()).(*T)
+	component, _ := reader.GetComponent(entityId, reflect.TypeFor[T]()).(*T)
+	return component
+}
+
+// Count returns the number of enabled entities that have a component of type T. It sums
+// per-archetype counts, so it takes time proportional to the number of archetypes rather
+// than entities.
+func Count[T any](storage *Storage) int {
+	return storage.countComponent(reflect.TypeFor[T]())
+}
+
+// countComponent returns the number of enabled entities that have a component of the given type
+func (s *Storage) countComponent(componentType reflect.Type) int {
+	count := 0
+	for _, archetype := range s.archetypes {
+		if componentStorage := archetype.storageFor(componentType); componentStorage != nil {
+			count += componentStorage.Len() - archetype.disabledCount
+		}
+	}
+	return count
 }
 
 // AddSingleton adds or updates a singleton component in storage.
 // Singleton components are not associated with any entity and provide
 // efficient global state access. Returns a pointer to the stored component.
+// An existing singleton of the same type is replaced; use TryNewSingleton to keep it.
 func (s *Storage) AddSingleton(component any) unsafe.Pointer {
 	// Get the actual value if component is a pointer
 	val := reflect.ValueOf(component)
@@ -371,8 +636,37 @@ func (s *Storage) AddSingleton(component any) unsafe.Pointer {
 	return dataPtr
 }
 
-// GetSingleton returns a pointer to a singleton component, or nil if it doesn't exist.
+// AddSingletonAs adds or updates a singleton component like AddSingleton and also registers it
+// under the interface type iface, so GetSingleton and ReadSingleton find it by the interface,
+// e.g. a Renderer interface implemented by several backends. Lookups by interface resolve to
+// the singleton of the registered concrete type, and registering another singleton under the
+// same interface replaces the registration, so the last one wins. Panics if iface is not an
+// interface type or if a pointer to the component does not implement it.
+func (s *Storage) AddSingletonAs(iface reflect.Type, component any) unsafe.Pointer {
+	if iface.Kind() != reflect.Interface {
+		panic("AddSingletonAs: " + iface.String() + " is not an interface type")
+	}
+	componentType := reflect.TypeOf(component)
+	if componentType.Kind() == reflect.Ptr {
+		componentType = componentType.Elem()
+	}
+	if !reflect.PointerTo(componentType).Implements(iface) {
+		panic("AddSingletonAs: " + componentType.String() + " does not implement " + iface.String())
+	}
+
+	if s.singletonAliases == nil {
+		s.singletonAliases = make(map[reflect.Type]reflect.Type)
+	}
+	s.singletonAliases[iface] = componentType
+	return s.AddSingleton(component)
+}
+
+// GetSingleton returns a pointer to a singleton component, or nil if it doesn't exist. For an
+// interface type registered with AddSingletonAs it returns a pointer to the concrete singleton.
 func (s *Storage) GetSingleton(componentType reflect.Type) any {
+	if concrete, ok := s.singletonAliases[componentType]; ok {
+		componentType = concrete
+	}
 	entry := s.singletons[componentType]
 	if entry == nil {
 		return nil
@@ -381,7 +675,9 @@ func (s *Storage) GetSingleton(componentType reflect.Type) any {
 }
 
 // ReadSingleton reads a singleton component into the provided pointer.
-// The ptr parameter must be a pointer to a pointer (e.g., &gameState where gameState is *GameState).
+// The ptr parameter must be a pointer to a pointer (e.g., &gameState where gameState is *GameState),
+// or a pointer to an interface registered with AddSingletonAs (e.g., &renderer where renderer
+// is a Renderer), which is set to a pointer to the concrete singleton.
 // Returns true if the singleton exists and was successfully read, false otherwise.
 //
 // Example usage:
@@ -397,6 +693,14 @@ func (s *Storage) ReadSingleton(ptr any) bool {
 	}
 
 	targetVal := ptrVal.Elem()
+	if targetVal.Kind() == reflect.Interface {
+		singleton := s.GetSingleton(targetVal.Type())
+		if singleton == nil {
+			return false
+		}
+		targetVal.Set(reflect.ValueOf(singleton))
+		return true
+	}
 	if targetVal.Kind() != reflect.Ptr {
 		panic("ReadSingleton: argument must be a pointer to a pointer")
 	}
@@ -431,18 +735,12 @@ func (s *Storage) CollectStats() *StorageStats {
 	totalSlots := 0
 	emptySlots := 0
 
-	for _, archetype := range s.archetypes {
-		entityCount := 0
-		if len(archetype.storages) > 0 {
-			for idx := range archetype.storages[0].Iter() {
-				entityCount++
-				_ = idx
-			}
-		}
+	for _, archetype := range s.archetypeOrder {
+		entityCount := archetype.Len()
 
 		componentTypes := make([]string, len(archetype.types))
 		for i, t := range archetype.types {
-			componentTypes[i] = t.String()
+			componentTypes[i] = TypeName(t)
 		}
 
 		stats.ArchetypeBreakdown = append(stats.ArchetypeBreakdown, ArchetypeStats{
@@ -458,7 +756,7 @@ func (s *Storage) CollectStats() *StorageStats {
 	stats.SingletonCount = len(s.singletons)
 
 	for t := range s.singletons {
-		stats.SingletonTypes = append(stats.SingletonTypes, t.String())
+		stats.SingletonTypes = append(stats.SingletonTypes, TypeName(t))
 	}
 
 	stats.TotalStorageSlots = totalSlots
@@ -467,5 +765,54 @@ func (s *Storage) CollectStats() *StorageStats {
 		stats.StorageUtilization = float32(totalSlots-emptySlots) / float32(totalSlots)
 	}
 
+	if s.moveCounts != nil {
+		stats.MoveTrackingEnabled = true
+		stats.MoveTransitions = make([]ArchetypeMoveStats, 0, len(s.moveCounts))
+		for move, count := range s.moveCounts {
+			stats.ArchetypeMoves += count
+			stats.MoveTransitions = append(stats.MoveTransitions, ArchetypeMoveStats{
+				From:  move.from,
+				To:    move.to,
+				Count: count,
+			})
+		}
+		slices.SortFunc(stats.MoveTransitions, func(a, b ArchetypeMoveStats) int {
+			if a.Count != b.Count {
+				return cmp.Compare(b.Count, a.Count)
+			}
+			if a.From != b.From {
+				return cmp.Compare(a.From, b.From)
+			}
+			return cmp.Compare(a.To, b.To)
+		})
+	}
+
 	return stats
 }
+
+// archetypeMove identifies a transition between two archetypes
+type archetypeMove struct {
+	from, to uint32
+}
+
+// SetMoveTracking enables or disables counting of archetype moves caused by AddComponent
+// and RemoveComponent. The counts are reported by CollectStats and help find systems that
+// thrash entities between archetypes. Tracking is off by default to keep the hot path
+// cheap; disabling it discards the collected counts. Moves are only counted in builds with
+// the ecs_debug tag, elsewhere tracking stays disabled.
+func (s *Storage) SetMoveTracking(enabled bool) {
+	if !enabled || !debugChecks {
+		s.moveCounts = nil
+		return
+	}
+	if s.moveCounts == nil {
+		s.moveCounts = make(map[archetypeMove]int64)
+	}
+}
+
+// countMove records an archetype move while move tracking is enabled
+func (s *Storage) countMove(from, to uint32) {
+	if debugChecks && s.moveCounts != nil {
+		s.moveCounts[archetypeMove{from: from, to: to}]++
+	}
+}
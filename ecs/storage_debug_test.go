@@ -0,0 +1,43 @@
+//go:build ecs_debug
+
+package ecs_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/plus3/ooftn/ecs"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMoveTracking(t *testing.T) {
+
+	storage := ecs.NewStorage(newTestRegistry())
+	id := storage.Spawn(&Position{})
+	positionOnly := id.ArchetypeId()
+
+	id = storage.AddComponent(id, &Velocity{})
+	assert.False(t, storage.CollectStats().MoveTrackingEnabled)
+	assert.Zero(t, storage.CollectStats().ArchetypeMoves)
+
+	storage.SetMoveTracking(true)
+	withVelocity := id.ArchetypeId()
+	for range 3 {
+		id = storage.RemoveComponent(id, reflect.TypeOf(Velocity{}))
+		id = storage.AddComponent(id, &Velocity{})
+	}
+	id = storage.AddComponent(id, &Health{})
+
+	stats := storage.CollectStats()
+	assert.True(t, stats.MoveTrackingEnabled)
+	assert.Equal(t, int64(7), stats.ArchetypeMoves)
+	assert.ElementsMatch(t, []ecs.ArchetypeMoveStats{
+		{From: positionOnly, To: withVelocity, Count: 3},
+		{From: withVelocity, To: positionOnly, Count: 3},
+		{From: withVelocity, To: id.ArchetypeId(), Count: 1},
+	}, stats.MoveTransitions)
+	assert.Equal(t, int64(1), stats.MoveTransitions[2].Count, "transitions are sorted by count")
+
+	storage.SetMoveTracking(false)
+	assert.Zero(t, storage.CollectStats().ArchetypeMoves)
+}
@@ -3,6 +3,7 @@ package ecs_test
 import (
 	"fmt"
 	"reflect"
+	"slices"
 	"testing"
 
 	"github.com/plus3/ooftn/ecs"
@@ -363,6 +364,9 @@ func TestComponentReader(t *testing.T) {
 
 	testB := ecs.ReadComponent[TestB](storage, id)
 	assert.Equal(t, *testB, TestB("B"))
+
+	assert.Nil(t, ecs.ReadComponent[Position](storage, id), "missing component")
+	assert.Nil(t, ecs.ReadComponent[TestA](storage, ecs.InvalidEntityId), "missing entity")
 }
 
 func TestGetArchetype(t *testing.T) {
@@ -485,6 +489,62 @@ func TestRemoveLastComponent(t *testing.T) {
 	assert.Nil(t, comp)
 }
 
+func TestRetainEmptyEntities(t *testing.T) {
+	storage := ecs.NewStorage(newTestRegistry())
+	storage.SetRetainEmptyEntities(true)
+	positions := ecs.NewView[struct{ *Position }](storage)
+
+	id := storage.Spawn(&Position{X: 1.0, Y: 2.0})
+	ref := storage.CreateEntityRef(id)
+
+	id = storage.RemoveComponent(id, reflect.TypeOf(Position{}))
+	assert.True(t, id.IsValid())
+	assert.True(t, storage.IsEmpty(id))
+	resolved, ok := storage.ResolveEntityRef(ref)
+	assert.True(t, ok, "the ref of a retained entity should stay valid")
+	assert.Equal(t, id, resolved)
+	assert.Nil(t, positions.Get(id))
+
+	id = storage.AddComponent(id, Velocity{DX: 3})
+	assert.False(t, storage.IsEmpty(id))
+	assert.Equal(t, id, ref.Id)
+	assert.Len(t, storage.ArchetypeOf(id).Types(), 1, "the empty marker should be dropped")
+	assert.Equal(t, float32(3), ecs.ReadComponent[Velocity](storage, id).DX)
+
+	t.Run("spawn empty", func(t *testing.T) {
+		empty := storage.SpawnEmpty()
+		assert.True(t, storage.IsEmpty(empty))
+		for range positions.Iter() {
+			t.Error("empty entities should not match views")
+		}
+
+		empty = storage.AddComponent(empty, Position{X: 5})
+		assert.Equal(t, float32(5), positions.Get(empty).Position.X)
+	})
+
+	t.Run("delete by default", func(t *testing.T) {
+		storage.SetRetainEmptyEntities(false)
+		id := storage.Spawn(Position{})
+		assert.False(t, storage.RemoveComponent(id, reflect.TypeOf(Position{})).IsValid())
+	})
+}
+
+func TestEntityIdIsValid(t *testing.T) {
+
+	storage := ecs.NewStorage(newTestRegistry())
+
+	assert.False(t, ecs.InvalidEntityId.IsValid())
+	assert.False(t, ecs.NewEntityId(0, 0).IsValid())
+	assert.False(t, ecs.NewEntityId(0, 5).IsValid())
+
+	id := storage.Spawn(&Position{X: 1.0, Y: 2.0})
+	assert.True(t, id.IsValid())
+
+	newId := storage.RemoveComponent(id, reflect.TypeOf(Position{}))
+	assert.Equal(t, ecs.InvalidEntityId, newId)
+	assert.False(t, newId.IsValid())
+}
+
 func TestPointerComponent(t *testing.T) {
 
 	storage := ecs.NewStorage(newTestRegistry())
@@ -602,6 +662,29 @@ func TestPointerComponentDeletion(t *testing.T) {
 	assert.Nil(t, comp2)
 }
 
+func TestArchetypeLen(t *testing.T) {
+	storage := ecs.NewStorage(newTestRegistry())
+
+	ids := make([]ecs.EntityId, 100)
+	for i := range 100 {
+		ids[i] = storage.Spawn(Position{X: float32(i), Y: float32(i)}, Velocity{DX: 1.0, DY: 1.0})
+	}
+
+	archetype := storage.GetArchetype(Position{}, Velocity{})
+	assert.Equal(t, 100, archetype.Len())
+
+	for i := 0; i < 100; i += 2 {
+		storage.Delete(ids[i])
+	}
+	assert.Equal(t, 50, archetype.Len())
+
+	storage.Spawn(Position{}, Velocity{})
+	assert.Equal(t, 51, archetype.Len())
+
+	archetype.Compact()
+	assert.Equal(t, 51, archetype.Len())
+}
+
 func TestArchetypeCompact(t *testing.T) {
 	storage := ecs.NewStorage(newTestRegistry())
 
@@ -673,6 +756,49 @@ func TestArchetypeCompactWithEntityRefs(t *testing.T) {
 	}
 }
 
+func TestArchetypeCompactDense(t *testing.T) {
+	storage := ecs.NewStorage(newTestRegistry())
+
+	ids := make([]ecs.EntityId, 10)
+	refs := make([]*ecs.EntityRef, 10)
+	for i := range ids {
+		ids[i] = storage.Spawn(Position{X: float32(i)}, Velocity{})
+		refs[i] = storage.CreateEntityRef(ids[i])
+	}
+
+	archetype := storage.GetArchetype(Position{}, Velocity{})
+	check := func(t *testing.T) {
+		for i, id := range ids {
+			assert.Equal(t, id, refs[i].Id)
+			resolved, ok := storage.ResolveEntityRef(refs[i])
+			assert.True(t, ok)
+			assert.Equal(t, id, resolved)
+			assert.Equal(t, float32(i), ecs.ReadComponent[Position](storage, id).X)
+		}
+		assert.Equal(t, len(ids), archetype.Len())
+	}
+
+	t.Run("never fragmented", func(t *testing.T) {
+		archetype.Compact()
+		check(t)
+	})
+
+	t.Run("already compacted", func(t *testing.T) {
+		storage.Delete(ids[3])
+		archetype.Compact()
+		ids, refs = slices.Delete(ids, 3, 4), slices.Delete(refs, 3, 4)
+		for i, ref := range refs {
+			ids[i] = ref.Id
+		}
+
+		archetype.Compact()
+		for i, id := range ids {
+			assert.Equal(t, id, refs[i].Id)
+		}
+		assert.Equal(t, float32(4), ecs.ReadComponent[Position](storage, ids[3]).X)
+	})
+}
+
 func TestArchetypeCompactMultipleTimes(t *testing.T) {
 	storage := ecs.NewStorage(newTestRegistry())
 
@@ -724,3 +850,692 @@ func TestCompactEmptyArchetype(t *testing.T) {
 
 	archetype.Compact()
 }
+
+type disposableHandle struct {
+	Name     string
+	disposed *[]string
+}
+
+func (h *disposableHandle) Dispose() {
+	*h.disposed = append(*h.disposed, h.Name)
+}
+
+func TestComponentDispose(t *testing.T) {
+
+	registry := newTestRegistry()
+	ecs.RegisterComponent[disposableHandle](registry)
+	storage := ecs.NewStorage(registry)
+
+	var disposed []string
+
+	deleted := storage.Spawn(&Position{}, &disposableHandle{Name: "deleted", disposed: &disposed})
+	storage.Delete(deleted)
+	assert.Equal(t, []string{"deleted"}, disposed)
+
+	moved := storage.Spawn(&Position{}, &disposableHandle{Name: "moved", disposed: &disposed})
+	moved = storage.AddComponent(moved, &Velocity{})
+	moved = storage.RemoveComponent(moved, reflect.TypeOf(Velocity{}))
+	assert.Equal(t, []string{"deleted"}, disposed, "migrating an entity should not dispose its components")
+
+	handle := storage.GetComponent(moved, reflect.TypeOf(disposableHandle{})).(*disposableHandle)
+	assert.Equal(t, "moved", handle.Name)
+
+	storage.RemoveComponent(moved, reflect.TypeOf(disposableHandle{}))
+	assert.Equal(t, []string{"deleted", "moved"}, disposed)
+}
+
+func TestOnArchetypeCreated(t *testing.T) {
+
+	storage := ecs.NewStorage(newTestRegistry())
+
+	type created struct {
+		id    uint32
+		types []reflect.Type
+		stack string
+	}
+	var events []created
+	storage.OnArchetypeCreated(func(archetypeId uint32, types []reflect.Type, stack []byte) {
+		events = append(events, created{archetypeId, types, string(stack)})
+	})
+
+	id := storage.Spawn(&Position{})
+	storage.Spawn(&Position{})
+	assert.Len(t, events, 1)
+	assert.Equal(t, id.ArchetypeId(), events[0].id)
+	assert.Equal(t, []reflect.Type{reflect.TypeOf(Position{})}, events[0].types)
+	assert.Contains(t, events[0].stack, "TestOnArchetypeCreated")
+
+	id = storage.AddComponent(id, &Velocity{})
+	assert.Len(t, events, 2)
+	assert.Equal(t, id.ArchetypeId(), events[1].id)
+
+	storage.OnArchetypeCreated(nil)
+	storage.Spawn(&Health{})
+	assert.Len(t, events, 2)
+}
+
+func TestArchetypeOf(t *testing.T) {
+
+	storage := ecs.NewStorage(newTestRegistry())
+
+	id := storage.Spawn(&Position{X: 1.0, Y: 2.0})
+	archetype := storage.ArchetypeOf(id)
+	assert.NotNil(t, archetype)
+	assert.Equal(t, id.ArchetypeId(), archetype.ID())
+
+	assert.Nil(t, storage.ArchetypeOf(ecs.InvalidEntityId))
+	assert.Nil(t, storage.ArchetypeOf(ecs.NewEntityId(id.ArchetypeId()+1, 0)))
+	assert.Nil(t, storage.ArchetypeOf(ecs.NewEntityId(id.ArchetypeId(), 1000)))
+
+	storage.Delete(id)
+	assert.Nil(t, storage.ArchetypeOf(id), "stale id into a live archetype should return nil")
+}
+
+func TestExists(t *testing.T) {
+	storage := ecs.NewStorage(newTestRegistry())
+
+	id := storage.Spawn(Position{X: 1})
+	other := storage.Spawn(Position{X: 2})
+	assert.True(t, storage.Exists(id))
+	assert.False(t, storage.Exists(ecs.InvalidEntityId))
+	assert.False(t, storage.Exists(ecs.NewEntityId(id.ArchetypeId()+1, 0)))
+
+	moved := storage.AddComponent(id, Velocity{})
+	assert.False(t, storage.Exists(id), "an entity's old id goes stale when it moves")
+	assert.True(t, storage.Exists(moved))
+
+	storage.Delete(other)
+	assert.False(t, storage.Exists(other))
+	assert.Equal(t, other, storage.Spawn(Position{X: 3}), "the freed slot is reused")
+	assert.True(t, storage.Exists(other), "ids carry no generation")
+}
+
+func TestOnStructuralChange(t *testing.T) {
+
+	storage := ecs.NewStorage(newTestRegistry())
+
+	var events []ecs.StructuralChange
+	unsubscribe := storage.OnStructuralChange(func(change ecs.StructuralChange) {
+		events = append(events, change)
+	})
+
+	var second int
+	unsubscribeSecond := storage.OnStructuralChange(func(ecs.StructuralChange) {
+		second++
+	})
+
+	id := storage.Spawn(&Position{})
+	moved := storage.AddComponent(id, &Velocity{})
+	removed := storage.RemoveComponent(moved, reflect.TypeOf(Velocity{}))
+	storage.Delete(removed)
+	storage.Delete(removed) // already gone, not reported again
+
+	assert.Equal(t, []ecs.StructuralChange{
+		{Kind: ecs.EntitySpawned, Id: id},
+		{Kind: ecs.EntityMoved, Id: moved, OldId: id},
+		{Kind: ecs.EntityMoved, Id: removed, OldId: moved},
+		{Kind: ecs.EntityDeleted, Id: removed},
+	}, events)
+	assert.Equal(t, 4, second)
+
+	// Removing the last component deletes the entity
+	id = storage.Spawn(&Health{})
+	storage.RemoveComponent(id, reflect.TypeOf(Health{}))
+	assert.Equal(t, ecs.StructuralChange{Kind: ecs.EntityDeleted, Id: id}, events[len(events)-1])
+
+	unsubscribeSecond()
+	storage.Spawn(&Position{})
+	assert.Len(t, events, 7)
+	assert.Equal(t, 6, second)
+
+	unsubscribe()
+	storage.Spawn(&Position{})
+	assert.Len(t, events, 7)
+}
+
+func TestOnEntityMoved(t *testing.T) {
+	storage := ecs.NewStorage(newTestRegistry())
+
+	cache := make(map[ecs.EntityId]string)
+	unsubscribe := storage.OnEntityMoved(func(oldId, newId ecs.EntityId) {
+		if name, ok := cache[oldId]; ok {
+			delete(cache, oldId)
+			cache[newId] = name
+		}
+	})
+
+	id := storage.Spawn(Position{})
+	cache[id] = "player"
+
+	id = storage.AddComponent(id, Velocity{})
+	id = storage.ReplaceComponents(id, Health{Current: 1})
+	storage.Spawn(Position{})
+	storage.Delete(storage.Spawn(Health{}))
+	assert.Equal(t, map[ecs.EntityId]string{id: "player"}, cache)
+
+	unsubscribe()
+	moved := storage.AddComponent(id, Position{})
+	assert.NotEqual(t, id, moved)
+	assert.Equal(t, map[ecs.EntityId]string{id: "player"}, cache)
+}
+
+func TestStorageReserve(t *testing.T) {
+
+	storage := ecs.NewStorage(newTestRegistry())
+	storage.Reserve(500, Position{}, Velocity{})
+
+	archetype := storage.GetArchetype(Position{}, Velocity{})
+	assert.NotNil(t, archetype)
+	assert.Equal(t, 0, storage.CollectStats().TotalEntityCount)
+
+	for i := range 500 {
+		storage.Spawn(Position{X: float32(i)}, Velocity{})
+	}
+	assert.Equal(t, 500, storage.CollectStats().TotalEntityCount)
+
+	assert.Panics(t, func() { storage.Reserve(10) })
+}
+
+func TestPrecreateArchetype(t *testing.T) {
+	storage := ecs.NewStorage(newTestRegistry())
+
+	archetype := storage.PrecreateArchetype(Velocity{}, Position{})
+	assert.NotNil(t, archetype)
+	assert.Same(t, archetype, storage.PrecreateArchetype(Position{}, Velocity{}), "precreating again should return the same archetype")
+	assert.Len(t, storage.GetArchetypes(), 1)
+	assert.Equal(t, 0, storage.CollectStats().TotalEntityCount)
+
+	id := storage.Spawn(Position{X: 1}, Velocity{DX: 2})
+	assert.Equal(t, archetype.ID(), id.ArchetypeId())
+	assert.Len(t, storage.GetArchetypes(), 1)
+
+	// Ids only depend on the component set, so another storage agrees on them
+	other := ecs.NewStorage(newTestRegistry())
+	assert.Equal(t, archetype.ID(), other.PrecreateArchetype(Position{}, Velocity{}).ID())
+
+	assert.Panics(t, func() { storage.PrecreateArchetype() })
+}
+
+func TestTransferComponent(t *testing.T) {
+	registry := newTestRegistry()
+	ecs.RegisterComponent[disposableHandle](registry)
+	storage := ecs.NewStorage(registry)
+	handleType := reflect.TypeFor[disposableHandle]()
+
+	var disposed []string
+	item := storage.CreateEntityRef(storage.Spawn(Position{}, disposableHandle{Name: "sword", disposed: &disposed}))
+	player := storage.CreateEntityRef(storage.Spawn(Position{}, Health{Current: 10}))
+
+	assert.True(t, storage.TransferComponent(item.Id, player.Id, handleType))
+	assert.False(t, storage.HasComponent(item.Id, handleType))
+	assert.Equal(t, "sword", ecs.ReadComponent[disposableHandle](storage, player.Id).Name)
+	assert.Equal(t, 10, ecs.ReadComponent[Health](storage, player.Id).Current, "the target keeps its other components")
+	assert.Empty(t, disposed, "transferred components should not be disposed")
+
+	t.Run("rejected transfers", func(t *testing.T) {
+		other := storage.Spawn(Position{}, disposableHandle{Name: "shield", disposed: &disposed})
+		assert.False(t, storage.TransferComponent(other, player.Id, handleType), "target already has one")
+		assert.False(t, storage.TransferComponent(item.Id, player.Id, handleType), "source has none")
+		assert.False(t, storage.TransferComponent(player.Id, player.Id, handleType))
+		assert.False(t, storage.TransferComponent(player.Id, ecs.InvalidEntityId, handleType))
+		assert.Equal(t, "shield", ecs.ReadComponent[disposableHandle](storage, other).Name)
+		assert.NoError(t, storage.Validate())
+	})
+
+	t.Run("last component", func(t *testing.T) {
+		id := storage.Spawn(disposableHandle{Name: "gem", disposed: &disposed})
+		ref := storage.CreateEntityRef(id)
+		storage.RemoveComponent(player.Id, handleType)
+		disposed = nil
+
+		assert.True(t, storage.TransferComponent(id, player.Id, handleType))
+		assert.False(t, ref.Id.IsValid(), "the source is deleted once it has no components")
+		assert.Equal(t, "gem", ecs.ReadComponent[disposableHandle](storage, player.Id).Name)
+		assert.Empty(t, disposed)
+	})
+}
+
+func TestReplaceComponents(t *testing.T) {
+
+	storage := ecs.NewStorage(newTestRegistry())
+	id := storage.Spawn(&Position{X: 1}, &Velocity{DX: 2})
+	ref := storage.CreateEntityRef(id)
+
+	newId := storage.ReplaceComponents(id, &Health{Current: 10, Max: 10}, Name("pooled"))
+	assert.NotEqual(t, id.ArchetypeId(), newId.ArchetypeId())
+
+	resolved, ok := storage.ResolveEntityRef(ref)
+	assert.True(t, ok, "ref should survive the replacement")
+	assert.Equal(t, newId, resolved)
+
+	assert.Nil(t, storage.GetComponent(newId, reflect.TypeOf(Position{})))
+	assert.Nil(t, storage.GetComponent(newId, reflect.TypeOf(Velocity{})))
+	assert.Equal(t, 10, storage.GetComponent(newId, reflect.TypeOf(Health{})).(*Health).Current)
+	assert.Equal(t, Name("pooled"), *storage.GetComponent(newId, reflect.TypeOf(Name(""))).(*Name))
+	assert.Nil(t, storage.ArchetypeOf(id), "old slot should be freed")
+
+	t.Run("same archetype resets values", func(t *testing.T) {
+		sameId := storage.ReplaceComponents(newId, &Health{Current: 1, Max: 1}, Name("reset"))
+		assert.Equal(t, 1, storage.GetComponent(sameId, reflect.TypeOf(Health{})).(*Health).Current)
+
+		resolved, ok := storage.ResolveEntityRef(ref)
+		assert.True(t, ok)
+		assert.Equal(t, sameId, resolved)
+	})
+
+	t.Run("missing entity", func(t *testing.T) {
+		assert.Equal(t, ecs.InvalidEntityId, storage.ReplaceComponents(id, &Position{}))
+		assert.Panics(t, func() { storage.ReplaceComponents(newId) })
+	})
+}
+
+func TestSlotPolicy(t *testing.T) {
+	spawnAfterDeletes := func(policy ecs.SlotPolicy) []uint32 {
+		registry := newTestRegistry()
+		registry.SetSlotPolicy(policy)
+		storage := ecs.NewStorage(registry)
+
+		ids := make([]ecs.EntityId, 4)
+		for i := range ids {
+			ids[i] = storage.Spawn(Position{X: float32(i)}, Velocity{})
+		}
+		storage.Delete(ids[1])
+		storage.Delete(ids[2])
+
+		var indices []uint32
+		for range 3 {
+			id := storage.Spawn(Position{}, Velocity{})
+			indices = append(indices, id.Index())
+			assert.NotNil(t, ecs.ReadComponent[Position](storage, id))
+			assert.NotNil(t, ecs.ReadComponent[Velocity](storage, id))
+		}
+		assert.Equal(t, 5, storage.GetArchetype(Position{}, Velocity{}).Len())
+		return indices
+	}
+
+	t.Run("lifo", func(t *testing.T) {
+		assert.Equal(t, []uint32{2, 1, 4}, spawnAfterDeletes(ecs.SlotReuseLIFO))
+	})
+
+	t.Run("fifo", func(t *testing.T) {
+		assert.Equal(t, []uint32{1, 2, 4}, spawnAfterDeletes(ecs.SlotReuseFIFO))
+	})
+
+	t.Run("monotonic", func(t *testing.T) {
+		assert.Equal(t, []uint32{4, 5, 6}, spawnAfterDeletes(ecs.SlotMonotonic))
+	})
+
+	t.Run("lowest", func(t *testing.T) {
+		assert.Equal(t, []uint32{1, 2, 4}, spawnAfterDeletes(ecs.SlotReuseLowest))
+	})
+
+	t.Run("lowest releases trailing slots", func(t *testing.T) {
+		registry := newTestRegistry()
+		registry.SetSlotPolicy(ecs.SlotReuseLowest)
+		storage := ecs.NewStorage(registry)
+
+		ids := make([]ecs.EntityId, 5)
+		for i := range ids {
+			ids[i] = storage.Spawn(Position{X: float32(i)})
+		}
+		storage.Delete(ids[3])
+		storage.Delete(ids[4])
+		storage.Delete(ids[1])
+
+		assert.Equal(t, uint32(1), storage.Spawn(Position{}).Index())
+		assert.Equal(t, uint32(3), storage.Spawn(Position{}).Index())
+		assert.NoError(t, storage.Validate())
+	})
+
+	t.Run("per component threshold", func(t *testing.T) {
+		registry := newTestRegistry()
+		ecs.RegisterComponentSlotPolicy[Velocity](registry, ecs.SlotReuseLowest, 3)
+		storage := ecs.NewStorage(registry)
+
+		ids := make([]ecs.EntityId, 6)
+		for i := range ids {
+			ids[i] = storage.Spawn(Position{X: float32(i)}, Velocity{DX: float32(i)})
+		}
+		storage.Delete(ids[4])
+		storage.Delete(ids[1])
+
+		spawn := func() uint32 {
+			id := storage.Spawn(Position{X: -1}, Velocity{DX: -1})
+			// Both storages of the archetype must allocate the same slot
+			assert.Equal(t, float32(-1), ecs.ReadComponent[Position](storage, id).X)
+			assert.Equal(t, float32(-1), ecs.ReadComponent[Velocity](storage, id).DX)
+			return id.Index()
+		}
+
+		assert.Equal(t, uint32(6), spawn(), "slots should not be reused below the threshold")
+		storage.Delete(ids[2])
+		assert.Equal(t, []uint32{1, 2, 4, 7}, []uint32{spawn(), spawn(), spawn(), spawn()})
+		assert.NoError(t, storage.Validate())
+
+		// Archetypes without Velocity keep the registry's policy
+		a := storage.Spawn(Position{})
+		storage.Spawn(Position{})
+		storage.Delete(a)
+		assert.Equal(t, a.Index(), storage.Spawn(Position{}).Index())
+	})
+
+	t.Run("monotonic compact", func(t *testing.T) {
+		registry := newTestRegistry()
+		registry.SetSlotPolicy(ecs.SlotMonotonic)
+		storage := ecs.NewStorage(registry)
+
+		first := storage.Spawn(Position{})
+		second := storage.Spawn(Position{X: 2})
+		storage.Delete(first)
+
+		ref := storage.CreateEntityRef(second)
+		storage.GetArchetype(Position{}).Compact()
+		assert.Equal(t, uint32(0), ref.Id.Index())
+		assert.Equal(t, uint32(1), storage.Spawn(Position{}).Index())
+	})
+}
+
+func TestComponentPriority(t *testing.T) {
+	registry := newTestRegistry()
+	ecs.SetComponentPriority[Velocity](registry, 10)
+	ecs.SetComponentPriority[Position](registry, 5)
+	storage := ecs.NewStorage(registry)
+
+	id := storage.Spawn(Health{Current: 1}, Position{X: 1}, Velocity{DX: 2})
+	archetype := storage.GetArchetype(Position{}, Health{}, Velocity{})
+	assert.NotNil(t, archetype)
+	assert.Equal(t, []reflect.Type{
+		reflect.TypeFor[Velocity](),
+		reflect.TypeFor[Position](),
+		reflect.TypeFor[Health](),
+	}, archetype.Types())
+
+	assert.Same(t, archetype, storage.GetArchetypeByTypes(archetype.Types()))
+	assert.Equal(t, reflect.TypeFor[Velocity](), archetype.Types()[0], "lookups must not reorder archetype types")
+
+	id = storage.RemoveComponent(id, reflect.TypeFor[Health]())
+	id = storage.AddComponent(id, Health{Current: 2})
+	assert.Equal(t, archetype.ID(), id.ArchetypeId(), "entity should return to the same archetype")
+	assert.Equal(t, float32(2), ecs.ReadComponent[Velocity](storage, id).DX)
+
+	assert.NoError(t, storage.Validate())
+	assert.Panics(t, func() {
+		type unregistered struct{}
+		ecs.SetComponentPriority[unregistered](registry, 1)
+	})
+}
+
+func TestSpawnDisabled(t *testing.T) {
+	storage := ecs.NewStorage(newTestRegistry())
+	positions := ecs.NewComponentView[Position](storage)
+	view := ecs.NewView[struct{ Position *Position }](storage)
+
+	active := storage.Spawn(Position{X: 1})
+	pooled := make([]ecs.EntityId, 3)
+	for i := range pooled {
+		pooled[i] = storage.SpawnDisabled(Position{X: float32(10 + i)})
+	}
+
+	countView := func() int {
+		count := 0
+		for range view.Iter() {
+			count++
+		}
+		return count
+	}
+
+	assert.True(t, storage.IsEnabled(active))
+	assert.False(t, storage.IsEnabled(pooled[0]))
+	assert.Equal(t, 1, positions.Count())
+	assert.Equal(t, 1, countView())
+	assert.Equal(t, 4, storage.ArchetypeOf(active).Len(), "disabled entities still occupy slots")
+	assert.Equal(t, float32(10), ecs.ReadComponent[Position](storage, pooled[0]).X, "disabled entities are readable by id")
+
+	ref := storage.CreateEntityRef(pooled[1])
+	assert.Equal(t, pooled[1], ref.Id)
+
+	t.Run("enable", func(t *testing.T) {
+		assert.True(t, storage.SetEnabled(pooled[0], true))
+		assert.True(t, storage.IsEnabled(pooled[0]))
+		assert.Equal(t, 2, countView())
+	})
+
+	t.Run("survives moves", func(t *testing.T) {
+		moved := storage.AddComponent(pooled[1], Velocity{DX: 1})
+		assert.False(t, storage.IsEnabled(moved))
+		assert.Equal(t, moved, ref.Id)
+		assert.Equal(t, 0, ecs.NewComponentView[Velocity](storage).Count())
+
+		pooled[1] = storage.ReplaceComponents(moved, Position{X: 11})
+		assert.False(t, storage.IsEnabled(pooled[1]))
+	})
+
+	t.Run("survives compaction", func(t *testing.T) {
+		storage.Delete(active)
+		archetype := storage.ArchetypeOf(pooled[2])
+		archetype.Compact()
+
+		assert.Equal(t, 2, archetype.DisabledCount())
+		assert.Equal(t, 1, countView())
+		assert.False(t, storage.IsEnabled(ref.Id))
+		assert.Equal(t, float32(11), ecs.ReadComponent[Position](storage, ref.Id).X)
+		assert.NoError(t, storage.Validate())
+	})
+
+	t.Run("delete clears", func(t *testing.T) {
+		id := ref.Id
+		storage.Delete(id)
+		assert.False(t, storage.IsEnabled(id))
+		assert.False(t, storage.SetEnabled(id, true))
+		assert.Equal(t, 1, storage.GetArchetype(Position{}).DisabledCount())
+
+		reused := storage.Spawn(Position{})
+		assert.True(t, storage.IsEnabled(reused), "reused slots start enabled")
+		assert.NoError(t, storage.Validate())
+	})
+}
+
+func TestBirthOrder(t *testing.T) {
+	storage := ecs.NewStorage(newTestRegistry())
+
+	first := storage.Spawn(Position{})
+	second := storage.Spawn(Velocity{})
+	third := storage.Spawn(Position{})
+	assert.Less(t, storage.BirthOrder(first), storage.BirthOrder(second))
+	assert.Less(t, storage.BirthOrder(second), storage.BirthOrder(third))
+	birth := storage.BirthOrder(third)
+
+	t.Run("survives moves", func(t *testing.T) {
+		third = storage.AddComponent(third, Velocity{})
+		assert.Equal(t, birth, storage.BirthOrder(third))
+		third = storage.RemoveComponent(third, reflect.TypeFor[Position]())
+		assert.Equal(t, birth, storage.BirthOrder(third))
+		third = storage.ReplaceComponents(third, Position{}, Health{})
+		assert.Equal(t, birth, storage.BirthOrder(third))
+	})
+
+	t.Run("survives compaction", func(t *testing.T) {
+		storage.Delete(first)
+		reused := storage.Spawn(Position{})
+		assert.Greater(t, storage.BirthOrder(reused), birth, "reused slots get a new birth order")
+
+		filler := storage.Spawn(Position{})
+		ref := storage.CreateEntityRef(storage.Spawn(Position{}))
+		moving := storage.BirthOrder(ref.Id)
+		storage.Delete(filler)
+
+		before := ref.Id
+		storage.ArchetypeOf(ref.Id).Compact()
+		assert.NotEqual(t, before, ref.Id, "compaction should move the entity")
+		assert.Equal(t, moving, storage.BirthOrder(ref.Id))
+	})
+
+	t.Run("spawned since", func(t *testing.T) {
+		mark := storage.LastBirthOrder()
+
+		spawned := storage.Spawn(Health{})
+		assert.Equal(t, storage.LastBirthOrder(), storage.BirthOrder(spawned))
+		assert.Greater(t, storage.BirthOrder(spawned), mark)
+		assert.LessOrEqual(t, storage.BirthOrder(second), mark)
+	})
+
+	t.Run("deleted entities", func(t *testing.T) {
+		storage.Delete(second)
+		assert.Equal(t, uint64(0), storage.BirthOrder(second))
+		assert.Equal(t, uint64(0), storage.BirthOrder(ecs.InvalidEntityId))
+	})
+}
+
+func TestBitsetStorage(t *testing.T) {
+	for _, policy := range []ecs.SlotPolicy{ecs.SlotReuseLIFO, ecs.SlotReuseLowest} {
+		registry := newTestRegistry()
+		registry.SetSlotPolicy(policy)
+		// Re-registering replaces the storage kind; Velocity keeps block storage
+		ecs.RegisterComponentWith[Position](registry, ecs.StorageBitset)
+		ecs.RegisterComponentWith[Health](registry, ecs.StorageBitset)
+		storage := ecs.NewStorage(registry)
+
+		ids := make([]ecs.EntityId, 150)
+		for i := range ids {
+			ids[i] = storage.Spawn(Position{X: float32(i)}, Velocity{DX: float32(i)})
+		}
+		for i := 0; i < len(ids); i += 3 {
+			storage.Delete(ids[i])
+		}
+		ref := storage.CreateEntityRef(ids[100])
+		storage.AddComponent(ids[101], Health{Current: 101})
+
+		view := ecs.NewView[struct {
+			*Position
+			*Velocity
+		}](storage)
+		count := 0
+		for item := range view.Iter() {
+			count++
+			assert.Equal(t, item.Position.X, item.Velocity.DX, "storages of an archetype must stay aligned")
+		}
+		assert.Equal(t, 100, count)
+
+		storage.ArchetypeOf(ref.Id).Compact()
+		assert.Equal(t, float32(100), ecs.ReadComponent[Position](storage, ref.Id).X)
+		assert.Equal(t, float32(100), ecs.ReadComponent[Velocity](storage, ref.Id).DX)
+		assert.Equal(t, 1, ecs.NewComponentView[Health](storage).Count())
+		assert.NoError(t, storage.Validate())
+	}
+}
+
+func TestLateRegistration(t *testing.T) {
+	registry := ecs.NewComponentRegistry()
+	ecs.RegisterComponent[Position](registry)
+	storage := ecs.NewStorage(registry)
+
+	id := storage.Spawn(Position{X: 1})
+	archetype := storage.ArchetypeOf(id)
+	mask := append(ecs.ComponentMask(nil), archetype.Mask()...)
+	bit := ecs.ComponentBitOf[Position](registry)
+
+	// Registering new types and re-registering existing ones leaves existing entities alone
+	ecs.RegisterComponent[Velocity](registry)
+	ecs.RegisterComponentWith[Position](registry, ecs.StorageBitset)
+	assert.Equal(t, bit, ecs.ComponentBitOf[Position](registry))
+	assert.Equal(t, mask, archetype.Mask())
+	assert.Equal(t, id.ArchetypeId(), storage.Spawn(Position{X: 2}).ArchetypeId())
+
+	moved := storage.AddComponent(id, Velocity{DX: 3})
+	assert.Equal(t, float32(1), ecs.ReadComponent[Position](storage, moved).X)
+	assert.True(t, storage.ArchetypeOf(moved).Mask().Has(bit))
+	assert.NoError(t, storage.Validate())
+}
+
+func TestIterDynamic(t *testing.T) {
+	registry := ecs.NewComponentRegistry()
+	ecs.RegisterComponent[Position](registry)
+	ecs.RegisterComponent[Velocity](registry)
+	ecs.RegisterComponent[Health](registry)
+	storage := ecs.NewStorage(registry)
+
+	moving := storage.Spawn(Position{X: 1}, Velocity{DX: 2})
+	wounded := storage.Spawn(Position{X: 3}, Velocity{DX: 4}, Health{Current: 5})
+	storage.Spawn(Position{X: 6})
+	storage.SpawnDisabled(Position{X: 7}, Velocity{DX: 8})
+
+	positionType, velocityType := reflect.TypeFor[Position](), reflect.TypeFor[Velocity]()
+	found := map[ecs.EntityId]map[reflect.Type]any{}
+	var order []ecs.EntityId
+	for id, components := range storage.IterDynamic(positionType, velocityType) {
+		found[id] = components
+		order = append(order, id)
+	}
+
+	assert.Len(t, found, 2, "disabled entities and entities missing a type are skipped")
+	assert.Equal(t, &Position{X: 1}, found[moving][positionType])
+	assert.Equal(t, &Velocity{DX: 4}, found[wounded][velocityType])
+	assert.Len(t, found[wounded], 2, "only the requested types are included")
+
+	found[moving][positionType].(*Position).X = 10
+	assert.Equal(t, float32(10), ecs.ReadComponent[Position](storage, moving).X, "components are returned by pointer")
+
+	var again []ecs.EntityId
+	for id := range storage.IterDynamic(positionType, velocityType) {
+		again = append(again, id)
+	}
+	assert.Equal(t, order, again, "the order is stable")
+
+	count := 0
+	for range storage.IterDynamic() {
+		count++
+	}
+	assert.Equal(t, 3, count)
+}
+
+type sparseGrid map[[2]int]uint8
+
+type sharedTarget struct {
+	Hits int
+}
+
+func TestReferenceComponents(t *testing.T) {
+	registry := newTestRegistry()
+	ecs.RegisterReferenceComponent[sparseGrid](registry)
+	ecs.RegisterReferenceComponent[*sharedTarget](registry)
+	storage := ecs.NewStorage(registry)
+
+	t.Run("map components", func(t *testing.T) {
+		grid := sparseGrid{{1, 2}: 3}
+		id := storage.Spawn(Position{}, grid)
+
+		view := ecs.NewView[struct {
+			*Position
+			Grid *sparseGrid
+		}](storage)
+		(*view.Get(id).Grid)[[2]int{4, 5}] = 6
+		assert.Equal(t, uint8(6), grid[[2]int{4, 5}], "the stored map aliases the spawned one")
+
+		id = storage.AddComponent(id, Velocity{})
+		assert.Len(t, *ecs.ReadComponent[sparseGrid](storage, id), 2, "moving the entity keeps the map")
+	})
+
+	t.Run("pointer components", func(t *testing.T) {
+		target := &sharedTarget{}
+		first := storage.Spawn(Position{}, target)
+		second := storage.Spawn(Position{})
+		second = storage.AddComponent(second, target)
+
+		for _, id := range []ecs.EntityId{first, second} {
+			stored := ecs.ReadComponent[*sharedTarget](storage, id)
+			assert.Same(t, target, *stored, "a registered pointer type is the component itself")
+			(*stored).Hits++
+		}
+		assert.Equal(t, 2, target.Hits)
+
+		other := &sharedTarget{Hits: 10}
+		id := storage.Spawn(&other)
+		assert.Same(t, other, *ecs.ReadComponent[*sharedTarget](storage, id), "pointers to the pointer are dereferenced")
+	})
+
+	t.Run("unregistered reference types", func(t *testing.T) {
+		assert.Panics(t, func() { storage.Spawn(map[string]int{}) })
+		assert.Panics(t, func() { storage.Spawn(&Position{}, func() {}) })
+		assert.Panics(t, func() { ecs.RegisterReferenceComponent[Position](registry) }, "value types are not references")
+	})
+}
@@ -0,0 +1,82 @@
+package ecs
+
+// StructuralChangeKind identifies the kind of structural change made to an entity
+type StructuralChangeKind uint8
+
+const (
+	// EntitySpawned is reported after a new entity has been created
+	EntitySpawned StructuralChangeKind = iota
+	// EntityDeleted is reported after an entity has been removed from storage
+	EntityDeleted
+	// EntityMoved is reported after an entity has moved to a different archetype
+	// because a component was added or removed, or has been moved within its archetype
+	// by the scheduler's compaction policy
+	EntityMoved
+)
+
+// StructuralChange describes a single entity being spawned, deleted or moved between
+// archetypes. For EntityMoved, OldId is the id the entity had before the move and Id is
+// its new id; for other kinds OldId is InvalidEntityId.
+//
+// Calling Archetype.Compact directly also reassigns entity ids but is not reported, since it
+// runs outside of the storage; listeners should rebuild their state after compacting. The
+// compactions of Scheduler.SetCompactionPolicy are reported.
+type StructuralChange struct {
+	Kind  StructuralChangeKind
+	Id    EntityId
+	OldId EntityId
+}
+
+// StructuralChangeFunc receives structural change notifications from a storage.
+// It is called synchronously while the storage is being modified, so it must not
+// modify the storage itself.
+type StructuralChangeFunc func(change StructuralChange)
+
+type structuralListener struct {
+	id int
+	fn StructuralChangeFunc
+}
+
+// OnStructuralChange subscribes fn to every spawn, delete and archetype move made to the
+// storage, which lets external caches stay up to date incrementally instead of rebuilding.
+// Listeners are called in subscription order. The returned function removes the subscription.
+func (s *Storage) OnStructuralChange(fn StructuralChangeFunc) func() {
+	s.nextListenerId++
+	id := s.nextListenerId
+	s.structuralListeners = append(s.structuralListeners, structuralListener{id: id, fn: fn})
+
+	return func() {
+		for i, listener := range s.structuralListeners {
+			if listener.id == id {
+				s.structuralListeners = append(s.structuralListeners[:i:i], s.structuralListeners[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// emitStructuralChange notifies all listeners of a structural change
+func (s *Storage) emitStructuralChange(kind StructuralChangeKind, id, oldId EntityId) {
+	if len(s.structuralListeners) == 0 {
+		return
+	}
+
+	change := StructuralChange{Kind: kind, Id: id, OldId: oldId}
+	for _, listener := range s.structuralListeners {
+		listener.fn(change)
+	}
+}
+
+// OnEntityMoved subscribes fn to entities moving between archetypes, which changes their id.
+// This is the subset of OnStructuralChange needed to keep a long-lived cache keyed by entity
+// id valid: instead of rebuilding the cache every frame, re-key the entries of moved entities.
+// Moves made by AddComponent, RemoveComponent, ReplaceComponents, flushed commands and the
+// scheduler's compaction policy are reported; Archetype.Compact is not. The returned function
+// removes the subscription.
+func (s *Storage) OnEntityMoved(fn func(oldId, newId EntityId)) func() {
+	return s.OnStructuralChange(func(change StructuralChange) {
+		if change.Kind == EntityMoved {
+			fn(change.OldId, change.Id)
+		}
+	})
+}
@@ -1,8 +1,30 @@
 package ecs
 
+import "reflect"
+
 // System represents a behavior that operates on entities with specific components.
 // User-defined systems should implement this interface and can include Query fields
 // for accessing entities, as well as custom state fields that persist between frames.
 type System interface {
 	Execute(frame *UpdateFrame)
 }
+
+// PauseExempt can be implemented by systems that must keep executing while the
+// scheduler is paused, such as debug UI, input handling or camera controls.
+type PauseExempt interface {
+	RunsWhilePaused() bool
+}
+
+// NamedSystem can be implemented by systems that want to choose the name shown in
+// scheduler stats instead of their type name. Names are still made unique by the
+// scheduler, so two systems reporting the same name become "Name" and "Name#2".
+type NamedSystem interface {
+	Name() string
+}
+
+// DependentSystem can be implemented by systems that must run after other systems. Dependencies
+// returns the types of those systems, either T or *T for a system registered as *T. Listed
+// types that are never registered are ignored.
+type DependentSystem interface {
+	Dependencies() []reflect.Type
+}
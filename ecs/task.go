@@ -0,0 +1,190 @@
+package ecs
+
+import "runtime"
+
+// TaskFunc is the body of a multi-frame task started with UpdateFrame.StartTask
+type TaskFunc func(ctx TaskCtx)
+
+// Task is a handle to a running multi-frame task
+type Task struct {
+	fn     TaskFunc
+	resume chan *UpdateFrame
+	parked chan struct{}
+
+	frame      *UpdateFrame
+	waitFrames int
+	waitTime   float64
+
+	running   bool
+	done      bool
+	cancelled bool
+	panicked  any
+}
+
+// TaskCtx is passed to a task's body and lets it wait for later frames
+type TaskCtx struct {
+	task *Task
+}
+
+// taskRunner holds the tasks started from a scheduler's frames
+type taskRunner struct {
+	tasks []*Task
+}
+
+// StartTask starts fn as a task that can span several frames, e.g. a build that takes five
+// seconds or a scripted sequence, without keeping timer state in components. fn runs
+// immediately, until it returns or calls one of the TaskCtx wait methods; it is then resumed
+// by the scheduler on the frame its wait ends, after that frame's systems have executed and
+// before its commands are flushed. Use TaskCtx.Frame to reach the current frame's storage and
+// commands, since the frame that started the task is finished by then.
+//
+// Each task runs on its own goroutine, but strictly in turn with the scheduler: control is
+// handed back and forth over unbuffered channels, so only one of them runs at any time and a
+// task may access storage exactly as a system does. Tasks only advance on frames where regular
+// systems execute, so pausing the scheduler pauses them too. Tasks that are waiting resume in
+// the order they were started. A panic in a task is re-raised in the scheduler's goroutine.
+// The goroutine of a task that never finishes is only released once the task is cancelled, so
+// cancel pending tasks with Scheduler.CancelTasks before dropping a scheduler.
+// Panics if the frame was not created by a Scheduler.
+func (f *UpdateFrame) StartTask(fn TaskFunc) *Task {
+	if f.tasks == nil {
+		panic("cannot start a task outside of a scheduler frame")
+	}
+
+	t := &Task{
+		fn:     fn,
+		resume: make(chan *UpdateFrame),
+		parked: make(chan struct{}),
+	}
+	f.tasks.tasks = append(f.tasks.tasks, t)
+	go t.run()
+	t.step(f)
+	return t
+}
+
+// Done reports whether the task has returned or was cancelled
+func (t *Task) Done() bool {
+	return t.done
+}
+
+// Cancel stops the task at its current wait. Deferred calls in the task run before Cancel
+// returns. A task can cancel itself, in which case it stops at its next wait.
+func (t *Task) Cancel() {
+	if t.done || t.cancelled {
+		return
+	}
+	t.cancelled = true
+	if !t.running {
+		t.step(nil)
+	}
+}
+
+// Wait suspends the task until seconds of frame time have passed. It resumes on the first
+// frame where the summed DeltaTime of the frames since the call reaches seconds, and always
+// on a later frame than the current one.
+func (ctx TaskCtx) Wait(seconds float64) {
+	ctx.task.waitFrames = 0
+	ctx.task.waitTime = seconds
+	ctx.task.park()
+}
+
+// WaitFrames suspends the task for n frames, so WaitFrames(1) resumes it on the next frame.
+// Values below 1 are treated as 1.
+func (ctx TaskCtx) WaitFrames(n int) {
+	ctx.task.waitFrames = max(n, 1)
+	ctx.task.waitTime = 0
+	ctx.task.park()
+}
+
+// Frame returns the frame the task is currently running in
+func (ctx TaskCtx) Frame() *UpdateFrame {
+	return ctx.task.frame
+}
+
+// run is the task's goroutine. It waits for the first step before calling the task's body.
+func (t *Task) run() {
+	defer func() {
+		t.panicked = recover()
+		t.done = true
+		t.running = false
+		t.parked <- struct{}{}
+	}()
+
+	t.frame = <-t.resume
+	if t.frame == nil {
+		runtime.Goexit()
+	}
+	t.fn(TaskCtx{task: t})
+}
+
+// step hands control to the task until it waits or finishes. A nil frame cancels it.
+func (t *Task) step(frame *UpdateFrame) {
+	t.running = true
+	t.resume <- frame
+	<-t.parked
+
+	if t.panicked != nil {
+		panicked := t.panicked
+		t.panicked = nil
+		panic(panicked)
+	}
+}
+
+// park hands control back to the scheduler until the task is resumed
+func (t *Task) park() {
+	if t.cancelled {
+		runtime.Goexit()
+	}
+
+	t.running = false
+	t.parked <- struct{}{}
+	t.frame = <-t.resume
+	if t.frame == nil {
+		runtime.Goexit()
+	}
+}
+
+// tick resumes the tasks whose wait ends on frame and drops finished ones. Tasks that started
+// or waited during this frame, including during the tick itself, are not resumed until the next.
+func (r *taskRunner) tick(frame *UpdateFrame) {
+	for i := 0; i < len(r.tasks); i++ {
+		t := r.tasks[i]
+		if t.done || t.frame == frame {
+			continue
+		}
+
+		if t.waitFrames > 0 {
+			t.waitFrames--
+			if t.waitFrames > 0 {
+				continue
+			}
+		} else {
+			t.waitTime -= frame.DeltaTime
+			if t.waitTime > 0 {
+				continue
+			}
+		}
+		t.step(frame)
+	}
+
+	r.tasks = deleteDone(r.tasks)
+}
+
+// cancelAll cancels the pending tasks in the order they were started and drops them
+func (r *taskRunner) cancelAll() {
+	for i := 0; i < len(r.tasks); i++ {
+		r.tasks[i].Cancel()
+	}
+	r.tasks = deleteDone(r.tasks)
+}
+
+func deleteDone(tasks []*Task) []*Task {
+	kept := tasks[:0]
+	for _, t := range tasks {
+		if !t.done {
+			kept = append(kept, t)
+		}
+	}
+	clear(tasks[len(kept):])
+	return kept
+}
@@ -0,0 +1,75 @@
+package ecs
+
+import (
+	"reflect"
+	"sync"
+)
+
+// typeNames maps each type to its canonical name once it has been registered or used
+var typeNames sync.Map // reflect.Type -> string
+
+// typeNamesMu serializes assigning names so two types can't claim the same name
+var typeNamesMu sync.Mutex
+
+// typeNameOwners maps each assigned name back to its type, guarded by typeNamesMu
+var typeNameOwners = map[string]reflect.Type{}
+
+// TypeName returns the canonical name of a component or singleton type. This is the name
+// used to order archetype component types, to key stats and config patches, and to label
+// types in the debug UI. It defaults to reflect.Type.String (e.g. "main.Position") unless a
+// stable name was registered with RegisterTypeName.
+// Archetype ids are derived from these names, so every type must have a distinct one. Panics
+// if the default name of t is already used by another type, as happens for same-named types
+// in two packages with the same name or for function-local types; give one of them a name
+// with RegisterTypeName.
+func TypeName(t reflect.Type) string {
+	if name, ok := typeNames.Load(t); ok {
+		return name.(string)
+	}
+
+	typeNamesMu.Lock()
+	defer typeNamesMu.Unlock()
+
+	if name, ok := typeNames.Load(t); ok {
+		return name.(string)
+	}
+	name := t.String()
+	if other, ok := typeNameOwners[name]; ok {
+		panic("type " + name + " from " + t.PkgPath() + " has the same name as " + other.String() + " from " + other.PkgPath() + ": register a distinct name for one of them with RegisterTypeName")
+	}
+	typeNameOwners[name] = t
+	typeNames.Store(t, name)
+	return name
+}
+
+// RegisterTypeName gives type T a stable name that is used instead of its Go type name, so
+// renaming the type or moving it to another package doesn't change saved data or wire formats
+// keyed by name. Names are global to the program. Register names during initialization,
+// before any storage uses the type, since the name determines how component types are ordered
+// and which archetype ids they get.
+// Panics if T already has a different name, either registered or in use, or if another type
+// already uses the name.
+func RegisterTypeName[T any](name string) {
+	if name == "" {
+		panic("type name cannot be empty")
+	}
+
+	t := reflect.TypeFor[T]()
+
+	typeNamesMu.Lock()
+	defer typeNamesMu.Unlock()
+
+	if existing, ok := typeNames.Load(t); ok {
+		if existing.(string) == name {
+			return
+		}
+		panic("cannot name type " + t.String() + " \"" + name + "\": it is already named \"" + existing.(string) + "\"")
+	}
+
+	if other, ok := typeNameOwners[name]; ok {
+		panic("cannot name type " + t.String() + " \"" + name + "\": the name is used by " + other.String())
+	}
+
+	typeNameOwners[name] = t
+	typeNames.Store(t, name)
+}
@@ -0,0 +1,77 @@
+package ecs_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/plus3/ooftn/ecs"
+	"github.com/stretchr/testify/assert"
+)
+
+type renamedComponent struct {
+	Value int
+}
+
+type defaultNamedComponent struct{}
+
+func TestTypeName(t *testing.T) {
+	ecs.RegisterTypeName[renamedComponent]("game/Stable")
+
+	assert.Equal(t, "ecs_test.Position", ecs.TypeName(reflect.TypeFor[Position]()))
+	assert.Equal(t, "game/Stable", ecs.TypeName(reflect.TypeFor[renamedComponent]()))
+
+	t.Run("used by storage", func(t *testing.T) {
+		registry := newTestRegistry()
+		ecs.RegisterComponent[renamedComponent](registry)
+		storage := ecs.NewStorage(registry)
+
+		storage.Spawn(Position{}, renamedComponent{})
+		stats := storage.CollectStats()
+		assert.Equal(t, []string{"ecs_test.Position", "game/Stable"}, stats.ArchetypeBreakdown[0].ComponentTypes)
+
+		assert.NoError(t, storage.ApplyConfigPatch([]byte(`{"game/Stable": {"Value": 3}}`)))
+		for _, c := range ecs.CollectMap(ecs.NewView[struct{ *renamedComponent }](storage)) {
+			assert.Equal(t, 3, c.renamedComponent.Value)
+		}
+	})
+
+	t.Run("conflicts", func(t *testing.T) {
+		assert.NotPanics(t, func() { ecs.RegisterTypeName[renamedComponent]("game/Stable") })
+		assert.Panics(t, func() { ecs.RegisterTypeName[renamedComponent]("game/Other") })
+		assert.Panics(t, func() { ecs.RegisterTypeName[Velocity]("game/Stable") })
+		assert.Panics(t, func() { ecs.RegisterTypeName[Velocity]("") })
+
+		ecs.TypeName(reflect.TypeFor[defaultNamedComponent]())
+		assert.Panics(t, func() { ecs.RegisterTypeName[defaultNamedComponent]("game/Late") }, "names can't change once used")
+	})
+}
+
+func TestTypeNameDuplicateDefault(t *testing.T) {
+	firstType := func() reflect.Type {
+		type Pos struct{ X int }
+		return reflect.TypeFor[Pos]()
+	}()
+	secondType := func() reflect.Type {
+		type Pos struct{ X int }
+		return reflect.TypeFor[Pos]()
+	}()
+	assert.NotEqual(t, firstType, secondType)
+
+	assert.Equal(t, "ecs_test.Pos", ecs.TypeName(firstType))
+	assert.Panics(t, func() { ecs.TypeName(secondType) }, "distinct types can't share an archetype id")
+
+	t.Run("spawn", func(t *testing.T) {
+		type Local struct{ X int }
+		registry := newTestRegistry()
+		ecs.RegisterComponent[Local](registry)
+		storage := ecs.NewStorage(registry)
+		storage.Spawn(Local{X: 1})
+
+		registerOther := func() {
+			type Local struct{ Y int }
+			ecs.RegisterComponent[Local](registry)
+			storage.Spawn(Local{Y: 2})
+		}
+		assert.Panics(t, registerOther)
+	})
+}
@@ -4,6 +4,18 @@ type UpdateFrame struct {
 	DeltaTime float64
 	Commands  *Commands
 	Storage   *Storage
+
+	// ReadOnly is set for frames of schedulers marked read-only with SetReadOnly, such as
+	// render schedulers. Systems must not modify storage during a read-only frame. In builds
+	// with the ecs_debug tag, queuing a structural command on a read-only frame panics.
+	ReadOnly bool
+
+	storages     map[string]*Storage
+	storageNames []string
+	commands     map[string]*Commands
+	tasks        *taskRunner
+	scheduler    *Scheduler
+	events       *eventBus
 }
 
 func newUpdateFrame(dt float64, storage *Storage) *UpdateFrame {
@@ -13,3 +25,52 @@ func newUpdateFrame(dt float64, storage *Storage) *UpdateFrame {
 		Storage:   storage,
 	}
 }
+
+// StorageNamed returns a named storage registered with the scheduler via AddStorage,
+// or nil if no storage with that name exists.
+func (f *UpdateFrame) StorageNamed(name string) *Storage {
+	return f.storages[name]
+}
+
+// Scheduler returns the scheduler executing the frame, or nil if the frame was not created by
+// a Scheduler. Systems registered through it during the frame start executing on the next frame.
+func (f *UpdateFrame) Scheduler() *Scheduler {
+	return f.scheduler
+}
+
+// Emit queues an event for the systems reading it through an Events field. The event is
+// readable during the next frame, whether the reading systems run before or after the
+// emitting one. Events emitted from RunSystem are readable after the next Once. Panics if
+// the frame was not created by a Scheduler.
+func (f *UpdateFrame) Emit(event any) {
+	if f.events == nil {
+		panic("Emit requires a frame created by a Scheduler")
+	}
+	f.events.emit(event)
+}
+
+// StorageNames returns the names of the storages registered with the scheduler via
+// AddStorage, in the order they were added. The slice must not be modified.
+func (f *UpdateFrame) StorageNames() []string {
+	return f.storageNames
+}
+
+// CommandsFor returns a command buffer for the named storage. The buffer is flushed
+// into that storage at the end of the frame, after the frame's main Commands.
+func (f *UpdateFrame) CommandsFor(name string) *Commands {
+	if _, ok := f.storages[name]; !ok {
+		panic("unknown storage: " + name)
+	}
+
+	commands, ok := f.commands[name]
+	if !ok {
+		if f.commands == nil {
+			f.commands = make(map[string]*Commands)
+		}
+		commands = newCommands()
+		commands.readOnly = f.ReadOnly
+		commands.onError = f.Commands.onError
+		f.commands[name] = commands
+	}
+	return commands
+}
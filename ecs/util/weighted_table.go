@@ -0,0 +1,56 @@
+// Package util contains small gameplay helpers that are commonly needed by games built
+// on the ecs package but don't belong in its core.
+package util
+
+import "sort"
+
+// RandomSource is the subset of math/rand and math/rand/v2's *Rand used by WeightedTable.
+// Passing a seeded generator makes picks deterministic.
+type RandomSource interface {
+	Float64() float64
+}
+
+// WeightedTable picks items at random with a probability proportional to their weight.
+// The zero value is an empty table ready to use.
+type WeightedTable[T any] struct {
+	items      []T
+	cumulative []float64
+}
+
+// Add adds an item with the given weight. Weights are relative, so 3 and 1 give a
+// 75%/25% split. Panics if the weight is not positive.
+func (t *WeightedTable[T]) Add(item T, weight float64) {
+	if !(weight > 0) {
+		panic("weighted table item weight must be positive")
+	}
+
+	t.items = append(t.items, item)
+	t.cumulative = append(t.cumulative, t.Total()+weight)
+}
+
+// Total returns the sum of all weights in the table
+func (t *WeightedTable[T]) Total() float64 {
+	if len(t.cumulative) == 0 {
+		return 0
+	}
+	return t.cumulative[len(t.cumulative)-1]
+}
+
+// Len returns the number of items in the table
+func (t *WeightedTable[T]) Len() int {
+	return len(t.items)
+}
+
+// Pick returns a random item using rng. Panics if the table is empty.
+func (t *WeightedTable[T]) Pick(rng RandomSource) T {
+	if len(t.items) == 0 {
+		panic("cannot pick from an empty weighted table")
+	}
+
+	roll := rng.Float64() * t.Total()
+	idx := sort.Search(len(t.cumulative), func(i int) bool {
+		return t.cumulative[i] > roll
+	})
+	// Guard against floating point rounding pushing the roll onto the upper bound
+	return t.items[min(idx, len(t.items)-1)]
+}
@@ -0,0 +1,59 @@
+package util_test
+
+import (
+	"math/rand/v2"
+	"testing"
+
+	"github.com/plus3/ooftn/ecs/util"
+	"github.com/stretchr/testify/assert"
+)
+
+type fixedRoll float64
+
+func (r fixedRoll) Float64() float64 { return float64(r) }
+
+func TestWeightedTable(t *testing.T) {
+	var table util.WeightedTable[string]
+	table.Add("tree", 5)
+	table.Add("rock", 3)
+	table.Add("bush", 2)
+
+	t.Run("total", func(t *testing.T) {
+		assert.Equal(t, 3, table.Len())
+		assert.Equal(t, 10.0, table.Total())
+	})
+
+	t.Run("boundaries", func(t *testing.T) {
+		assert.Equal(t, "tree", table.Pick(fixedRoll(0)))
+		assert.Equal(t, "tree", table.Pick(fixedRoll(0.49)))
+		assert.Equal(t, "rock", table.Pick(fixedRoll(0.5)))
+		assert.Equal(t, "rock", table.Pick(fixedRoll(0.79)))
+		assert.Equal(t, "bush", table.Pick(fixedRoll(0.8)))
+		assert.Equal(t, "bush", table.Pick(fixedRoll(0.999999)))
+	})
+
+	t.Run("distribution", func(t *testing.T) {
+		rng := rand.New(rand.NewPCG(1, 2))
+		counts := make(map[string]int)
+		for range 10000 {
+			counts[table.Pick(rng)]++
+		}
+		assert.InDelta(t, 5000, counts["tree"], 300)
+		assert.InDelta(t, 3000, counts["rock"], 300)
+		assert.InDelta(t, 2000, counts["bush"], 300)
+	})
+
+	t.Run("deterministic with seeded rng", func(t *testing.T) {
+		a := rand.New(rand.NewPCG(7, 7))
+		b := rand.New(rand.NewPCG(7, 7))
+		for range 100 {
+			assert.Equal(t, table.Pick(a), table.Pick(b))
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		var empty util.WeightedTable[int]
+		assert.Panics(t, func() { empty.Pick(fixedRoll(0)) })
+		assert.Panics(t, func() { empty.Add(1, 0) })
+	})
+}
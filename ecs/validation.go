@@ -0,0 +1,63 @@
+package ecs
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// componentValidator checks a stored component, which is always passed as a *T
+type componentValidator func(component any) error
+
+// RegisterValidator registers a validation callback for component type T. The callback
+// returns an error describing the invalid field, e.g. "Current: must not be negative".
+// Validators only run on storages with validation enabled (see Storage.SetValidation) in
+// builds with the ecs_debug tag, and are invoked whenever a T is written through Spawn,
+// AddComponent, ReplaceComponents, View.Spawn, flushed commands or IterMut2. Several
+// validators may be registered for the same type and run in registration order.
+func RegisterValidator[T any](r *ComponentRegistry, fn func(*T) error) {
+	t := reflect.TypeFor[T]()
+	if r.getFactory(t) == nil {
+		panic("cannot register validator for unregistered component type " + t.String())
+	}
+
+	r.validators[t] = append(r.validators[t], func(component any) error {
+		return fn(component.(*T))
+	})
+}
+
+// SetValidation enables or disables the component validators registered with this storage's
+// registry. A failing validator panics with the entity id, component type and error. Validation
+// is off by default, and validators never run in builds without the ecs_debug tag, so release
+// builds don't pay for the checks.
+func (s *Storage) SetValidation(enabled bool) {
+	s.validation = enabled
+}
+
+// validateComponents runs the registered validators for the given component types of an entity
+func (s *Storage) validateComponents(id EntityId, types ...reflect.Type) {
+	if !debugChecks || !s.validation {
+		return
+	}
+
+	for _, t := range types {
+		validators := s.registry.validators[t]
+		if len(validators) == 0 {
+			continue
+		}
+
+		if err := s.validate(t, s.GetComponent(id, t)); err != nil {
+			panic(fmt.Sprintf("invalid %s on entity %d: %v", t, id, err))
+		}
+	}
+}
+
+// validate runs the registered validators for type t on component, a *t, and returns the
+// first error
+func (s *Storage) validate(t reflect.Type, component any) error {
+	for _, validate := range s.registry.validators[t] {
+		if err := validate(component); err != nil {
+			return err
+		}
+	}
+	return nil
+}
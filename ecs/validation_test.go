@@ -0,0 +1,119 @@
+//go:build ecs_debug
+
+package ecs_test
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/plus3/ooftn/ecs"
+	"github.com/stretchr/testify/assert"
+)
+
+func newValidatedStorage() *ecs.Storage {
+	registry := newTestRegistry()
+	ecs.RegisterValidator(registry, func(h *Health) error {
+		if h.Current < 0 {
+			return errors.New("Current: must not be negative")
+		}
+		return nil
+	})
+	ecs.RegisterValidator(registry, func(p *Position) error {
+		if math.IsNaN(float64(p.X)) || math.IsNaN(float64(p.Y)) {
+			return fmt.Errorf("X/Y: must not be NaN, got (%f, %f)", p.X, p.Y)
+		}
+		return nil
+	})
+
+	storage := ecs.NewStorage(registry)
+	storage.SetValidation(true)
+	return storage
+}
+
+func assertValidationPanic(t *testing.T, contains string, fn func()) {
+	t.Helper()
+	defer func() {
+		t.Helper()
+		r := recover()
+		if r == nil {
+			t.Fatal("expected validation panic")
+		}
+		assert.True(t, strings.Contains(fmt.Sprint(r), contains), "unexpected panic: %v", r)
+	}()
+	fn()
+}
+
+func TestComponentValidation(t *testing.T) {
+	t.Run("valid components pass", func(t *testing.T) {
+		storage := newValidatedStorage()
+		id := storage.Spawn(Position{X: 1}, Health{Current: 10})
+		storage.AddComponent(id, Velocity{})
+	})
+
+	t.Run("spawn", func(t *testing.T) {
+		storage := newValidatedStorage()
+		assertValidationPanic(t, "Current: must not be negative", func() {
+			storage.Spawn(Health{Current: -1})
+		})
+	})
+
+	t.Run("add component", func(t *testing.T) {
+		storage := newValidatedStorage()
+		id := storage.Spawn(Velocity{})
+		assertValidationPanic(t, "ecs_test.Health on entity", func() {
+			storage.AddComponent(id, Health{Current: -5})
+		})
+	})
+
+	t.Run("command flush", func(t *testing.T) {
+		storage := newValidatedStorage()
+		id := storage.Spawn(Velocity{})
+
+		commands := &ecs.Commands{}
+		commands.AddComponent(id, Position{X: float32(math.NaN())})
+		assertValidationPanic(t, "must not be NaN", func() {
+			commands.Flush(storage)
+		})
+	})
+
+	t.Run("iter mut", func(t *testing.T) {
+		storage := newValidatedStorage()
+		storage.Spawn(Position{}, Velocity{})
+
+		assertValidationPanic(t, "must not be NaN", func() {
+			ecs.IterMut2(storage, func(id ecs.EntityId, pos *Position, vel *Velocity) {
+				pos.X = pos.X / 0 * 0
+			})
+		})
+	})
+
+	t.Run("config patch", func(t *testing.T) {
+		storage := newValidatedStorage()
+		id := storage.Spawn(Position{X: 1}, Health{Current: 10})
+		storage.Spawn(Health{Current: 20})
+
+		err := storage.ApplyConfigPatch([]byte(`{"Position": {"X": 2}, "Health": {"Current": -1}}`))
+		assert.ErrorContains(t, err, "Current: must not be negative")
+		assert.Equal(t, Position{X: 1}, *ecs.ReadComponent[Position](storage, id), "nothing is patched")
+		assert.Equal(t, 10, ecs.ReadComponent[Health](storage, id).Current)
+
+		assert.NoError(t, storage.ApplyConfigPatch([]byte(`{"Position": {"X": 2}, "Health": {"Current": 5}}`)))
+		assert.Equal(t, Position{X: 2}, *ecs.ReadComponent[Position](storage, id))
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		storage := newValidatedStorage()
+		storage.SetValidation(false)
+		storage.Spawn(Health{Current: -1})
+	})
+
+	t.Run("unregistered type", func(t *testing.T) {
+		type unregistered struct{}
+		assert.Panics(t, func() {
+			ecs.RegisterValidator(ecs.NewComponentRegistry(), func(*unregistered) error { return nil })
+		})
+	})
+}
@@ -3,6 +3,8 @@ package ecs
 import (
 	"iter"
 	"reflect"
+	"strconv"
+	"strings"
 	"unsafe"
 
 	"golang.org/x/tools/container/intsets"
@@ -11,6 +13,8 @@ import (
 // View represents a query for entities with a specific combination of components
 // The type T should be a struct with embedded pointer fields for each component type
 // Named fields can be marked as optional using the `ecs:"optional"` struct tag
+// Named fields can be populated from a related entity using the `ecs:"via=Field.RefField"` struct tag
+// Named fields can address a keyed component using the `ecs:"key=Name"` struct tag
 type View[T any] struct {
 	storage *Storage
 	types   []reflect.Type
@@ -19,22 +23,152 @@ type View[T any] struct {
 
 	optional    []bool
 	fieldOffset []uintptr
+	viaFields   []viaField
+	keyedFields []keyedField
+	// indexedTypes holds types followed by the Keyed types of keyedFields, the component types
+	// whose storage indices are cached per archetype
+	indexedTypes []reflect.Type
+
+	addedFilters   []reflect.Type
+	removedFilters []removedField
+	// changes keeps the storage tracking structural changes while the view exists
+	changes *changeUser
+
+	// lodOffset is the offset of the *LODLevel field tagged `ecs:"lod<=N"`, and maxLOD is N
+	lodOffset *uintptr
+	maxLOD    int
 
 	entityIdFieldOffset *uintptr
 
-	cachedArchetypeId   *uint32
 	cachedSortedTypes   []reflect.Type
 	cachedSortedIndices []int
 	cachedRequiredCount int
 	cachedArchetype     *Archetype
 
 	storageIndicesCache map[uint32][]int
+	lastArchetype       *Archetype
+	lastStorageIndices  []int
+
+	matchingCache []*Archetype
+	matchingCount int
+}
+
+// viaField describes a view field that is populated from the entity referenced by
+// an *EntityRef field on another component in the view.
+type viaField struct {
+	componentType reflect.Type
+	fieldOffset   uintptr
+	sourceIndex   int
+	refOffset     uintptr
+	optional      bool
+}
+
+// keyedField describes a view field tagged `ecs:"key=Name"`, populated with the component
+// stored under key in the entity's Keyed component of the field's type
+type keyedField struct {
+	keyedType   reflect.Type
+	key         string
+	fieldOffset uintptr
+	optional    bool
+}
+
+// removedField describes a view field tagged `ecs:"removed"`. The field is always nil
+// because the component is no longer present on the entity.
+type removedField struct {
+	componentType reflect.Type
+	fieldOffset   uintptr
+}
+
+// viewTag is the parsed form of an `ecs:"..."` struct tag
+type viewTag struct {
+	optional bool
+	added    bool
+	removed  bool
+	via      string
+	key      string
+	// maxLOD is the level of a `lod<=N` value, or -1
+	maxLOD int
+}
+
+// parseViewTag parses a comma separated `ecs` struct tag
+func parseViewTag(tag string) viewTag {
+	parsed := viewTag{maxLOD: -1}
+	if tag == "" {
+		return parsed
+	}
+
+	for _, part := range strings.Split(tag, ",") {
+		switch {
+		case part == "optional":
+			parsed.optional = true
+		case part == "added":
+			parsed.added = true
+		case part == "removed":
+			parsed.removed = true
+		case strings.HasPrefix(part, "via="):
+			parsed.via = strings.TrimPrefix(part, "via=")
+		case strings.HasPrefix(part, "key="):
+			parsed.key = strings.TrimPrefix(part, "key=")
+			if parsed.key == "" {
+				panic("invalid ecs tag value: \"" + tag + "\" (expected \"key=Name\" with a non-empty Name)")
+			}
+		case strings.HasPrefix(part, "lod<="):
+			level, err := strconv.Atoi(strings.TrimPrefix(part, "lod<="))
+			if err != nil || level < 0 {
+				panic("invalid ecs tag value: \"" + tag + "\" (expected \"lod<=N\" with N a level of at least 0)")
+			}
+			parsed.maxLOD = level
+		default:
+			panic("invalid ecs tag value: \"" + tag + "\" (supported: \"optional\", \"added\", \"removed\", \"via=Field.RefField\", \"key=Name\", \"lod<=N\")")
+		}
+	}
+
+	if parsed.key != "" && (parsed.added || parsed.removed || parsed.via != "") {
+		panic("invalid ecs tag value: \"" + tag + "\" (\"key=Name\" can only be combined with \"optional\")")
+	}
+
+	if parsed.maxLOD >= 0 && (parsed.added || parsed.removed || parsed.via != "" || parsed.key != "") {
+		panic("invalid ecs tag value: \"" + tag + "\" (\"lod<=N\" can only be combined with \"optional\")")
+	}
+
+	if (parsed.added || parsed.removed) && (parsed.optional || parsed.via != "" || parsed.added == parsed.removed) {
+		panic("invalid ecs tag value: \"" + tag + "\" (\"added\" and \"removed\" cannot be combined with other values)")
+	}
+	return parsed
 }
 
 // NewView creates a new view for the given struct type
 // The struct T should have embedded or named fields that are pointers to component types
 // Embedded fields are always required
 // Named fields can be marked as optional using the `ecs:"optional"` struct tag
+//
+// Embedded struct values (not pointers) are flattened recursively, so a shared set of
+// component fields such as `type Creature struct{ *Position; *Stats }` can be embedded in
+// many view structs. Tags on the fields inside the embedded struct are respected.
+//
+// Named fields tagged with `ecs:"via=Source.Ref"` are populated from the entity referenced
+// by the *EntityRef field Ref of the view's Source component field, rather than from the
+// entity itself. If the ref is nil, dead, or the referenced entity lacks the component, the
+// entity is skipped; combine with optional (`ecs:"via=Source.Ref,optional"`) to nil the
+// field instead.
+//
+// Named fields tagged with `ecs:"added"` only match entities that gained that component
+// during the storage's last command flush. Fields tagged with `ecs:"removed"` only match
+// entities that lost the component during the last flush; the field itself is always nil.
+//
+// Named *C fields tagged with `ecs:"key=Name"` are populated with the component stored under
+// key Name in the entity's Keyed[C], see RegisterKeyedComponent. Entities without a component
+// under that key are skipped; combine with optional (`ecs:"key=Name,optional"`) to nil the
+// field instead. Several fields may address different keys of the same Keyed[C]:
+//
+//	type armed struct {
+//		*Position
+//		Left  *Weapon `ecs:"key=left"`
+//		Right *Weapon `ecs:"key=right,optional"`
+//	}
+//
+// A named *LODLevel field tagged `ecs:"lod<=N"` skips entities whose level of detail is above
+// N. The field is optional, since entities without a LODLevel count as level 0. See LODLevel.
 func NewView[T any](storage *Storage) *View[T] {
 	var zero T
 	structType := reflect.TypeOf(zero)
@@ -50,8 +184,19 @@ func NewView[T any](storage *Storage) *View[T] {
 
 	var entityIdFieldOffset *uintptr
 
-	for i := 0; i < structType.NumField(); i++ {
-		field := structType.Field(i)
+	type pendingVia struct {
+		field reflect.StructField
+		tag   viewTag
+	}
+	var pendingVias []pendingVia
+	var keyedFields []keyedField
+	var addedFilters []reflect.Type
+	var removedFilters []removedField
+	var lodOffset *uintptr
+	maxLOD := -1
+	fieldIndexByName := make(map[string]int)
+
+	for _, field := range flattenViewFields(structType, 0) {
 		fieldType := field.Type
 
 		if fieldType == reflect.TypeOf(EntityId(0)) {
@@ -64,29 +209,92 @@ func NewView[T any](storage *Storage) *View[T] {
 			panic("View struct fields must be pointer types")
 		}
 
-		componentType := fieldType.Elem()
-		types = append(types, componentType)
-		fieldOffset = append(fieldOffset, field.Offset)
-
 		// Parse struct tag to check if component is optional
 		// Embedded fields (field.Anonymous) are always required
-		isOptional := false
+		tag := viewTag{maxLOD: -1}
 		if !field.Anonymous {
-			tag := field.Tag.Get("ecs")
-			if tag != "" {
-				if tag == "optional" {
-					isOptional = true
-				} else {
-					panic("invalid ecs tag value: \"" + tag + "\" (only \"optional\" is supported)")
-				}
+			tag = parseViewTag(field.Tag.Get("ecs"))
+		}
+
+		if tag.via != "" {
+			pendingVias = append(pendingVias, pendingVia{field: field, tag: tag})
+			continue
+		}
+
+		if tag.key != "" {
+			keyedType := storage.registry.keyedTypeFor(fieldType.Elem())
+			keyedFields = append(keyedFields, keyedField{
+				keyedType:   keyedType,
+				key:         tag.key,
+				fieldOffset: field.Offset,
+				optional:    tag.optional,
+			})
+			if !tag.optional {
+				typeSet.Insert(typeId(keyedType))
+			}
+			continue
+		}
+
+		if tag.removed {
+			removedFilters = append(removedFilters, removedField{
+				componentType: fieldType.Elem(),
+				fieldOffset:   field.Offset,
+			})
+			continue
+		}
+
+		if tag.added {
+			addedFilters = append(addedFilters, fieldType.Elem())
+		}
+
+		if tag.maxLOD >= 0 {
+			if fieldType.Elem() != lodLevelType {
+				panic("ecs lod tag must be on a *LODLevel field: " + field.Name)
+			}
+			if lodOffset != nil {
+				panic("view has more than one field tagged ecs:\"lod<=N\": " + field.Name)
 			}
+			offset := field.Offset
+			lodOffset, maxLOD = &offset, tag.maxLOD
+			tag.optional = true
 		}
 
-		if !isOptional {
+		componentType := fieldType.Elem()
+		fieldIndexByName[field.Name] = len(types)
+		types = append(types, componentType)
+		fieldOffset = append(fieldOffset, field.Offset)
+
+		if !tag.optional {
 			typeSet.Insert(typeId(componentType))
 		}
 
-		optional = append(optional, isOptional)
+		optional = append(optional, tag.optional)
+	}
+
+	viaFields := make([]viaField, 0, len(pendingVias))
+	for _, pending := range pendingVias {
+		sourceName, refName, ok := strings.Cut(pending.tag.via, ".")
+		if !ok {
+			panic("invalid ecs via tag: \"" + pending.tag.via + "\" (expected \"Field.RefField\")")
+		}
+
+		sourceIndex, ok := fieldIndexByName[sourceName]
+		if !ok {
+			panic("ecs via tag references unknown view field: " + sourceName)
+		}
+
+		refField, ok := types[sourceIndex].FieldByName(refName)
+		if !ok || refField.Type != reflect.TypeOf((*EntityRef)(nil)) {
+			panic("ecs via tag must reference an *EntityRef field: " + pending.tag.via)
+		}
+
+		viaFields = append(viaFields, viaField{
+			componentType: pending.field.Type.Elem(),
+			fieldOffset:   pending.field.Offset,
+			sourceIndex:   sourceIndex,
+			refOffset:     refField.Offset,
+			optional:      pending.tag.optional,
+		})
 	}
 
 	requiredCount := 0
@@ -103,7 +311,7 @@ func NewView[T any](storage *Storage) *View[T] {
 
 	for i := range sortedIndices {
 		for j := i + 1; j < len(sortedIndices); j++ {
-			if types[sortedIndices[i]].String() > types[sortedIndices[j]].String() {
+			if TypeName(types[sortedIndices[i]]) > TypeName(types[sortedIndices[j]]) {
 				sortedIndices[i], sortedIndices[j] = sortedIndices[j], sortedIndices[i]
 			}
 		}
@@ -114,69 +322,112 @@ func NewView[T any](storage *Storage) *View[T] {
 		sortedTypes[i] = types[idx]
 	}
 
+	var changes *changeUser
+	if len(addedFilters) > 0 || len(removedFilters) > 0 {
+		changes = &changeUser{}
+		storage.trackChanges(changes)
+	}
+
+	indexedTypes := types[:len(types):len(types)]
+	for _, keyed := range keyedFields {
+		indexedTypes = append(indexedTypes, keyed.keyedType)
+	}
+
 	return &View[T]{
 		storage:             storage,
 		types:               types,
 		typeSet:             typeSet,
 		optional:            optional,
 		fieldOffset:         fieldOffset,
+		viaFields:           viaFields,
+		keyedFields:         keyedFields,
+		indexedTypes:        indexedTypes,
+		addedFilters:        addedFilters,
+		removedFilters:      removedFilters,
+		changes:             changes,
+		lodOffset:           lodOffset,
+		maxLOD:              maxLOD,
 		entityIdFieldOffset: entityIdFieldOffset,
 		cachedSortedIndices: sortedIndices,
 		cachedSortedTypes:   sortedTypes,
 		cachedRequiredCount: requiredCount,
 		storageIndicesCache: make(map[uint32][]int),
+		matchingCount:       -1,
 	}
 }
 
+// flattenViewFields returns the fields of a view struct with embedded (non-pointer) struct
+// fields expanded recursively, so shared component sets can be composed into many views.
+// Offsets of the returned fields are relative to the outermost struct.
+func flattenViewFields(structType reflect.Type, baseOffset uintptr) []reflect.StructField {
+	fields := make([]reflect.StructField, 0, structType.NumField())
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		field.Offset += baseOffset
+
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			fields = append(fields, flattenViewFields(field.Type, field.Offset)...)
+			continue
+		}
+		fields = append(fields, field)
+	}
+	return fields
+}
+
 // Fill populates the provided struct pointer with component data for the given entity
 // Returns false if the entity is missing any required components
 // Optional components are set to nil if not present
 func (v *View[T]) Fill(id EntityId, ptr *T) bool {
-	archetypeId := id.ArchetypeId()
-	archetype, ok := v.storage.archetypes[archetypeId]
+	archetype, ok := v.storage.archetypes[id.ArchetypeId()]
 	if !ok {
 		return false
 	}
 
-	storageIndices, ok := v.storageIndicesCache[archetypeId]
-	if !ok {
-		storageIndices = v.buildStorageIndices(archetype)
-		v.storageIndicesCache[archetypeId] = storageIndices
-	}
-
-	structPtr := unsafe.Pointer(ptr)
-	entityIndex := int(id.Index())
+	return v.populateResult(unsafe.Pointer(ptr), archetype, int(id.Index()), v.storageIndicesFor(archetype), id)
+}
 
-	for i := 0; i < len(v.types); i++ {
-		fieldPtr := unsafe.Pointer(uintptr(structPtr) + v.fieldOffset[i])
+// FillInArchetype populates the provided struct pointer with the components at index in
+// the given archetype. It skips the archetype lookup Fill performs, which suits custom
+// loops that walk an archetype directly. The caller must guarantee that index refers to
+// a live entity in the archetype. Returns false if the archetype lacks a required component.
+func (v *View[T]) FillInArchetype(archetype *Archetype, index int, ptr *T) bool {
+	return v.populateResult(unsafe.Pointer(ptr), archetype, index, v.storageIndicesFor(archetype), NewEntityId(archetype.id, uint32(index)))
+}
 
-		storageIdx := storageIndices[i]
-		if storageIdx == -1 {
-			if !v.optional[i] {
-				return false
-			}
-			*(*unsafe.Pointer)(fieldPtr) = nil
-			continue
-		}
+// matchingArchetypes returns the archetypes that have all of the view's required components.
+// Archetypes are never removed, so the cache only needs rebuilding when new ones were created.
+// A rebuilt cache is a new slice, leaving the old one intact for iterations still using it.
+func (v *View[T]) matchingArchetypes() []*Archetype {
+	if len(v.storage.archetypes) == v.matchingCount {
+		return v.matchingCache
+	}
 
-		component := archetype.storages[storageIdx].Get(entityIndex)
-		if component == nil {
-			if !v.optional[i] {
-				return false
-			}
-			*(*unsafe.Pointer)(fieldPtr) = nil
-		} else {
-			componentPtr := (*iface)(unsafe.Pointer(&component)).data
-			*(*unsafe.Pointer)(fieldPtr) = componentPtr
+	matching := make([]*Archetype, 0, len(v.matchingCache)+1)
+	for _, archetype := range v.storage.archetypeOrder {
+		if v.matchesArchetype(archetype) && len(archetype.storages) > 0 {
+			matching = append(matching, archetype)
 		}
 	}
+	v.matchingCache = matching
+	v.matchingCount = len(v.storage.archetypes)
+	return v.matchingCache
+}
 
-	if v.entityIdFieldOffset != nil {
-		entityIdPtr := (*EntityId)(unsafe.Pointer(uintptr(structPtr) + *v.entityIdFieldOffset))
-		*entityIdPtr = id
+// storageIndicesFor returns the cached storage indices for an archetype. The most recently
+// used archetype is remembered so repeated fills within one archetype skip the map lookup.
+func (v *View[T]) storageIndicesFor(archetype *Archetype) []int {
+	if archetype == v.lastArchetype {
+		return v.lastStorageIndices
 	}
 
-	return true
+	storageIndices, ok := v.storageIndicesCache[archetype.id]
+	if !ok {
+		storageIndices = v.buildStorageIndices(archetype)
+		v.storageIndicesCache[archetype.id] = storageIndices
+	}
+	v.lastArchetype = archetype
+	v.lastStorageIndices = storageIndices
+	return storageIndices
 }
 
 // Get returns a populated view struct for the given entity, or nil if the entity
@@ -210,8 +461,13 @@ func (v *View[T]) matchesArchetype(archetype *Archetype) bool {
 }
 
 func (v *View[T]) buildStorageIndices(archetype *Archetype) []int {
-	storageIndices := make([]int, len(v.types))
-	for i, componentType := range v.types {
+	return buildStorageIndices(archetype, v.indexedTypes)
+}
+
+// buildStorageIndices maps each component type to its storage index in the archetype, or -1 if absent
+func buildStorageIndices(archetype *Archetype, types []reflect.Type) []int {
+	storageIndices := make([]int, len(types))
+	for i, componentType := range types {
 		storageIndices[i] = -1
 		for idx, archetypeType := range archetype.types {
 			if archetypeType == componentType {
@@ -224,7 +480,7 @@ func (v *View[T]) buildStorageIndices(archetype *Archetype) []int {
 }
 
 func (v *View[T]) populateResult(resultPtr unsafe.Pointer, archetype *Archetype, entityIndex int, storageIndices []int, entityId EntityId) bool {
-	for i, storageIdx := range storageIndices {
+	for i, storageIdx := range storageIndices[:len(v.types)] {
 		fieldPtr := unsafe.Pointer(uintptr(resultPtr) + v.fieldOffset[i])
 
 		if storageIdx == -1 {
@@ -248,6 +504,24 @@ func (v *View[T]) populateResult(resultPtr unsafe.Pointer, archetype *Archetype,
 		*(*unsafe.Pointer)(fieldPtr) = componentPtr
 	}
 
+	if v.lodOffset != nil {
+		if lod := *(**LODLevel)(unsafe.Add(resultPtr, *v.lodOffset)); lod != nil && lod.Level > v.maxLOD {
+			return false
+		}
+	}
+
+	if (len(v.addedFilters) > 0 || len(v.removedFilters) > 0) && !v.matchesChanges(resultPtr, entityId) {
+		return false
+	}
+
+	if len(v.keyedFields) > 0 && !v.populateKeyed(resultPtr, archetype, entityIndex, storageIndices[len(v.types):]) {
+		return false
+	}
+
+	if len(v.viaFields) > 0 && !v.populateVia(resultPtr) {
+		return false
+	}
+
 	if v.entityIdFieldOffset != nil {
 		entityIdPtr := (*EntityId)(unsafe.Pointer(uintptr(resultPtr) + *v.entityIdFieldOffset))
 		*entityIdPtr = entityId
@@ -256,38 +530,105 @@ func (v *View[T]) populateResult(resultPtr unsafe.Pointer, archetype *Archetype,
 	return true
 }
 
+// matchesChanges checks the entity against the view's `added` and `removed` filters
+// and clears the removed fields. Returns false if any filter does not match.
+func (v *View[T]) matchesChanges(resultPtr unsafe.Pointer, id EntityId) bool {
+	for _, componentType := range v.addedFilters {
+		if !v.storage.wasAdded(id, componentType) {
+			return false
+		}
+	}
+
+	for _, removed := range v.removedFilters {
+		if !v.storage.wasRemoved(id, removed.componentType) {
+			return false
+		}
+		*(*unsafe.Pointer)(unsafe.Pointer(uintptr(resultPtr) + removed.fieldOffset)) = nil
+	}
+	return true
+}
+
+// populateKeyed resolves the `key` fields of a view struct from the entity's Keyed components,
+// whose storage indices are given in the order of the fields. Returns false if a required
+// keyed field has no component under its key.
+func (v *View[T]) populateKeyed(resultPtr unsafe.Pointer, archetype *Archetype, entityIndex int, storageIndices []int) bool {
+	for i, keyed := range v.keyedFields {
+		fieldPtr := unsafe.Pointer(uintptr(resultPtr) + keyed.fieldOffset)
+
+		var componentPtr unsafe.Pointer
+		if storageIdx := storageIndices[i]; storageIdx != -1 {
+			if component, ok := archetype.storages[storageIdx].Get(entityIndex).(keyedComponent); ok {
+				componentPtr = component.pointer(keyed.key)
+			}
+		}
+
+		if componentPtr == nil && !keyed.optional {
+			return false
+		}
+		*(*unsafe.Pointer)(fieldPtr) = componentPtr
+	}
+	return true
+}
+
+// populateVia resolves the `via` fields of an already populated view struct
+// Returns false if a required via field could not be resolved
+func (v *View[T]) populateVia(resultPtr unsafe.Pointer) bool {
+	for _, via := range v.viaFields {
+		fieldPtr := unsafe.Pointer(uintptr(resultPtr) + via.fieldOffset)
+
+		var component any
+		sourcePtr := *(*unsafe.Pointer)(unsafe.Pointer(uintptr(resultPtr) + v.fieldOffset[via.sourceIndex]))
+		if sourcePtr != nil {
+			ref := *(**EntityRef)(unsafe.Pointer(uintptr(sourcePtr) + via.refOffset))
+			if targetId, ok := v.storage.ResolveEntityRef(ref); ok {
+				component = v.storage.GetComponent(targetId, via.componentType)
+			}
+		}
+
+		if component == nil {
+			if !via.optional {
+				return false
+			}
+			*(*unsafe.Pointer)(fieldPtr) = nil
+			continue
+		}
+
+		*(*unsafe.Pointer)(fieldPtr) = (*iface)(unsafe.Pointer(&component)).data
+	}
+	return true
+}
+
 // Iter returns an iterator over all entities that have all the required components for this view
 // The iterator yields T where T is the populated view struct
 // Optional components are set to nil if not present
+// Archetypes are visited ordered by the TypeNames of their component types and their entities
+// in ascending slot order, so a program that makes the same changes to a storage visits its
+// entities in the same order in every run, unlike archetype ids which differ between runs
 func (v *View[T]) Iter() iter.Seq[T] {
 	return func(yield func(T) bool) {
-		for archetypeId, archetype := range v.storage.archetypes {
-			if !v.matchesArchetype(archetype) {
-				continue
-			}
-
-			if len(archetype.storages) == 0 {
-				continue
-			}
-
-			storageIndices, ok := v.storageIndicesCache[archetypeId]
-			if !ok {
-				storageIndices = v.buildStorageIndices(archetype)
-				v.storageIndicesCache[archetypeId] = storageIndices
+		for _, item := range v.iterEntities() {
+			if !yield(item) {
+				return
 			}
+		}
+	}
+}
 
-			firstStorage := archetype.storages[0]
+func (v *View[T]) iterEntities() iter.Seq2[EntityId, T] {
+	return func(yield func(EntityId, T) bool) {
+		for _, archetype := range v.matchingArchetypes() {
+			storageIndices := v.storageIndicesFor(archetype)
 
 			var result T
 			resultPtr := unsafe.Pointer(&result)
 
-			for entityIndex := range firstStorage.Iter() {
-				entityId := NewEntityId(archetypeId, uint32(entityIndex))
+			for entityIndex := range archetype.enabledIndices() {
+				entityId := NewEntityId(archetype.id, uint32(entityIndex))
 				if !v.populateResult(resultPtr, archetype, entityIndex, storageIndices, entityId) {
 					continue
 				}
 
-				if !yield(result) {
+				if !yield(entityId, result) {
 					return
 				}
 			}
@@ -295,6 +636,106 @@ func (v *View[T]) Iter() iter.Seq[T] {
 	}
 }
 
+func (v *View[T]) lenHint() int {
+	total := 0
+	for _, archetype := range v.matchingArchetypes() {
+		total += archetype.Len()
+	}
+	return total
+}
+
+// Count returns the number of entities Iter would yield. It sums the entity counts of the
+// matching archetypes without visiting their entities, unless the view has fields tagged
+// `added`, `removed`, `lod<=N` or a required `via` or `key`, whose filters are checked per
+// entity.
+func (v *View[T]) Count() int {
+	if v.filtersEntities() {
+		count := 0
+		for range v.iterEntities() {
+			count++
+		}
+		return count
+	}
+
+	count := 0
+	for _, archetype := range v.matchingArchetypes() {
+		count += archetype.Len() - archetype.disabledCount
+	}
+	return count
+}
+
+// filtersEntities reports whether the view can skip entities of a matching archetype
+func (v *View[T]) filtersEntities() bool {
+	if v.lodOffset != nil || len(v.addedFilters) > 0 || len(v.removedFilters) > 0 {
+		return true
+	}
+	for _, via := range v.viaFields {
+		if !via.optional {
+			return true
+		}
+	}
+	for _, keyed := range v.keyedFields {
+		if !keyed.optional {
+			return true
+		}
+	}
+	return false
+}
+
+// IterArchetype returns an iterator over the entities of a single archetype, paired with their ids.
+// This avoids scanning every archetype when the caller already knows where the entities live.
+// Nothing is yielded if the archetype doesn't exist or lacks the view's required components.
+func (v *View[T]) IterArchetype(archetypeId uint32) iter.Seq2[EntityId, T] {
+	archetype, ok := v.storage.archetypes[archetypeId]
+	if !ok || !v.matchesArchetype(archetype) {
+		return func(func(EntityId, T) bool) {}
+	}
+	return v.iterArchetype(archetype)
+}
+
+// IterByArchetype returns an iterator over the matching archetypes that hold entities, each
+// paired with an iterator over its entities. This lets a system set up per-archetype state once,
+// e.g. look up columns or precompute values, before processing the archetype's entities, and lets
+// tools show which archetypes results came from. The entity iterator may yield nothing if every
+// entity of the archetype is disabled or filtered out.
+func (v *View[T]) IterByArchetype() iter.Seq2[*Archetype, iter.Seq2[EntityId, T]] {
+	return func(yield func(*Archetype, iter.Seq2[EntityId, T]) bool) {
+		for _, archetype := range v.matchingArchetypes() {
+			if archetype.Len() == 0 {
+				continue
+			}
+			if !yield(archetype, v.iterArchetype(archetype)) {
+				return
+			}
+		}
+	}
+}
+
+// iterArchetype iterates the entities of an archetype known to match the view
+func (v *View[T]) iterArchetype(archetype *Archetype) iter.Seq2[EntityId, T] {
+	return func(yield func(EntityId, T) bool) {
+		if len(archetype.storages) == 0 {
+			return
+		}
+
+		storageIndices := v.storageIndicesFor(archetype)
+
+		var result T
+		resultPtr := unsafe.Pointer(&result)
+
+		for entityIndex := range archetype.enabledIndices() {
+			entityId := NewEntityId(archetype.id, uint32(entityIndex))
+			if !v.populateResult(resultPtr, archetype, entityIndex, storageIndices, entityId) {
+				continue
+			}
+
+			if !yield(entityId, result) {
+				return
+			}
+		}
+	}
+}
+
 // Spawn creates a new entity with components extracted from the view struct
 func (v *View[T]) Spawn(data T) EntityId {
 	structPtr := unsafe.Pointer(&data)
@@ -329,8 +770,7 @@ func (v *View[T]) Spawn(data T) EntityId {
 			components[i] = component
 		}
 
-		entityIndex := v.cachedArchetype.Spawn(components)
-		return NewEntityId(*v.cachedArchetypeId, entityIndex)
+		return v.storage.finishSpawn(v.cachedArchetype, v.cachedArchetype.Spawn(components), v.cachedSortedTypes)
 	}
 
 	components := make([]any, 0, componentCount)
@@ -364,22 +804,11 @@ func (v *View[T]) Spawn(data T) EntityId {
 		}
 	}
 
-	archetypeId := hashTypesToUint32(sortedTypes)
-
-	if allRequired {
-		v.cachedArchetypeId = &archetypeId
-	}
-
-	archetype, exists := v.storage.archetypes[archetypeId]
-	if !exists {
-		archetype = NewArchetype(archetypeId, sortedTypes, v.storage.registry)
-		v.storage.archetypes[archetypeId] = archetype
-	}
+	archetype := v.storage.getOrCreateArchetype(hashTypesToUint32(sortedTypes), sortedTypes)
 
 	if allRequired {
 		v.cachedArchetype = archetype
 	}
 
-	entityIndex := archetype.Spawn(sortedComponents)
-	return NewEntityId(archetypeId, entityIndex)
+	return v.storage.finishSpawn(archetype, archetype.Spawn(sortedComponents), sortedTypes)
 }
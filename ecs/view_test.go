@@ -2,6 +2,7 @@ package ecs_test
 
 import (
 	"reflect"
+	"sync"
 	"testing"
 
 	"github.com/plus3/ooftn/ecs"
@@ -108,6 +109,35 @@ func TestViewFillMissingComponent(t *testing.T) {
 	assert.False(t, ok)
 }
 
+func TestViewFillInArchetype(t *testing.T) {
+	storage := ecs.NewStorage(newTestRegistry())
+	for i := range 5 {
+		storage.Spawn(Position{X: float32(i)}, Health{Current: i})
+	}
+	storage.Spawn(Position{X: 100})
+
+	type positionHealth struct {
+		Id ecs.EntityId
+		*Position
+		*Health
+	}
+	view := ecs.NewView[positionHealth](storage)
+
+	archetype := storage.GetArchetype(Position{}, Health{})
+	count := 0
+	for id := range archetype.Iter() {
+		var result positionHealth
+		assert.True(t, view.FillInArchetype(archetype, int(id.Index()), &result))
+		assert.Equal(t, id, result.Id)
+		assert.Equal(t, float32(result.Health.Current), result.Position.X)
+		count++
+	}
+	assert.Equal(t, 5, count)
+
+	var result positionHealth
+	assert.False(t, view.FillInArchetype(storage.GetArchetype(Position{}), 0, &result))
+}
+
 func TestViewComponentMutation(t *testing.T) {
 
 	storage := ecs.NewStorage(newTestRegistry())
@@ -311,6 +341,215 @@ func TestViewIterMultipleArchetypes(t *testing.T) {
 	assert.True(t, entities[id4])
 }
 
+func TestViewIterArchetype(t *testing.T) {
+
+	storage := ecs.NewStorage(newTestRegistry())
+
+	id1 := storage.Spawn(&Position{X: 1, Y: 1}, &Velocity{DX: 0.1, DY: 0.1})
+	id2 := storage.Spawn(&Position{X: 2, Y: 2}, &Velocity{DX: 0.2, DY: 0.2})
+	named := storage.Spawn(&Position{X: 3, Y: 3}, &Velocity{DX: 0.3, DY: 0.3}, Name("Entity3"))
+	positionOnly := storage.Spawn(&Position{X: 99, Y: 99})
+
+	view := ecs.NewView[struct {
+		*Position
+		*Velocity
+	}](storage)
+
+	entities := make(map[ecs.EntityId]float32)
+	for id, item := range view.IterArchetype(id1.ArchetypeId()) {
+		entities[id] = item.Position.X
+	}
+	assert.Equal(t, map[ecs.EntityId]float32{id1: 1, id2: 2}, entities)
+
+	count := 0
+	for id := range view.IterArchetype(named.ArchetypeId()) {
+		assert.Equal(t, named, id)
+		count++
+	}
+	assert.Equal(t, 1, count)
+
+	for range view.IterArchetype(positionOnly.ArchetypeId()) {
+		t.Error("archetype missing required components should yield nothing")
+	}
+	for range view.IterArchetype(12345) {
+		t.Error("unknown archetype should yield nothing")
+	}
+}
+
+func TestViewIterInRect(t *testing.T) {
+	storage := ecs.NewStorage(newTestRegistry())
+
+	inside := storage.Spawn(&Position{X: 5, Y: 5})
+	edge := storage.Spawn(&Position{X: 10, Y: 0}, &Velocity{})
+	storage.Spawn(&Position{X: 10.5, Y: 5})
+	storage.Spawn(&Position{X: 5, Y: -1})
+
+	view := ecs.NewView[struct{ *Position }](storage)
+	pos := func(item struct{ *Position }) (float32, float32) { return item.X, item.Y }
+
+	var ids []ecs.EntityId
+	for id, item := range view.IterInRect(0, 0, 10, 10, pos) {
+		assert.Equal(t, ecs.ReadComponent[Position](storage, id), item.Position)
+		ids = append(ids, id)
+	}
+	assert.ElementsMatch(t, []ecs.EntityId{inside, edge}, ids)
+
+	count := 0
+	for range view.IterInRect(0, 0, 10, 10, pos) {
+		count++
+		break
+	}
+	assert.Equal(t, 1, count)
+}
+
+func TestViewForEachChunk(t *testing.T) {
+	storage := ecs.NewStorage(newTestRegistry())
+
+	for i := range 10 {
+		storage.Spawn(&Position{X: float32(i)}, &Velocity{DX: 1})
+	}
+	named := storage.Spawn(&Position{X: 10}, &Velocity{DX: 1}, Name("Entity"))
+	disabled := storage.Spawn(&Position{X: 11}, &Velocity{DX: 1}, Name("Disabled"))
+	storage.SetEnabled(disabled, false)
+	storage.Spawn(&Position{X: 99})
+
+	view := ecs.NewView[struct {
+		*Position
+		*Velocity
+	}](storage)
+
+	var chunks []int
+	var wg sync.WaitGroup
+	view.ForEachChunk(func(chunk ecs.ViewChunk[struct {
+		*Position
+		*Velocity
+	}]) {
+		chunks = append(chunks, chunk.Len())
+		for i := range chunk.Len() {
+			assert.Equal(t, chunk.Archetype().ID(), chunk.Id(i).ArchetypeId())
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range chunk.Len() {
+				item := chunk.At(i)
+				item.Position.X += item.Velocity.DX
+			}
+		}()
+	})
+	wg.Wait()
+
+	assert.ElementsMatch(t, []int{10, 1}, chunks, "one chunk per archetype, without disabled entities")
+	assert.Equal(t, float32(11), ecs.ReadComponent[Position](storage, named).X)
+	assert.Equal(t, float32(11), ecs.ReadComponent[Position](storage, disabled).X)
+}
+
+func TestViewIterByArchetype(t *testing.T) {
+	storage := ecs.NewStorage(newTestRegistry())
+
+	id1 := storage.Spawn(&Position{X: 1}, &Velocity{})
+	id2 := storage.Spawn(&Position{X: 2}, &Velocity{})
+	named := storage.Spawn(&Position{X: 3}, &Velocity{}, Name("Entity3"))
+	storage.Spawn(&Position{X: 99})
+	emptied := storage.Spawn(&Position{}, &Velocity{}, Health{})
+	storage.Delete(emptied)
+
+	view := ecs.NewView[struct {
+		*Position
+		*Velocity
+	}](storage)
+
+	grouped := make(map[uint32][]ecs.EntityId)
+	for archetype, entities := range view.IterByArchetype() {
+		for id, item := range entities {
+			assert.Equal(t, archetype.ID(), id.ArchetypeId())
+			assert.NotNil(t, item.Position)
+			grouped[archetype.ID()] = append(grouped[archetype.ID()], id)
+		}
+	}
+	assert.Equal(t, map[uint32][]ecs.EntityId{
+		id1.ArchetypeId():   {id1, id2},
+		named.ArchetypeId(): {named},
+	}, grouped, "empty and non-matching archetypes should be skipped")
+
+	query := ecs.NewQueryFromView(view)
+	archetypes := 0
+	for range query.IterByArchetype() {
+		archetypes++
+	}
+	assert.Equal(t, 2, archetypes)
+}
+
+func TestViewCount(t *testing.T) {
+	registry := newTestRegistry()
+	ecs.RegisterComponent[ecs.LODLevel](registry)
+	storage := ecs.NewStorage(registry)
+
+	storage.Spawn(&Position{X: 1}, &Velocity{})
+	storage.Spawn(&Position{X: 2}, &Velocity{}, Name("Entity2"))
+	disabled := storage.Spawn(&Position{X: 3}, &Velocity{})
+	deleted := storage.Spawn(&Position{X: 4}, &Velocity{})
+	storage.Spawn(&Position{X: 5}, ecs.LODLevel{Level: 3})
+	storage.SetEnabled(disabled, false)
+	storage.Delete(deleted)
+
+	moving := ecs.NewView[struct {
+		*Position
+		*Velocity
+	}](storage)
+	assert.Equal(t, 2, moving.Count(), "disabled and deleted entities are not counted")
+
+	withOptional := ecs.NewView[struct {
+		*Position
+		Velocity *Velocity `ecs:"optional"`
+	}](storage)
+	assert.Equal(t, 3, withOptional.Count())
+
+	nearby := ecs.NewView[struct {
+		*Position
+		LOD *ecs.LODLevel `ecs:"lod<=2"`
+	}](storage)
+	count := 0
+	for range nearby.Iter() {
+		count++
+	}
+	assert.Equal(t, 2, count)
+	assert.Equal(t, count, nearby.Count(), "filtered views count what they yield")
+}
+
+func TestViewIterOrder(t *testing.T) {
+	extras := []any{Velocity{}, Name("n"), Health{}, AI{}, Score(1), Tag("t"), Temperature(2), TestA("a")}
+
+	// Two storages holding the same entities, whose archetypes were created in opposite orders
+	spawn := func(order []int) *ecs.Storage {
+		storage := ecs.NewStorage(newTestRegistry())
+		for _, i := range order {
+			storage.Spawn(Position{X: float32(i)}, extras[i])
+			storage.Spawn(Position{X: float32(i) + 0.5}, extras[i])
+		}
+		return storage
+	}
+	forward := spawn([]int{0, 1, 2, 3, 4, 5, 6, 7})
+	backward := spawn([]int{7, 6, 5, 4, 3, 2, 1, 0})
+
+	positions := func(storage *ecs.Storage) []float32 {
+		var xs []float32
+		for item := range ecs.NewView[struct{ *Position }](storage).Iter() {
+			xs = append(xs, item.Position.X)
+		}
+		return xs
+	}
+	xs := positions(forward)
+	assert.Len(t, xs, 16)
+	assert.Equal(t, xs, positions(backward), "archetypes are visited in the same order")
+	assert.Equal(t, xs, positions(forward), "repeated iteration is stable")
+
+	for i := 0; i < len(xs); i += 2 {
+		assert.Equal(t, xs[i]+0.5, xs[i+1], "entities are visited in slot order")
+	}
+}
+
 func TestViewIterMutation(t *testing.T) {
 
 	storage := ecs.NewStorage(newTestRegistry())
@@ -397,6 +636,35 @@ func TestViewIterWithDeletedEntities(t *testing.T) {
 	assert.True(t, entities[id4])
 }
 
+func TestViewNestedIterAfterNewArchetype(t *testing.T) {
+	storage := ecs.NewStorage(newTestRegistry())
+	first := storage.Spawn(&Position{X: 1})
+	second := storage.Spawn(&Position{X: 2}, &Velocity{})
+
+	view := ecs.NewView[struct {
+		Id ecs.EntityId
+		*Position
+	}](storage)
+	for range view.Iter() { // fills the matching archetype cache
+	}
+
+	var outer []ecs.EntityId
+	inner := 0
+	for item := range view.Iter() {
+		outer = append(outer, item.Id)
+		if len(outer) == 1 {
+			// The new archetype sorts before the ones being iterated
+			storage.Spawn(&Health{}, &Position{X: 3})
+			for range view.Iter() {
+				inner++
+			}
+		}
+	}
+
+	assert.Equal(t, []ecs.EntityId{first, second}, outer)
+	assert.Equal(t, 3, inner)
+}
+
 func TestViewIterLargeDataset(t *testing.T) {
 
 	storage := ecs.NewStorage(newTestRegistry())
@@ -645,6 +913,58 @@ func TestViewEmbeddedAndOptionalMixed(t *testing.T) {
 	assert.NotNil(t, item2.Health)
 }
 
+type viewMover struct {
+	*Position
+	Velocity *Velocity `ecs:"optional"`
+}
+
+type viewCreature struct {
+	viewMover
+	Health *Health
+}
+
+func TestViewEmbeddedStruct(t *testing.T) {
+
+	storage := ecs.NewStorage(newTestRegistry())
+	full := storage.Spawn(&Position{X: 1}, &Velocity{DX: 2}, &Health{Current: 3})
+	noVelocity := storage.Spawn(&Position{X: 4}, &Health{Current: 5})
+	storage.Spawn(&Position{X: 6}, &Velocity{DX: 7})
+
+	view := ecs.NewView[struct {
+		ecs.EntityId
+		viewCreature
+		Name *Name `ecs:"optional"`
+	}](storage)
+
+	item := view.Get(full)
+	assert.NotNil(t, item)
+	assert.Equal(t, full, item.EntityId)
+	assert.Equal(t, float32(1), item.Position.X)
+	assert.Equal(t, float32(2), item.Velocity.DX)
+	assert.Equal(t, 3, item.Health.Current)
+	assert.Nil(t, item.Name)
+
+	// The optional tag inside the embedded struct is respected
+	item = view.Get(noVelocity)
+	assert.NotNil(t, item)
+	assert.Nil(t, item.Velocity)
+	assert.Equal(t, float32(4), item.Position.X)
+
+	found := make(map[ecs.EntityId]bool)
+	for item := range view.Iter() {
+		found[item.EntityId] = true
+	}
+	assert.Equal(t, map[ecs.EntityId]bool{full: true, noVelocity: true}, found)
+
+	// Spawning through the view reads the flattened fields as well
+	spawned := view.Spawn(struct {
+		ecs.EntityId
+		viewCreature
+		Name *Name `ecs:"optional"`
+	}{viewCreature: viewCreature{viewMover: viewMover{Position: &Position{X: 8}}, Health: &Health{Current: 9}}})
+	assert.Equal(t, 9, view.Get(spawned).Health.Current)
+}
+
 func TestViewInvalidTag(t *testing.T) {
 
 	defer func() {
@@ -1225,3 +1545,211 @@ func TestViewSpawnIgnoresEntityId(t *testing.T) {
 	assert.Equal(t, spawnedId, item.Id)
 	assert.Equal(t, float32(5), item.Position.X)
 }
+
+type testMember struct {
+	GroupRef *ecs.EntityRef
+}
+
+func TestViewVia(t *testing.T) {
+	registry := newTestRegistry()
+	ecs.RegisterComponent[testMember](registry)
+	storage := ecs.NewStorage(registry)
+
+	group := storage.Spawn(Health{Current: 10, Max: 20}, Name("group"))
+	groupRef := storage.CreateEntityRef(group)
+
+	member := storage.Spawn(testMember{GroupRef: groupRef}, Position{X: 1})
+	orphan := storage.Spawn(testMember{}, Position{X: 2})
+
+	view := ecs.NewView[struct {
+		ecs.EntityId
+		*testMember
+		GroupHealth *Health `ecs:"via=testMember.GroupRef"`
+	}](storage)
+
+	item := view.Get(member)
+	assert.NotNil(t, item)
+	assert.Equal(t, 10, item.GroupHealth.Current)
+
+	// Writes go through to the referenced entity
+	item.GroupHealth.Current = 15
+	assert.Equal(t, 15, ecs.ReadComponent[Health](storage, group).Current)
+
+	assert.Nil(t, view.Get(orphan))
+
+	count := 0
+	for item := range view.Iter() {
+		assert.Equal(t, member, item.EntityId)
+		count++
+	}
+	assert.Equal(t, 1, count)
+
+	// The via field follows the ref when the target moves archetypes
+	group = storage.AddComponent(group, Position{})
+	item = view.Get(member)
+	assert.NotNil(t, item)
+	assert.Equal(t, 15, item.GroupHealth.Current)
+
+	// Dead refs skip the entity
+	storage.Delete(group)
+	assert.Nil(t, view.Get(member))
+}
+
+func TestViewViaOptional(t *testing.T) {
+	registry := newTestRegistry()
+	ecs.RegisterComponent[testMember](registry)
+	storage := ecs.NewStorage(registry)
+
+	group := storage.Spawn(Health{Current: 10, Max: 20})
+	member := storage.Spawn(testMember{GroupRef: storage.CreateEntityRef(group)})
+	orphan := storage.Spawn(testMember{})
+
+	view := ecs.NewView[struct {
+		*testMember
+		GroupHealth *Health `ecs:"via=testMember.GroupRef,optional"`
+		GroupName   *Name   `ecs:"via=testMember.GroupRef,optional"`
+	}](storage)
+
+	item := view.Get(member)
+	assert.NotNil(t, item)
+	assert.NotNil(t, item.GroupHealth)
+	assert.Nil(t, item.GroupName)
+
+	item = view.Get(orphan)
+	assert.NotNil(t, item)
+	assert.Nil(t, item.GroupHealth)
+
+	count := 0
+	for range view.Iter() {
+		count++
+	}
+	assert.Equal(t, 2, count)
+}
+
+func TestViewViaInvalidTag(t *testing.T) {
+	storage := ecs.NewStorage(newTestRegistry())
+
+	assert.Panics(t, func() {
+		_ = ecs.NewView[struct {
+			*Position
+			Health *Health `ecs:"via=Missing.Ref"`
+		}](storage)
+	})
+
+	assert.Panics(t, func() {
+		_ = ecs.NewView[struct {
+			*Position
+			Health *Health `ecs:"via=Position.X"`
+		}](storage)
+	})
+}
+
+func TestViewFilter(t *testing.T) {
+
+	storage := ecs.NewStorage(newTestRegistry())
+	for i := 0; i < 10; i++ {
+		storage.Spawn(&Position{X: float32(i), Y: 0}, &Health{Current: i * 10, Max: 100})
+	}
+	lowId := storage.Spawn(&Position{X: 100, Y: 0}, &Health{Current: 5, Max: 100})
+
+	view := ecs.NewView[struct {
+		ecs.EntityId
+		*Position
+		*Health
+	}](storage)
+
+	wounded := view.Filter(func(item struct {
+		ecs.EntityId
+		*Position
+		*Health
+	}) bool {
+		return item.Health.Current < 50
+	})
+
+	count := 0
+	for item := range wounded.Iter() {
+		assert.Less(t, item.Health.Current, 50)
+		count++
+	}
+	assert.Equal(t, 6, count)
+
+	far := wounded.Filter(func(item struct {
+		ecs.EntityId
+		*Position
+		*Health
+	}) bool {
+		return item.Position.X > 50
+	})
+
+	count = 0
+	for item := range far.Iter() {
+		assert.Equal(t, lowId, item.EntityId)
+		count++
+	}
+	assert.Equal(t, 1, count)
+
+	assert.NotNil(t, far.Get(lowId))
+	assert.Equal(t, view, far.View())
+
+	// Predicates are evaluated lazily, so changes are reflected on the next iteration
+	view.Get(lowId).Health.Current = 90
+	assert.Nil(t, far.Get(lowId))
+	count = 0
+	for range wounded.Iter() {
+		count++
+	}
+	assert.Equal(t, 5, count)
+}
+
+func TestViewLOD(t *testing.T) {
+	registry := newTestRegistry()
+	ecs.RegisterComponent[ecs.LODLevel](registry)
+	storage := ecs.NewStorage(registry)
+
+	near := storage.Spawn(Position{X: 1}, ecs.LODLevel{Level: 0})
+	middle := storage.Spawn(Position{X: 2}, ecs.LODLevel{Level: 2})
+	far := storage.Spawn(Position{X: 3}, ecs.LODLevel{Level: 3})
+	untagged := storage.Spawn(Position{X: 4})
+
+	view := ecs.NewView[struct {
+		ecs.EntityId
+		*Position
+		LOD *ecs.LODLevel `ecs:"lod<=2"`
+	}](storage)
+
+	var ids []ecs.EntityId
+	for item := range view.Iter() {
+		ids = append(ids, item.EntityId)
+	}
+	assert.ElementsMatch(t, []ecs.EntityId{near, middle, untagged}, ids, "entities without a level count as level 0")
+	assert.Nil(t, view.Get(far))
+	assert.Nil(t, view.Get(untagged).LOD)
+
+	ecs.ReadComponent[ecs.LODLevel](storage, far).Level = 1
+	assert.NotNil(t, view.Get(far), "changing the level takes effect immediately")
+
+	t.Run("distance thresholds", func(t *testing.T) {
+		assert.Equal(t, 0, ecs.LODForDistance(50, 50, 200))
+		assert.Equal(t, 1, ecs.LODForDistance(51, 50, 200))
+		assert.Equal(t, 2, ecs.LODForDistance(500, 50, 200))
+		assert.Equal(t, 0, ecs.LODForDistance(500))
+	})
+
+	t.Run("invalid tags", func(t *testing.T) {
+		assert.Panics(t, func() {
+			ecs.NewView[struct {
+				Position *Position `ecs:"lod<=1"`
+			}](storage)
+		}, "lod tags only apply to LODLevel fields")
+		assert.Panics(t, func() {
+			ecs.NewView[struct {
+				LOD *ecs.LODLevel `ecs:"lod<=x"`
+			}](storage)
+		})
+		assert.Panics(t, func() {
+			ecs.NewView[struct {
+				LOD *ecs.LODLevel `ecs:"lod<=1,added"`
+			}](storage)
+		})
+	})
+}
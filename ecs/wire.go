@@ -0,0 +1,615 @@
+package ecs
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"math"
+	"reflect"
+	"strings"
+	"sync"
+	"unsafe"
+)
+
+// maxWireLength bounds the length of strings, slices and maps read by DecodeComponent
+const maxWireLength = 1 << 24
+
+// wirePrealloc bounds the bytes allocated for a string, slice or map before its contents are
+// read. Longer ones grow as they are read, so a corrupt or hostile length prefix can't make
+// DecodeComponent allocate much more memory than the message holds.
+const wirePrealloc = 1 << 16
+
+// ComponentTypeId returns the numeric id of a component type used by EncodeComponent. It is
+// derived from the type's TypeName, so it is the same in every process as long as the name
+// is, which RegisterTypeName guarantees across renames.
+func ComponentTypeId(t reflect.Type) uint32 {
+	h := fnv.New32a()
+	h.Write([]byte(TypeName(t)))
+	return h.Sum32()
+}
+
+// ComponentTypeById returns the registered component type whose ComponentTypeId is id.
+// Returns false if no registered type, or more than one, has that id.
+func (r *ComponentRegistry) ComponentTypeById(id uint32) (reflect.Type, bool) {
+	if r.typesById == nil {
+		r.typesById = make(map[uint32]reflect.Type, len(r.factories))
+		for t := range r.factories {
+			typeId := ComponentTypeId(t)
+			if _, taken := r.typesById[typeId]; taken {
+				r.typesById[typeId] = nil // ambiguous
+			} else {
+				r.typesById[typeId] = t
+			}
+		}
+	}
+	t := r.typesById[id]
+	return t, t != nil
+}
+
+// EncodeComponent writes a single component to w in a compact binary format meant for
+// sending component updates over the network, e.g. the components that changed during a
+// frame. value is a component or a pointer to one, and typeId must be its ComponentTypeId.
+//
+// The message is the type id followed by the value's fields in declaration order, including
+// unexported ones, all little-endian. Numbers take their fixed size, with int and uint
+// widened to 64 bits, so components made only of numbers, bools and arrays of them are
+// encoded in a fixed number of bytes. Strings, slices and maps are prefixed with their length,
+// and pointers, slices and maps with whether they are nil. EntityId and EntityRef values are
+// written as the raw id, which is only meaningful to a peer sharing the sender's ids. Map
+// entries are written in iteration order. Components holding interfaces, functions or
+// channels cannot be encoded.
+func EncodeComponent(w io.Writer, typeId uint32, value any) error {
+	if value == nil {
+		return errors.New("cannot encode a nil component")
+	}
+	v := reflect.ValueOf(value)
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return errors.New("cannot encode a nil component")
+		}
+		v = v.Elem()
+	} else {
+		addressable := reflect.New(v.Type()).Elem()
+		addressable.Set(v)
+		v = addressable
+	}
+
+	codec, err := wireCodecFor(v.Type())
+	if err != nil {
+		return err
+	}
+	if codec.id != typeId {
+		return fmt.Errorf("type id %d does not match component type %s with id %d", typeId, TypeName(v.Type()), codec.id)
+	}
+
+	buf := wireBuffers.Get().(*[]byte)
+	defer wireBuffers.Put(buf)
+	*buf = binary.LittleEndian.AppendUint32((*buf)[:0], typeId)
+	*buf = codec.encode(*buf, v.Addr().UnsafePointer())
+	_, err = w.Write(*buf)
+	return err
+}
+
+// DecodeComponent reads a component written by EncodeComponent from r. It returns the type
+// id and a pointer to the decoded component, whose type is looked up among the types
+// registered with registry. It reads exactly one message, so messages can be written back
+// to back on a stream; reading from an io.ByteReader such as a bufio.Reader is faster.
+// EntityRef values are decoded without an archetype, so they must be resolved with
+// Storage.CreateEntityRef or similar before use.
+func DecodeComponent(r io.Reader, registry *ComponentRegistry) (uint32, any, error) {
+	d := &wireDecoder{r: r}
+	d.byteReader, _ = r.(io.ByteReader)
+
+	header, err := d.read(4)
+	if err != nil {
+		return 0, nil, err
+	}
+	typeId := binary.LittleEndian.Uint32(header)
+
+	t, ok := registry.ComponentTypeById(typeId)
+	if !ok {
+		return typeId, nil, fmt.Errorf("no registered component type has id %d", typeId)
+	}
+	codec, err := wireCodecFor(t)
+	if err != nil {
+		return typeId, nil, err
+	}
+
+	value := reflect.New(t)
+	if err := codec.decode(d, value.UnsafePointer()); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return typeId, nil, fmt.Errorf("decoding %s: %w", TypeName(t), err)
+	}
+	return typeId, value.Interface(), nil
+}
+
+var wireBuffers = sync.Pool{New: func() any { return new([]byte) }}
+
+// wireDecoder reads the parts of a message without reading past its end
+type wireDecoder struct {
+	r          io.Reader
+	byteReader io.ByteReader
+	buf        []byte
+}
+
+// read returns the next n bytes, valid until the next call
+func (d *wireDecoder) read(n int) ([]byte, error) {
+	if cap(d.buf) < n {
+		d.buf = make([]byte, n)
+	}
+	d.buf = d.buf[:n]
+	if _, err := io.ReadFull(d.r, d.buf); err != nil {
+		return nil, err
+	}
+	return d.buf, nil
+}
+
+func (d *wireDecoder) ReadByte() (byte, error) {
+	if d.byteReader != nil {
+		return d.byteReader.ReadByte()
+	}
+	b, err := d.read(1)
+	if err != nil {
+		return 0, err
+	}
+	return b[0], nil
+}
+
+// readString reads a string of n bytes
+func (d *wireDecoder) readString(n int) (string, error) {
+	if n <= wirePrealloc {
+		b, err := d.read(n)
+		return string(b), err
+	}
+	var b strings.Builder
+	_, err := io.CopyN(&b, d.r, int64(n))
+	return b.String(), err
+}
+
+// preallocLen returns how many of n elements of the given size to allocate up front. Elements
+// of size zero count as one byte, since a map still allocates buckets for them.
+func preallocLen(n int, size uintptr) int {
+	return min(n, wirePrealloc/max(int(size), 1))
+}
+
+// uvarint reads a length prefix, refusing lengths beyond maxWireLength
+func (d *wireDecoder) uvarint() (int, error) {
+	n, err := binary.ReadUvarint(d)
+	if err != nil {
+		return 0, err
+	}
+	if n > maxWireLength+1 {
+		return 0, fmt.Errorf("length %d exceeds the limit of %d", n, maxWireLength)
+	}
+	return int(n), nil
+}
+
+// length reads the length of a slice or map written by appendLength, returning -1 for nil
+func (d *wireDecoder) length() (int, error) {
+	n, err := d.uvarint()
+	return n - 1, err
+}
+
+// appendLength writes the length of a slice or map, with nil written as -1
+func appendLength(buf []byte, n int) []byte {
+	return binary.AppendUvarint(buf, uint64(n+1))
+}
+
+// wireCodec encodes and decodes values of one type in place through pointers
+type wireCodec struct {
+	// id is the ComponentTypeId of top-level component types
+	id uint32
+
+	// size is the encoded size of fixed-size types, which also implement put and get, or -1
+	size int
+	put  func(b []byte, p unsafe.Pointer)
+	get  func(b []byte, p unsafe.Pointer)
+
+	encode func(buf []byte, p unsafe.Pointer) []byte
+	decode func(d *wireDecoder, p unsafe.Pointer) error
+}
+
+var (
+	// wireCodecs caches the codec of every top-level component type, see wireCodecFor
+	wireCodecs sync.Map // reflect.Type -> *wireCodec
+
+	// wireCodecsMu serializes building codecs, which are shared between the types they nest
+	wireCodecsMu sync.Mutex
+	wireBuilt    = map[reflect.Type]*wireCodec{}
+)
+
+// wireCodecFor returns the codec for t, building and caching it on first use
+func wireCodecFor(t reflect.Type) (*wireCodec, error) {
+	if codec, ok := wireCodecs.Load(t); ok {
+		return codec.(*wireCodec), nil
+	}
+
+	wireCodecsMu.Lock()
+	defer wireCodecsMu.Unlock()
+	if codec, ok := wireCodecs.Load(t); ok {
+		return codec.(*wireCodec), nil
+	}
+
+	building := map[reflect.Type]*wireCodec{}
+	codec, err := buildWireCodec(t, building)
+	if err != nil {
+		return nil, fmt.Errorf("component type %s cannot be encoded: %w", TypeName(t), err)
+	}
+	for t, c := range building {
+		wireBuilt[t] = c
+	}
+	codec.id = ComponentTypeId(t)
+	wireCodecs.Store(t, codec)
+	return codec, nil
+}
+
+// buildWireCodec builds the codec for t. Codecs under construction are kept in building, so
+// recursive types refer to their own codec instead of building it forever.
+func buildWireCodec(t reflect.Type, building map[reflect.Type]*wireCodec) (*wireCodec, error) {
+	if codec, ok := wireBuilt[t]; ok {
+		return codec, nil
+	}
+	if codec, ok := building[t]; ok {
+		return codec, nil
+	}
+
+	codec := &wireCodec{size: -1}
+	building[t] = codec
+
+	if t == entityRefType {
+		// Only the id is sent, the archetype pointer means nothing to the receiver
+		codec.size = 8
+		codec.put = func(b []byte, p unsafe.Pointer) {
+			binary.LittleEndian.PutUint64(b, uint64((*EntityRef)(p).Id))
+		}
+		codec.get = func(b []byte, p unsafe.Pointer) {
+			*(*EntityRef)(p) = EntityRef{Id: EntityId(binary.LittleEndian.Uint64(b))}
+		}
+		codec.fixed()
+		return codec, nil
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		codec.scalar(1,
+			func(b []byte, p unsafe.Pointer) { b[0] = boolByte(*(*bool)(p)) },
+			func(b []byte, p unsafe.Pointer) { *(*bool)(p) = b[0] != 0 })
+	case reflect.Int8, reflect.Uint8:
+		codec.scalar(1,
+			func(b []byte, p unsafe.Pointer) { b[0] = *(*uint8)(p) },
+			func(b []byte, p unsafe.Pointer) { *(*uint8)(p) = b[0] })
+	case reflect.Int16, reflect.Uint16:
+		codec.scalar(2,
+			func(b []byte, p unsafe.Pointer) { binary.LittleEndian.PutUint16(b, *(*uint16)(p)) },
+			func(b []byte, p unsafe.Pointer) { *(*uint16)(p) = binary.LittleEndian.Uint16(b) })
+	case reflect.Int32, reflect.Uint32, reflect.Float32:
+		codec.scalar(4,
+			func(b []byte, p unsafe.Pointer) { binary.LittleEndian.PutUint32(b, *(*uint32)(p)) },
+			func(b []byte, p unsafe.Pointer) { *(*uint32)(p) = binary.LittleEndian.Uint32(b) })
+	case reflect.Int64, reflect.Uint64, reflect.Float64:
+		codec.scalar(8,
+			func(b []byte, p unsafe.Pointer) { binary.LittleEndian.PutUint64(b, *(*uint64)(p)) },
+			func(b []byte, p unsafe.Pointer) { *(*uint64)(p) = binary.LittleEndian.Uint64(b) })
+	case reflect.Int:
+		codec.scalar(8,
+			func(b []byte, p unsafe.Pointer) { binary.LittleEndian.PutUint64(b, uint64(*(*int)(p))) },
+			func(b []byte, p unsafe.Pointer) { *(*int)(p) = int(binary.LittleEndian.Uint64(b)) })
+	case reflect.Uint, reflect.Uintptr:
+		codec.scalar(8,
+			func(b []byte, p unsafe.Pointer) { binary.LittleEndian.PutUint64(b, uint64(*(*uint)(p))) },
+			func(b []byte, p unsafe.Pointer) { *(*uint)(p) = uint(binary.LittleEndian.Uint64(b)) })
+	case reflect.Complex64:
+		codec.scalar(8,
+			func(b []byte, p unsafe.Pointer) {
+				c := *(*complex64)(p)
+				binary.LittleEndian.PutUint32(b, math.Float32bits(real(c)))
+				binary.LittleEndian.PutUint32(b[4:], math.Float32bits(imag(c)))
+			},
+			func(b []byte, p unsafe.Pointer) {
+				re := math.Float32frombits(binary.LittleEndian.Uint32(b))
+				im := math.Float32frombits(binary.LittleEndian.Uint32(b[4:]))
+				*(*complex64)(p) = complex(re, im)
+			})
+	case reflect.Complex128:
+		codec.scalar(16,
+			func(b []byte, p unsafe.Pointer) {
+				c := *(*complex128)(p)
+				binary.LittleEndian.PutUint64(b, math.Float64bits(real(c)))
+				binary.LittleEndian.PutUint64(b[8:], math.Float64bits(imag(c)))
+			},
+			func(b []byte, p unsafe.Pointer) {
+				re := math.Float64frombits(binary.LittleEndian.Uint64(b))
+				im := math.Float64frombits(binary.LittleEndian.Uint64(b[8:]))
+				*(*complex128)(p) = complex(re, im)
+			})
+	case reflect.String:
+		codec.encode = func(buf []byte, p unsafe.Pointer) []byte {
+			s := *(*string)(p)
+			return append(binary.AppendUvarint(buf, uint64(len(s))), s...)
+		}
+		codec.decode = func(d *wireDecoder, p unsafe.Pointer) error {
+			n, err := d.uvarint()
+			if err != nil {
+				return err
+			}
+			str, err := d.readString(n)
+			if err != nil {
+				return err
+			}
+			*(*string)(p) = str
+			return nil
+		}
+	case reflect.Array:
+		return codec, codec.array(t, building)
+	case reflect.Struct:
+		return codec, codec.structure(t, building)
+	case reflect.Pointer:
+		return codec, codec.pointer(t, building)
+	case reflect.Slice:
+		return codec, codec.slice(t, building)
+	case reflect.Map:
+		return codec, codec.mapping(t, building)
+	default:
+		return nil, fmt.Errorf("%s of kind %s is not supported", t, t.Kind())
+	}
+	return codec, nil
+}
+
+func boolByte(v bool) byte {
+	if v {
+		return 1
+	}
+	return 0
+}
+
+// scalar sets up a fixed-size codec from functions writing and reading size bytes
+func (c *wireCodec) scalar(size int, put, get func(b []byte, p unsafe.Pointer)) {
+	c.size, c.put, c.get = size, put, get
+	c.fixed()
+}
+
+// fixed derives encode and decode from put and get
+func (c *wireCodec) fixed() {
+	size, put, get := c.size, c.put, c.get
+	c.encode = func(buf []byte, p unsafe.Pointer) []byte {
+		start := len(buf)
+		buf = append(buf, make([]byte, size)...)
+		put(buf[start:], p)
+		return buf
+	}
+	c.decode = func(d *wireDecoder, p unsafe.Pointer) error {
+		b, err := d.read(size)
+		if err != nil {
+			return err
+		}
+		get(b, p)
+		return nil
+	}
+}
+
+func (c *wireCodec) array(t reflect.Type, building map[reflect.Type]*wireCodec) error {
+	elem, err := buildWireCodec(t.Elem(), building)
+	if err != nil {
+		return err
+	}
+	n, stride := t.Len(), t.Elem().Size()
+
+	if elem.size >= 0 {
+		c.size = n * elem.size
+		c.put = func(b []byte, p unsafe.Pointer) {
+			for i := range n {
+				elem.put(b[i*elem.size:], unsafe.Add(p, uintptr(i)*stride))
+			}
+		}
+		c.get = func(b []byte, p unsafe.Pointer) {
+			for i := range n {
+				elem.get(b[i*elem.size:], unsafe.Add(p, uintptr(i)*stride))
+			}
+		}
+		c.fixed()
+		return nil
+	}
+
+	c.encode = func(buf []byte, p unsafe.Pointer) []byte {
+		for i := range n {
+			buf = elem.encode(buf, unsafe.Add(p, uintptr(i)*stride))
+		}
+		return buf
+	}
+	c.decode = func(d *wireDecoder, p unsafe.Pointer) error {
+		for i := range n {
+			if err := elem.decode(d, unsafe.Add(p, uintptr(i)*stride)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return nil
+}
+
+func (c *wireCodec) structure(t reflect.Type, building map[reflect.Type]*wireCodec) error {
+	type field struct {
+		offset uintptr
+		pos    int // position in the encoding of fixed-size structs
+		codec  *wireCodec
+	}
+
+	fields := make([]field, 0, t.NumField())
+	size := 0
+	for i := range t.NumField() {
+		f := t.Field(i)
+		codec, err := buildWireCodec(f.Type, building)
+		if err != nil {
+			return fmt.Errorf("field %s: %w", f.Name, err)
+		}
+		fields = append(fields, field{offset: f.Offset, pos: size, codec: codec})
+		if codec.size < 0 || size < 0 {
+			size = -1
+		} else {
+			size += codec.size
+		}
+	}
+
+	if size >= 0 {
+		c.size = size
+		c.put = func(b []byte, p unsafe.Pointer) {
+			for _, f := range fields {
+				f.codec.put(b[f.pos:], unsafe.Add(p, f.offset))
+			}
+		}
+		c.get = func(b []byte, p unsafe.Pointer) {
+			for _, f := range fields {
+				f.codec.get(b[f.pos:], unsafe.Add(p, f.offset))
+			}
+		}
+		c.fixed()
+		return nil
+	}
+
+	c.encode = func(buf []byte, p unsafe.Pointer) []byte {
+		for _, f := range fields {
+			buf = f.codec.encode(buf, unsafe.Add(p, f.offset))
+		}
+		return buf
+	}
+	c.decode = func(d *wireDecoder, p unsafe.Pointer) error {
+		for _, f := range fields {
+			if err := f.codec.decode(d, unsafe.Add(p, f.offset)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return nil
+}
+
+func (c *wireCodec) pointer(t reflect.Type, building map[reflect.Type]*wireCodec) error {
+	elem, err := buildWireCodec(t.Elem(), building)
+	if err != nil {
+		return err
+	}
+
+	c.encode = func(buf []byte, p unsafe.Pointer) []byte {
+		target := *(*unsafe.Pointer)(p)
+		if target == nil {
+			return append(buf, 0)
+		}
+		return elem.encode(append(buf, 1), target)
+	}
+	c.decode = func(d *wireDecoder, p unsafe.Pointer) error {
+		present, err := d.ReadByte()
+		if err != nil {
+			return err
+		}
+		if present == 0 {
+			*(*unsafe.Pointer)(p) = nil
+			return nil
+		}
+		target := reflect.New(t.Elem())
+		if err := elem.decode(d, target.UnsafePointer()); err != nil {
+			return err
+		}
+		*(*unsafe.Pointer)(p) = target.UnsafePointer()
+		return nil
+	}
+	return nil
+}
+
+func (c *wireCodec) slice(t reflect.Type, building map[reflect.Type]*wireCodec) error {
+	elem, err := buildWireCodec(t.Elem(), building)
+	if err != nil {
+		return err
+	}
+	stride := t.Elem().Size()
+
+	c.encode = func(buf []byte, p unsafe.Pointer) []byte {
+		v := reflect.NewAt(t, p).Elem()
+		if v.IsNil() {
+			return appendLength(buf, -1)
+		}
+		buf = appendLength(buf, v.Len())
+		base := v.UnsafePointer()
+		for i := range v.Len() {
+			buf = elem.encode(buf, unsafe.Add(base, uintptr(i)*stride))
+		}
+		return buf
+	}
+	c.decode = func(d *wireDecoder, p unsafe.Pointer) error {
+		n, err := d.length()
+		if err != nil {
+			return err
+		}
+		v := reflect.NewAt(t, p).Elem()
+		if n < 0 {
+			v.SetZero()
+			return nil
+		}
+		v.Set(reflect.MakeSlice(t, 0, preallocLen(n, stride)))
+		for i := range n {
+			if i == v.Cap() {
+				v.Grow(1)
+			}
+			v.SetLen(i + 1)
+			if err := elem.decode(d, unsafe.Add(v.UnsafePointer(), uintptr(i)*stride)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return nil
+}
+
+func (c *wireCodec) mapping(t reflect.Type, building map[reflect.Type]*wireCodec) error {
+	key, err := buildWireCodec(t.Key(), building)
+	if err != nil {
+		return err
+	}
+	elem, err := buildWireCodec(t.Elem(), building)
+	if err != nil {
+		return err
+	}
+
+	c.encode = func(buf []byte, p unsafe.Pointer) []byte {
+		v := reflect.NewAt(t, p).Elem()
+		if v.IsNil() {
+			return appendLength(buf, -1)
+		}
+		buf = appendLength(buf, v.Len())
+		k, e := reflect.New(t.Key()).Elem(), reflect.New(t.Elem()).Elem()
+		for iter := v.MapRange(); iter.Next(); {
+			k.SetIterKey(iter)
+			e.SetIterValue(iter)
+			buf = key.encode(buf, k.Addr().UnsafePointer())
+			buf = elem.encode(buf, e.Addr().UnsafePointer())
+		}
+		return buf
+	}
+	c.decode = func(d *wireDecoder, p unsafe.Pointer) error {
+		n, err := d.length()
+		if err != nil {
+			return err
+		}
+		v := reflect.NewAt(t, p).Elem()
+		if n < 0 {
+			v.SetZero()
+			return nil
+		}
+		v.Set(reflect.MakeMapWithSize(t, preallocLen(n, t.Key().Size()+t.Elem().Size())))
+		for range n {
+			k, e := reflect.New(t.Key()), reflect.New(t.Elem())
+			if err := key.decode(d, k.UnsafePointer()); err != nil {
+				return err
+			}
+			if err := elem.decode(d, e.UnsafePointer()); err != nil {
+				return err
+			}
+			v.SetMapIndex(k.Elem(), e.Elem())
+		}
+		return nil
+	}
+	return nil
+}
@@ -0,0 +1,196 @@
+package ecs_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"reflect"
+	"runtime"
+	"testing"
+	"testing/iotest"
+
+	"github.com/plus3/ooftn/ecs"
+	"github.com/stretchr/testify/assert"
+)
+
+type wireNode struct {
+	Value int
+	Next  *wireNode
+}
+
+type wireState struct {
+	Name     string
+	Flags    [3]bool
+	Scores   []int16
+	Empty    []int16
+	Counts   map[string]uint8
+	Node     *wireNode
+	Missing  *Position
+	Owner    ecs.EntityRef
+	Spin     complex64
+	hidden   float64
+	Entities []ecs.EntityId
+}
+
+type wireCallback struct {
+	OnHit func()
+}
+
+type wireSamples struct {
+	Samples []Position
+}
+
+type wireLookup struct {
+	Lookup map[int32]Position
+}
+
+type wireSet struct {
+	Members map[struct{}]struct{}
+}
+
+func TestWireFormat(t *testing.T) {
+	registry := ecs.NewComponentRegistry()
+	ecs.RegisterComponent[Position](registry)
+	ecs.RegisterComponent[wireState](registry)
+	ecs.RegisterComponent[wireCallback](registry)
+	ecs.RegisterComponent[wireSamples](registry)
+	ecs.RegisterComponent[wireLookup](registry)
+	ecs.RegisterComponent[wireSet](registry)
+
+	positionId := ecs.ComponentTypeId(reflect.TypeFor[Position]())
+	stateId := ecs.ComponentTypeId(reflect.TypeFor[wireState]())
+
+	t.Run("fixed-size components", func(t *testing.T) {
+		var buf bytes.Buffer
+		assert.NoError(t, ecs.EncodeComponent(&buf, positionId, Position{X: 1.5, Y: -2}))
+		assert.Equal(t, 4+8, buf.Len(), "the type id followed by two float32")
+
+		typeId, value, err := ecs.DecodeComponent(&buf, registry)
+		assert.NoError(t, err)
+		assert.Equal(t, positionId, typeId)
+		assert.Equal(t, &Position{X: 1.5, Y: -2}, value)
+	})
+
+	t.Run("variable-size components", func(t *testing.T) {
+		state := &wireState{
+			Name:     "crate",
+			Flags:    [3]bool{true, false, true},
+			Scores:   []int16{-1, 2, 300},
+			Empty:    []int16{},
+			Counts:   map[string]uint8{"wood": 3, "nails": 40},
+			Node:     &wireNode{Value: 1, Next: &wireNode{Value: 2}},
+			Owner:    ecs.EntityRef{Id: ecs.NewEntityId(7, 3), Archetype: &ecs.Archetype{}},
+			Spin:     complex(1, -1),
+			hidden:   0.25,
+			Entities: []ecs.EntityId{ecs.NewEntityId(1, 2)},
+		}
+
+		var buf bytes.Buffer
+		assert.NoError(t, ecs.EncodeComponent(&buf, stateId, state))
+		_, value, err := ecs.DecodeComponent(&buf, registry)
+		assert.NoError(t, err)
+
+		expected := *state
+		expected.Owner.Archetype = nil
+		assert.Equal(t, &expected, value)
+		assert.NotNil(t, value.(*wireState).Empty, "empty and nil slices are kept apart")
+		assert.Nil(t, value.(*wireState).Missing)
+	})
+
+	t.Run("messages back to back", func(t *testing.T) {
+		var buf bytes.Buffer
+		for i := range 3 {
+			assert.NoError(t, ecs.EncodeComponent(&buf, positionId, &Position{X: float32(i)}))
+			assert.NoError(t, ecs.EncodeComponent(&buf, stateId, wireState{Name: "n", Scores: []int16{int16(i)}}))
+		}
+
+		// A reader without ReadByte must not read past the end of a message either
+		r := iotest.OneByteReader(&buf)
+		for i := range 3 {
+			_, position, err := ecs.DecodeComponent(r, registry)
+			assert.NoError(t, err)
+			assert.Equal(t, float32(i), position.(*Position).X)
+
+			_, state, err := ecs.DecodeComponent(r, registry)
+			assert.NoError(t, err)
+			assert.Equal(t, []int16{int16(i)}, state.(*wireState).Scores)
+		}
+		_, _, err := ecs.DecodeComponent(r, registry)
+		assert.ErrorIs(t, err, io.EOF)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		var buf bytes.Buffer
+		assert.Error(t, ecs.EncodeComponent(&buf, stateId, Position{}), "mismatched type id")
+		assert.Error(t, ecs.EncodeComponent(&buf, positionId, (*Position)(nil)))
+		assert.Error(t, ecs.EncodeComponent(&buf, ecs.ComponentTypeId(reflect.TypeFor[wireCallback]()), wireCallback{}))
+		assert.Zero(t, buf.Len(), "nothing is written on error")
+
+		assert.NoError(t, ecs.EncodeComponent(&buf, positionId, Position{}))
+		_, _, err := ecs.DecodeComponent(bytes.NewReader(buf.Bytes()[:6]), registry)
+		assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+		_, _, err = ecs.DecodeComponent(&buf, ecs.NewComponentRegistry())
+		assert.Error(t, err, "unregistered type")
+	})
+
+	t.Run("hostile lengths", func(t *testing.T) {
+		// Each message claims the largest length the decoder accepts, then ends
+		for _, typ := range []reflect.Type{
+			reflect.TypeFor[wireState](),
+			reflect.TypeFor[wireSamples](),
+			reflect.TypeFor[wireLookup](),
+		} {
+			message := binary.LittleEndian.AppendUint32(nil, ecs.ComponentTypeId(typ))
+			message = binary.AppendUvarint(message, 1<<24)
+
+			var before, after runtime.MemStats
+			runtime.ReadMemStats(&before)
+			_, _, err := ecs.DecodeComponent(bytes.NewReader(message), registry)
+			runtime.ReadMemStats(&after)
+			assert.ErrorIs(t, err, io.ErrUnexpectedEOF, typ.String())
+			assert.Less(t, after.TotalAlloc-before.TotalAlloc, uint64(1<<20), "%s allocates for contents the message doesn't hold", typ)
+		}
+
+		// Entries of size zero take no bytes, so the message is complete but must not size the map
+		message := binary.LittleEndian.AppendUint32(nil, ecs.ComponentTypeId(reflect.TypeFor[wireSet]()))
+		message = binary.AppendUvarint(message, 1<<20)
+		var before, after runtime.MemStats
+		runtime.ReadMemStats(&before)
+		_, _, err := ecs.DecodeComponent(bytes.NewReader(message), registry)
+		runtime.ReadMemStats(&after)
+		assert.NoError(t, err)
+		assert.Less(t, after.TotalAlloc-before.TotalAlloc, uint64(1<<20), "a map of empty entries is sized from its length prefix")
+	})
+}
+
+func BenchmarkEncodeComponent(b *testing.B) {
+	registry := ecs.NewComponentRegistry()
+	ecs.RegisterComponent[Position](registry)
+	typeId := ecs.ComponentTypeId(reflect.TypeFor[Position]())
+	position := &Position{X: 1, Y: 2}
+
+	var buf bytes.Buffer
+	b.Run("encode", func(b *testing.B) {
+		b.ReportAllocs()
+		for range b.N {
+			buf.Reset()
+			if err := ecs.EncodeComponent(&buf, typeId, position); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("decode", func(b *testing.B) {
+		b.ReportAllocs()
+		buf.Reset()
+		ecs.EncodeComponent(&buf, typeId, position)
+		message := buf.Bytes()
+		r := bytes.NewReader(message)
+		for range b.N {
+			r.Reset(message)
+			if _, _, err := ecs.DecodeComponent(r, registry); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
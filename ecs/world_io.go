@@ -0,0 +1,370 @@
+package ecs
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
+)
+
+// worldFormatVersion is bumped whenever the saved world layout changes incompatibly
+const worldFormatVersion = 1
+
+// savedWorld is the JSON layout written by SaveWorld
+type savedWorld struct {
+	Version    int                        `json:"version"`
+	Singletons map[string]json.RawMessage `json:"singletons"`
+	Entities   []savedEntity              `json:"entities"`
+}
+
+type savedEntity struct {
+	Id         EntityId                   `json:"id"`
+	Disabled   bool                       `json:"disabled,omitempty"`
+	Components map[string]json.RawMessage `json:"components"`
+}
+
+// MarshalJSON encodes the ref as the id of the entity it points at, or null if the entity
+// was deleted. The id is only meaningful within the saved world, see Storage.LoadWorld.
+func (r *EntityRef) MarshalJSON() ([]byte, error) {
+	if !r.Id.IsValid() {
+		return []byte("null"), nil
+	}
+	return []byte(strconv.FormatUint(uint64(r.Id), 10)), nil
+}
+
+// UnmarshalJSON decodes an id written by MarshalJSON. The ref is not bound to a storage
+// until Storage.LoadWorld remaps it.
+func (r *EntityRef) UnmarshalJSON(data []byte) error {
+	r.Archetype = nil
+	if string(data) == "null" {
+		r.Id = InvalidEntityId
+		return nil
+	}
+
+	id, err := strconv.ParseUint(string(data), 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid entity ref %s: %w", data, err)
+	}
+	r.Id = EntityId(id)
+	return nil
+}
+
+// SaveWorld writes every entity and singleton in storage to w as JSON. Components and
+// singletons are keyed by TypeName, so registering stable names keeps saves loadable after
+// types are renamed. Values are encoded with encoding/json: only exported fields are saved,
+// and EntityRef and *EntityRef fields are written as the id of the referenced entity. Disabled entities
+// are saved as disabled, and empty entities without components. Entities are written in
+// birth order, and LoadWorld spawns them in the order they were written, so the loaded
+// entities keep their relative BirthOrder.
+func (s *Storage) SaveWorld(w io.Writer) error {
+	world, err := s.saveWorld()
+	if err != nil {
+		return err
+	}
+	return json.NewEncoder(w).Encode(world)
+}
+
+// saveWorld captures the entities and singletons of storage as written by SaveWorld
+func (s *Storage) saveWorld() (*savedWorld, error) {
+	world := &savedWorld{
+		Version:    worldFormatVersion,
+		Singletons: make(map[string]json.RawMessage, len(s.singletons)),
+	}
+
+	for t, entry := range s.singletons {
+		data, err := json.Marshal(reflect.NewAt(t, entry.dataPtr).Interface())
+		if err != nil {
+			return nil, fmt.Errorf("saving singleton %s: %w", TypeName(t), err)
+		}
+		world.Singletons[TypeName(t)] = data
+	}
+
+	var births []uint64
+	for _, archetype := range s.archetypes {
+		for id := range archetype.Iter() {
+			entity := savedEntity{
+				Id:         id,
+				Disabled:   archetype.isDisabled(int(id.Index())),
+				Components: make(map[string]json.RawMessage, len(archetype.types)),
+			}
+			for i, t := range archetype.types {
+				if t == emptyEntityType {
+					continue
+				}
+				data, err := json.Marshal(archetype.storages[i].Get(int(id.Index())))
+				if err != nil {
+					return nil, fmt.Errorf("saving %s of entity %d: %w", TypeName(t), id, err)
+				}
+				entity.Components[TypeName(t)] = data
+			}
+			world.Entities = append(world.Entities, entity)
+			births = append(births, archetype.birth(int(id.Index())))
+		}
+	}
+	sortByBirth(world.Entities, births)
+	return world, nil
+}
+
+// LoadWorld restores a world written by SaveWorld into storage. Saved entities are spawned
+// as new entities, so they receive new ids, and every EntityRef and *EntityRef reachable from
+// their components and from the loaded singletons is rebound to the new id of the entity it
+// referenced; *EntityRefs to entities that weren't saved become nil, and EntityRef values
+// become invalid. Singletons that already exist are decoded in place, so fields missing from
+// the save (including unexported ones) keep their values, refs among them included, and
+// pointers handed out to systems stay valid; other singletons are created. Singletons missing
+// from the save are left untouched.
+//
+// Component types are looked up in the storage's registry and singleton types among the
+// storage's singletons by TypeName. The whole document is decoded before storage is modified,
+// so unknown types or malformed values return an error and leave storage untouched.
+func (s *Storage) LoadWorld(r io.Reader) error {
+	var world savedWorld
+	if err := json.NewDecoder(r).Decode(&world); err != nil {
+		return fmt.Errorf("invalid world: %w", err)
+	}
+	return s.loadWorld(&world, nil)
+}
+
+// loadWorld restores world into storage as LoadWorld does. Saved singletons that don't exist
+// in storage are created if their type is listed in singletonTypes.
+func (s *Storage) loadWorld(world *savedWorld, singletonTypes map[string]reflect.Type) error {
+	if world.Version != worldFormatVersion {
+		return fmt.Errorf("unsupported world format version %d", world.Version)
+	}
+
+	componentTypes := make(map[string]reflect.Type, len(s.registry.factories))
+	for t := range s.registry.factories {
+		componentTypes[TypeName(t)] = t
+	}
+	singletonTypes = maps.Clone(singletonTypes)
+	if singletonTypes == nil {
+		singletonTypes = make(map[string]reflect.Type, len(s.singletons))
+	}
+	for t := range s.singletons {
+		singletonTypes[TypeName(t)] = t
+	}
+
+	// Decode everything first so errors leave storage untouched
+	spawns := make([][]any, len(world.Entities))
+	for i, entity := range world.Entities {
+		names := make([]string, 0, len(entity.Components))
+		for name := range entity.Components {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			t, ok := componentTypes[name]
+			if !ok {
+				return fmt.Errorf("saved entity %d has unknown component type %q", entity.Id, name)
+			}
+			value := reflect.New(t)
+			if err := json.Unmarshal(entity.Components[name], value.Interface()); err != nil {
+				return fmt.Errorf("loading %s of entity %d: %w", name, entity.Id, err)
+			}
+			spawns[i] = append(spawns[i], value.Elem().Interface())
+		}
+	}
+
+	for name := range world.Singletons {
+		if _, ok := singletonTypes[name]; !ok {
+			return fmt.Errorf("saved world has unknown singleton type %q", name)
+		}
+	}
+	for name, data := range world.Singletons {
+		t := singletonTypes[name]
+		if err := json.Unmarshal(data, reflect.New(t).Interface()); err != nil {
+			return fmt.Errorf("loading singleton %s: %w", name, err)
+		}
+	}
+
+	newIds := make(map[EntityId]EntityId, len(world.Entities))
+	for i, entity := range world.Entities {
+		var id EntityId
+		if len(spawns[i]) == 0 {
+			id = s.SpawnEmpty()
+		} else {
+			id = s.SpawnSlice(spawns[i])
+		}
+		if entity.Disabled {
+			s.ArchetypeOf(id).setDisabled(int(id.Index()), true)
+		}
+		newIds[entity.Id] = id
+	}
+
+	remap := func(ref *EntityRef) *EntityRef {
+		newId, ok := newIds[ref.Id]
+		if !ok {
+			return nil
+		}
+		return s.CreateEntityRef(newId)
+	}
+
+	for _, newId := range newIds {
+		archetype := s.archetypes[newId.ArchetypeId()]
+		for i, t := range archetype.types {
+			if typeHasEntityRefs(t) {
+				remapEntityRefs(reflect.ValueOf(archetype.storages[i].Get(int(newId.Index()))).Elem(), remap)
+			}
+		}
+	}
+
+	for name, data := range world.Singletons {
+		t := singletonTypes[name]
+		if s.singletons[t] == nil {
+			s.AddSingleton(reflect.New(t).Interface())
+		}
+		value := reflect.NewAt(t, s.singletons[t].dataPtr)
+		if !typeHasEntityRefs(t) {
+			if err := json.Unmarshal(data, value.Interface()); err != nil {
+				return fmt.Errorf("loading singleton %s: %w", name, err)
+			}
+			continue
+		}
+
+		// Swap the current refs for placeholders so decoding doesn't overwrite live refs, then
+		// put back the ones the save didn't decode over and rebind the decoded ones
+		live := make(map[*EntityRef]*EntityRef)
+		liveValues := make(map[EntityId]*EntityRef)
+		remapEntityRefs(value.Elem(), func(ref *EntityRef) *EntityRef {
+			placeholder := &EntityRef{Id: ref.Id, Archetype: undecodedArchetype}
+			live[placeholder] = ref
+			liveValues[ref.Id] = ref
+			return placeholder
+		})
+		if err := json.Unmarshal(data, value.Interface()); err != nil {
+			return fmt.Errorf("loading singleton %s: %w", name, err)
+		}
+		remapEntityRefs(value.Elem(), func(ref *EntityRef) *EntityRef {
+			if ref.Archetype != undecodedArchetype {
+				return remap(ref)
+			}
+			if kept, ok := live[ref]; ok {
+				return kept
+			}
+			// EntityRef values are remapped through a copy, so find them by id
+			return liveValues[ref.Id]
+		})
+	}
+
+	return nil
+}
+
+// undecodedArchetype marks the placeholder refs of a singleton being loaded, see loadWorld.
+// Decoding a ref always clears its archetype, so refs still pointing here weren't in the save.
+var undecodedArchetype = &Archetype{}
+
+// typeHasEntityRefs reports whether values of type t can contain an EntityRef or *EntityRef
+func typeHasEntityRefs(t reflect.Type) bool {
+	return hasEntityRefs(t, make(map[reflect.Type]bool))
+}
+
+func hasEntityRefs(t reflect.Type, visiting map[reflect.Type]bool) bool {
+	if t == entityRefType {
+		return true
+	}
+	if visiting[t] {
+		return false
+	}
+	visiting[t] = true
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		return t.Elem() == entityRefType || hasEntityRefs(t.Elem(), visiting)
+	case reflect.Slice, reflect.Array:
+		return hasEntityRefs(t.Elem(), visiting)
+	case reflect.Map:
+		return hasEntityRefs(t.Elem(), visiting)
+	case reflect.Struct:
+		if reflect.PointerTo(t).Implements(keyedComponentType) {
+			return hasEntityRefs(reflect.New(t).Interface().(keyedComponent).valueType(), visiting)
+		}
+		for i := 0; i < t.NumField(); i++ {
+			if t.Field(i).IsExported() && hasEntityRefs(t.Field(i).Type, visiting) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// remapEntityRefs replaces every *EntityRef reachable from v through exported fields,
+// pointers, slices, arrays, map values and the values of Keyed components with the result of
+// remap. EntityRef values are replaced with a copy of the remapped ref, or an invalid ref if
+// remap returns nil.
+func remapEntityRefs(v reflect.Value, remap func(*EntityRef) *EntityRef) {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return
+		}
+		if v.Type().Elem() == entityRefType {
+			v.Set(reflect.ValueOf(remap(v.Interface().(*EntityRef))))
+			return
+		}
+		remapEntityRefs(v.Elem(), remap)
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			remapEntityRefs(v.Index(i), remap)
+		}
+	case reflect.Map:
+		if !hasEntityRefs(v.Type().Elem(), make(map[reflect.Type]bool)) {
+			return
+		}
+		// Map values aren't addressable, so remap a copy and store it back
+		iter := v.MapRange()
+		for iter.Next() {
+			value := reflect.New(v.Type().Elem()).Elem()
+			value.Set(iter.Value())
+			remapEntityRefs(value, remap)
+			v.SetMapIndex(iter.Key(), value)
+		}
+	case reflect.Struct:
+		if v.Type() == entityRefType {
+			ref := v.Interface().(EntityRef)
+			remapped := EntityRef{Id: InvalidEntityId}
+			if ref.Id.IsValid() {
+				if newRef := remap(&ref); newRef != nil {
+					remapped = *newRef
+				}
+			}
+			v.Set(reflect.ValueOf(remapped))
+			return
+		}
+		if v.CanAddr() && v.Addr().Type().Implements(keyedComponentType) {
+			v.Addr().Interface().(keyedComponent).remapEntityRefs(remap)
+			return
+		}
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).IsExported() {
+				remapEntityRefs(v.Field(i), remap)
+			}
+		}
+	}
+}
+
+// sortByBirth sorts entities by their births, breaking ties by id so that entities without a
+// birth order are still saved in a deterministic order
+func sortByBirth(entities []savedEntity, births []uint64) {
+	order := make([]int, len(entities))
+	for i := range order {
+		order[i] = i
+	}
+	slices.SortFunc(order, func(a, b int) int {
+		if c := cmp.Compare(births[a], births[b]); c != 0 {
+			return c
+		}
+		return cmp.Compare(entities[a].Id, entities[b].Id)
+	})
+
+	sorted := make([]savedEntity, len(entities))
+	for i, index := range order {
+		sorted[i] = entities[index]
+	}
+	copy(entities, sorted)
+}
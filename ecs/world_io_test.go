@@ -0,0 +1,334 @@
+package ecs_test
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/gob"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/plus3/ooftn/ecs"
+	"github.com/stretchr/testify/assert"
+)
+
+type savedColony struct {
+	Name string
+	Food int
+}
+
+type savedMember struct {
+	Colony  *ecs.EntityRef
+	Friends []*ecs.EntityRef
+	Age     int
+}
+
+type savedClock struct {
+	Day     int
+	Leader  *ecs.EntityRef
+	scratch int
+}
+
+type savedRival struct {
+	Rival  ecs.EntityRef
+	Former ecs.EntityRef
+}
+
+type savedCamera struct {
+	Follow *ecs.EntityRef
+}
+
+type savedCalendar struct {
+	Day     int
+	Watched *ecs.EntityRef `json:"-"`
+	Pinned  ecs.EntityRef  `json:"-"`
+}
+
+func newSaveRegistry() *ecs.ComponentRegistry {
+	registry := newTestRegistry()
+	ecs.RegisterComponent[savedColony](registry)
+	ecs.RegisterComponent[savedMember](registry)
+	ecs.RegisterComponent[savedRival](registry)
+	return registry
+}
+
+func TestSaveLoadWorld(t *testing.T) {
+	registry := newSaveRegistry()
+	original := ecs.NewStorage(registry)
+
+	red := original.Spawn(savedColony{Name: "red", Food: 10}, Position{X: 1})
+	blue := original.Spawn(savedColony{Name: "blue", Food: 20}, Position{X: 2})
+	gone := original.Spawn(Position{})
+
+	alice := original.Spawn(savedMember{Colony: original.CreateEntityRef(red), Age: 30}, Position{X: 5})
+	bob := original.Spawn(savedMember{
+		Colony:  original.CreateEntityRef(blue),
+		Friends: []*ecs.EntityRef{original.CreateEntityRef(alice), original.CreateEntityRef(gone)},
+		Age:     25,
+	}, Position{X: 6})
+	original.Delete(gone)
+	original.SetEnabled(bob, false)
+
+	ecs.NewSingleton(original, savedClock{Day: 3, Leader: original.CreateEntityRef(alice)})
+
+	var buf bytes.Buffer
+	if !assert.NoError(t, original.SaveWorld(&buf)) {
+		return
+	}
+
+	loaded := ecs.NewStorage(registry)
+	clock := ecs.NewSingleton(loaded, savedClock{scratch: 7})
+	if !assert.NoError(t, loaded.LoadWorld(&buf)) {
+		return
+	}
+
+	members := make(map[int]ecs.EntityId)
+	for id, m := range ecs.CollectMap(ecs.NewView[struct{ *savedMember }](loaded)) {
+		members[m.savedMember.Age] = id
+	}
+	assert.Len(t, members, 1, "disabled entities are not visible to views")
+	assert.Equal(t, 4, loaded.CollectStats().TotalEntityCount)
+
+	colonyOf := func(member *savedMember) string {
+		id, ok := loaded.ResolveEntityRef(member.Colony)
+		if !assert.True(t, ok) {
+			return ""
+		}
+		return ecs.ReadComponent[savedColony](loaded, id).Name
+	}
+
+	newAlice := members[30]
+	aliceData := ecs.ReadComponent[savedMember](loaded, newAlice)
+	assert.Equal(t, "red", colonyOf(aliceData))
+	assert.Equal(t, float32(5), ecs.ReadComponent[Position](loaded, newAlice).X)
+
+	var newBob ecs.EntityId
+	for id := range loaded.GetArchetype(savedMember{}, Position{}).Iter() {
+		if !loaded.IsEnabled(id) {
+			newBob = id
+		}
+	}
+	if !assert.True(t, newBob.IsValid()) {
+		return
+	}
+	bobData := ecs.ReadComponent[savedMember](loaded, newBob)
+	assert.Equal(t, "blue", colonyOf(bobData))
+	if !assert.Len(t, bobData.Friends, 2) {
+		return
+	}
+	assert.Equal(t, newAlice, bobData.Friends[0].Id)
+	assert.Nil(t, bobData.Friends[1], "refs to deleted entities load as nil")
+
+	assert.Equal(t, 3, clock.Get().Day)
+	assert.Equal(t, 7, clock.Get().scratch, "unexported singleton fields are kept")
+	assert.Equal(t, newAlice, clock.Get().Leader.Id)
+	assert.Same(t, loaded.CreateEntityRef(newAlice), clock.Get().Leader, "loaded refs are tracked by the storage")
+
+	// Refs stay live after loading
+	moved := loaded.AddComponent(newAlice, Velocity{})
+	assert.Equal(t, moved, clock.Get().Leader.Id)
+	assert.NoError(t, loaded.Validate())
+}
+
+func TestSaveLoadEmptyEntities(t *testing.T) {
+	original := ecs.NewStorage(newSaveRegistry())
+	empty := original.SpawnEmpty()
+	original.Spawn(savedMember{Colony: original.CreateEntityRef(empty)})
+
+	var buf bytes.Buffer
+	if !assert.NoError(t, original.SaveWorld(&buf)) {
+		return
+	}
+	loaded := ecs.NewStorage(newSaveRegistry())
+	if !assert.NoError(t, loaded.LoadWorld(&buf)) {
+		return
+	}
+
+	members := 0
+	for item := range ecs.NewView[struct{ *savedMember }](loaded).Iter() {
+		members++
+		assert.True(t, loaded.IsEmpty(item.savedMember.Colony.Id), "refs to empty entities should be restored")
+	}
+	assert.Equal(t, 1, members)
+}
+
+func TestSaveLoadEntityRefValues(t *testing.T) {
+	original := ecs.NewStorage(newSaveRegistry())
+	red := original.Spawn(savedColony{Name: "red"})
+	gone := original.Spawn(savedColony{Name: "gone"})
+	blue := original.Spawn(savedColony{Name: "blue"}, savedRival{
+		Rival:  *original.CreateEntityRef(red),
+		Former: *original.CreateEntityRef(gone),
+	})
+	original.Delete(gone)
+
+	var buf bytes.Buffer
+	if !assert.NoError(t, original.SaveWorld(&buf)) {
+		return
+	}
+	// Entities spawned beforehand make the loaded entities' ids differ from the saved ones
+	loaded := ecs.NewStorage(newSaveRegistry())
+	loaded.Spawn(savedColony{}, savedRival{})
+	loaded.Spawn(savedColony{})
+	if !assert.NoError(t, loaded.LoadWorld(&buf)) {
+		return
+	}
+
+	names := make(map[string]ecs.EntityId)
+	for id, item := range ecs.CollectMap(ecs.NewView[struct{ *savedColony }](loaded)) {
+		names[item.savedColony.Name] = id
+	}
+	assert.NotEqual(t, blue, names["blue"])
+
+	rival := ecs.ReadComponent[savedRival](loaded, names["blue"])
+	id, ok := loaded.ResolveEntityRef(&rival.Rival)
+	assert.True(t, ok)
+	assert.Equal(t, names["red"], id, "ref values are rebound to the loaded entities")
+	assert.False(t, rival.Former.Id.IsValid(), "ref values to deleted entities load as invalid")
+}
+
+func TestSaveLoadKeepsUnsavedSingletonRefs(t *testing.T) {
+	original := ecs.NewStorage(newSaveRegistry())
+	original.Spawn(savedColony{Name: "saved"})
+	ecs.NewSingleton(original, savedCalendar{Day: 4})
+
+	var buf bytes.Buffer
+	if !assert.NoError(t, original.SaveWorld(&buf)) {
+		return
+	}
+
+	// The live entity has the same id as the saved one, so a remapped ref would move to it
+	loaded := ecs.NewStorage(newSaveRegistry())
+	target := loaded.Spawn(savedColony{Name: "live"})
+	follow := loaded.CreateEntityRef(target)
+	camera := ecs.NewSingleton(loaded, savedCamera{Follow: follow})
+	calendar := ecs.NewSingleton(loaded, savedCalendar{Watched: follow, Pinned: *follow})
+	if !assert.NoError(t, loaded.LoadWorld(&buf)) {
+		return
+	}
+
+	assert.Same(t, follow, camera.Get().Follow, "singletons missing from the save are left untouched")
+	assert.Equal(t, 4, calendar.Get().Day)
+	assert.Same(t, follow, calendar.Get().Watched, "refs missing from the save keep their values")
+	assert.Equal(t, *follow, calendar.Get().Pinned)
+	id, ok := loaded.ResolveEntityRef(follow)
+	assert.True(t, ok)
+	assert.Equal(t, "live", ecs.ReadComponent[savedColony](loaded, id).Name)
+}
+
+func TestSaveLoadBirthOrder(t *testing.T) {
+	original := ecs.NewStorage(newSaveRegistry())
+	for i := 0; i < 6; i++ {
+		// Alternate archetypes so birth order differs from archetype order
+		if i%2 == 0 {
+			original.Spawn(Position{X: float32(i)})
+		} else {
+			original.Spawn(Position{X: float32(i)}, Velocity{})
+		}
+	}
+
+	var buf bytes.Buffer
+	if !assert.NoError(t, original.SaveWorld(&buf)) {
+		return
+	}
+	loaded := ecs.NewStorage(newSaveRegistry())
+	if !assert.NoError(t, loaded.LoadWorld(&buf)) {
+		return
+	}
+
+	var ids []ecs.EntityId
+	for item := range ecs.NewView[struct {
+		EntityId ecs.EntityId
+		*Position
+	}](loaded).Iter() {
+		ids = append(ids, item.EntityId)
+	}
+	slices.SortFunc(ids, func(a, b ecs.EntityId) int {
+		return cmp.Compare(loaded.BirthOrder(a), loaded.BirthOrder(b))
+	})
+	for i, id := range ids {
+		assert.Equal(t, float32(i), ecs.ReadComponent[Position](loaded, id).X)
+	}
+}
+
+func TestLoadWorldErrors(t *testing.T) {
+	registry := newSaveRegistry()
+
+	tests := []struct {
+		name  string
+		world string
+		err   string
+	}{
+		{"malformed", `{`, "invalid world"},
+		{"version", `{"version": 99}`, "unsupported world format version"},
+		{"unknown component", `{"version": 1, "entities": [{"id": 1, "components": {"nope.Type": {}}}]}`, "unknown component type"},
+		{"bad value", `{"version": 1, "entities": [{"id": 1, "components": {"ecs_test.Position": {"X": "a"}}}]}`, "loading ecs_test.Position"},
+		{"unknown singleton", `{"version": 1, "singletons": {"nope.Clock": {}}}`, "unknown singleton type"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			storage := ecs.NewStorage(registry)
+			err := storage.LoadWorld(strings.NewReader(tt.world))
+			if !assert.Error(t, err) {
+				return
+			}
+			assert.Contains(t, err.Error(), tt.err)
+			assert.Equal(t, 0, storage.CollectStats().TotalEntityCount, "failed loads leave storage untouched")
+		})
+	}
+}
+
+func TestSnapshotRestore(t *testing.T) {
+	original := ecs.NewStorage(newSaveRegistry())
+	colony := original.Spawn(savedColony{Name: "red", Food: 10})
+	member := original.Spawn(savedMember{Colony: original.CreateEntityRef(colony), Age: 30})
+	ecs.NewSingleton(original, savedClock{Day: 3, Leader: original.CreateEntityRef(member)})
+
+	hash := original.StateHash()
+	snapshot, err := original.Snapshot()
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, 2, snapshot.Len())
+
+	// Changes after the snapshot don't leak into it
+	ecs.ReadComponent[savedColony](original, colony).Food = 0
+	original.Delete(member)
+
+	t.Run("into a fresh storage", func(t *testing.T) {
+		restored := ecs.NewStorage(newSaveRegistry())
+		if !assert.NoError(t, restored.Restore(snapshot)) {
+			return
+		}
+		assert.Equal(t, hash, restored.StateHash())
+
+		clock := ecs.BindSingleton[savedClock](restored).Get()
+		assert.Equal(t, 3, clock.Day, "missing singletons are created")
+		leader, ok := restored.ResolveEntityRef(clock.Leader)
+		assert.True(t, ok)
+		colonyId, ok := restored.ResolveEntityRef(ecs.ReadComponent[savedMember](restored, leader).Colony)
+		assert.True(t, ok)
+		assert.Equal(t, 10, ecs.ReadComponent[savedColony](restored, colonyId).Food)
+	})
+
+	t.Run("encoded with gob", func(t *testing.T) {
+		var buf bytes.Buffer
+		if !assert.NoError(t, gob.NewEncoder(&buf).Encode(snapshot)) {
+			return
+		}
+		var decoded ecs.Snapshot
+		if !assert.NoError(t, gob.NewDecoder(&buf).Decode(&decoded)) {
+			return
+		}
+
+		assert.Error(t, ecs.NewStorage(newSaveRegistry()).Restore(&decoded), "decoded snapshots don't know singleton types")
+
+		restored := ecs.NewStorage(newSaveRegistry())
+		ecs.NewSingleton[savedClock](restored)
+		if assert.NoError(t, restored.Restore(&decoded)) {
+			assert.Equal(t, hash, restored.StateHash())
+			assert.Equal(t, 3, ecs.BindSingleton[savedClock](restored).Get().Day)
+		}
+	})
+}
@@ -192,15 +192,6 @@ type GameTime struct {
 	CurrentDay int
 }
 
-type PauseState struct {
-	Paused          bool
-	StepRequested   bool    // Set to true to advance one tick while paused
-	FramesToAdvance int     // Number of frames to advance while paused
-	TimeToAdvance   float32 // Total time to advance (in seconds)
-	TimeAdvanced    float32 // Time advanced so far
-	SkipUIRender    bool    // Set to true to skip UI rendering (for intermediate ticks)
-}
-
 type Camera struct {
 	X       float32
 	Y       float32
@@ -218,18 +209,27 @@ type InputState struct {
 	PrevMouseLeft bool
 }
 
+// GridEntity is the view indexed by the SpatialGrid
+type GridEntity struct {
+	*GridPosition
+}
+
+// SpatialGrid indexes every entity with a GridPosition by cell
 type SpatialGrid struct {
-	CellSize int
-	Cells    map[[2]int][]ecs.EntityId
+	*ecs.IncrementalGrid[GridEntity]
+}
+
+// FighterGridEntity is the view indexed by the FighterGrid
+type FighterGridEntity struct {
+	*GridPosition
+	*Combat
 }
 
 // FighterGrid is a specialized spatial grid that only contains fighters
 // This allows CombatSystem to skip iterating over non-fighter entities
 // Updated incrementally to avoid rebuilding every frame
 type FighterGrid struct {
-	CellSize    int
-	Cells       map[[2]int][]ecs.EntityId
-	Initialized bool
+	*ecs.IncrementalGrid[FighterGridEntity]
 }
 
 type RenderLayer int
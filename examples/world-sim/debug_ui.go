@@ -31,16 +31,8 @@ func NewPerformanceChart() *PerformanceChart {
 func spawnECSDebugWindow(storage *ecs.Storage) {
 	storage.Spawn(debugui.ImguiItem{
 		Render: func() {
-			var perf *PerformanceMetrics
-			if !storage.ReadSingleton(&perf) {
-				return
-			}
-
-			var pauseState *PauseState
-			storage.ReadSingleton(&pauseState)
-
-			// Skip UI rendering during intermediate ticks
-			if pauseState != nil && pauseState.SkipUIRender {
+			perf, ok := ecs.GetSingletonT[PerformanceMetrics](storage)
+			if !ok {
 				return
 			}
 
@@ -67,23 +59,12 @@ func spawnECSDebugWindow(storage *ecs.Storage) {
 func spawnSimulationStatsWindow(storage *ecs.Storage) {
 	storage.Spawn(debugui.ImguiItem{
 		Render: func() {
-			var sim *SimulationMetrics
-			var gameTime *GameTime
-			var worldConfig *WorldConfig
-
-			if !storage.ReadSingleton(&sim) {
-				return
-			}
-			storage.ReadSingleton(&gameTime)
-			storage.ReadSingleton(&worldConfig)
-
-			var pauseState *PauseState
-			storage.ReadSingleton(&pauseState)
-
-			// Skip UI rendering during intermediate ticks
-			if pauseState != nil && pauseState.SkipUIRender {
+			sim, ok := ecs.GetSingletonT[SimulationMetrics](storage)
+			if !ok {
 				return
 			}
+			gameTime, _ := ecs.GetSingletonT[GameTime](storage)
+			worldConfig, _ := ecs.GetSingletonT[WorldConfig](storage)
 
 			imgui.SetNextWindowPosV(imgui.NewVec2(10, 270), imgui.CondOnce, imgui.NewVec2(0, 0))
 			imgui.SetNextWindowSizeV(imgui.NewVec2(300, 220), imgui.CondOnce)
@@ -117,14 +98,6 @@ func spawnSimulationStatsWindow(storage *ecs.Storage) {
 func spawnColonyInfoWindow(storage *ecs.Storage) {
 	storage.Spawn(debugui.ImguiItem{
 		Render: func() {
-			var pauseState *PauseState
-			storage.ReadSingleton(&pauseState)
-
-			// Skip UI rendering during intermediate ticks
-			if pauseState != nil && pauseState.SkipUIRender {
-				return
-			}
-
 			imgui.SetNextWindowPosV(imgui.NewVec2(320, 10), imgui.CondOnce, imgui.NewVec2(0, 0))
 			imgui.SetNextWindowSizeV(imgui.NewVec2(350, 400), imgui.CondOnce)
 
@@ -200,14 +173,6 @@ func spawnColonyInfoWindow(storage *ecs.Storage) {
 func spawnSystemPerformanceWindow(storage *ecs.Storage, scheduler *ecs.Scheduler) {
 	storage.Spawn(debugui.ImguiItem{
 		Render: func() {
-			var pauseState *PauseState
-			storage.ReadSingleton(&pauseState)
-
-			// Skip UI rendering during intermediate ticks
-			if pauseState != nil && pauseState.SkipUIRender {
-				return
-			}
-
 			stats := scheduler.GetStats()
 
 			imgui.SetNextWindowPosV(imgui.NewVec2(680, 10), imgui.CondOnce, imgui.NewVec2(0, 0))
@@ -218,7 +183,8 @@ func spawnSystemPerformanceWindow(storage *ecs.Storage, scheduler *ecs.Scheduler
 				imgui.Separator()
 
 				const tableFlags = imgui.TableFlagsBorders | imgui.TableFlagsRowBg | imgui.TableFlagsSortable | imgui.TableFlagsSizingFixedFit
-				if imgui.BeginTableV("Systems", 4, tableFlags, imgui.NewVec2(0, 0), 0) {
+				if imgui.BeginTableV("Systems", 5, tableFlags, imgui.NewVec2(0, 0), 0) {
+					imgui.TableSetupColumnV("", imgui.TableColumnFlagsNoSort, 0, 0)
 					imgui.TableSetupColumn("Name")
 					imgui.TableSetupColumn("Avg (ms)")
 					imgui.TableSetupColumn("Min (ms)")
@@ -234,13 +200,13 @@ func spawnSystemPerformanceWindow(storage *ecs.Storage, scheduler *ecs.Scheduler
 
 							var less bool
 							switch spec.ColumnIndex() {
-							case 0: // Name
+							case 1: // Name
 								less = left.Name < right.Name
-							case 1: // Avg (ms)
+							case 2: // Avg (ms)
 								less = left.AvgDuration < right.AvgDuration
-							case 2: // Min (ms)
+							case 3: // Min (ms)
 								less = left.MinDuration < right.MinDuration
-							case 3: // Max (ms)
+							case 4: // Max (ms)
 								less = left.MaxDuration < right.MaxDuration
 							}
 
@@ -254,6 +220,13 @@ func spawnSystemPerformanceWindow(storage *ecs.Storage, scheduler *ecs.Scheduler
 					for _, sys := range systems {
 						imgui.TableNextRow()
 
+						// Disabling a system keeps its stats, so its timings stay visible
+						imgui.TableNextColumn()
+						enabled := !sys.Disabled
+						if imgui.Checkbox("##enabled"+sys.Name, &enabled) {
+							scheduler.EnabledByName(sys.Name, enabled)
+						}
+
 						imgui.TableNextColumn()
 						imgui.Text(sys.Name)
 
@@ -278,21 +251,12 @@ func spawnSystemPerformanceWindow(storage *ecs.Storage, scheduler *ecs.Scheduler
 func spawnPerformanceChartWindow(storage *ecs.Storage, scheduler *ecs.Scheduler) {
 	storage.Spawn(debugui.ImguiItem{
 		Render: func() {
-			var perf *PerformanceMetrics
-			var chartData *PerformanceChart
-
-			if !storage.ReadSingleton(&perf) {
-				return
-			}
-			if !storage.ReadSingleton(&chartData) {
+			perf, ok := ecs.GetSingletonT[PerformanceMetrics](storage)
+			if !ok {
 				return
 			}
-
-			var pauseState *PauseState
-			storage.ReadSingleton(&pauseState)
-
-			// Skip UI rendering during intermediate ticks
-			if pauseState != nil && pauseState.SkipUIRender {
+			chartData, ok := ecs.GetSingletonT[PerformanceChart](storage)
+			if !ok {
 				return
 			}
 
@@ -443,88 +407,6 @@ func spawnPerformanceChartWindow(storage *ecs.Storage, scheduler *ecs.Scheduler)
 	})
 }
 
-func spawnPauseControlWindow(storage *ecs.Storage) {
-	storage.Spawn(debugui.ImguiItem{
-		Render: func() {
-			var pauseState *PauseState
-			if !storage.ReadSingleton(&pauseState) {
-				return
-			}
-
-			// Skip UI rendering during intermediate ticks
-			if pauseState.SkipUIRender {
-				return
-			}
-
-			imgui.SetNextWindowPosV(imgui.NewVec2(680, 420), imgui.CondOnce, imgui.NewVec2(0, 0))
-			imgui.SetNextWindowSizeV(imgui.NewVec2(250, 180), imgui.CondOnce)
-
-			if imgui.BeginV("Simulation Control", nil, 0) {
-				if pauseState.Paused {
-					imgui.PushStyleColorVec4(imgui.ColButton, imgui.NewVec4(0.2, 0.7, 0.2, 1.0))
-					imgui.PushStyleColorVec4(imgui.ColButtonHovered, imgui.NewVec4(0.3, 0.8, 0.3, 1.0))
-					imgui.PushStyleColorVec4(imgui.ColButtonActive, imgui.NewVec4(0.1, 0.6, 0.1, 1.0))
-					if imgui.Button("Resume") {
-						pauseState.Paused = false
-						pauseState.TimeToAdvance = 0
-						pauseState.TimeAdvanced = 0
-						pauseState.FramesToAdvance = 0
-					}
-					imgui.PopStyleColor()
-					imgui.PopStyleColor()
-					imgui.PopStyleColor()
-
-					imgui.TextColored(imgui.NewVec4(1.0, 0.8, 0.0, 1.0), "PAUSED")
-
-					// Show progress if advancing
-					if pauseState.TimeToAdvance > 0 {
-						progress := pauseState.TimeAdvanced / pauseState.TimeToAdvance
-						imgui.ProgressBarV(progress, imgui.NewVec2(-1, 0), fmt.Sprintf("%.1f/%.1fs", pauseState.TimeAdvanced, pauseState.TimeToAdvance))
-					}
-
-					imgui.Separator()
-					imgui.Text("Step Forward:")
-
-					if imgui.Button("1 Tick") {
-						pauseState.StepRequested = true
-					}
-
-					imgui.SameLine()
-					if imgui.Button("1 Second") {
-						pauseState.TimeToAdvance = 1.0
-						pauseState.TimeAdvanced = 0
-					}
-
-					if imgui.Button("5 Seconds") {
-						pauseState.TimeToAdvance = 5.0
-						pauseState.TimeAdvanced = 0
-					}
-
-					imgui.SameLine()
-					if imgui.Button("1 Minute") {
-						pauseState.TimeToAdvance = 60.0
-						pauseState.TimeAdvanced = 0
-					}
-				} else {
-					imgui.PushStyleColorVec4(imgui.ColButton, imgui.NewVec4(0.7, 0.2, 0.2, 1.0))
-					imgui.PushStyleColorVec4(imgui.ColButtonHovered, imgui.NewVec4(0.8, 0.3, 0.3, 1.0))
-					imgui.PushStyleColorVec4(imgui.ColButtonActive, imgui.NewVec4(0.6, 0.1, 0.1, 1.0))
-					if imgui.Button("Pause") {
-						pauseState.Paused = true
-					}
-					imgui.PopStyleColor()
-					imgui.PopStyleColor()
-					imgui.PopStyleColor()
-
-					imgui.TextColored(imgui.NewVec4(0.0, 1.0, 0.0, 1.0), "RUNNING")
-				}
-
-				imgui.End()
-			}
-		},
-	})
-}
-
 func initDebugUI(storage *ecs.Storage, scheduler *ecs.Scheduler) {
 	storage.AddSingleton(NewPerformanceChart())
 
@@ -533,5 +415,5 @@ func initDebugUI(storage *ecs.Storage, scheduler *ecs.Scheduler) {
 	spawnColonyInfoWindow(storage)
 	spawnSystemPerformanceWindow(storage, scheduler)
 	spawnPerformanceChartWindow(storage, scheduler)
-	spawnPauseControlWindow(storage)
+	storage.Spawn(debugui.NewTimeControlPanel(scheduler))
 }
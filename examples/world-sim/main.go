@@ -13,6 +13,7 @@ import (
 	"github.com/plus3/ooftn/ecs"
 	"github.com/plus3/ooftn/ecs/debugui"
 	debugui_ebiten "github.com/plus3/ooftn/ecs/debugui/ebiten"
+	"github.com/plus3/ooftn/ecs/util"
 )
 
 const (
@@ -87,7 +88,6 @@ func main() {
 	ecs.RegisterComponent[PerformanceMetrics](registry)
 	ecs.RegisterComponent[SimulationMetrics](registry)
 	ecs.RegisterComponent[PerformanceChart](registry)
-	ecs.RegisterComponent[PauseState](registry)
 
 	storage := ecs.NewStorage(registry)
 
@@ -121,12 +121,14 @@ func main() {
 
 	ecs.NewSingleton[InputState](storage, InputState{})
 	ecs.NewSingleton[SpatialGrid](storage, SpatialGrid{
-		CellSize: 10,
-		Cells:    make(map[[2]int][]ecs.EntityId),
+		ecs.NewIncrementalGrid(storage, 10, func(e GridEntity) (int, int) {
+			return e.GridPosition.X, e.GridPosition.Y
+		}),
 	})
 	ecs.NewSingleton[FighterGrid](storage, FighterGrid{
-		CellSize: 10,
-		Cells:    make(map[[2]int][]ecs.EntityId),
+		ecs.NewIncrementalGrid(storage, 10, func(e FighterGridEntity) (int, int) {
+			return e.GridPosition.X, e.GridPosition.Y
+		}),
 	})
 
 	ecs.NewSingleton[PendingDeaths](storage, PendingDeaths{
@@ -137,15 +139,12 @@ func main() {
 		LastFrameSamples: make([]float32, 0, 60),
 	})
 	ecs.NewSingleton[SimulationMetrics](storage, SimulationMetrics{})
-	ecs.NewSingleton[PauseState](storage, PauseState{
-		Paused:        false,
-		StepRequested: false,
-	})
 
 	initWorld(storage)
 
+	// Systems run in registration order, except that readers of GameTime and the spatial
+	// grids are scheduled after the systems tagged as writing them
 	scheduler := ecs.NewScheduler(storage)
-	scheduler.Register(&PauseControlSystem{})
 	scheduler.Register(&ClearPendingDeathsSystem{})
 	scheduler.Register(&MetricsSystem{})
 	scheduler.Register(&debugui.ImguiSystem{})
@@ -163,8 +162,10 @@ func main() {
 	scheduler.Register(&ResourceRegrowthSystem{})
 	scheduler.Register(&DeathSystem{})
 	scheduler.Register(&CameraControlSystem{})
+	scheduler.Register(&TuningReloadSystem{})
 
 	renderScheduler := ecs.NewScheduler(storage)
+	renderScheduler.SetReadOnly(true)
 	renderSystem := &RenderSystem{}
 	renderScheduler.Register(renderSystem)
 
@@ -175,7 +176,7 @@ func main() {
 		Scheduler:       scheduler,
 		RenderScheduler: renderScheduler,
 		RenderSystem:    renderSystem,
-		ImguiBackend:    ecs.NewSingleton[debugui_ebiten.ImguiBackend](storage),
+		ImguiBackend:    ecs.BindSingleton[debugui_ebiten.ImguiBackend](storage),
 		Screen:          ecs.NewSingleton[Screen](storage),
 	}
 
@@ -192,34 +193,12 @@ func (g *Game) Update() error {
 	var perf *PerformanceMetrics
 	g.Storage.ReadSingleton(&perf)
 
-	// Check if we're advancing time while paused - if so, run multiple ticks per frame
-	var pauseState *PauseState
-	g.Storage.ReadSingleton(&pauseState)
-
-	ticksPerFrame := 1
-	if pauseState != nil && pauseState.TimeToAdvance > 0 {
-		// Run 10 ticks per frame when advancing time (makes it 10x faster)
-		ticksPerFrame = 10
-	}
-
 	g.ImguiBackend.Get().BeginFrame()
 
-	for i := 0; i < ticksPerFrame; i++ {
-		// Only render UI on the last tick
-		if pauseState != nil {
-			pauseState.SkipUIRender = (i < ticksPerFrame-1)
-		}
-		g.Scheduler.Once(1.0 / 60.0)
-	}
+	timings := g.Scheduler.OnceTimed(1.0 / 60.0)
 
 	if perf != nil {
-		// Calculate actual update time from scheduler stats
-		stats := g.Scheduler.GetStats()
-		totalUpdateTime := float32(0)
-		for _, sys := range stats.Systems {
-			totalUpdateTime += float32(sys.AvgDuration.Seconds())
-		}
-		perf.UpdateTime = totalUpdateTime
+		perf.UpdateTime = float32(timings.Total.Seconds())
 	}
 
 	g.ImguiBackend.Get().EndFrame()
@@ -235,17 +214,11 @@ func (g *Game) Draw(screen *ebiten.Image) {
 
 	g.Screen.Get().Image = screen
 
-	g.RenderScheduler.Once(0)
+	timings := g.RenderScheduler.OnceTimed(0)
 
 	var perf *PerformanceMetrics
 	if g.Storage.ReadSingleton(&perf) {
-		// Calculate actual render time from render scheduler stats
-		stats := g.RenderScheduler.GetStats()
-		totalRenderTime := float32(0)
-		for _, sys := range stats.Systems {
-			totalRenderTime += float32(sys.AvgDuration.Seconds())
-		}
-		perf.RenderTime = totalRenderTime
+		perf.RenderTime = float32(timings.Total.Seconds())
 	}
 
 	g.ImguiBackend.Get().Draw(screen)
@@ -272,42 +245,43 @@ func initWorld(storage *ecs.Storage) {
 	}
 }
 
+type resourceKind struct {
+	Type   ResourceType
+	Color  [3]uint8
+	Amount int
+}
+
 func spawnResources(storage *ecs.Storage) {
-	for i := 0; i < 1800; i++ {
-		x := rand.IntN(WorldWidth)
-		y := rand.IntN(WorldHeight)
-
-		var resType ResourceType
-		var color [3]uint8
-		var amount int
-
-		roll := rand.Float32()
-		if roll < 0.5 {
-			resType = ResourceTree
-			color = [3]uint8{144, 238, 144}
-			amount = 20
-		} else if roll < 0.8 {
-			resType = ResourceRock
-			color = [3]uint8{169, 169, 169}
-			amount = 15
-		} else {
-			resType = ResourceBerryBush
-			color = [3]uint8{255, 182, 193}
-			amount = 10
-		}
+	const resourceCount = 1800
+	storage.Reserve(resourceCount, Position{}, GridPosition{}, Sprite{}, Resource{})
+
+	// Resources are laid out from the world seed so a seed reproduces the same map
+	var config *WorldConfig
+	storage.ReadSingleton(&config)
+	rng := rand.New(rand.NewPCG(uint64(config.Seed), 0))
+
+	var kinds util.WeightedTable[resourceKind]
+	kinds.Add(resourceKind{Type: ResourceTree, Color: [3]uint8{144, 238, 144}, Amount: 20}, 5)
+	kinds.Add(resourceKind{Type: ResourceRock, Color: [3]uint8{169, 169, 169}, Amount: 15}, 3)
+	kinds.Add(resourceKind{Type: ResourceBerryBush, Color: [3]uint8{255, 182, 193}, Amount: 10}, 2)
+
+	for i := 0; i < resourceCount; i++ {
+		x := rng.IntN(WorldWidth)
+		y := rng.IntN(WorldHeight)
+		kind := kinds.Pick(rng)
 
 		storage.Spawn(
 			Position{X: float32(x), Y: float32(y)},
 			GridPosition{X: x, Y: y},
 			Sprite{
-				Color: color,
+				Color: kind.Color,
 				Scale: 0.6,
 				Shape: ShapeSquare,
 			},
 			Resource{
-				Type:         resType,
-				Amount:       amount,
-				MaxAmount:    amount,
+				Type:         kind.Type,
+				Amount:       kind.Amount,
+				MaxAmount:    kind.Amount,
 				RegrowthRate: 0.01,
 			},
 		)
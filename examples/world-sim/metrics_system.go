@@ -15,14 +15,16 @@ type MetricsSystem struct {
 		*ColonyMember
 		*Task
 	}]
-	Colonies  ecs.Query[struct{ *Colony }]
 	Resources ecs.Query[struct{ *Resource }]
-	Dead      ecs.Query[struct{ *Dead }]
 
 	lastTime          time.Time
 	storageStatsCache *ecs.StorageStats
 }
 
+func (m *MetricsSystem) RunsWhilePaused() bool {
+	return true
+}
+
 func (m *MetricsSystem) Execute(frame *ecs.UpdateFrame) {
 	now := time.Now()
 	if !m.lastTime.IsZero() {
@@ -74,28 +76,11 @@ func (m *MetricsSystem) Execute(frame *ecs.UpdateFrame) {
 		}
 	}
 
-	colonyCount := 0
-	for range m.Colonies.Iter() {
-		colonyCount++
-	}
-
-	resourceCount := 0
-	totalResources := 0
-	for resource := range m.Resources.Iter() {
-		resourceCount++
-		totalResources += resource.Resource.Amount
-	}
-
-	deadCount := 0
-	for range m.Dead.Iter() {
-		deadCount++
-	}
-
 	sim := m.Simulation.Get()
 	sim.TotalPopulation = colonistCount
 	sim.ActiveTasks = activeTasks
-	sim.ColonyCount = colonyCount
-	sim.ResourceCount = resourceCount
-	sim.TotalResources = totalResources
-	sim.DeadCount = deadCount
+	sim.ColonyCount = ecs.Count[Colony](frame.Storage)
+	sim.ResourceCount = ecs.Count[Resource](frame.Storage)
+	sim.TotalResources = ecs.Sum(&m.Resources, func(r struct{ *Resource }) int { return r.Resource.Amount })
+	sim.DeadCount = ecs.Count[Dead](frame.Storage)
 }
@@ -11,67 +11,20 @@ import (
 	"github.com/plus3/ooftn/ecs/debugui"
 )
 
-type PauseControlSystem struct {
-	PauseState ecs.Singleton[PauseState]
-}
-
-func (s *PauseControlSystem) Execute(frame *ecs.UpdateFrame) {
-	pauseState := s.PauseState.Get()
-
-	// Handle time-based advancement
-	if pauseState.TimeToAdvance > 0 {
-		pauseState.TimeAdvanced += float32(frame.DeltaTime)
-
-		// Check if we've advanced enough time
-		if pauseState.TimeAdvanced >= pauseState.TimeToAdvance {
-			pauseState.TimeToAdvance = 0
-			pauseState.TimeAdvanced = 0
-			pauseState.FramesToAdvance = 0
-		}
-	} else if pauseState.FramesToAdvance > 0 {
-		// Handle frame-based advancement
-		pauseState.FramesToAdvance--
-		if pauseState.FramesToAdvance == 0 {
-			pauseState.TimeAdvanced = 0
-		}
-	}
-
-	// Reset step request after it's been processed
-	if pauseState.StepRequested {
-		pauseState.StepRequested = false
-	}
-}
-
-func (s *PauseControlSystem) ShouldRunSimulation() bool {
-	pauseState := s.PauseState.Get()
-	return !pauseState.Paused || pauseState.StepRequested || pauseState.FramesToAdvance > 0 || pauseState.TimeToAdvance > 0
-}
-
 type ClearPendingDeathsSystem struct {
-	PendingDeaths ecs.Singleton[PendingDeaths]
-	PauseState    ecs.Singleton[PauseState]
+	PendingDeaths *PendingDeaths
 }
 
 func (s *ClearPendingDeathsSystem) Execute(frame *ecs.UpdateFrame) {
-	pauseState := s.PauseState.Get()
-	if pauseState.Paused && !pauseState.StepRequested && pauseState.TimeToAdvance == 0 {
-		return
-	}
-	clear(s.PendingDeaths.Get().pending)
+	clear(s.PendingDeaths.pending)
 }
 
 type TimeSystem struct {
-	GameTime   ecs.Singleton[GameTime]
-	PauseState ecs.Singleton[PauseState]
+	GameTime *GameTime `ecs:"writes"`
 }
 
 func (s *TimeSystem) Execute(frame *ecs.UpdateFrame) {
-	pauseState := s.PauseState.Get()
-	if pauseState.Paused && !pauseState.StepRequested && pauseState.TimeToAdvance == 0 {
-		return
-	}
-
-	time := s.GameTime.Get()
+	time := s.GameTime
 	time.Elapsed += float32(frame.DeltaTime)
 
 	newDay := int(time.Elapsed / time.DayLength)
@@ -80,36 +33,17 @@ func (s *TimeSystem) Execute(frame *ecs.UpdateFrame) {
 	}
 }
 
+// SpatialGridSystem moves entities whose GridPosition changed to their new cell
 type SpatialGridSystem struct {
-	Grid       ecs.Singleton[SpatialGrid]
-	PauseState ecs.Singleton[PauseState]
-	Entities   ecs.Query[struct {
-		ecs.EntityId
-		*GridPosition
-	}]
+	Grid ecs.Singleton[SpatialGrid] `ecs:"writes"`
 }
 
 func (s *SpatialGridSystem) Execute(frame *ecs.UpdateFrame) {
-	pauseState := s.PauseState.Get()
-	if pauseState.Paused && !pauseState.StepRequested && pauseState.TimeToAdvance == 0 {
-		return
-	}
-
-	grid := s.Grid.Get()
-	clear(grid.Cells)
-
-	// Populate the grid
-	for entity := range s.Entities.Iter() {
-		cellX := entity.GridPosition.X / grid.CellSize
-		cellY := entity.GridPosition.Y / grid.CellSize
-		cellKey := [2]int{cellX, cellY}
-		grid.Cells[cellKey] = append(grid.Cells[cellKey], entity.EntityId)
-	}
+	s.Grid.Get().Update()
 }
 
 type ColonyManagementSystem struct {
-	PauseState ecs.Singleton[PauseState]
-	Colonies   ecs.Query[struct {
+	Colonies ecs.Query[struct {
 		ecs.EntityId
 		*Colony
 		*ColonyResources
@@ -124,11 +58,6 @@ type ColonyManagementSystem struct {
 }
 
 func (s *ColonyManagementSystem) Execute(frame *ecs.UpdateFrame) {
-	pauseState := s.PauseState.Get()
-	if pauseState.Paused && !pauseState.StepRequested && pauseState.TimeToAdvance == 0 {
-		return
-	}
-
 	for colony := range s.Colonies.Iter() {
 		population := 0
 		roleCount := make(map[RoleType]int)
@@ -180,8 +109,7 @@ func (s *ColonyManagementSystem) reassignRole(frame *ecs.UpdateFrame, colonyId e
 }
 
 type TaskAssignmentSystem struct {
-	PauseState ecs.Singleton[PauseState]
-	Colonists  ecs.Query[struct {
+	Colonists ecs.Query[struct {
 		ecs.EntityId
 		*ColonyMember
 		*Role
@@ -202,11 +130,6 @@ type TaskAssignmentSystem struct {
 }
 
 func (s *TaskAssignmentSystem) Execute(frame *ecs.UpdateFrame) {
-	pauseState := s.PauseState.Get()
-	if pauseState.Paused && !pauseState.StepRequested && pauseState.TimeToAdvance == 0 {
-		return
-	}
-
 	for colonist := range s.Colonists.Iter() {
 		if colonist.Task.Type != TaskIdle {
 			continue
@@ -306,8 +229,7 @@ func (s *TaskAssignmentSystem) assignFarmTask(frame *ecs.UpdateFrame, colonist s
 }
 
 type MovementSystem struct {
-	PauseState ecs.Singleton[PauseState]
-	Moving     ecs.Query[struct {
+	Moving ecs.Query[struct {
 		*Position
 		*GridPosition
 		*Task
@@ -317,11 +239,6 @@ type MovementSystem struct {
 }
 
 func (s *MovementSystem) Execute(frame *ecs.UpdateFrame) {
-	pauseState := s.PauseState.Get()
-	if pauseState.Paused && !pauseState.StepRequested && pauseState.TimeToAdvance == 0 {
-		return
-	}
-
 	for entity := range s.Moving.Iter() {
 		if entity.Task.Type == TaskIdle {
 			continue
@@ -355,8 +272,7 @@ func (s *MovementSystem) Execute(frame *ecs.UpdateFrame) {
 }
 
 type WorkSystem struct {
-	PauseState ecs.Singleton[PauseState]
-	Workers    ecs.Query[struct {
+	Workers ecs.Query[struct {
 		ecs.EntityId
 		*Task
 		*GridPosition
@@ -380,11 +296,6 @@ type WorkSystem struct {
 }
 
 func (s *WorkSystem) Execute(frame *ecs.UpdateFrame) {
-	pauseState := s.PauseState.Get()
-	if pauseState.Paused && !pauseState.StepRequested && pauseState.TimeToAdvance == 0 {
-		return
-	}
-
 	for worker := range s.Workers.Iter() {
 		if worker.Task.Type == TaskIdle || worker.Task.Type == TaskWander {
 			continue
@@ -526,41 +437,24 @@ func (s *WorkSystem) processReturn(frame *ecs.UpdateFrame, worker struct {
 }
 
 type HungerSystem struct {
-	PauseState ecs.Singleton[PauseState]
-	Living     ecs.Query[struct {
+	Living ecs.Query[struct {
 		ecs.EntityId
 		*Stats
 		*ColonyMember
-	}]
-	Colonies ecs.Query[struct {
-		ecs.EntityId
-		*ColonyResources
+		ColonyResources *ColonyResources `ecs:"via=ColonyMember.ColonyRef,optional"`
 	}]
 	PendingDeaths ecs.Singleton[PendingDeaths]
 }
 
 func (s *HungerSystem) Execute(frame *ecs.UpdateFrame) {
-	pauseState := s.PauseState.Get()
-	if pauseState.Paused && !pauseState.StepRequested && pauseState.TimeToAdvance == 0 {
-		return
-	}
-
 	pending := s.PendingDeaths.Get().pending
 	for entity := range s.Living.Iter() {
 		entity.Stats.Hunger += int(float32(frame.DeltaTime) * 2)
 
 		if entity.Stats.Hunger >= entity.Stats.MaxHunger {
-			if entity.ColonyMember.ColonyRef != nil {
-				colonyId, valid := frame.Storage.ResolveEntityRef(entity.ColonyMember.ColonyRef)
-				if valid {
-					for colony := range s.Colonies.Iter() {
-						if colony.EntityId == colonyId && colony.ColonyResources.Food > 0 {
-							colony.ColonyResources.Food--
-							entity.Stats.Hunger = 0
-							break
-						}
-					}
-				}
+			if entity.ColonyResources != nil && entity.ColonyResources.Food > 0 {
+				entity.ColonyResources.Food--
+				entity.Stats.Hunger = 0
 			}
 
 			if entity.Stats.Hunger >= entity.Stats.MaxHunger {
@@ -577,7 +471,6 @@ func (s *HungerSystem) Execute(frame *ecs.UpdateFrame) {
 }
 
 type ReproductionSystem struct {
-	PauseState       ecs.Singleton[PauseState]
 	FertileColonists ecs.Query[struct {
 		ecs.EntityId
 		*ColonyMember
@@ -590,15 +483,10 @@ type ReproductionSystem struct {
 		*Colony
 		*ColonyTraits
 	}]
-	GameTime ecs.Singleton[GameTime]
+	GameTime ecs.Singleton[GameTime] `ecs:"reads"`
 }
 
 func (s *ReproductionSystem) Execute(frame *ecs.UpdateFrame) {
-	pauseState := s.PauseState.Get()
-	if pauseState.Paused && !pauseState.StepRequested && pauseState.TimeToAdvance == 0 {
-		return
-	}
-
 	time := s.GameTime.Get()
 
 	for colonist := range s.FertileColonists.Iter() {
@@ -633,49 +521,23 @@ func (s *ReproductionSystem) Execute(frame *ecs.UpdateFrame) {
 
 // FighterGridSystem maintains a spatial grid containing only fighters
 type FighterGridSystem struct {
-	PauseState ecs.Singleton[PauseState]
-	Fighters   ecs.Query[struct {
-		ecs.EntityId
-		*GridPosition
-		*Combat
-	}]
-	FighterGrid ecs.Singleton[FighterGrid]
+	FighterGrid ecs.Singleton[FighterGrid] `ecs:"writes"`
 }
 
 func (s *FighterGridSystem) Execute(frame *ecs.UpdateFrame) {
-	pauseState := s.PauseState.Get()
-	if pauseState.Paused && !pauseState.StepRequested && pauseState.TimeToAdvance == 0 {
-		return
-	}
-
-	grid := s.FighterGrid.Get()
-
-	// Clear and rebuild - reuse slice capacity to avoid allocations
-	for k := range grid.Cells {
-		grid.Cells[k] = grid.Cells[k][:0]
-	}
-
-	// Rebuild with only fighters
-	for fighter := range s.Fighters.Iter() {
-		cellX := fighter.GridPosition.X / grid.CellSize
-		cellY := fighter.GridPosition.Y / grid.CellSize
-		cellKey := [2]int{cellX, cellY}
-
-		grid.Cells[cellKey] = append(grid.Cells[cellKey], fighter.EntityId)
-	}
+	s.FighterGrid.Get().Update()
 }
 
 type CombatSystem struct {
-	PauseState ecs.Singleton[PauseState]
-	Camera     ecs.Singleton[Camera]
-	Fighters   ecs.Query[struct {
+	Camera   ecs.Singleton[Camera]
+	Fighters ecs.Query[struct {
 		ecs.EntityId
 		*Combat
 		*GridPosition
 		*Stats
 		*ColonyMember
 	}]
-	FighterGrid   ecs.Singleton[FighterGrid]
+	FighterGrid   ecs.Singleton[FighterGrid] `ecs:"reads"`
 	PendingDeaths ecs.Singleton[PendingDeaths]
 
 	// Cache for fast lookups
@@ -694,14 +556,10 @@ type combatData struct {
 	colonyMember *ColonyMember
 	colonyId     ecs.EntityId // Cached resolved colony ID
 	hasColony    bool         // Whether colony ref is valid
+	birth        uint64       // Birth order, orders each pair of fighters deterministically
 }
 
 func (s *CombatSystem) Execute(frame *ecs.UpdateFrame) {
-	pauseState := s.PauseState.Get()
-	if pauseState.Paused && !pauseState.StepRequested && pauseState.TimeToAdvance == 0 {
-		return
-	}
-
 	camera := s.Camera.Get()
 	grid := s.FighterGrid.Get()
 	pending := s.PendingDeaths.Get().pending
@@ -743,6 +601,7 @@ func (s *CombatSystem) Execute(frame *ecs.UpdateFrame) {
 			colonyMember: fighter.ColonyMember,
 			colonyId:     colonyId,
 			hasColony:    hasColony,
+			birth:        frame.Storage.BirthOrder(fighter.EntityId),
 		}
 	}
 
@@ -783,20 +642,13 @@ func (s *CombatSystem) Execute(frame *ecs.UpdateFrame) {
 		// (f1X, f1Y already calculated above for rate limiting)
 		f1PosX := f1.GridPosition.X
 		f1PosY := f1.GridPosition.Y
-		f1EntityId := f1.EntityId
-		cellX := f1PosX / grid.CellSize
-		cellY := f1PosY / grid.CellSize
+		cell := grid.CellOf(f1PosX, f1PosY)
 
 		for dx := -1; dx <= 1; dx++ {
 			for dy := -1; dy <= 1; dy++ {
-				cellKey := [2]int{cellX + dx, cellY + dy}
-				entitiesInCell := grid.Cells[cellKey]
+				entitiesInCell := grid.Cell(cell[0]+dx, cell[1]+dy)
 
 				for _, entityId := range entitiesInCell {
-					if f1EntityId >= entityId {
-						continue
-					}
-
 					// Check pending without map lookup in hot path
 					if pending[entityId] {
 						continue
@@ -808,6 +660,13 @@ func (s *CombatSystem) Execute(frame *ecs.UpdateFrame) {
 						continue // Not in cache (shouldn't happen, but be defensive)
 					}
 
+					// Process each pair once, from its older fighter. Entity ids change as
+					// fighters move between archetypes and reuse slots, so they don't give
+					// a stable order.
+					if f1Data.birth >= f2Data.birth {
+						continue
+					}
+
 					// Use cached colony IDs instead of resolving refs
 					if !f2Data.hasColony || f1Data.colonyId == f2Data.colonyId {
 						continue // Same colony or no colony
@@ -865,22 +724,16 @@ func (s *CombatSystem) Execute(frame *ecs.UpdateFrame) {
 }
 
 type LifespanSystem struct {
-	PauseState ecs.Singleton[PauseState]
-	Aging      ecs.Query[struct {
+	Aging ecs.Query[struct {
 		ecs.EntityId
 		*Lifespan
 		*Stats
 	}]
-	GameTime      ecs.Singleton[GameTime]
+	GameTime      ecs.Singleton[GameTime] `ecs:"reads"`
 	PendingDeaths ecs.Singleton[PendingDeaths]
 }
 
 func (s *LifespanSystem) Execute(frame *ecs.UpdateFrame) {
-	pauseState := s.PauseState.Get()
-	if pauseState.Paused && !pauseState.StepRequested && pauseState.TimeToAdvance == 0 {
-		return
-	}
-
 	time := s.GameTime.Get()
 	pending := s.PendingDeaths.Get().pending
 
@@ -896,37 +749,25 @@ func (s *LifespanSystem) Execute(frame *ecs.UpdateFrame) {
 }
 
 type DeathSystem struct {
-	PauseState ecs.Singleton[PauseState]
-	Dead       ecs.Query[struct {
+	Dead ecs.Query[struct {
 		ecs.EntityId
 		*Dead
 	}]
 }
 
 func (s *DeathSystem) Execute(frame *ecs.UpdateFrame) {
-	pauseState := s.PauseState.Get()
-	if pauseState.Paused && !pauseState.StepRequested && pauseState.TimeToAdvance == 0 {
-		return
-	}
-
 	for entity := range s.Dead.Iter() {
 		frame.Commands.Delete(entity.EntityId)
 	}
 }
 
 type ResourceRegrowthSystem struct {
-	PauseState ecs.Singleton[PauseState]
-	Resources  ecs.Query[struct {
+	Resources ecs.Query[struct {
 		*Resource
 	}]
 }
 
 func (s *ResourceRegrowthSystem) Execute(frame *ecs.UpdateFrame) {
-	pauseState := s.PauseState.Get()
-	if pauseState.Paused && !pauseState.StepRequested && pauseState.TimeToAdvance == 0 {
-		return
-	}
-
 	for resource := range s.Resources.Iter() {
 		if resource.Resource.Amount < resource.Resource.MaxAmount {
 			resource.Resource.RegrowthTime += float32(frame.DeltaTime)
@@ -944,11 +785,15 @@ type CameraControlSystem struct {
 	ImguiInputState ecs.Singleton[debugui.ImguiInputState]
 }
 
+func (s *CameraControlSystem) RunsWhilePaused() bool {
+	return true
+}
+
 func (s *CameraControlSystem) Execute(frame *ecs.UpdateFrame) {
 	camera := s.Camera.Get()
 	input := s.InputState.Get()
 
-	imguiInput := s.ImguiInputState.Get()
+	imguiInput := s.ImguiInputState.GetOrCreate()
 
 	if imguiInput.WantCaptureMouse {
 		// reset input state?
@@ -1130,40 +975,28 @@ func (s *RenderSystem) Execute(frame *ecs.UpdateFrame) {
 	minWorldY := camera.Y - 20
 	maxWorldY := camera.Y + float32(camera.ScreenH)/(cellSize*camera.Zoom) + 20
 
-	minCellX := int(minWorldX) / grid.CellSize
-	maxCellX := int(maxWorldX) / grid.CellSize
-	minCellY := int(minWorldY) / grid.CellSize
-	maxCellY := int(maxWorldY) / grid.CellSize
-
 	// LOD: Skip rendering individual entities when zoomed out too far
 	// At low zoom levels, individual entities are tiny (< 2 pixels) and not visible anyway
 	skipDetailedRendering := camera.Zoom < 0.8
 
 	if !skipDetailedRendering {
-		for x := minCellX; x <= maxCellX; x++ {
-			for y := minCellY; y <= maxCellY; y++ {
-				cellKey := [2]int{x, y}
-				if entitiesInCell, ok := grid.Cells[cellKey]; ok {
-					for _, entityId := range entitiesInCell {
-						data, exists := s.entityCache[entityId]
-						if !exists {
-							continue
-						}
+		for entityId := range grid.InRect(int(minWorldX), int(minWorldY), int(maxWorldX), int(maxWorldY)) {
+			data, exists := s.entityCache[entityId]
+			if !exists {
+				continue
+			}
 
-						// Skip resources with 0 amount
-						if data.resource != nil && data.resource.Amount <= 0 {
-							continue
-						}
+			// Skip resources with 0 amount
+			if data.resource != nil && data.resource.Amount <= 0 {
+				continue
+			}
 
-						// Render the entity
-						s.renderEntity(screen, data.pos, data.sprite, camera, cellSize)
+			// Render the entity
+			s.renderEntity(screen, data.pos, data.sprite, camera, cellSize)
 
-						// Render health bar for colonists (skip at medium-low zoom)
-						if data.stats != nil && data.colonyMember != nil && camera.Zoom > 1.2 {
-							s.renderHealthBar(screen, data.pos, data.sprite, data.stats, camera, cellSize)
-						}
-					}
-				}
+			// Render health bar for colonists (skip at medium-low zoom)
+			if data.stats != nil && data.colonyMember != nil && camera.Zoom > 1.2 {
+				s.renderHealthBar(screen, data.pos, data.sprite, data.stats, camera, cellSize)
 			}
 		}
 	}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"log"
+	"os"
+	"time"
+
+	"github.com/plus3/ooftn/ecs"
+)
+
+// tuningFile is polled by TuningReloadSystem; patches in it are applied to live components
+const tuningFile = "tuning.json"
+
+// TuningReloadSystem applies tuning.json to live components whenever the file changes, e.g.
+//
+//	{"ColonyTraits": {"Reproduction": 0.4}, "Resource": {"RegrowthRate": 0.05}}
+//
+// so balance can be adjusted without restarting the simulation.
+type TuningReloadSystem struct {
+	lastCheck   time.Time
+	lastModTime time.Time
+}
+
+func (s *TuningReloadSystem) RunsWhilePaused() bool {
+	return true
+}
+
+func (s *TuningReloadSystem) Execute(frame *ecs.UpdateFrame) {
+	if time.Since(s.lastCheck) < time.Second {
+		return
+	}
+	s.lastCheck = time.Now()
+
+	info, err := os.Stat(tuningFile)
+	if err != nil || !info.ModTime().After(s.lastModTime) {
+		return
+	}
+	s.lastModTime = info.ModTime()
+
+	data, err := os.ReadFile(tuningFile)
+	if err != nil {
+		log.Printf("reading %s: %v", tuningFile, err)
+		return
+	}
+	if err := frame.Storage.ApplyConfigPatch(data); err != nil {
+		log.Printf("applying %s: %v", tuningFile, err)
+		return
+	}
+	log.Printf("applied %s", tuningFile)
+}